@@ -0,0 +1,170 @@
+// Command config-bundle exports an organization's configuration to a JSON bundle and
+// imports a bundle into another organization.
+//
+//	config-bundle export -org <id> [-out bundle.json]
+//	config-bundle import -org <id> -in bundle.json [-apply] [-prune]
+//
+// Import prints the diff and only changes the organization when -apply is given.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"flex-erp-poc/internal/database"
+	"flex-erp-poc/internal/models"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	// Load environment variables
+	if err := godotenv.Load(".env"); err != nil {
+		log.Println("No .env file found")
+	}
+
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL not set")
+	}
+
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		log.Fatalf("Failed to connect: %v", err)
+	}
+	defer db.Close()
+
+	dbService := &database.PostgresService{DB: db}
+
+	switch os.Args[1] {
+	case "export":
+		runExport(dbService, os.Args[2:])
+	case "import":
+		runImport(dbService, os.Args[2:])
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: config-bundle export -org <id> [-out file]")
+	fmt.Fprintln(os.Stderr, "       config-bundle import -org <id> -in file [-apply] [-prune]")
+	os.Exit(2)
+}
+
+func runExport(dbService *database.PostgresService, args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	orgID := flags.String("org", "", "organization ID to export")
+	out := flags.String("out", "", "file to write the bundle to (default stdout)")
+	flags.Parse(args)
+	if *orgID == "" {
+		usage()
+	}
+
+	bundle, err := dbService.ExportConfigBundle(context.Background(), *orgID)
+	if err != nil {
+		log.Fatalf("Failed to export configuration: %v", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", *out, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bundle); err != nil {
+		log.Fatalf("Failed to write bundle: %v", err)
+	}
+}
+
+func runImport(dbService *database.PostgresService, args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	orgID := flags.String("org", "", "organization ID to import into")
+	in := flags.String("in", "", "bundle file to import")
+	apply := flags.Bool("apply", false, "apply the changes instead of only showing them")
+	prune := flags.Bool("prune", false, "delete aliases and custom fields missing from the bundle")
+	flags.Parse(args)
+	if *orgID == "" || *in == "" {
+		usage()
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v", *in, err)
+	}
+
+	req := models.ImportConfigBundleRequest{Apply: *apply, Prune: *prune}
+	if err := json.Unmarshal(data, &req.Bundle); err != nil {
+		log.Fatalf("Failed to parse bundle: %v", err)
+	}
+
+	result, err := dbService.ImportConfigBundle(context.Background(), *orgID, req)
+	if err != nil {
+		log.Fatalf("Failed to import configuration: %v", err)
+	}
+
+	if len(result.Changes) == 0 {
+		fmt.Println("No changes")
+		return
+	}
+
+	for _, change := range result.Changes {
+		printChange(change)
+	}
+
+	if result.Applied {
+		fmt.Printf("\nApplied %d change(s)\n", len(result.Changes))
+	} else {
+		fmt.Printf("\n%d change(s), run again with -apply to apply them\n", len(result.Changes))
+	}
+}
+
+var changeMarkers = map[string]string{
+	models.ConfigActionCreate: "+",
+	models.ConfigActionUpdate: "~",
+	models.ConfigActionDelete: "-",
+	models.ConfigActionSkip:   "!",
+}
+
+func printChange(change *models.ConfigChange) {
+	line := fmt.Sprintf("%s %s", changeMarkers[change.Action], change.Section)
+	if change.Key != "" {
+		line += " " + change.Key
+	}
+	if change.Note != "" {
+		line += " (" + change.Note + ")"
+	}
+	fmt.Println(line)
+
+	if change.Before != nil {
+		fmt.Printf("    before: %s\n", compactJSON(change.Before))
+	}
+	if change.After != nil {
+		fmt.Printf("    after:  %s\n", compactJSON(change.After))
+	}
+}
+
+func compactJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -1,15 +1,24 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"flex-erp-poc/internal/database"
+	"flex-erp-poc/internal/events"
 	"flex-erp-poc/internal/handlers"
+	"flex-erp-poc/internal/jobs"
 	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+	"flex-erp-poc/internal/notifications"
+	"flex-erp-poc/internal/storage"
+	"flex-erp-poc/internal/webhooks"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
@@ -41,11 +50,158 @@ func main() {
 
 	log.Println("Successfully connected to database")
 
-	// Initialize handlers and middleware
+	// Initialize handlers and middleware. Each database operation is bounded by
+	// DB_QUERY_TIMEOUT and each statement of a report by DB_REPORT_TIMEOUT.
 	dbService := &database.PostgresService{DB: db}
-	h := &handlers.Handler{DB: dbService}
+	for name, timeout := range map[string]*time.Duration{
+		"DB_QUERY_TIMEOUT":  &dbService.QueryTimeout,
+		"DB_REPORT_TIMEOUT": &dbService.ReportTimeout,
+	} {
+		if value := os.Getenv(name); value != "" {
+			*timeout, err = time.ParseDuration(value)
+			if err != nil || *timeout <= 0 {
+				log.Fatalf("Invalid %s: %s", name, value)
+			}
+		}
+	}
+
+	// Attachment contents are kept on the local filesystem
+	storageDir := os.Getenv("ATTACHMENT_STORAGE_DIR")
+	if storageDir == "" {
+		storageDir = "uploads"
+	}
+	fileStorage, err := storage.NewLocalStorage(storageDir)
+	if err != nil {
+		log.Fatalf("Failed to open attachment storage: %v", err)
+	}
+	maxAttachmentBytes := int64(models.DefaultMaxAttachmentBytes)
+	if value := os.Getenv("ATTACHMENT_MAX_BYTES"); value != "" {
+		maxAttachmentBytes, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || maxAttachmentBytes <= 0 {
+			log.Fatalf("Invalid ATTACHMENT_MAX_BYTES: %s", value)
+		}
+	}
+
+	// Published outbox events reach live streams and other in-process subscribers
+	bus := events.NewBus()
+
+	h := &handlers.Handler{DB: dbService, Store: dbService, Storage: fileStorage, MaxAttachmentBytes: maxAttachmentBytes, Events: bus}
 	permMiddleware := middleware.NewPermissionMiddleware(dbService)
 
+	// Send queued webhook deliveries in the background
+	go webhooks.NewDispatcher(dbService).Run(5 * time.Second)
+
+	// Notify users of the events that concern them. Without SMTP_ADDR emails stay
+	// queued until a mail server is configured.
+	var mailer notifications.Mailer
+	if addr := os.Getenv("SMTP_ADDR"); addr != "" {
+		from := os.Getenv("SMTP_FROM")
+		if from == "" {
+			from = "Flex ERP <no-reply@localhost>"
+		}
+		mailer = notifications.NewSMTPMailer(addr, from, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"))
+	} else {
+		log.Println("SMTP_ADDR not set, notification emails will not be sent")
+	}
+	appURL := os.Getenv("APP_URL")
+	if appURL == "" {
+		appURL = "http://localhost:5173"
+	}
+	digestHour := 7
+	if value := os.Getenv("NOTIFICATION_DIGEST_HOUR"); value != "" {
+		digestHour, err = strconv.Atoi(value)
+		if err != nil || digestHour < 0 || digestHour > 23 {
+			log.Fatalf("Invalid NOTIFICATION_DIGEST_HOUR: %s", value)
+		}
+	}
+	notificationService := notifications.NewService(dbService, mailer, appURL)
+
+	// Publish outbox events to the webhook queue, to notifications, and to a file
+	// or URL when configured, retrying failures; then to in-process subscribers
+	sinks := []events.Sink{
+		events.NewHandlerSink("webhooks", func(event *models.OutboxEvent) error {
+			return dbService.QueueWebhookEvent(context.Background(), event)
+		}),
+		events.NewHandlerSink("notifications", notificationService.HandleEvent),
+	}
+	if path := os.Getenv("OUTBOX_FILE"); path != "" {
+		fileSink, err := events.NewFileSink(path)
+		if err != nil {
+			log.Fatalf("Failed to open outbox file: %v", err)
+		}
+		defer fileSink.Close()
+		sinks = append(sinks, fileSink)
+	}
+	if url := os.Getenv("OUTBOX_HTTP_URL"); url != "" {
+		sinks = append(sinks, events.NewHTTPSink(url))
+	}
+	go events.NewDispatcher(dbService, bus, sinks...).Run(time.Second)
+
+	// Recurring work runs on one server instance at a time, on a cron schedule in UTC
+	scheduler := jobs.NewScheduler(dbService)
+	for _, job := range []jobs.Job{
+		{
+			Name:        "reservation_expiry",
+			Description: "Release stock reservations past their expiry",
+			Schedule:    "* * * * *",
+			Run: func(ctx context.Context, _ time.Time) (string, error) {
+				released, err := dbService.ReleaseExpiredReservations(ctx)
+				return fmt.Sprintf("released %d reservations", released), err
+			},
+		},
+		{
+			Name:        "low_stock_evaluation",
+			Description: "Report stock at or below the low stock threshold that no issue reported",
+			Schedule:    "*/15 * * * *",
+			Run: func(ctx context.Context, _ time.Time) (string, error) {
+				reported, err := dbService.EvaluateLowStock(ctx)
+				return fmt.Sprintf("reported %d SKUs", reported), err
+			},
+		},
+		{
+			Name:        "notification_emails",
+			Description: "Send queued notification emails",
+			Schedule:    "* * * * *",
+			Run: func(ctx context.Context, _ time.Time) (string, error) {
+				sent, err := notificationService.SendDue(ctx)
+				return fmt.Sprintf("sent %d emails", sent), err
+			},
+		},
+		{
+			Name:        "notification_digest",
+			Description: "Queue the daily digest email of each user's notifications",
+			Schedule:    fmt.Sprintf("0 %d * * *", digestHour),
+			MaxRetries:  3,
+			Run: func(ctx context.Context, scheduledFor time.Time) (string, error) {
+				queued, err := notificationService.QueueDigests(ctx, scheduledFor)
+				return fmt.Sprintf("queued %d digests", queued), err
+			},
+		},
+		{
+			Name:        "outbox_cleanup",
+			Description: "Delete published outbox events past their retention",
+			Schedule:    "0 * * * *",
+			Run: func(ctx context.Context, scheduledFor time.Time) (string, error) {
+				deleted, err := dbService.DeletePublishedOutboxEvents(ctx, scheduledFor.Add(-events.Retention))
+				return fmt.Sprintf("deleted %d events", deleted), err
+			},
+		},
+		{
+			Name:        "job_run_cleanup",
+			Description: "Delete job run history older than 30 days",
+			Schedule:    "30 3 * * *",
+			Run: func(ctx context.Context, scheduledFor time.Time) (string, error) {
+				deleted, err := dbService.DeleteJobRuns(ctx, scheduledFor.AddDate(0, 0, -30))
+				return fmt.Sprintf("deleted %d runs", deleted), err
+			},
+		},
+	} {
+		if err := scheduler.Register(job); err != nil {
+			log.Fatalf("Failed to register job: %v", err)
+		}
+	}
+	go scheduler.Run(10 * time.Second)
+
 	// Setup routes
 	r := mux.NewRouter()
 
@@ -70,17 +226,155 @@ func main() {
 	api.HandleFunc("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}", h.GetSKU).Methods("GET")
 	api.HandleFunc("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}", h.UpdateSKU).Methods("PATCH")
 	api.HandleFunc("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/status", h.UpdateSKUStatus).Methods("PATCH")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/bulk",
+		permMiddleware.RequirePermission("skus", "update")(http.HandlerFunc(h.BulkUpdateSKUs))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/duplicates",
+		permMiddleware.RequirePermission("skus", "read")(http.HandlerFunc(h.FindDuplicateSKUs))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/merge",
+		permMiddleware.RequirePermission("skus", "delete")(http.HandlerFunc(h.MergeSKUs))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/barcodes",
+		permMiddleware.RequirePermission("skus", "read")(http.HandlerFunc(h.GetSKUBarcodes))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/barcodes",
+		permMiddleware.RequirePermission("skus", "update")(http.HandlerFunc(h.CreateSKUBarcode))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/barcodes/{barcodeId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("skus", "update")(http.HandlerFunc(h.DeleteSKUBarcode))).Methods("DELETE")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/attachments",
+		permMiddleware.RequirePermission("skus", "read")(http.HandlerFunc(h.GetSKUAttachments))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/attachments",
+		permMiddleware.RequirePermission("skus", "update")(http.HandlerFunc(h.UploadSKUAttachment))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/attachments/{attachmentId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("skus", "read")(http.HandlerFunc(h.GetSKUAttachmentContent))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/attachments/{attachmentId:[0-9a-f-]+}/thumbnail",
+		permMiddleware.RequirePermission("skus", "read")(http.HandlerFunc(h.GetSKUAttachmentThumbnail))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/attachments/{attachmentId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("skus", "update")(http.HandlerFunc(h.DeleteSKUAttachment))).Methods("DELETE")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/variants",
+		permMiddleware.RequirePermission("skus", "read")(http.HandlerFunc(h.GetSKUVariants))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/variants",
+		permMiddleware.RequirePermission("skus", "create")(http.HandlerFunc(h.GenerateSKUVariants))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/bom",
+		permMiddleware.RequirePermission("skus", "read")(http.HandlerFunc(h.GetKitComponents))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/bom",
+		permMiddleware.RequirePermission("skus", "update")(http.HandlerFunc(h.SetKitComponents))).Methods("PUT")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/assemble",
+		permMiddleware.RequirePermission("transactions", "create")(http.HandlerFunc(h.AssembleKit))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/disassemble",
+		permMiddleware.RequirePermission("transactions", "create")(http.HandlerFunc(h.DisassembleKit))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/kits",
+		permMiddleware.RequirePermission("skus", "read")(http.HandlerFunc(h.GetKits))).Methods("GET")
+
+	// Category routes
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/categories",
+		permMiddleware.RequirePermission("skus", "read")(http.HandlerFunc(h.GetCategories))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/categories",
+		permMiddleware.RequirePermission("skus", "create")(http.HandlerFunc(h.CreateCategory))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/categories/{categoryId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("skus", "read")(http.HandlerFunc(h.GetCategory))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/categories/{categoryId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("skus", "update")(http.HandlerFunc(h.UpdateCategory))).Methods("PATCH")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/categories/{categoryId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("skus", "delete")(http.HandlerFunc(h.DeleteCategory))).Methods("DELETE")
 
 	// Inventory routes
 	api.HandleFunc("/orgs/{orgId:[0-9a-f-]+}/inventory", h.GetInventory).Methods("GET")
 	api.HandleFunc("/orgs/{orgId:[0-9a-f-]+}/inventory", h.CreateInventory).Methods("POST")
 	api.HandleFunc("/orgs/{orgId:[0-9a-f-]+}/inventory/sku/{skuId:[0-9a-f-]+}", h.GetInventoryBySKU).Methods("GET")
 	api.HandleFunc("/orgs/{orgId:[0-9a-f-]+}/inventory/sku/{skuId:[0-9a-f-]+}/cost", h.UpdateManualCost).Methods("PATCH")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/inventory/sku/{skuId:[0-9a-f-]+}/custom-fields",
+		permMiddleware.RequirePermission("inventory", "update")(http.HandlerFunc(h.UpdateInventoryCustomFields))).Methods("PATCH")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/inventory/rollup",
+		permMiddleware.RequirePermission("inventory", "read")(http.HandlerFunc(h.GetCategoryInventoryRollup))).Methods("GET")
+
+	// Stock reservation routes
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/reservations",
+		permMiddleware.RequirePermission("inventory", "read")(http.HandlerFunc(h.GetReservations))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/reservations",
+		permMiddleware.RequirePermission("inventory", "update")(http.HandlerFunc(h.CreateReservation))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/reservations/{reservationId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("inventory", "read")(http.HandlerFunc(h.GetReservation))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/reservations/{reservationId:[0-9a-f-]+}/release",
+		permMiddleware.RequirePermission("inventory", "update")(http.HandlerFunc(h.ReleaseReservation))).Methods("POST")
 
 	// Transaction routes
 	api.HandleFunc("/orgs/{orgId:[0-9a-f-]+}/transactions", h.GetTransactions).Methods("GET")
 	api.HandleFunc("/orgs/{orgId:[0-9a-f-]+}/transactions", h.CreateTransaction).Methods("POST")
 	api.HandleFunc("/orgs/{orgId:[0-9a-f-]+}/transactions/summary", h.GetTransactionSummary).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/scan/in",
+		permMiddleware.RequirePermission("transactions", "create")(http.HandlerFunc(h.ScanIn))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/scan/out",
+		permMiddleware.RequirePermission("transactions", "create")(http.HandlerFunc(h.ScanOut))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/barcodes/{barcode}",
+		permMiddleware.RequirePermission("skus", "read")(http.HandlerFunc(h.LookupBarcode))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/transactions/summary/categories",
+		permMiddleware.RequirePermission("transactions", "read")(http.HandlerFunc(h.GetCategoryTransactionRollup))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/transactions/{transactionId:[0-9a-f-]+}/labels",
+		permMiddleware.RequirePermission("transactions", "read")(http.HandlerFunc(h.PrintTransactionLabels))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/transactions/{transactionId:[0-9a-f-]+}/attachments",
+		permMiddleware.RequirePermission("transactions", "read")(http.HandlerFunc(h.GetTransactionAttachments))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/transactions/{transactionId:[0-9a-f-]+}/attachments",
+		permMiddleware.RequirePermission("transactions", "create")(http.HandlerFunc(h.UploadTransactionAttachment))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/transactions/{transactionId:[0-9a-f-]+}/attachments/{attachmentId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("transactions", "read")(http.HandlerFunc(h.GetTransactionAttachmentContent))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/transactions/{transactionId:[0-9a-f-]+}/attachments/{attachmentId:[0-9a-f-]+}/thumbnail",
+		permMiddleware.RequirePermission("transactions", "read")(http.HandlerFunc(h.GetTransactionAttachmentThumbnail))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/transactions/{transactionId:[0-9a-f-]+}/attachments/{attachmentId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("transactions", "update")(http.HandlerFunc(h.DeleteTransactionAttachment))).Methods("DELETE")
+
+	// Label routes
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/labels",
+		permMiddleware.RequirePermission("skus", "read")(http.HandlerFunc(h.PrintLabels))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/label-templates",
+		permMiddleware.RequirePermission("skus", "read")(http.HandlerFunc(h.GetLabelTemplates))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/label-templates",
+		permMiddleware.RequirePermission("skus", "update")(http.HandlerFunc(h.CreateLabelTemplate))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/label-templates/{templateId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("skus", "update")(http.HandlerFunc(h.UpdateLabelTemplate))).Methods("PATCH")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/label-templates/{templateId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("skus", "update")(http.HandlerFunc(h.DeleteLabelTemplate))).Methods("DELETE")
+
+	// Purchase order routes
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/purchase-orders",
+		permMiddleware.RequirePermission("purchase_orders", "read")(http.HandlerFunc(h.GetPurchaseOrders))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/purchase-orders",
+		permMiddleware.RequirePermission("purchase_orders", "create")(http.HandlerFunc(h.CreatePurchaseOrder))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/purchase-orders/{purchaseOrderId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("purchase_orders", "read")(http.HandlerFunc(h.GetPurchaseOrder))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/purchase-orders/{purchaseOrderId:[0-9a-f-]+}/status",
+		permMiddleware.RequirePermission("purchase_orders", "update")(http.HandlerFunc(h.UpdatePurchaseOrderStatus))).Methods("PATCH")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/purchase-orders/{purchaseOrderId:[0-9a-f-]+}/receive",
+		permMiddleware.RequirePermission("purchase_orders", "receive")(http.HandlerFunc(h.ReceivePurchaseOrder))).Methods("POST")
+
+	// Sales order routes
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/sales-orders",
+		permMiddleware.RequirePermission("sales_orders", "read")(http.HandlerFunc(h.GetSalesOrders))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/sales-orders",
+		permMiddleware.RequirePermission("sales_orders", "create")(http.HandlerFunc(h.CreateSalesOrder))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/sales-orders/{salesOrderId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("sales_orders", "read")(http.HandlerFunc(h.GetSalesOrder))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/sales-orders/{salesOrderId:[0-9a-f-]+}/confirm",
+		permMiddleware.RequirePermission("sales_orders", "update")(http.HandlerFunc(h.ConfirmSalesOrder))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/sales-orders/{salesOrderId:[0-9a-f-]+}/allocate",
+		permMiddleware.RequirePermission("sales_orders", "update")(http.HandlerFunc(h.AllocateSalesOrder))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/sales-orders/{salesOrderId:[0-9a-f-]+}/ship",
+		permMiddleware.RequirePermission("sales_orders", "ship")(http.HandlerFunc(h.ShipSalesOrder))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/sales-orders/{salesOrderId:[0-9a-f-]+}/cancel",
+		permMiddleware.RequirePermission("sales_orders", "update")(http.HandlerFunc(h.CancelSalesOrder))).Methods("POST")
+
+	// Supplier routes
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/suppliers",
+		permMiddleware.RequirePermission("suppliers", "read")(http.HandlerFunc(h.GetSuppliers))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/suppliers",
+		permMiddleware.RequirePermission("suppliers", "create")(http.HandlerFunc(h.CreateSupplier))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/suppliers/{supplierId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("suppliers", "read")(http.HandlerFunc(h.GetSupplier))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/suppliers/{supplierId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("suppliers", "update")(http.HandlerFunc(h.UpdateSupplier))).Methods("PATCH")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/suppliers",
+		permMiddleware.RequirePermission("suppliers", "read")(http.HandlerFunc(h.GetSKUSuppliers))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/suppliers/{supplierId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("suppliers", "update")(http.HandlerFunc(h.UpsertSKUSupplier))).Methods("PUT")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/skus/{skuId:[0-9a-f-]+}/suppliers/{supplierId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("suppliers", "update")(http.HandlerFunc(h.DeleteSKUSupplier))).Methods("DELETE")
 
 	// User management routes (with role-based access control)
 	api.Handle("/orgs/{orgId:[0-9a-f-]+}/users",
@@ -107,6 +401,62 @@ func main() {
 	api.Handle("/orgs/{orgId:[0-9a-f-]+}/field-aliases/{aliasId:[0-9a-f-]+}",
 		permMiddleware.RequirePermission("settings", "update")(http.HandlerFunc(h.DeleteFieldAlias))).Methods("DELETE")
 
+	// Custom field routes (settings/customization feature)
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/custom-fields",
+		permMiddleware.RequirePermission("settings", "read")(http.HandlerFunc(h.GetCustomFields))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/custom-fields",
+		permMiddleware.RequirePermission("settings", "update")(http.HandlerFunc(h.CreateCustomField))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/custom-fields/{fieldId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("settings", "read")(http.HandlerFunc(h.GetCustomField))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/custom-fields/{fieldId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("settings", "update")(http.HandlerFunc(h.UpdateCustomField))).Methods("PATCH")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/custom-fields/{fieldId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("settings", "update")(http.HandlerFunc(h.DeleteCustomField))).Methods("DELETE")
+
+	// Field translation routes (settings/customization feature)
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/field-aliases/{aliasId:[0-9a-f-]+}/translations",
+		permMiddleware.RequirePermission("settings", "read")(http.HandlerFunc(h.GetFieldAliasTranslations))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/field-aliases/{aliasId:[0-9a-f-]+}/translations/{locale}",
+		permMiddleware.RequirePermission("settings", "update")(http.HandlerFunc(h.UpsertFieldAliasTranslation))).Methods("PUT")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/field-aliases/{aliasId:[0-9a-f-]+}/translations/{locale}",
+		permMiddleware.RequirePermission("settings", "update")(http.HandlerFunc(h.DeleteFieldAliasTranslation))).Methods("DELETE")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/custom-fields/{fieldId:[0-9a-f-]+}/translations",
+		permMiddleware.RequirePermission("settings", "read")(http.HandlerFunc(h.GetCustomFieldTranslations))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/custom-fields/{fieldId:[0-9a-f-]+}/translations/{locale}",
+		permMiddleware.RequirePermission("settings", "update")(http.HandlerFunc(h.UpsertCustomFieldTranslation))).Methods("PUT")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/custom-fields/{fieldId:[0-9a-f-]+}/translations/{locale}",
+		permMiddleware.RequirePermission("settings", "update")(http.HandlerFunc(h.DeleteCustomFieldTranslation))).Methods("DELETE")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/field-translations/missing",
+		permMiddleware.RequirePermission("settings", "read")(http.HandlerFunc(h.GetMissingFieldTranslations))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/default-locale",
+		permMiddleware.RequirePermission("settings", "read")(http.HandlerFunc(h.GetDefaultLocale))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/default-locale",
+		permMiddleware.RequirePermission("settings", "update")(http.HandlerFunc(h.UpdateDefaultLocale))).Methods("PUT")
+
+	// Business rules and configuration bundles
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/business-rules",
+		permMiddleware.RequirePermission("settings", "read")(http.HandlerFunc(h.GetBusinessRules))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/business-rules",
+		permMiddleware.RequirePermission("settings", "update")(http.HandlerFunc(h.UpdateBusinessRules))).Methods("PUT")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/config/export",
+		permMiddleware.RequirePermission("settings", "read")(http.HandlerFunc(h.ExportConfigBundle))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/config/import",
+		permMiddleware.RequirePermission("settings", "update")(http.HandlerFunc(h.ImportConfigBundle))).Methods("POST")
+
+	// Saved view routes
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/views",
+		permMiddleware.RequirePermission("views", "read")(http.HandlerFunc(h.GetSavedViews))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/views",
+		permMiddleware.RequirePermission("views", "create")(http.HandlerFunc(h.CreateSavedView))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/views/{viewId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("views", "read")(http.HandlerFunc(h.GetSavedView))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/views/{viewId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("views", "update")(http.HandlerFunc(h.UpdateSavedView))).Methods("PATCH")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/views/{viewId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("views", "delete")(http.HandlerFunc(h.DeleteSavedView))).Methods("DELETE")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/views/{viewId:[0-9a-f-]+}/apply",
+		permMiddleware.RequirePermission("views", "read")(http.HandlerFunc(h.ApplySavedView))).Methods("GET")
+
 	// Table fields management - get customized fields for a specific table
 	api.Handle("/orgs/{orgId:[0-9a-f-]+}/tables/{tableName}/fields",
 		permMiddleware.RequirePermission("settings", "read")(http.HandlerFunc(h.GetTableFields))).Methods("GET")
@@ -127,6 +477,52 @@ func main() {
 	api.Handle("/orgs/{orgId:[0-9a-f-]+}/activity-summary",
 		permMiddleware.RequirePermission("logs", "read")(http.HandlerFunc(h.GetActivitySummary))).Methods("GET")
 
+	// Webhook routes
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/webhooks",
+		permMiddleware.RequirePermission("settings", "read")(http.HandlerFunc(h.GetWebhookEndpoints))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/webhooks",
+		permMiddleware.RequirePermission("settings", "update")(http.HandlerFunc(h.CreateWebhookEndpoint))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/webhooks/{webhookId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("settings", "read")(http.HandlerFunc(h.GetWebhookEndpoint))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/webhooks/{webhookId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("settings", "update")(http.HandlerFunc(h.UpdateWebhookEndpoint))).Methods("PATCH")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/webhooks/{webhookId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("settings", "update")(http.HandlerFunc(h.DeleteWebhookEndpoint))).Methods("DELETE")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/webhooks/{webhookId:[0-9a-f-]+}/deliveries",
+		permMiddleware.RequirePermission("settings", "read")(http.HandlerFunc(h.GetWebhookDeliveries))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/webhooks/{webhookId:[0-9a-f-]+}/deliveries/{deliveryId:[0-9a-f-]+}/redeliver",
+		permMiddleware.RequirePermission("settings", "update")(http.HandlerFunc(h.RedeliverWebhook))).Methods("POST")
+
+	// Notification routes, for the signed-in user's own inbox and preferences
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/notifications",
+		permMiddleware.RequirePermission("notifications", "read")(http.HandlerFunc(h.GetNotifications))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/notifications/unread-count",
+		permMiddleware.RequirePermission("notifications", "read")(http.HandlerFunc(h.GetUnreadNotificationCount))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/notifications/read-all",
+		permMiddleware.RequirePermission("notifications", "update")(http.HandlerFunc(h.MarkAllNotificationsRead))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/notifications/{notificationId:[0-9a-f-]+}",
+		permMiddleware.RequirePermission("notifications", "update")(http.HandlerFunc(h.UpdateNotification))).Methods("PATCH")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/notification-preferences",
+		permMiddleware.RequirePermission("notifications", "read")(http.HandlerFunc(h.GetNotificationPreferences))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/notification-preferences",
+		permMiddleware.RequirePermission("notifications", "update")(http.HandlerFunc(h.UpdateNotificationPreferences))).Methods("PUT")
+
+	// Background job routes, server-wide and for admins only
+	api.Handle("/admin/jobs",
+		permMiddleware.RequireRole("admin")(http.HandlerFunc(h.GetScheduledJobs))).Methods("GET")
+	api.Handle("/admin/jobs/{jobName:[a-z0-9_]+}",
+		permMiddleware.RequireRole("admin")(http.HandlerFunc(h.GetScheduledJob))).Methods("GET")
+	api.Handle("/admin/jobs/{jobName:[a-z0-9_]+}",
+		permMiddleware.RequireRole("admin")(http.HandlerFunc(h.UpdateScheduledJob))).Methods("PATCH")
+	api.Handle("/admin/jobs/{jobName:[a-z0-9_]+}/run",
+		permMiddleware.RequireRole("admin")(http.HandlerFunc(h.TriggerScheduledJob))).Methods("POST")
+	api.Handle("/admin/jobs/{jobName:[a-z0-9_]+}/runs",
+		permMiddleware.RequireRole("admin")(http.HandlerFunc(h.GetJobRuns))).Methods("GET")
+
+	// Live event stream
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/events/stream",
+		permMiddleware.RequirePermission("inventory", "read")(http.HandlerFunc(h.StreamEvents))).Methods("GET")
+
 	// CORS setup
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:5173", "http://localhost:3000"},
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"flex-erp-poc/internal/models"
+)
+
+const attachmentSelect = `
+	SELECT a.id, a.organization_id, a.entity_type, a.entity_id, a.file_name, a.content_type, a.size_bytes,
+		a.checksum, a.uploaded_by, COALESCE(u.name, ''), a.created_at, a.storage_key, a.thumbnail_key
+	FROM attachments a
+	LEFT JOIN users u ON u.id = a.uploaded_by`
+
+func scanAttachment(row rowScanner) (*models.Attachment, error) {
+	attachment := &models.Attachment{}
+	err := row.Scan(
+		&attachment.ID,
+		&attachment.OrganizationID,
+		&attachment.EntityType,
+		&attachment.EntityID,
+		&attachment.FileName,
+		&attachment.ContentType,
+		&attachment.SizeBytes,
+		&attachment.Checksum,
+		&attachment.UploadedBy,
+		&attachment.UploadedByName,
+		&attachment.CreatedAt,
+		&attachment.StorageKey,
+		&attachment.ThumbnailKey,
+	)
+	if err != nil {
+		return nil, err
+	}
+	attachment.HasThumbnail = attachment.ThumbnailKey != nil
+	return attachment, nil
+}
+
+// Attachment Methods
+
+// CheckAttachmentEntity reports whether the SKU or transaction files are
+// attached to exists in the organization
+func (p *PostgresService) CheckAttachmentEntity(ctx context.Context, organizationID, entityType, entityID string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	table, notFound := "skus", fmt.Errorf("SKU not found")
+	if entityType == "transaction" {
+		table, notFound = "transactions", fmt.Errorf("transaction not found")
+	}
+	if !isUUID(entityID) {
+		return notFound
+	}
+
+	var exists bool
+	err := p.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE organization_id = $1 AND id = $2)`,
+		organizationID, entityID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return notFound
+	}
+	return nil
+}
+
+func (p *PostgresService) GetAttachments(ctx context.Context, organizationID, entityType, entityID string) ([]*models.Attachment, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if err := p.CheckAttachmentEntity(ctx, organizationID, entityType, entityID); err != nil {
+		return nil, err
+	}
+
+	rows, err := p.DB.QueryContext(ctx, attachmentSelect+`
+		WHERE a.organization_id = $1 AND a.entity_type = $2 AND a.entity_id = $3
+		ORDER BY a.created_at DESC, a.id
+	`, organizationID, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := make([]*models.Attachment, 0)
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, rows.Err()
+}
+
+func (p *PostgresService) GetAttachment(ctx context.Context, organizationID, entityType, entityID, attachmentID string) (*models.Attachment, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if !isUUID(entityID) || !isUUID(attachmentID) {
+		return nil, fmt.Errorf("attachment not found")
+	}
+	attachment, err := scanAttachment(p.DB.QueryRowContext(ctx, attachmentSelect+`
+		WHERE a.organization_id = $1 AND a.entity_type = $2 AND a.entity_id = $3 AND a.id = $4
+	`, organizationID, entityType, entityID, attachmentID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("attachment not found")
+	}
+	return attachment, err
+}
+
+// CreateAttachment records the metadata of a file already written to storage
+func (p *PostgresService) CreateAttachment(ctx context.Context, organizationID, userID string, attachment *models.Attachment) (*models.Attachment, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var attachmentID string
+	err := p.DB.QueryRowContext(ctx, `
+		INSERT INTO attachments (organization_id, entity_type, entity_id, file_name, content_type, size_bytes,
+			checksum, storage_key, thumbnail_key, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id
+	`, organizationID, attachment.EntityType, attachment.EntityID, attachment.FileName, attachment.ContentType,
+		attachment.SizeBytes, attachment.Checksum, attachment.StorageKey, attachment.ThumbnailKey, userID).Scan(&attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.GetAttachment(ctx, organizationID, attachment.EntityType, attachment.EntityID, attachmentID)
+}
+
+// DeleteAttachment removes the metadata and returns it, so the caller can remove
+// the stored files
+func (p *PostgresService) DeleteAttachment(ctx context.Context, organizationID, entityType, entityID, attachmentID string) (*models.Attachment, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	attachment, err := p.GetAttachment(ctx, organizationID, entityType, entityID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.DB.ExecContext(ctx, `DELETE FROM attachments WHERE organization_id = $1 AND id = $2`, organizationID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
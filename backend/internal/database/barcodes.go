@@ -0,0 +1,239 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/models"
+	"flex-erp-poc/internal/utils"
+
+	"github.com/lib/pq"
+)
+
+const skuBarcodeColumns = `id, sku_id, barcode, unit_of_measure, units_per_scan, created_at, updated_at`
+
+func scanSKUBarcode(row rowScanner) (*models.SKUBarcode, error) {
+	barcode := &models.SKUBarcode{}
+	err := row.Scan(
+		&barcode.ID,
+		&barcode.SKUID,
+		&barcode.Barcode,
+		&barcode.UnitOfMeasure,
+		&barcode.UnitsPerScan,
+		&barcode.CreatedAt,
+		&barcode.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return barcode, nil
+}
+
+// normalizeSKUBarcode validates the primary barcode of a SKU being saved. An empty
+// barcode clears it.
+func normalizeSKUBarcode(ctx context.Context, q queryer, organizationID string, skuID *string, barcode *string) (*string, error) {
+	if barcode == nil || strings.TrimSpace(*barcode) == "" {
+		return nil, nil
+	}
+
+	code, err := utils.ValidateBarcode(*barcode)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBarcodeAvailable(ctx, q, organizationID, code, skuID); err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// checkBarcodeAvailable rejects a code, or a GTIN form of it, already used as a
+// primary or alternate barcode in the organization. The primary barcode of skuID
+// does not count, so a SKU can be saved with its own barcode.
+func checkBarcodeAvailable(ctx context.Context, q queryer, organizationID, code string, skuID *string) error {
+	var skuCode string
+	err := q.QueryRowContext(ctx, `
+		SELECT s.sku_code FROM skus s
+		WHERE s.organization_id = $1 AND s.barcode = ANY($2) AND s.merged_into_sku_id IS NULL
+			AND s.id IS DISTINCT FROM $3
+		UNION ALL
+		SELECT s.sku_code FROM sku_barcodes b
+		JOIN skus s ON s.id = b.sku_id
+		WHERE b.organization_id = $1 AND b.barcode = ANY($2)
+		LIMIT 1
+	`, organizationID, pq.Array(utils.BarcodeLookupKeys(code)), skuID).Scan(&skuCode)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("duplicate barcode: %s is already used by SKU %s", code, skuCode)
+}
+
+// Barcode Methods
+
+// LookupBarcode resolves a scanned code to its SKU, trying the primary barcodes
+// before alternates. The match carries the SKU's current inventory, if any.
+func (p *PostgresService) LookupBarcode(ctx context.Context, organizationID, code string) (*models.BarcodeMatch, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil, fmt.Errorf("barcode not found")
+	}
+
+	match := &models.BarcodeMatch{}
+	var skuID string
+	err := p.DB.QueryRowContext(ctx, `
+		SELECT sku_id, barcode, is_primary, unit_of_measure, units_per_scan
+		FROM (
+			SELECT COALESCE(s.merged_into_sku_id, s.id) AS sku_id, s.barcode, true AS is_primary,
+				'each' AS unit_of_measure, 1 AS units_per_scan, s.merged_into_sku_id IS NOT NULL AS via_merge
+			FROM skus s
+			WHERE s.organization_id = $1 AND s.barcode = ANY($2)
+			UNION ALL
+			SELECT b.sku_id, b.barcode, false, b.unit_of_measure, b.units_per_scan, false
+			FROM sku_barcodes b
+			WHERE b.organization_id = $1 AND b.barcode = ANY($2)
+		) matches
+		ORDER BY via_merge, is_primary DESC
+		LIMIT 1
+	`, organizationID, pq.Array(utils.BarcodeLookupKeys(code))).Scan(
+		&skuID, &match.Barcode, &match.IsPrimary, &match.UnitOfMeasure, &match.UnitsPerScan)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("barcode not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	match.SKU, err = p.GetSKUByID(ctx, organizationID, skuID)
+	if err != nil {
+		return nil, err
+	}
+	match.Inventory, err = p.GetInventoryBySKUID(ctx, organizationID, skuID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return match, nil
+}
+
+func (p *PostgresService) GetSKUBarcodes(ctx context.Context, organizationID, skuID string) ([]*models.SKUBarcode, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT `+skuBarcodeColumns+`
+		FROM sku_barcodes
+		WHERE organization_id = $1 AND sku_id = $2
+		ORDER BY units_per_scan, barcode
+	`, organizationID, skuID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	barcodes := make([]*models.SKUBarcode, 0)
+	for rows.Next() {
+		barcode, err := scanSKUBarcode(rows)
+		if err != nil {
+			return nil, err
+		}
+		barcodes = append(barcodes, barcode)
+	}
+	return barcodes, rows.Err()
+}
+
+func (p *PostgresService) CreateSKUBarcode(ctx context.Context, organizationID, skuID string, req models.CreateSKUBarcodeRequest) (*models.SKUBarcode, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	code, err := utils.ValidateBarcode(req.Barcode)
+	if err != nil {
+		return nil, err
+	}
+	unitOfMeasure := strings.TrimSpace(req.UnitOfMeasure)
+	if unitOfMeasure == "" {
+		unitOfMeasure = "each"
+	}
+	if len(unitOfMeasure) > 20 {
+		return nil, fmt.Errorf("invalid barcode: unit of measure must be at most 20 characters")
+	}
+	unitsPerScan := req.UnitsPerScan
+	if unitsPerScan == 0 {
+		unitsPerScan = 1
+	}
+	if unitsPerScan < 0 {
+		return nil, fmt.Errorf("invalid barcode: units per scan must be positive")
+	}
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM skus WHERE organization_id = $1 AND id = $2)`, organizationID, skuID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("SKU not found")
+	}
+
+	if err := checkBarcodeAvailable(ctx, tx, organizationID, code, nil); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	barcode, err := scanSKUBarcode(tx.QueryRowContext(ctx, `
+		INSERT INTO sku_barcodes (organization_id, sku_id, barcode, unit_of_measure, units_per_scan, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		RETURNING `+skuBarcodeColumns,
+		organizationID, skuID, code, unitOfMeasure, unitsPerScan, now))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeSKUEvent(ctx, tx, organizationID, "sku.updated", skuID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return barcode, nil
+}
+
+func (p *PostgresService) DeleteSKUBarcode(ctx context.Context, organizationID, skuID, barcodeID string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM sku_barcodes WHERE organization_id = $1 AND sku_id = $2 AND id = $3`,
+		organizationID, skuID, barcodeID)
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return fmt.Errorf("barcode not found")
+	}
+
+	if err := writeSKUEvent(ctx, tx, organizationID, "sku.updated", skuID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
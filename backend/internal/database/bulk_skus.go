@@ -0,0 +1,318 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/models"
+)
+
+// maxBulkSKUs caps the SKUs one bulk request may touch
+const maxBulkSKUs = 500
+
+// bulkSKUChange is an action resolved once for the whole batch
+type bulkSKUChange struct {
+	action       string
+	categoryID   *string
+	categoryPath *string
+	supplier     string
+	reason       *string
+	metadata     json.RawMessage
+}
+
+// bulkItemError is why one SKU of a batch failed, worded for the client. Any
+// other error is logged and reported as errBulkItemFailed.
+type bulkItemError string
+
+func (e bulkItemError) Error() string {
+	return string(e)
+}
+
+const errBulkItemFailed = "SKU could not be updated"
+
+// bulkItemMessage is the result error reported for a failed SKU
+func bulkItemMessage(skuID string, err error) string {
+	var itemErr bulkItemError
+	if errors.As(err, &itemErr) {
+		return itemErr.Error()
+	}
+	log.Printf("Bulk SKU action failed for %s: %v", skuID, err)
+	return errBulkItemFailed
+}
+
+// Bulk SKU Methods
+
+// BulkUpdateSKUs applies one action to many SKUs in a single database transaction.
+// Each SKU runs under its own savepoint so a failing item is reported without
+// undoing the others, unless AllOrNothing is set. filter selects the SKUs when the
+// request has no IDs.
+func (p *PostgresService) BulkUpdateSKUs(ctx context.Context, organizationID, userID string, req models.BulkSKURequest, filter *models.SKUListParams) (*models.BulkSKUResponse, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if !containsString(models.BulkSKUActions, req.Action) {
+		return nil, fmt.Errorf("invalid bulk request: unsupported action %s, expected one of %s", req.Action, strings.Join(models.BulkSKUActions, ", "))
+	}
+
+	skuIDs, err := p.bulkSKUIDs(ctx, organizationID, req.SKUIDs, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	response := &models.BulkSKUResponse{Action: req.Action, Results: make([]*models.BulkSKUResult, 0, len(skuIDs))}
+	if err := tx.QueryRowContext(ctx, `SELECT gen_random_uuid()`).Scan(&response.BatchID); err != nil {
+		return nil, err
+	}
+
+	change, err := resolveBulkSKUChange(ctx, tx, organizationID, req, response.BatchID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, skuID := range skuIDs {
+		result := &models.BulkSKUResult{SKUID: skuID}
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT bulk_sku`); err != nil {
+			return nil, err
+		}
+
+		changed, err := applyBulkSKUChange(ctx, tx, organizationID, userID, skuID, change, result)
+		switch {
+		case err != nil:
+			if _, rbErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT bulk_sku`); rbErr != nil {
+				return nil, rbErr
+			}
+			result.Status = "failed"
+			result.Error = bulkItemMessage(skuID, err)
+			response.Failed++
+		case changed:
+			result.Status = "updated"
+			response.Updated++
+		default:
+			result.Status = "unchanged"
+			response.Unchanged++
+		}
+
+		if err == nil {
+			if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT bulk_sku`); err != nil {
+				return nil, err
+			}
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	if req.AllOrNothing && response.Failed > 0 {
+		return response, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	response.Applied = true
+	return response, nil
+}
+
+// bulkSKUIDs returns the SKUs a bulk request targets, either the listed IDs without
+// duplicates or every SKU the filter matches
+func (p *PostgresService) bulkSKUIDs(ctx context.Context, organizationID string, ids []string, filter *models.SKUListParams) ([]string, error) {
+	if len(ids) > 0 && filter != nil {
+		return nil, fmt.Errorf("invalid bulk request: give either sku_ids or filter, not both")
+	}
+
+	if filter == nil {
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("invalid bulk request: sku_ids or filter is required")
+		}
+		seen := make(map[string]bool, len(ids))
+		unique := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				unique = append(unique, id)
+			}
+		}
+		if len(unique) > maxBulkSKUs {
+			return nil, fmt.Errorf("invalid bulk request: at most %d SKUs per request", maxBulkSKUs)
+		}
+		return unique, nil
+	}
+
+	params := *filter
+	params.Sort, params.Cursor, params.Page, params.Limit = "", "", 1, maxBulkSKUs
+	skus, page, err := p.GetSKUs(ctx, organizationID, params)
+	if err != nil {
+		return nil, err
+	}
+	if page.Total > maxBulkSKUs {
+		return nil, fmt.Errorf("invalid bulk request: filter matches %d SKUs, at most %d per request", page.Total, maxBulkSKUs)
+	}
+
+	skuIDs := make([]string, 0, len(skus))
+	for _, sku := range skus {
+		skuIDs = append(skuIDs, sku.ID)
+	}
+	return skuIDs, nil
+}
+
+// resolveBulkSKUChange looks up the category or supplier an action sets, creating
+// it when given by name, and builds the change log metadata shared by the batch
+func resolveBulkSKUChange(ctx context.Context, q queryer, organizationID string, req models.BulkSKURequest, batchID string) (*bulkSKUChange, error) {
+	change := &bulkSKUChange{action: req.Action, reason: req.Reason}
+
+	switch req.Action {
+	case "set_category":
+		categoryID, categoryPath, err := resolveSKUCategory(ctx, q, organizationID, req.CategoryID, req.Category)
+		if err != nil {
+			return nil, err
+		}
+		if categoryID == nil {
+			return nil, fmt.Errorf("invalid bulk request: category_id or category is required for set_category")
+		}
+		change.categoryID, change.categoryPath = categoryID, categoryPath
+	case "set_supplier":
+		switch {
+		case req.SupplierID != nil && *req.SupplierID != "":
+			supplier, err := getSupplier(ctx, q, organizationID, *req.SupplierID)
+			if err != nil {
+				return nil, err
+			}
+			change.supplier = supplier.Name
+		case req.Supplier != nil && strings.TrimSpace(*req.Supplier) != "":
+			change.supplier = strings.TrimSpace(*req.Supplier)
+		default:
+			return nil, fmt.Errorf("invalid bulk request: supplier_id or supplier is required for set_supplier")
+		}
+	}
+
+	if change.reason == nil || *change.reason == "" {
+		reason := "Bulk " + strings.ReplaceAll(req.Action, "_", " ")
+		change.reason = &reason
+	}
+
+	metadata, err := json.Marshal(map[string]string{"batch_id": batchID, "bulk_action": req.Action})
+	if err != nil {
+		return nil, err
+	}
+	change.metadata = metadata
+	return change, nil
+}
+
+// applyBulkSKUChange applies the batch action to one locked SKU and logs it. It
+// reports false when the SKU already matched the action.
+func applyBulkSKUChange(ctx context.Context, tx *sql.Tx, organizationID, userID, skuID string, change *bulkSKUChange, result *models.BulkSKUResult) (bool, error) {
+	if !isUUID(skuID) {
+		return false, bulkItemError("SKU not found")
+	}
+
+	var archived bool
+	sku, err := scanSKU(tx.QueryRowContext(ctx, `
+		SELECT `+skuColumns+`, archived_at IS NOT NULL
+		FROM skus
+		WHERE organization_id = $1 AND id = $2
+		FOR UPDATE
+	`, organizationID, skuID), &archived)
+	if err == sql.ErrNoRows {
+		return false, bulkItemError("SKU not found")
+	}
+	if err != nil {
+		return false, err
+	}
+	result.SKUCode = &sku.SKUCode
+
+	logReq := models.NewSKUChangeLog(organizationID, userID, sku.ID, change.action)
+	now := time.Now()
+
+	switch change.action {
+	case "activate":
+		if sku.IsActive && !archived {
+			return false, nil
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE skus SET is_active = true, archived_at = NULL, updated_at = $3 WHERE organization_id = $1 AND id = $2`,
+			organizationID, sku.ID, now)
+
+	case "deactivate":
+		if !sku.IsActive {
+			return false, nil
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE skus SET is_active = false, updated_at = $3 WHERE organization_id = $1 AND id = $2`,
+			organizationID, sku.ID, now)
+
+	case "archive":
+		if archived {
+			return false, nil
+		}
+		var onHand int
+		if err := tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(quantity), 0) FROM inventory WHERE organization_id = $1 AND sku_id = $2`,
+			organizationID, sku.ID).Scan(&onHand); err != nil {
+			return false, err
+		}
+		if onHand > 0 {
+			return false, bulkItemError(fmt.Sprintf("SKU has %d units on hand", onHand))
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE skus SET is_active = false, archived_at = $3, updated_at = $3 WHERE organization_id = $1 AND id = $2`,
+			organizationID, sku.ID, now)
+
+	case "set_category", "set_supplier":
+		if sku.ParentSKUID != nil {
+			return false, bulkItemError("variants take their category and supplier from the parent SKU")
+		}
+
+		fieldName := "category"
+		oldValue, newValue := sku.Category, change.categoryPath
+		if change.action == "set_category" {
+			if sku.CategoryID != nil && *sku.CategoryID == *change.categoryID {
+				return false, nil
+			}
+			sku.Category, sku.CategoryID = change.categoryPath, change.categoryID
+		} else {
+			fieldName = "supplier"
+			oldValue, newValue = sku.Supplier, &change.supplier
+			if sku.Supplier != nil && *sku.Supplier == change.supplier {
+				return false, nil
+			}
+			sku.Supplier = &change.supplier
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE skus SET category = $3, category_id = $4, supplier = $5, updated_at = $6 WHERE organization_id = $1 AND id = $2`,
+			organizationID, sku.ID, sku.Category, sku.CategoryID, sku.Supplier, now)
+		if err != nil {
+			return false, err
+		}
+		if change.action == "set_supplier" {
+			if err := linkSupplierByName(ctx, tx, organizationID, sku.ID, change.supplier); err != nil {
+				return false, err
+			}
+		}
+		if err := propagateVariantFields(ctx, tx, organizationID, sku); err != nil {
+			return false, err
+		}
+
+		logReq.ChangeType = "update"
+		logReq.FieldName = &fieldName
+		logReq.OldValue, logReq.NewValue = oldValue, newValue
+	}
+	if err != nil {
+		return false, err
+	}
+
+	logReq.Reason = change.reason
+	logReq.Metadata = change.metadata
+	if _, err := createChangeLog(ctx, tx, organizationID, userID, *logReq); err != nil {
+		return false, err
+	}
+	if err := writeSKUEvent(ctx, tx, organizationID, "sku.updated", sku.ID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
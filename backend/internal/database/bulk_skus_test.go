@@ -0,0 +1,26 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestBulkItemMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"item error", bulkItemError("SKU not found"), "SKU not found"},
+		{"wrapped item error", fmt.Errorf("archive: %w", bulkItemError("SKU has 3 units on hand")), "SKU has 3 units on hand"},
+		{"database error", &pq.Error{Code: "23514", Message: `new row for relation "change_logs" violates check constraint "change_logs_change_type_check"`}, errBulkItemFailed},
+		{"other error", fmt.Errorf("connection reset"), errBulkItemFailed},
+	}
+	for _, tt := range tests {
+		if got := bulkItemMessage("sku-1", tt.err); got != tt.want {
+			t.Errorf("%s: bulkItemMessage = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
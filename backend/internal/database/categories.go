@@ -0,0 +1,548 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/models"
+)
+
+const categorySelect = `
+	SELECT c.id, c.organization_id, c.parent_id, c.name, c.path, c.depth, c.created_at, c.updated_at,
+		(SELECT COUNT(*) FROM skus s WHERE s.category_id = c.id) AS sku_count
+	FROM categories c
+`
+
+// categoryDescendantJoin joins d to category c itself and every category below it
+const categoryDescendantJoin = `d.organization_id = c.organization_id AND (d.id = c.id OR left(d.path, length(c.path) + 1) = c.path || '/')`
+
+// categoryPathSplit accepts both "Electronics/Phones" and "Electronics > Phones"
+var categoryPathSplit = regexp.MustCompile(`\s*[/>]\s*`)
+
+func scanCategory(row rowScanner) (*models.Category, error) {
+	category := &models.Category{}
+	err := row.Scan(
+		&category.ID,
+		&category.OrganizationID,
+		&category.ParentID,
+		&category.Name,
+		&category.Path,
+		&category.Depth,
+		&category.CreatedAt,
+		&category.UpdatedAt,
+		&category.SKUCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// categoryFilterSQL restricts a category_id column to the matched category and its
+// descendants. match is a condition on c, e.g. "c.id = $3".
+func categoryFilterSQL(column, match string) string {
+	return fmt.Sprintf(` AND %s IN (
+		SELECT d.id FROM categories c
+		JOIN categories d ON `+categoryDescendantJoin+`
+		WHERE c.organization_id = $1 AND %s)`, column, match)
+}
+
+// addCategoryFilters appends the category_id and legacy category path filters shared by
+// the SKU, inventory and transaction listings
+func addCategoryFilters(query string, args []interface{}, argIndex int, column string, categoryID, category *string) (string, []interface{}, int) {
+	if categoryID != nil && *categoryID != "" {
+		query += categoryFilterSQL(column, fmt.Sprintf("c.id = $%d", argIndex))
+		args = append(args, *categoryID)
+		argIndex++
+	}
+
+	if category != nil && *category != "" {
+		query += categoryFilterSQL(column, fmt.Sprintf("lower(c.path) = lower($%d)", argIndex))
+		args = append(args, normalizeCategoryPath(*category))
+		argIndex++
+	}
+
+	return query, args, argIndex
+}
+
+// normalizeCategoryPath turns user input such as " Electronics > Phones " into "Electronics/Phones"
+func normalizeCategoryPath(path string) string {
+	parts := make([]string, 0)
+	for _, part := range categoryPathSplit.Split(strings.TrimSpace(path), -1) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, models.CategoryPathSeparator)
+}
+
+// Category Methods
+
+func (p *PostgresService) GetCategories(ctx context.Context, organizationID string) ([]*models.Category, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, categorySelect+" WHERE c.organization_id = $1 ORDER BY c.path", organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := make([]*models.Category, 0)
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+// GetCategoryTree returns the root categories with their descendants nested under Children
+func (p *PostgresService) GetCategoryTree(ctx context.Context, organizationID string) ([]*models.Category, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	categories, err := p.GetCategories(ctx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*models.Category, len(categories))
+	for _, category := range categories {
+		byID[category.ID] = category
+	}
+
+	// Ordered by path, so parents are always seen before their children
+	roots := make([]*models.Category, 0)
+	for _, category := range categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+		if parent, ok := byID[*category.ParentID]; ok {
+			parent.Children = append(parent.Children, category)
+		}
+	}
+
+	return roots, nil
+}
+
+func (p *PostgresService) GetCategoryByID(ctx context.Context, organizationID, id string) (*models.Category, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return getCategory(ctx, p.DB, organizationID, id)
+}
+
+func getCategory(ctx context.Context, q queryer, organizationID, id string) (*models.Category, error) {
+	category, err := scanCategory(q.QueryRowContext(ctx, categorySelect+" WHERE c.organization_id = $1 AND c.id = $2", organizationID, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("category not found")
+	}
+	return category, err
+}
+
+func (p *PostgresService) CreateCategory(ctx context.Context, organizationID string, req models.CreateCategoryRequest) (*models.Category, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return createCategory(ctx, p.DB, organizationID, strings.TrimSpace(req.Name), req.ParentID)
+}
+
+func createCategory(ctx context.Context, q queryer, organizationID, name string, parentID *string) (*models.Category, error) {
+	if name == "" || strings.Contains(name, models.CategoryPathSeparator) {
+		return nil, fmt.Errorf("invalid category name")
+	}
+
+	path := name
+	depth := 0
+	if parentID != nil && *parentID != "" {
+		parent, err := getCategory(ctx, q, organizationID, *parentID)
+		if err != nil {
+			return nil, fmt.Errorf("parent category not found")
+		}
+		path = parent.Path + models.CategoryPathSeparator + name
+		depth = parent.Depth + 1
+	} else {
+		parentID = nil
+	}
+
+	var id string
+	now := time.Now()
+	err := q.QueryRowContext(ctx, `
+		INSERT INTO categories (organization_id, parent_id, name, path, depth, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		RETURNING id
+	`, organizationID, parentID, name, path, depth, now).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+
+	return getCategory(ctx, q, organizationID, id)
+}
+
+// UpdateCategory renames or moves a category. Paths and depths of every descendant and the
+// category copy held on assigned SKUs are rewritten in the same transaction. Categories
+// have no change log entries or events of their own; each SKU in the subtree whose path
+// changed gets an update entry and a sku.updated event instead.
+func (p *PostgresService) UpdateCategory(ctx context.Context, organizationID, userID, id string, req models.UpdateCategoryRequest) (*models.Category, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || strings.Contains(name, models.CategoryPathSeparator) {
+		return nil, fmt.Errorf("invalid category name")
+	}
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	current, err := getCategory(ctx, tx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var parentID *string
+	path := name
+	depth := 0
+	if req.ParentID != nil && *req.ParentID != "" {
+		parent, err := getCategory(ctx, tx, organizationID, *req.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("parent category not found")
+		}
+		if parent.ID == current.ID || strings.HasPrefix(parent.Path, current.Path+models.CategoryPathSeparator) {
+			return nil, fmt.Errorf("category cannot be moved under itself")
+		}
+		parentID = &parent.ID
+		path = parent.Path + models.CategoryPathSeparator + name
+		depth = parent.Depth + 1
+	}
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE categories SET parent_id = $3, name = $4, path = $5, depth = $6, updated_at = $7
+		WHERE organization_id = $1 AND id = $2
+	`, organizationID, id, parentID, name, path, depth, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if path != current.Path {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE categories
+			SET path = $3 || substr(path, length($2) + 1), depth = depth + $4, updated_at = $5
+			WHERE organization_id = $1 AND left(path, length($2) + 1) = $2 || '/'
+		`, organizationID, current.Path, path, depth-current.Depth, now)
+		if err != nil {
+			return nil, err
+		}
+
+		moved, err := moveCategorySKUs(ctx, tx, organizationID, id, now)
+		if err != nil {
+			return nil, err
+		}
+		reason := "Category moved from " + current.Path + " to " + path
+		fieldName := "category"
+		for _, sku := range moved {
+			logReq := models.NewSKUChangeLog(organizationID, userID, sku.id, "update")
+			logReq.FieldName = &fieldName
+			logReq.OldValue, logReq.NewValue = sku.oldPath, &sku.newPath
+			logReq.Reason = &reason
+			if _, err := createChangeLog(ctx, tx, organizationID, userID, *logReq); err != nil {
+				return nil, err
+			}
+			if err := writeSKUEvent(ctx, tx, organizationID, "sku.updated", sku.id); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	category, err := getCategory(ctx, tx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// movedCategorySKU is a SKU whose category path a move rewrote
+type movedCategorySKU struct {
+	id      string
+	oldPath *string
+	newPath string
+}
+
+// moveCategorySKUs copies the rewritten paths of a category and its descendants
+// onto their SKUs and returns the SKUs whose path changed
+func moveCategorySKUs(ctx context.Context, tx *sql.Tx, organizationID, id string, now time.Time) ([]movedCategorySKU, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT s.id, s.category, d.path
+		FROM categories c
+		JOIN categories d ON `+categoryDescendantJoin+`
+		JOIN skus s ON s.category_id = d.id
+		WHERE c.organization_id = $1 AND c.id = $2 AND s.category IS DISTINCT FROM d.path
+		ORDER BY s.id
+		FOR UPDATE OF s
+	`, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	moved := make([]movedCategorySKU, 0)
+	for rows.Next() {
+		var sku movedCategorySKU
+		if err := rows.Scan(&sku.id, &sku.oldPath, &sku.newPath); err != nil {
+			return nil, err
+		}
+		moved = append(moved, sku)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, sku := range moved {
+		_, err := tx.ExecContext(ctx, `UPDATE skus SET category = $3, updated_at = $4 WHERE organization_id = $1 AND id = $2`,
+			organizationID, sku.id, sku.newPath, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return moved, nil
+}
+
+// DeleteCategory removes a leaf category and unassigns its SKUs
+func (p *PostgresService) DeleteCategory(ctx context.Context, organizationID, id string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var hasChildren bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM categories WHERE organization_id = $1 AND parent_id = $2)`, organizationID, id).Scan(&hasChildren)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return fmt.Errorf("category has subcategories")
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE skus SET category = NULL, category_id = NULL, updated_at = $3 WHERE organization_id = $1 AND category_id = $2`, organizationID, id, time.Now())
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM categories WHERE organization_id = $1 AND id = $2`, organizationID, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("category not found")
+	}
+
+	return tx.Commit()
+}
+
+// ensureCategoryPath finds the category for a free-text path, creating any missing
+// nodes along the way. Matching is case-insensitive.
+func ensureCategoryPath(ctx context.Context, q queryer, organizationID, path string) (*models.Category, error) {
+	var parent *models.Category
+	for _, name := range strings.Split(normalizeCategoryPath(path), models.CategoryPathSeparator) {
+		nodePath := name
+		var parentID *string
+		if parent != nil {
+			nodePath = parent.Path + models.CategoryPathSeparator + name
+			parentID = &parent.ID
+		}
+
+		category, err := scanCategory(q.QueryRowContext(ctx, categorySelect+" WHERE c.organization_id = $1 AND lower(c.path) = lower($2)", organizationID, nodePath))
+		if err == sql.ErrNoRows {
+			category, err = createCategory(ctx, q, organizationID, name, parentID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent = category
+	}
+
+	return parent, nil
+}
+
+// resolveSKUCategory returns the category_id and path to store on a SKU. An explicit
+// category ID wins; otherwise the free-text category is resolved into the tree.
+func resolveSKUCategory(ctx context.Context, q queryer, organizationID string, categoryID, category *string) (*string, *string, error) {
+	if categoryID != nil && *categoryID != "" {
+		node, err := getCategory(ctx, q, organizationID, *categoryID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &node.ID, &node.Path, nil
+	}
+
+	if category == nil || normalizeCategoryPath(*category) == "" {
+		return nil, nil, nil
+	}
+
+	node, err := ensureCategoryPath(ctx, q, organizationID, *category)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &node.ID, &node.Path, nil
+}
+
+// Category Rollups
+
+// GetCategoryInventoryRollup totals inventory for each category at params.Level, counting
+// every SKU assigned to the category or any of its descendants
+func (p *PostgresService) GetCategoryInventoryRollup(ctx context.Context, organizationID string, params models.CategoryRollupParams) ([]*models.CategoryInventoryRollup, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.beginReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	query := `
+		SELECT c.id, c.name, c.path, c.depth,
+			COUNT(DISTINCT s.id),
+			COALESCE(SUM(inv.quantity), 0), COALESCE(SUM(inv.reserved_quantity), 0), COALESCE(SUM(inv.total_value), 0)
+		FROM categories c
+		LEFT JOIN categories d ON ` + categoryDescendantJoin + `
+		LEFT JOIN skus s ON s.category_id = d.id AND s.is_active = true
+		LEFT JOIN (
+			SELECT i.sku_id, i.quantity, i.total_value,
+				` + reservedQuantitySQL + `
+			FROM inventory i
+			WHERE i.organization_id = $1
+		) inv ON inv.sku_id = s.id
+		WHERE c.organization_id = $1 AND c.depth = $2
+	`
+	args := []interface{}{organizationID, params.Level}
+	argIndex := 3
+
+	if params.ParentID != nil && *params.ParentID != "" {
+		query += fmt.Sprintf(" AND c.parent_id = $%d", argIndex)
+		args = append(args, *params.ParentID)
+	}
+
+	query += " GROUP BY c.id, c.name, c.path, c.depth ORDER BY c.path"
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rollups := make([]*models.CategoryInventoryRollup, 0)
+	for rows.Next() {
+		rollup := &models.CategoryInventoryRollup{}
+		err := rows.Scan(
+			&rollup.CategoryID,
+			&rollup.Name,
+			&rollup.Path,
+			&rollup.Depth,
+			&rollup.SKUCount,
+			&rollup.Quantity,
+			&rollup.ReservedQuantity,
+			&rollup.TotalValue,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rollup.AvailableQuantity = rollup.Quantity - rollup.ReservedQuantity
+		rollups = append(rollups, rollup)
+	}
+
+	return rollups, rows.Err()
+}
+
+// GetCategoryTransactionRollup is GetTransactionSummary grouped by the categories at params.Level
+func (p *PostgresService) GetCategoryTransactionRollup(ctx context.Context, organizationID string, params models.CategoryRollupParams) ([]*models.CategoryTransactionRollup, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.beginReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	query := `
+		SELECT c.id, c.name, c.path, c.depth, t.transaction_type,
+			COUNT(*), SUM(t.quantity), SUM(t.total_cost)
+		FROM categories c
+		JOIN categories d ON ` + categoryDescendantJoin + `
+		JOIN skus s ON s.category_id = d.id
+		JOIN transactions t ON t.sku_id = s.id AND t.organization_id = c.organization_id
+		WHERE c.organization_id = $1 AND c.depth = $2
+	`
+	args := []interface{}{organizationID, params.Level}
+	argIndex := 3
+
+	if params.ParentID != nil && *params.ParentID != "" {
+		query += fmt.Sprintf(" AND c.parent_id = $%d", argIndex)
+		args = append(args, *params.ParentID)
+		argIndex++
+	}
+
+	if params.StartDate != nil && *params.StartDate != "" {
+		query += fmt.Sprintf(" AND t.created_at >= $%d", argIndex)
+		args = append(args, *params.StartDate)
+		argIndex++
+	}
+
+	if params.EndDate != nil && *params.EndDate != "" {
+		query += fmt.Sprintf(" AND t.created_at <= $%d", argIndex)
+		args = append(args, *params.EndDate)
+	}
+
+	query += " GROUP BY c.id, c.name, c.path, c.depth, t.transaction_type ORDER BY c.path, t.transaction_type"
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rollups := make([]*models.CategoryTransactionRollup, 0)
+	for rows.Next() {
+		rollup := &models.CategoryTransactionRollup{}
+		err := rows.Scan(
+			&rollup.CategoryID,
+			&rollup.Name,
+			&rollup.Path,
+			&rollup.Depth,
+			&rollup.TransactionType,
+			&rollup.TotalTransactions,
+			&rollup.TotalQuantity,
+			&rollup.TotalValue,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rollups = append(rollups, rollup)
+	}
+
+	return rollups, rows.Err()
+}
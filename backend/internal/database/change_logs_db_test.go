@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"flex-erp-poc/internal/models"
+)
+
+// testDB connects to the migrated database at TEST_DATABASE_URL, skipping the
+// test when it is not set. Tests make their changes in a transaction they roll back.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Ping(); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	return db
+}
+
+// The change_logs check constraint is redefined by migrations, separately from
+// models.SupportedChangeTypes; every type the code writes must pass it
+func TestChangeLogTypesAllowedByDatabase(t *testing.T) {
+	ctx := context.Background()
+	tx, err := testDB(t).BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	var organizationID, userID string
+	if err := tx.QueryRowContext(ctx, `INSERT INTO organizations (name) VALUES ('Change log test') RETURNING id`).Scan(&organizationID); err != nil {
+		t.Fatalf("insert organization: %v", err)
+	}
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO users (organization_id, email, name, role) VALUES ($1, 'change-log-test@example.com', 'Change Log Test', 'admin') RETURNING id
+	`, organizationID).Scan(&userID); err != nil {
+		t.Fatalf("insert user: %v", err)
+	}
+	skus := make([]*models.SKU, 2)
+	for i, code := range []string{"CL-SURVIVOR", "CL-SOURCE"} {
+		skus[i] = &models.SKU{SKUCode: code}
+		if err := tx.QueryRowContext(ctx, `
+			INSERT INTO skus (organization_id, sku_code, product_name) VALUES ($1, $2, $2) RETURNING id
+		`, organizationID, code).Scan(&skus[i].ID); err != nil {
+			t.Fatalf("insert SKU %s: %v", code, err)
+		}
+	}
+
+	// attempt runs fn under a savepoint, so a rejected insert does not abort the
+	// transaction for the rest of the test
+	attempt := func(name string, fn func() error) {
+		t.Helper()
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT change_log_test`); err != nil {
+			t.Fatal(err)
+		}
+		if err := fn(); err != nil {
+			t.Errorf("%s: %v", name, err)
+			if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT change_log_test`); err != nil {
+				t.Fatal(err)
+			}
+			return
+		}
+		if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT change_log_test`); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, changeType := range models.SupportedChangeTypes {
+		attempt("change type "+changeType, func() error {
+			_, err := createChangeLog(ctx, tx, organizationID, userID, *models.NewSKUChangeLog(organizationID, userID, skus[0].ID, changeType))
+			return err
+		})
+	}
+	attempt("logSKUMerge", func() error {
+		return logSKUMerge(ctx, tx, organizationID, userID, skus[0], skus[1], nil, map[string]interface{}{"test": true})
+	})
+}
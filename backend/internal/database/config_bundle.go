@@ -0,0 +1,593 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/models"
+	"flex-erp-poc/internal/utils"
+)
+
+// Business Rules Methods
+
+func (p *PostgresService) GetBusinessRules(ctx context.Context, organizationID string) (*models.BusinessRules, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return getBusinessRules(ctx, p.DB, organizationID)
+}
+
+func getBusinessRules(ctx context.Context, q queryer, organizationID string) (*models.BusinessRules, error) {
+	var data []byte
+	if err := q.QueryRowContext(ctx, `SELECT business_rules FROM organizations WHERE id = $1`, organizationID).Scan(&data); err != nil {
+		return nil, err
+	}
+	rules := &models.BusinessRules{}
+	if err := json.Unmarshal(data, rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (p *PostgresService) UpdateBusinessRules(ctx context.Context, organizationID string, rules models.BusinessRules) (*models.BusinessRules, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if err := validateBusinessRules(rules); err != nil {
+		return nil, err
+	}
+	if err := setBusinessRules(ctx, p.DB, organizationID, rules); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+func setBusinessRules(ctx context.Context, q queryer, organizationID string, rules models.BusinessRules) error {
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, `UPDATE organizations SET business_rules = $2, updated_at = $3 WHERE id = $1`, organizationID, data, time.Now())
+	return err
+}
+
+func validateBusinessRules(rules models.BusinessRules) error {
+	if rules.AllowNegativeInventory {
+		return fmt.Errorf("invalid business rules: allow_negative_inventory is not supported")
+	}
+	if rules.MaxTransactionQuantity < 0 {
+		return fmt.Errorf("invalid business rules: max_transaction_quantity must not be negative")
+	}
+	if rules.LowStockThreshold < 0 {
+		return fmt.Errorf("invalid business rules: low_stock_threshold must not be negative")
+	}
+	if rules.LargeAdjustmentQuantity < 0 {
+		return fmt.Errorf("invalid business rules: large_adjustment_quantity must not be negative")
+	}
+	return nil
+}
+
+// checkBusinessRules enforces the organization's rules on a transaction entered directly
+func checkBusinessRules(ctx context.Context, q queryer, organizationID string, req models.CreateTransactionRequest) error {
+	rules, err := getBusinessRules(ctx, q, organizationID)
+	if err != nil {
+		return err
+	}
+	return applyBusinessRules(rules, req)
+}
+
+func applyBusinessRules(rules *models.BusinessRules, req models.CreateTransactionRequest) error {
+	if rules.RequireReferenceNumber && (req.ReferenceNumber == nil || strings.TrimSpace(*req.ReferenceNumber) == "") {
+		return fmt.Errorf("business rule violated: a reference number is required")
+	}
+	if rules.MaxTransactionQuantity > 0 && req.Quantity > rules.MaxTransactionQuantity {
+		return fmt.Errorf("business rule violated: quantity %d exceeds the maximum of %d", req.Quantity, rules.MaxTransactionQuantity)
+	}
+	return nil
+}
+
+// Config Bundle Methods
+
+// configState is an organization's configuration as a bundle, along with the IDs of
+// its aliases and custom fields keyed by "<table_name>.<field_name>"
+type configState struct {
+	bundle         *models.ConfigBundle
+	aliasIDs       map[string]string
+	customFieldIDs map[string]string
+}
+
+func configKey(tableName, fieldName string) string {
+	return tableName + "." + fieldName
+}
+
+func (p *PostgresService) ExportConfigBundle(ctx context.Context, organizationID string) (*models.ConfigBundle, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	state, err := loadConfigState(ctx, p.DB, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	state.bundle.ExportedAt = time.Now().UTC()
+	return state.bundle, nil
+}
+
+func loadConfigState(ctx context.Context, q queryer, organizationID string) (*configState, error) {
+	state := &configState{
+		bundle: &models.ConfigBundle{
+			Version:      models.ConfigBundleVersion,
+			Roles:        models.DefaultRoles,
+			FieldAliases: make([]*models.BundleFieldAlias, 0),
+			CustomFields: make([]*models.BundleCustomField, 0),
+		},
+		aliasIDs:       make(map[string]string),
+		customFieldIDs: make(map[string]string),
+	}
+
+	var rules []byte
+	err := q.QueryRowContext(ctx, `SELECT name, default_locale, business_rules FROM organizations WHERE id = $1`, organizationID).
+		Scan(&state.bundle.Organization, &state.bundle.DefaultLocale, &rules)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(rules, &state.bundle.BusinessRules); err != nil {
+		return nil, err
+	}
+
+	translations, err := loadBundleTranslations(ctx, q, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, table_name, field_name, display_name, description, is_hidden, sort_order
+		FROM field_aliases
+		WHERE organization_id = $1
+		ORDER BY table_name, sort_order, field_name
+	`, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		alias := &models.BundleFieldAlias{}
+		if err := rows.Scan(&id, &alias.TableName, &alias.FieldName, &alias.DisplayName, &alias.Description, &alias.IsHidden, &alias.SortOrder); err != nil {
+			return nil, err
+		}
+		alias.Translations = translations[id]
+		state.bundle.FieldAliases = append(state.bundle.FieldAliases, alias)
+		state.aliasIDs[configKey(alias.TableName, alias.FieldName)] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	fields, err := getCustomFields(ctx, q, organizationID, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		if len(field.Options) == 0 {
+			field.Options = nil
+		}
+		state.bundle.CustomFields = append(state.bundle.CustomFields, &models.BundleCustomField{
+			TableName:    field.TableName,
+			FieldName:    field.FieldName,
+			DisplayName:  field.DisplayName,
+			Description:  field.Description,
+			FieldType:    field.FieldType,
+			IsRequired:   field.IsRequired,
+			Options:      field.Options,
+			MinValue:     field.MinValue,
+			MaxValue:     field.MaxValue,
+			MaxLength:    field.MaxLength,
+			Pattern:      field.Pattern,
+			SortOrder:    field.SortOrder,
+			Translations: translations[field.ID],
+		})
+		state.customFieldIDs[configKey(field.TableName, field.FieldName)] = field.ID
+	}
+
+	return state, nil
+}
+
+// loadBundleTranslations returns every translation of the organization keyed by the
+// alias or custom field it belongs to
+func loadBundleTranslations(ctx context.Context, q queryer, organizationID string) (map[string][]*models.BundleTranslation, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT COALESCE(field_alias_id, custom_field_id), locale, display_name, description
+		FROM field_translations
+		WHERE organization_id = $1
+		ORDER BY locale
+	`, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := make(map[string][]*models.BundleTranslation)
+	for rows.Next() {
+		var ownerID string
+		translation := &models.BundleTranslation{}
+		if err := rows.Scan(&ownerID, &translation.Locale, &translation.DisplayName, &translation.Description); err != nil {
+			return nil, err
+		}
+		translations[ownerID] = append(translations[ownerID], translation)
+	}
+
+	return translations, rows.Err()
+}
+
+// ImportConfigBundle diffs a bundle against the organization's configuration and,
+// when req.Apply is set, applies the changes in a single transaction
+func (p *PostgresService) ImportConfigBundle(ctx context.Context, organizationID string, req models.ImportConfigBundleRequest) (*models.ImportConfigBundleResponse, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	bundle := &req.Bundle
+	if err := normalizeConfigBundle(bundle); err != nil {
+		return nil, err
+	}
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Serialize imports into the same organization so the diff stays accurate
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM organizations WHERE id = $1 FOR UPDATE`, organizationID); err != nil {
+		return nil, err
+	}
+
+	current, err := loadConfigState(ctx, tx, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.ImportConfigBundleResponse{
+		Applied: req.Apply,
+		Changes: diffConfigBundle(current.bundle, bundle, req.Prune),
+	}
+	if !req.Apply {
+		return response, nil
+	}
+
+	for _, change := range response.Changes {
+		if err := applyConfigChange(ctx, tx, organizationID, current, change); err != nil {
+			return nil, fmt.Errorf("failed to apply %s %s: %w", change.Section, change.Key, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// normalizeConfigBundle validates a bundle and puts it in the form loadConfigState
+// produces, so unchanged entries compare equal
+func normalizeConfigBundle(bundle *models.ConfigBundle) error {
+	if bundle.Version != models.ConfigBundleVersion {
+		return fmt.Errorf("invalid config bundle: unsupported version %d", bundle.Version)
+	}
+
+	if bundle.DefaultLocale != "" {
+		locale, ok := utils.NormalizeLocale(bundle.DefaultLocale)
+		if !ok {
+			return fmt.Errorf("invalid config bundle: invalid default_locale %s", bundle.DefaultLocale)
+		}
+		bundle.DefaultLocale = locale
+	}
+
+	if err := validateBusinessRules(bundle.BusinessRules); err != nil {
+		return fmt.Errorf("invalid config bundle: %s", strings.TrimPrefix(err.Error(), "invalid business rules: "))
+	}
+
+	seen := make(map[string]bool)
+	for _, alias := range bundle.FieldAliases {
+		key := configKey(alias.TableName, alias.FieldName)
+		if !isSupportedTable(alias.TableName) {
+			return fmt.Errorf("invalid config bundle: field alias %s: unsupported table %s", key, alias.TableName)
+		}
+		if alias.FieldName == "" || strings.TrimSpace(alias.DisplayName) == "" {
+			return fmt.Errorf("invalid config bundle: field alias %s: field_name and display_name are required", key)
+		}
+		if seen[key] {
+			return fmt.Errorf("invalid config bundle: field alias %s appears more than once", key)
+		}
+		seen[key] = true
+
+		translations, err := normalizeBundleTranslations(alias.Translations)
+		if err != nil {
+			return fmt.Errorf("invalid config bundle: field alias %s: %s", key, err)
+		}
+		alias.Translations = translations
+	}
+
+	seen = make(map[string]bool)
+	for _, field := range bundle.CustomFields {
+		key := configKey(field.TableName, field.FieldName)
+		if len(field.Options) == 0 {
+			field.Options = nil
+		}
+		definition := &models.CustomField{
+			TableName:   field.TableName,
+			FieldName:   field.FieldName,
+			DisplayName: field.DisplayName,
+			FieldType:   field.FieldType,
+			Options:     field.Options,
+			MinValue:    field.MinValue,
+			MaxValue:    field.MaxValue,
+			MaxLength:   field.MaxLength,
+			Pattern:     field.Pattern,
+		}
+		if err := validateCustomFieldDefinition(definition); err != nil {
+			return fmt.Errorf("invalid config bundle: custom field %s: %s", key, strings.TrimPrefix(err.Error(), "invalid custom field: "))
+		}
+		if seen[key] {
+			return fmt.Errorf("invalid config bundle: custom field %s appears more than once", key)
+		}
+		seen[key] = true
+
+		translations, err := normalizeBundleTranslations(field.Translations)
+		if err != nil {
+			return fmt.Errorf("invalid config bundle: custom field %s: %s", key, err)
+		}
+		field.Translations = translations
+	}
+
+	return nil
+}
+
+func normalizeBundleTranslations(translations []*models.BundleTranslation) ([]*models.BundleTranslation, error) {
+	if len(translations) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, translation := range translations {
+		locale, ok := utils.NormalizeLocale(translation.Locale)
+		if !ok {
+			return nil, fmt.Errorf("invalid locale %s", translation.Locale)
+		}
+		if seen[locale] {
+			return nil, fmt.Errorf("locale %s appears more than once", locale)
+		}
+		if strings.TrimSpace(translation.DisplayName) == "" {
+			return nil, fmt.Errorf("translation %s: display_name is required", locale)
+		}
+		seen[locale] = true
+		translation.Locale = locale
+	}
+
+	sort.Slice(translations, func(i, j int) bool { return translations[i].Locale < translations[j].Locale })
+	return translations, nil
+}
+
+func isSupportedTable(tableName string) bool {
+	for _, supportedTable := range models.SupportedTables {
+		if tableName == supportedTable {
+			return true
+		}
+	}
+	return false
+}
+
+// diffConfigBundle lists the changes that turn current into bundle. Entries missing
+// from the bundle are only deleted when prune is set.
+func diffConfigBundle(current, bundle *models.ConfigBundle, prune bool) []*models.ConfigChange {
+	changes := make([]*models.ConfigChange, 0)
+
+	if bundle.DefaultLocale != "" && bundle.DefaultLocale != current.DefaultLocale {
+		changes = append(changes, &models.ConfigChange{
+			Section: models.ConfigSectionDefaultLocale,
+			Action:  models.ConfigActionUpdate,
+			Before:  current.DefaultLocale,
+			After:   bundle.DefaultLocale,
+		})
+	}
+
+	if bundle.BusinessRules != current.BusinessRules {
+		changes = append(changes, &models.ConfigChange{
+			Section: models.ConfigSectionBusinessRules,
+			Action:  models.ConfigActionUpdate,
+			Before:  current.BusinessRules,
+			After:   bundle.BusinessRules,
+		})
+	}
+
+	// Roles are defined in code, so differences are reported but never applied
+	for _, role := range bundle.Roles {
+		builtIn := models.GetRoleByName(role.Name)
+		switch {
+		case builtIn == nil:
+			changes = append(changes, &models.ConfigChange{
+				Section: models.ConfigSectionRoles,
+				Key:     role.Name,
+				Action:  models.ConfigActionSkip,
+				After:   role,
+				Note:    "roles are built in, unknown roles cannot be created",
+			})
+		case !reflect.DeepEqual(*builtIn, role):
+			changes = append(changes, &models.ConfigChange{
+				Section: models.ConfigSectionRoles,
+				Key:     role.Name,
+				Action:  models.ConfigActionSkip,
+				Before:  builtIn,
+				After:   role,
+				Note:    "roles are built in and are not changed by import",
+			})
+		}
+	}
+
+	currentAliases := make(map[string]*models.BundleFieldAlias)
+	for _, alias := range current.FieldAliases {
+		currentAliases[configKey(alias.TableName, alias.FieldName)] = alias
+	}
+	bundleAliases := make(map[string]bool)
+	for _, alias := range bundle.FieldAliases {
+		key := configKey(alias.TableName, alias.FieldName)
+		bundleAliases[key] = true
+		existing, ok := currentAliases[key]
+		switch {
+		case !ok:
+			changes = append(changes, &models.ConfigChange{Section: models.ConfigSectionFieldAliases, Key: key, Action: models.ConfigActionCreate, After: alias})
+		case !reflect.DeepEqual(existing, alias):
+			changes = append(changes, &models.ConfigChange{Section: models.ConfigSectionFieldAliases, Key: key, Action: models.ConfigActionUpdate, Before: existing, After: alias})
+		}
+	}
+	if prune {
+		for _, alias := range current.FieldAliases {
+			if key := configKey(alias.TableName, alias.FieldName); !bundleAliases[key] {
+				changes = append(changes, &models.ConfigChange{Section: models.ConfigSectionFieldAliases, Key: key, Action: models.ConfigActionDelete, Before: alias})
+			}
+		}
+	}
+
+	currentFields := make(map[string]*models.BundleCustomField)
+	for _, field := range current.CustomFields {
+		currentFields[configKey(field.TableName, field.FieldName)] = field
+	}
+	bundleFields := make(map[string]bool)
+	for _, field := range bundle.CustomFields {
+		key := configKey(field.TableName, field.FieldName)
+		bundleFields[key] = true
+		existing, ok := currentFields[key]
+		switch {
+		case !ok:
+			changes = append(changes, &models.ConfigChange{Section: models.ConfigSectionCustomFields, Key: key, Action: models.ConfigActionCreate, After: field})
+		case existing.FieldType != field.FieldType:
+			changes = append(changes, &models.ConfigChange{
+				Section: models.ConfigSectionCustomFields,
+				Key:     key,
+				Action:  models.ConfigActionSkip,
+				Before:  existing,
+				After:   field,
+				Note:    fmt.Sprintf("field type cannot change from %s to %s", existing.FieldType, field.FieldType),
+			})
+		case !reflect.DeepEqual(existing, field):
+			changes = append(changes, &models.ConfigChange{Section: models.ConfigSectionCustomFields, Key: key, Action: models.ConfigActionUpdate, Before: existing, After: field})
+		}
+	}
+	if prune {
+		for _, field := range current.CustomFields {
+			if key := configKey(field.TableName, field.FieldName); !bundleFields[key] {
+				changes = append(changes, &models.ConfigChange{
+					Section: models.ConfigSectionCustomFields,
+					Key:     key,
+					Action:  models.ConfigActionDelete,
+					Before:  field,
+					Note:    "values stored for this field are deleted too",
+				})
+			}
+		}
+	}
+
+	return changes
+}
+
+func applyConfigChange(ctx context.Context, q queryer, organizationID string, current *configState, change *models.ConfigChange) error {
+	if change.Action == models.ConfigActionSkip {
+		return nil
+	}
+
+	now := time.Now()
+	switch change.Section {
+	case models.ConfigSectionDefaultLocale:
+		_, err := q.ExecContext(ctx, `UPDATE organizations SET default_locale = $2, updated_at = $3 WHERE id = $1`, organizationID, change.After, now)
+		return err
+
+	case models.ConfigSectionBusinessRules:
+		return setBusinessRules(ctx, q, organizationID, change.After.(models.BusinessRules))
+
+	case models.ConfigSectionFieldAliases:
+		if change.Action == models.ConfigActionDelete {
+			_, err := q.ExecContext(ctx, `DELETE FROM field_aliases WHERE organization_id = $1 AND id = $2`, organizationID, current.aliasIDs[change.Key])
+			return err
+		}
+
+		alias := change.After.(*models.BundleFieldAlias)
+		aliasID := current.aliasIDs[change.Key]
+		var err error
+		if change.Action == models.ConfigActionCreate {
+			err = q.QueryRowContext(ctx, `
+				INSERT INTO field_aliases (organization_id, table_name, field_name, display_name, description, is_hidden, sort_order, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+				RETURNING id
+			`, organizationID, alias.TableName, alias.FieldName, alias.DisplayName, alias.Description, alias.IsHidden, alias.SortOrder, now).Scan(&aliasID)
+		} else {
+			_, err = q.ExecContext(ctx, `
+				UPDATE field_aliases
+				SET display_name = $3, description = $4, is_hidden = $5, sort_order = $6, updated_at = $7
+				WHERE organization_id = $1 AND id = $2
+			`, organizationID, aliasID, alias.DisplayName, alias.Description, alias.IsHidden, alias.SortOrder, now)
+		}
+		if err != nil {
+			return err
+		}
+		return replaceFieldTranslations(ctx, q, organizationID, models.FieldTranslationKindAlias, aliasID, alias.Translations)
+
+	case models.ConfigSectionCustomFields:
+		if change.Action == models.ConfigActionDelete {
+			return deleteCustomField(ctx, q, organizationID, current.customFieldIDs[change.Key])
+		}
+
+		field := change.After.(*models.BundleCustomField)
+		options, err := customFieldOptionsJSON(field.Options)
+		if err != nil {
+			return err
+		}
+		fieldID := current.customFieldIDs[change.Key]
+		if change.Action == models.ConfigActionCreate {
+			err = q.QueryRowContext(ctx, `
+				INSERT INTO custom_fields (organization_id, table_name, field_name, display_name, description, field_type,
+					is_required, options, min_value, max_value, max_length, pattern, sort_order, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $14)
+				RETURNING id
+			`, organizationID, field.TableName, field.FieldName, field.DisplayName, field.Description, field.FieldType,
+				field.IsRequired, options, field.MinValue, field.MaxValue, field.MaxLength, field.Pattern, field.SortOrder, now).Scan(&fieldID)
+		} else {
+			_, err = q.ExecContext(ctx, `
+				UPDATE custom_fields
+				SET display_name = $3, description = $4, is_required = $5, options = $6, min_value = $7, max_value = $8,
+					max_length = $9, pattern = $10, sort_order = $11, updated_at = $12
+				WHERE organization_id = $1 AND id = $2
+			`, organizationID, fieldID, field.DisplayName, field.Description, field.IsRequired, options, field.MinValue,
+				field.MaxValue, field.MaxLength, field.Pattern, field.SortOrder, now)
+		}
+		if err != nil {
+			return err
+		}
+		return replaceFieldTranslations(ctx, q, organizationID, models.FieldTranslationKindCustomField, fieldID, field.Translations)
+	}
+
+	return fmt.Errorf("unknown config section %s", change.Section)
+}
+
+// replaceFieldTranslations makes translations the complete set of labels for an alias or custom field
+func replaceFieldTranslations(ctx context.Context, q queryer, organizationID, kind, ownerID string, translations []*models.BundleTranslation) error {
+	owner := fieldTranslationOwners[kind]
+	if _, err := q.ExecContext(ctx, `DELETE FROM field_translations WHERE organization_id = $1 AND `+owner.column+` = $2`, organizationID, ownerID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, translation := range translations {
+		_, err := q.ExecContext(ctx, `
+			INSERT INTO field_translations (organization_id, `+owner.column+`, locale, display_name, description, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $6)
+		`, organizationID, ownerID, translation.Locale, translation.DisplayName, translation.Description, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
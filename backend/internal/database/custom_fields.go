@@ -0,0 +1,502 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"flex-erp-poc/internal/models"
+)
+
+// customFieldEntityTables maps a custom field table name to the table holding its values
+var customFieldEntityTables = map[string]string{
+	"skus":                   "skus",
+	"inventory":              "inventory",
+	"inventory_transactions": "transactions",
+}
+
+var customFieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+const customFieldSelect = `
+	SELECT id, organization_id, table_name, field_name, display_name, description, field_type,
+		is_required, options, min_value, max_value, max_length, pattern, sort_order, created_at, updated_at
+	FROM custom_fields`
+
+func scanCustomField(row rowScanner) (*models.CustomField, error) {
+	field := &models.CustomField{}
+	var options []byte
+	err := row.Scan(
+		&field.ID,
+		&field.OrganizationID,
+		&field.TableName,
+		&field.FieldName,
+		&field.DisplayName,
+		&field.Description,
+		&field.FieldType,
+		&field.IsRequired,
+		&options,
+		&field.MinValue,
+		&field.MaxValue,
+		&field.MaxLength,
+		&field.Pattern,
+		&field.SortOrder,
+		&field.CreatedAt,
+		&field.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if options != nil {
+		if err := json.Unmarshal(options, &field.Options); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+// Custom Field Methods
+
+// GetCustomFields lists the custom fields of a table, or of every table when tableName is empty
+func (p *PostgresService) GetCustomFields(ctx context.Context, organizationID, tableName string) ([]*models.CustomField, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return getCustomFields(ctx, p.DB, organizationID, tableName)
+}
+
+func getCustomFields(ctx context.Context, q queryer, organizationID, tableName string) ([]*models.CustomField, error) {
+	query := customFieldSelect + ` WHERE organization_id = $1`
+	args := []interface{}{organizationID}
+	if tableName != "" {
+		query += ` AND table_name = $2`
+		args = append(args, tableName)
+	}
+	query += ` ORDER BY table_name, sort_order, field_name`
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := make([]*models.CustomField, 0)
+	for rows.Next() {
+		field, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, rows.Err()
+}
+
+func (p *PostgresService) GetCustomFieldByID(ctx context.Context, organizationID, fieldID string) (*models.CustomField, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	field, err := scanCustomField(p.DB.QueryRowContext(ctx, customFieldSelect+` WHERE organization_id = $1 AND id = $2`, organizationID, fieldID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("custom field not found")
+	}
+	return field, err
+}
+
+func (p *PostgresService) CreateCustomField(ctx context.Context, organizationID string, req models.CreateCustomFieldRequest) (*models.CustomField, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	field := &models.CustomField{
+		TableName:   req.TableName,
+		FieldName:   req.FieldName,
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+		FieldType:   req.FieldType,
+		IsRequired:  req.IsRequired,
+		Options:     req.Options,
+		MinValue:    req.MinValue,
+		MaxValue:    req.MaxValue,
+		MaxLength:   req.MaxLength,
+		Pattern:     req.Pattern,
+	}
+	if req.SortOrder != nil {
+		field.SortOrder = *req.SortOrder
+	}
+	if err := validateCustomFieldDefinition(field); err != nil {
+		return nil, err
+	}
+
+	options, err := customFieldOptionsJSON(field.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return scanCustomField(p.DB.QueryRowContext(ctx, `
+		INSERT INTO custom_fields (organization_id, table_name, field_name, display_name, description, field_type,
+			is_required, options, min_value, max_value, max_length, pattern, sort_order, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $14)
+		RETURNING id, organization_id, table_name, field_name, display_name, description, field_type,
+			is_required, options, min_value, max_value, max_length, pattern, sort_order, created_at, updated_at
+	`, organizationID, field.TableName, field.FieldName, field.DisplayName, field.Description, field.FieldType,
+		field.IsRequired, options, field.MinValue, field.MaxValue, field.MaxLength, field.Pattern, field.SortOrder, now))
+}
+
+func (p *PostgresService) UpdateCustomField(ctx context.Context, organizationID, fieldID string, req models.UpdateCustomFieldRequest) (*models.CustomField, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	field, err := p.GetCustomFieldByID(ctx, organizationID, fieldID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DisplayName != nil {
+		field.DisplayName = *req.DisplayName
+	}
+	if req.Description != nil {
+		field.Description = req.Description
+	}
+	if req.IsRequired != nil {
+		field.IsRequired = *req.IsRequired
+	}
+	if req.Options != nil {
+		field.Options = req.Options
+	}
+	if req.MinValue != nil {
+		field.MinValue = req.MinValue
+	}
+	if req.MaxValue != nil {
+		field.MaxValue = req.MaxValue
+	}
+	if req.MaxLength != nil {
+		field.MaxLength = req.MaxLength
+	}
+	if req.Pattern != nil {
+		field.Pattern = req.Pattern
+	}
+	if req.SortOrder != nil {
+		field.SortOrder = *req.SortOrder
+	}
+	if err := validateCustomFieldDefinition(field); err != nil {
+		return nil, err
+	}
+
+	options, err := customFieldOptionsJSON(field.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanCustomField(p.DB.QueryRowContext(ctx, `
+		UPDATE custom_fields
+		SET display_name = $3, description = $4, is_required = $5, options = $6, min_value = $7, max_value = $8,
+			max_length = $9, pattern = $10, sort_order = $11, updated_at = $12
+		WHERE organization_id = $1 AND id = $2
+		RETURNING id, organization_id, table_name, field_name, display_name, description, field_type,
+			is_required, options, min_value, max_value, max_length, pattern, sort_order, created_at, updated_at
+	`, organizationID, fieldID, field.DisplayName, field.Description, field.IsRequired, options, field.MinValue,
+		field.MaxValue, field.MaxLength, field.Pattern, field.SortOrder, time.Now()))
+}
+
+// DeleteCustomField removes a definition together with the values stored for it
+func (p *PostgresService) DeleteCustomField(ctx context.Context, organizationID, fieldID string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteCustomField(ctx, tx, organizationID, fieldID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func deleteCustomField(ctx context.Context, q queryer, organizationID, fieldID string) error {
+	var tableName, fieldName string
+	err := q.QueryRowContext(ctx, `
+		DELETE FROM custom_fields WHERE organization_id = $1 AND id = $2
+		RETURNING table_name, field_name
+	`, organizationID, fieldID).Scan(&tableName, &fieldName)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("custom field not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	entityTable := customFieldEntityTables[tableName]
+	_, err = q.ExecContext(ctx, `UPDATE `+entityTable+` SET custom_fields = custom_fields - $2::text WHERE organization_id = $1 AND custom_fields ? $2`, organizationID, fieldName)
+	return err
+}
+
+func validateCustomFieldDefinition(field *models.CustomField) error {
+	if _, ok := customFieldEntityTables[field.TableName]; !ok {
+		return fmt.Errorf("invalid custom field: unsupported table %s", field.TableName)
+	}
+	if !customFieldNamePattern.MatchString(field.FieldName) || len(field.FieldName) > 63 {
+		return fmt.Errorf("invalid custom field: field_name must be lowercase letters, digits and underscores, starting with a letter")
+	}
+	for _, defaultField := range models.DefaultTableFields[field.TableName] {
+		if defaultField.FieldName == field.FieldName {
+			return fmt.Errorf("invalid custom field: %s is a built-in field of %s", field.FieldName, field.TableName)
+		}
+	}
+	if strings.TrimSpace(field.DisplayName) == "" {
+		return fmt.Errorf("invalid custom field: display_name is required")
+	}
+
+	switch field.FieldType {
+	case models.CustomFieldTypeText, models.CustomFieldTypeNumber, models.CustomFieldTypeDate, models.CustomFieldTypeBoolean:
+		if len(field.Options) > 0 {
+			return fmt.Errorf("invalid custom field: options only apply to enum fields")
+		}
+	case models.CustomFieldTypeEnum:
+		if len(field.Options) == 0 {
+			return fmt.Errorf("invalid custom field: enum fields need at least one option")
+		}
+		seen := make(map[string]bool, len(field.Options))
+		for _, option := range field.Options {
+			if strings.TrimSpace(option) == "" || seen[option] {
+				return fmt.Errorf("invalid custom field: enum options must be unique and not empty")
+			}
+			seen[option] = true
+		}
+	default:
+		return fmt.Errorf("invalid custom field: unsupported type %s", field.FieldType)
+	}
+
+	if (field.MinValue != nil || field.MaxValue != nil) && field.FieldType != models.CustomFieldTypeNumber {
+		return fmt.Errorf("invalid custom field: min_value and max_value only apply to number fields")
+	}
+	if field.MinValue != nil && field.MaxValue != nil && *field.MinValue > *field.MaxValue {
+		return fmt.Errorf("invalid custom field: min_value is greater than max_value")
+	}
+	if (field.MaxLength != nil || field.Pattern != nil) && field.FieldType != models.CustomFieldTypeText {
+		return fmt.Errorf("invalid custom field: max_length and pattern only apply to text fields")
+	}
+	if field.MaxLength != nil && *field.MaxLength <= 0 {
+		return fmt.Errorf("invalid custom field: max_length must be positive")
+	}
+	if field.Pattern != nil {
+		if _, err := regexp.Compile(*field.Pattern); err != nil {
+			return fmt.Errorf("invalid custom field: pattern is not a valid regular expression")
+		}
+	}
+
+	return nil
+}
+
+func customFieldOptionsJSON(options []string) ([]byte, error) {
+	if len(options) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(options)
+}
+
+// resolveCustomFieldValues applies changes to the existing values of an entity and
+// validates the result against the table's definitions. A nil value in changes
+// removes the field. Required fields must be present in the result.
+func resolveCustomFieldValues(ctx context.Context, q queryer, organizationID, tableName string, existing, changes models.CustomFieldValues) (models.CustomFieldValues, error) {
+	fields, err := getCustomFields(ctx, q, organizationID, tableName)
+	if err != nil {
+		return nil, err
+	}
+	definitions := make(map[string]*models.CustomField, len(fields))
+	for _, field := range fields {
+		definitions[field.FieldName] = field
+	}
+
+	values := models.CustomFieldValues{}
+	for name, value := range existing {
+		values[name] = value
+	}
+
+	// Sorted so the first invalid field reported is stable
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		field, ok := definitions[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid custom field values: unknown field %s", name)
+		}
+		if changes[name] == nil {
+			delete(values, name)
+			continue
+		}
+		value, err := coerceCustomFieldValue(field, changes[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid custom field values: %s %v", name, err)
+		}
+		values[name] = value
+	}
+
+	for _, field := range fields {
+		if _, ok := values[field.FieldName]; field.IsRequired && !ok {
+			return nil, fmt.Errorf("invalid custom field values: %s is required", field.FieldName)
+		}
+	}
+
+	return values, nil
+}
+
+func coerceCustomFieldValue(field *models.CustomField, value interface{}) (interface{}, error) {
+	switch field.FieldType {
+	case models.CustomFieldTypeText:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be text")
+		}
+		if field.MaxLength != nil && utf8.RuneCountInString(text) > *field.MaxLength {
+			return nil, fmt.Errorf("must be at most %d characters", *field.MaxLength)
+		}
+		if field.Pattern != nil {
+			matched, err := regexp.MatchString(*field.Pattern, text)
+			if err != nil || !matched {
+				return nil, fmt.Errorf("does not match the required format")
+			}
+		}
+		return text, nil
+	case models.CustomFieldTypeNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("must be a number")
+		}
+		if field.MinValue != nil && number < *field.MinValue {
+			return nil, fmt.Errorf("must be at least %v", *field.MinValue)
+		}
+		if field.MaxValue != nil && number > *field.MaxValue {
+			return nil, fmt.Errorf("must be at most %v", *field.MaxValue)
+		}
+		return number, nil
+	case models.CustomFieldTypeDate:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a date (YYYY-MM-DD)")
+		}
+		if _, err := time.Parse(models.CustomFieldDateLayout, text); err != nil {
+			return nil, fmt.Errorf("must be a date (YYYY-MM-DD)")
+		}
+		return text, nil
+	case models.CustomFieldTypeEnum:
+		text, ok := value.(string)
+		if ok {
+			for _, option := range field.Options {
+				if option == text {
+					return text, nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("must be one of %s", strings.Join(field.Options, ", "))
+	case models.CustomFieldTypeBoolean:
+		flag, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		return flag, nil
+	}
+	return nil, fmt.Errorf("has an unsupported type")
+}
+
+// addCustomFieldFilters appends conditions for cf.<field_name> query filters on the
+// custom_fields column. Number and date fields also take .min and .max bounds.
+func addCustomFieldFilters(ctx context.Context, q queryer, organizationID, tableName, query string, args []interface{}, argIndex int, column string, filters map[string]string) (string, []interface{}, int, error) {
+	if len(filters) == 0 {
+		return query, args, argIndex, nil
+	}
+
+	fields, err := getCustomFields(ctx, q, organizationID, tableName)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	definitions := make(map[string]*models.CustomField, len(fields))
+	for _, field := range fields {
+		definitions[field.FieldName] = field
+	}
+
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name, bound := key, ""
+		if i := strings.LastIndex(key, "."); i > 0 {
+			name, bound = key[:i], key[i+1:]
+		}
+		field, ok := definitions[name]
+		if !ok {
+			return "", nil, 0, fmt.Errorf("invalid custom field filter: unknown field %s", name)
+		}
+
+		operator := "="
+		switch bound {
+		case "":
+		case "min":
+			operator = ">="
+		case "max":
+			operator = "<="
+		default:
+			return "", nil, 0, fmt.Errorf("invalid custom field filter: unknown bound %s", bound)
+		}
+		if bound != "" && field.FieldType != models.CustomFieldTypeNumber && field.FieldType != models.CustomFieldTypeDate {
+			return "", nil, 0, fmt.Errorf("invalid custom field filter: %s does not support ranges", name)
+		}
+
+		value := filters[key]
+		var condition string
+		var arg interface{} = value
+		switch field.FieldType {
+		case models.CustomFieldTypeText:
+			condition = fmt.Sprintf("LOWER(%s ->> $%d::text) = LOWER($%d)", column, argIndex, argIndex+1)
+		case models.CustomFieldTypeEnum:
+			condition = fmt.Sprintf("%s ->> $%d::text = $%d", column, argIndex, argIndex+1)
+		case models.CustomFieldTypeNumber:
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return "", nil, 0, fmt.Errorf("invalid custom field filter: %s must be a number", name)
+			}
+			arg = number
+			condition = fmt.Sprintf("(%s ->> $%d::text)::numeric %s $%d", column, argIndex, operator, argIndex+1)
+		case models.CustomFieldTypeDate:
+			if _, err := time.Parse(models.CustomFieldDateLayout, value); err != nil {
+				return "", nil, 0, fmt.Errorf("invalid custom field filter: %s must be a date (YYYY-MM-DD)", name)
+			}
+			condition = fmt.Sprintf("(%s ->> $%d::text)::date %s $%d::date", column, argIndex, operator, argIndex+1)
+		case models.CustomFieldTypeBoolean:
+			flag, err := strconv.ParseBool(value)
+			if err != nil {
+				return "", nil, 0, fmt.Errorf("invalid custom field filter: %s must be true or false", name)
+			}
+			arg = flag
+			condition = fmt.Sprintf("(%s ->> $%d::text)::boolean = $%d", column, argIndex, argIndex+1)
+		}
+
+		query += " AND " + condition
+		args = append(args, name, arg)
+		argIndex += 2
+	}
+
+	return query, args, argIndex, nil
+}
+
+// customFieldSearchSQL matches a LIKE pattern against any custom field value in column
+func customFieldSearchSQL(column string, argIndex int) string {
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM jsonb_each_text(%s) cf WHERE LOWER(cf.value) LIKE $%d ESCAPE '\\')", column, argIndex)
+}
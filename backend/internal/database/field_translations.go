@@ -0,0 +1,316 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// fieldTranslationOwners maps a translation kind to its owner column and table
+var fieldTranslationOwners = map[string]struct {
+	column   string
+	table    string
+	notFound string
+}{
+	models.FieldTranslationKindAlias:       {column: "field_alias_id", table: "field_aliases", notFound: "field alias not found"},
+	models.FieldTranslationKindCustomField: {column: "custom_field_id", table: "custom_fields", notFound: "custom field not found"},
+}
+
+const fieldTranslationColumns = `id, organization_id, field_alias_id, custom_field_id, locale, display_name, description, created_at, updated_at`
+
+func scanFieldTranslation(row rowScanner) (*models.FieldTranslation, error) {
+	translation := &models.FieldTranslation{}
+	err := row.Scan(
+		&translation.ID,
+		&translation.OrganizationID,
+		&translation.FieldAliasID,
+		&translation.CustomFieldID,
+		&translation.Locale,
+		&translation.DisplayName,
+		&translation.Description,
+		&translation.CreatedAt,
+		&translation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return translation, nil
+}
+
+// Field Translation Methods
+
+func (p *PostgresService) GetFieldTranslations(ctx context.Context, organizationID, kind, ownerID string) ([]*models.FieldTranslation, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	owner := fieldTranslationOwners[kind]
+	if err := checkTranslationOwner(ctx, p.DB, organizationID, kind, ownerID); err != nil {
+		return nil, err
+	}
+
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT `+fieldTranslationColumns+`
+		FROM field_translations
+		WHERE organization_id = $1 AND `+owner.column+` = $2
+		ORDER BY locale
+	`, organizationID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := make([]*models.FieldTranslation, 0)
+	for rows.Next() {
+		translation, err := scanFieldTranslation(rows)
+		if err != nil {
+			return nil, err
+		}
+		translations = append(translations, translation)
+	}
+
+	return translations, rows.Err()
+}
+
+func (p *PostgresService) UpsertFieldTranslation(ctx context.Context, organizationID, kind, ownerID, locale string, req models.UpsertFieldTranslationRequest) (*models.FieldTranslation, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	owner := fieldTranslationOwners[kind]
+	if err := checkTranslationOwner(ctx, p.DB, organizationID, kind, ownerID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return scanFieldTranslation(p.DB.QueryRowContext(ctx, `
+		INSERT INTO field_translations (organization_id, `+owner.column+`, locale, display_name, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (`+owner.column+`, locale) WHERE `+owner.column+` IS NOT NULL
+		DO UPDATE SET display_name = EXCLUDED.display_name, description = EXCLUDED.description, updated_at = EXCLUDED.updated_at
+		RETURNING `+fieldTranslationColumns,
+		organizationID, ownerID, locale, req.DisplayName, req.Description, now,
+	))
+}
+
+func (p *PostgresService) DeleteFieldTranslation(ctx context.Context, organizationID, kind, ownerID, locale string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	owner := fieldTranslationOwners[kind]
+	result, err := p.DB.ExecContext(ctx, `
+		DELETE FROM field_translations WHERE organization_id = $1 AND `+owner.column+` = $2 AND locale = $3
+	`, organizationID, ownerID, locale)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("translation not found")
+	}
+	return nil
+}
+
+func checkTranslationOwner(ctx context.Context, q queryer, organizationID, kind, ownerID string) error {
+	owner, ok := fieldTranslationOwners[kind]
+	if !ok {
+		return fmt.Errorf("unsupported translation kind: %s", kind)
+	}
+
+	var exists bool
+	err := q.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM `+owner.table+` WHERE organization_id = $1 AND id = $2)`, organizationID, ownerID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%s", owner.notFound)
+	}
+	return nil
+}
+
+// loadFieldTranslations returns the translations in the given locales keyed by owner ID and locale
+func loadFieldTranslations(ctx context.Context, q queryer, organizationID, kind string, locales []string) (map[string]map[string]*models.FieldTranslation, error) {
+	owner := fieldTranslationOwners[kind]
+	rows, err := q.QueryContext(ctx, `
+		SELECT `+fieldTranslationColumns+`
+		FROM field_translations
+		WHERE organization_id = $1 AND `+owner.column+` IS NOT NULL AND locale = ANY($2)
+	`, organizationID, pq.Array(locales))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := make(map[string]map[string]*models.FieldTranslation)
+	for rows.Next() {
+		translation, err := scanFieldTranslation(rows)
+		if err != nil {
+			return nil, err
+		}
+		ownerID := translation.FieldAliasID
+		if kind == models.FieldTranslationKindCustomField {
+			ownerID = translation.CustomFieldID
+		}
+		if translations[*ownerID] == nil {
+			translations[*ownerID] = make(map[string]*models.FieldTranslation)
+		}
+		translations[*ownerID][translation.Locale] = translation
+	}
+
+	return translations, rows.Err()
+}
+
+// localizeFieldAliases replaces alias labels with the first translation found along
+// the locale chain. Aliases without one keep their untranslated label.
+func localizeFieldAliases(ctx context.Context, q queryer, organizationID string, aliases []*models.FieldAlias, locales []string) error {
+	if len(locales) == 0 || len(aliases) == 0 {
+		return nil
+	}
+
+	translations, err := loadFieldTranslations(ctx, q, organizationID, models.FieldTranslationKindAlias, locales)
+	if err != nil {
+		return err
+	}
+
+	for _, alias := range aliases {
+		if translation := pickTranslation(translations[alias.ID], locales); translation != nil {
+			alias.DisplayName = translation.DisplayName
+			if translation.Description != nil {
+				alias.Description = translation.Description
+			}
+			alias.Locale = translation.Locale
+		}
+	}
+	return nil
+}
+
+// localizeCustomFields is localizeFieldAliases for custom field labels
+func localizeCustomFields(ctx context.Context, q queryer, organizationID string, fields []*models.CustomField, locales []string) error {
+	if len(locales) == 0 || len(fields) == 0 {
+		return nil
+	}
+
+	translations, err := loadFieldTranslations(ctx, q, organizationID, models.FieldTranslationKindCustomField, locales)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		if translation := pickTranslation(translations[field.ID], locales); translation != nil {
+			field.DisplayName = translation.DisplayName
+			if translation.Description != nil {
+				field.Description = translation.Description
+			}
+			field.Locale = translation.Locale
+		}
+	}
+	return nil
+}
+
+func pickTranslation(byLocale map[string]*models.FieldTranslation, locales []string) *models.FieldTranslation {
+	for _, locale := range locales {
+		if translation, ok := byLocale[locale]; ok {
+			return translation
+		}
+	}
+	return nil
+}
+
+// GetMissingFieldTranslations lists the aliases and custom fields that have no label in
+// locale or in its parent language. An empty tableName covers every table.
+func (p *PostgresService) GetMissingFieldTranslations(ctx context.Context, organizationID, locale, tableName string) (*models.MissingTranslationsReport, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	locales := []string{locale}
+	if i := strings.Index(locale, "-"); i > 0 {
+		locales = append(locales, locale[:i])
+	}
+
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT kind, id, table_name, field_name, display_name, translated
+		FROM (
+			SELECT 'field_alias' AS kind, a.id, a.table_name, a.field_name, a.display_name, a.sort_order,
+				EXISTS (SELECT 1 FROM field_translations t WHERE t.field_alias_id = a.id AND t.locale = ANY($2)) AS translated
+			FROM field_aliases a
+			WHERE a.organization_id = $1
+			UNION ALL
+			SELECT 'custom_field', c.id, c.table_name, c.field_name, c.display_name, c.sort_order,
+				EXISTS (SELECT 1 FROM field_translations t WHERE t.custom_field_id = c.id AND t.locale = ANY($2))
+			FROM custom_fields c
+			WHERE c.organization_id = $1
+		) fields
+		WHERE $3 = '' OR table_name = $3
+		ORDER BY table_name, kind DESC, sort_order, field_name
+	`, organizationID, pq.Array(locales), tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &models.MissingTranslationsReport{
+		Locale:  locale,
+		Missing: make([]*models.MissingTranslation, 0),
+	}
+	for rows.Next() {
+		missing := &models.MissingTranslation{}
+		var translated bool
+		if err := rows.Scan(&missing.Kind, &missing.ID, &missing.TableName, &missing.FieldName, &missing.DisplayName, &translated); err != nil {
+			return nil, err
+		}
+		report.TotalFields++
+		if translated {
+			report.TranslatedFields++
+			continue
+		}
+		report.Missing = append(report.Missing, missing)
+	}
+
+	return report, rows.Err()
+}
+
+// Locale Preference Methods
+
+func (p *PostgresService) GetOrganizationDefaultLocale(ctx context.Context, organizationID string) (string, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var locale string
+	err := p.DB.QueryRowContext(ctx, `SELECT default_locale FROM organizations WHERE id = $1`, organizationID).Scan(&locale)
+	return locale, err
+}
+
+func (p *PostgresService) UpdateOrganizationDefaultLocale(ctx context.Context, organizationID, locale string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	result, err := p.DB.ExecContext(ctx, `UPDATE organizations SET default_locale = $2, updated_at = $3 WHERE id = $1`, organizationID, locale, time.Now())
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (p *PostgresService) GetUserPreferredLocale(ctx context.Context, userID string) (*string, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var locale *string
+	err := p.DB.QueryRowContext(ctx, `SELECT preferred_locale FROM users WHERE id = $1`, userID).Scan(&locale)
+	return locale, err
+}
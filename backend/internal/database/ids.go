@@ -0,0 +1,11 @@
+package database
+
+import "regexp"
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// isUUID reports whether an ID from a request can be looked up in a uuid column,
+// where a malformed one would fail the query rather than match nothing
+func isUUID(id string) bool {
+	return uuidPattern.MatchString(id)
+}
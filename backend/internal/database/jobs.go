@@ -0,0 +1,342 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"flex-erp-poc/internal/models"
+
+	"github.com/lib/pq"
+)
+
+const jobRunColumns = `id, job_name, trigger, scheduled_for, attempt, status, instance, result, error, started_at, finished_at`
+
+func scanJobRun(row rowScanner) (*models.JobRun, error) {
+	run := &models.JobRun{}
+	err := row.Scan(&run.ID, &run.JobName, &run.Trigger, &run.ScheduledFor, &run.Attempt, &run.Status,
+		&run.Instance, &run.Result, &run.Error, &run.StartedAt, &run.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// SyncScheduledJob records a job registered in code. The schedule, description and
+// retries follow the code; paused carries over, and the next run is only moved
+// when the schedule changed.
+func (p *PostgresService) SyncScheduledJob(ctx context.Context, job models.ScheduledJob) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	_, err := p.DB.ExecContext(ctx, `
+		INSERT INTO scheduled_jobs (name, description, schedule, max_retries, next_run_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (name) DO UPDATE SET
+			description = EXCLUDED.description,
+			max_retries = EXCLUDED.max_retries,
+			schedule = EXCLUDED.schedule,
+			next_run_at = CASE WHEN scheduled_jobs.schedule = EXCLUDED.schedule
+				THEN scheduled_jobs.next_run_at ELSE EXCLUDED.next_run_at END,
+			updated_at = CASE WHEN scheduled_jobs.schedule = EXCLUDED.schedule AND scheduled_jobs.description = EXCLUDED.description
+				AND scheduled_jobs.max_retries = EXCLUDED.max_retries THEN scheduled_jobs.updated_at ELSE now() END
+	`, job.Name, job.Description, job.Schedule, job.MaxRetries, job.NextRunAt)
+	return err
+}
+
+// GetDueJobNames returns which of the named jobs should run now: those triggered by
+// hand, and unpaused ones whose next run has come
+func (p *PostgresService) GetDueJobNames(ctx context.Context, names []string) ([]string, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT name FROM scheduled_jobs
+		WHERE name = ANY($1) AND (run_requested_at IS NOT NULL OR (NOT paused AND next_run_at <= now()))
+	`, pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		due = append(due, name)
+	}
+	return due, rows.Err()
+}
+
+// WithJobLock runs fn holding the job's advisory lock, which one server instance
+// at a time can hold. It reports false without running fn when another holds it.
+// The lock is tied to a connection, so it goes with an instance that dies. It is
+// released even when ctx is cancelled while fn runs.
+func (p *PostgresService) WithJobLock(ctx context.Context, name string, fn func() error) (bool, error) {
+	conn, err := p.DB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	var locked bool
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext('scheduled_jobs'), hashtext($1))`, name).Scan(&locked)
+	if err != nil || !locked {
+		return false, err
+	}
+	defer conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock(hashtext('scheduled_jobs'), hashtext($1))`, name)
+
+	return true, fn()
+}
+
+// ClaimJobRun starts a run of the job if one is due and returns it, or nil when
+// there is nothing to run. A run requested by hand comes first; otherwise a retry
+// or the scheduled run, after which the next run is set from next. Call it holding
+// the job's lock: runs left "running" are from an instance that stopped mid-run.
+func (p *PostgresService) ClaimJobRun(ctx context.Context, name, instance string, next func(time.Time) time.Time) (*models.JobRun, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var job models.ScheduledJob
+	var runRequestedAt *time.Time
+	var now time.Time
+	err = tx.QueryRowContext(ctx, `
+		SELECT paused, next_run_at, retry_for, retry_attempt, run_requested_at, now()
+		FROM scheduled_jobs WHERE name = $1
+		FOR UPDATE
+	`, name).Scan(&job.Paused, &job.NextRunAt, &job.RetryFor, &job.RetryAttempt, &runRequestedAt, &now)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("scheduled job not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	trigger, scheduledFor, attempt := "", now, 1
+	switch {
+	case runRequestedAt != nil:
+		trigger, scheduledFor = "manual", *runRequestedAt
+		_, err = tx.ExecContext(ctx, `UPDATE scheduled_jobs SET run_requested_at = NULL WHERE name = $1`, name)
+	case !job.Paused && !job.NextRunAt.After(now):
+		trigger, scheduledFor = "schedule", job.NextRunAt
+		if job.RetryFor != nil {
+			trigger, scheduledFor, attempt = "retry", *job.RetryFor, job.RetryAttempt+1
+		}
+		// Runs missed while no instance was up are not made up one by one
+		_, err = tx.ExecContext(ctx, `UPDATE scheduled_jobs SET next_run_at = $2, retry_for = NULL, retry_attempt = 0 WHERE name = $1`,
+			name, next(now))
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE job_runs SET status = 'failed', error = 'interrupted', finished_at = now()
+		WHERE job_name = $1 AND status = 'running'
+	`, name)
+	if err != nil {
+		return nil, err
+	}
+
+	run, err := scanJobRun(tx.QueryRowContext(ctx, `
+		INSERT INTO job_runs (job_name, trigger, scheduled_for, attempt, instance)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+jobRunColumns,
+		name, trigger, scheduledFor, attempt, instance))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// FinishJobRun records how a run ended. A failed run is retried at retryAt when it
+// is set, unless the next scheduled run comes first.
+func (p *PostgresService) FinishJobRun(ctx context.Context, run *models.JobRun, result string, runErr error, retryAt *time.Time) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	status := "succeeded"
+	var errorText *string
+	if runErr != nil {
+		status = "failed"
+		message := runErr.Error()
+		errorText = &message
+	}
+	var resultText *string
+	if result != "" {
+		resultText = &result
+	}
+	_, err = tx.ExecContext(ctx, `UPDATE job_runs SET status = $2, result = $3, error = $4, finished_at = now() WHERE id = $1`,
+		run.ID, status, resultText, errorText)
+	if err != nil {
+		return err
+	}
+
+	if runErr != nil && retryAt != nil {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE scheduled_jobs SET retry_for = $2, retry_attempt = $3, next_run_at = $4
+			WHERE name = $1 AND next_run_at > $4
+		`, run.JobName, run.ScheduledFor, run.Attempt, *retryAt)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetScheduledJobs lists every job with its most recent run
+func (p *PostgresService) GetScheduledJobs(ctx context.Context) ([]*models.ScheduledJob, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return p.getScheduledJobs(ctx, "")
+}
+
+func (p *PostgresService) GetScheduledJob(ctx context.Context, name string) (*models.ScheduledJob, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	jobs, err := p.getScheduledJobs(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("scheduled job not found")
+	}
+	return jobs[0], nil
+}
+
+// getScheduledJobs returns the named job, or every job when name is empty
+func (p *PostgresService) getScheduledJobs(ctx context.Context, name string) ([]*models.ScheduledJob, error) {
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT j.name, j.description, j.schedule, j.max_retries, j.paused, j.next_run_at, j.run_requested_at IS NOT NULL, j.updated_at,
+			r.id, r.job_name, r.trigger, r.scheduled_for, r.attempt, r.status, r.instance, r.result, r.error, r.started_at, r.finished_at
+		FROM scheduled_jobs j
+		LEFT JOIN LATERAL (
+			SELECT * FROM job_runs WHERE job_name = j.name ORDER BY started_at DESC LIMIT 1
+		) r ON true
+		WHERE $1 = '' OR j.name = $1
+		ORDER BY j.name
+	`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := make([]*models.ScheduledJob, 0)
+	for rows.Next() {
+		job := &models.ScheduledJob{}
+		var runID, jobName, trigger, status, instance sql.NullString
+		var scheduledFor, startedAt sql.NullTime
+		var attempt sql.NullInt64
+		run := &models.JobRun{}
+		err := rows.Scan(&job.Name, &job.Description, &job.Schedule, &job.MaxRetries, &job.Paused, &job.NextRunAt, &job.RunRequested, &job.UpdatedAt,
+			&runID, &jobName, &trigger, &scheduledFor, &attempt, &status, &instance, &run.Result, &run.Error, &startedAt, &run.FinishedAt)
+		if err != nil {
+			return nil, err
+		}
+		if runID.Valid {
+			run.ID, run.JobName, run.Trigger, run.Status, run.Instance = runID.String, jobName.String, trigger.String, status.String, instance.String
+			run.ScheduledFor, run.StartedAt, run.Attempt = scheduledFor.Time, startedAt.Time, int(attempt.Int64)
+			job.LastRun = run
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// SetScheduledJobPaused pauses or resumes a job's schedule. A paused job can still
+// be run by hand.
+func (p *PostgresService) SetScheduledJobPaused(ctx context.Context, name string, paused bool) (*models.ScheduledJob, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	result, err := p.DB.ExecContext(ctx, `UPDATE scheduled_jobs SET paused = $2, updated_at = now() WHERE name = $1`, name, paused)
+	if err != nil {
+		return nil, err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if affected == 0 {
+		return nil, fmt.Errorf("scheduled job not found")
+	}
+	return p.GetScheduledJob(ctx, name)
+}
+
+// RequestJobRun asks for the job to run as soon as a scheduler picks it up
+func (p *PostgresService) RequestJobRun(ctx context.Context, name string) (*models.ScheduledJob, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	result, err := p.DB.ExecContext(ctx, `
+		UPDATE scheduled_jobs SET run_requested_at = COALESCE(run_requested_at, now()) WHERE name = $1
+	`, name)
+	if err != nil {
+		return nil, err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if affected == 0 {
+		return nil, fmt.Errorf("scheduled job not found")
+	}
+	return p.GetScheduledJob(ctx, name)
+}
+
+// GetJobRuns returns the job's most recent runs, newest first
+func (p *PostgresService) GetJobRuns(ctx context.Context, name string, limit int) ([]*models.JobRun, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := p.GetScheduledJob(ctx, name); err != nil {
+		return nil, err
+	}
+
+	rows, err := p.DB.QueryContext(ctx, `SELECT `+jobRunColumns+` FROM job_runs WHERE job_name = $1 ORDER BY started_at DESC LIMIT $2`, name, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := make([]*models.JobRun, 0)
+	for rows.Next() {
+		run, err := scanJobRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// DeleteJobRuns removes finished runs that started before the cutoff
+func (p *PostgresService) DeleteJobRuns(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	result, err := p.DB.ExecContext(ctx, `DELETE FROM job_runs WHERE started_at < $1 AND status <> 'running'`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,376 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"flex-erp-poc/internal/models"
+)
+
+// Kit Methods
+
+func (p *PostgresService) GetKits(ctx context.Context, organizationID string) ([]*models.Kit, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT DISTINCT kc.kit_sku_id, s.sku_code
+		FROM kit_components kc
+		JOIN skus s ON kc.kit_sku_id = s.id
+		WHERE kc.organization_id = $1
+		ORDER BY s.sku_code
+	`, organizationID)
+	if err != nil {
+		return nil, err
+	}
+
+	kitIDs := make([]string, 0)
+	for rows.Next() {
+		var kitID, skuCode string
+		if err := rows.Scan(&kitID, &skuCode); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		kitIDs = append(kitIDs, kitID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	kits := make([]*models.Kit, 0, len(kitIDs))
+	for _, kitID := range kitIDs {
+		kit, err := getKit(ctx, p.DB, organizationID, kitID)
+		if err != nil {
+			return nil, err
+		}
+		kits = append(kits, kit)
+	}
+
+	return kits, nil
+}
+
+func (p *PostgresService) GetKit(ctx context.Context, organizationID, kitID string) (*models.Kit, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return getKit(ctx, p.DB, organizationID, kitID)
+}
+
+// getKit loads a kit's bill of materials with the component stock and costs it is
+// assembled from. A SKU without components is returned with an empty BOM.
+func getKit(ctx context.Context, q queryer, organizationID, kitID string) (*models.Kit, error) {
+	kit := &models.Kit{SKUID: kitID}
+	err := q.QueryRowContext(ctx, `SELECT sku_code, product_name FROM skus WHERE organization_id = $1 AND id = $2`, organizationID, kitID).Scan(&kit.SKUCode, &kit.ProductName)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("SKU not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT kc.id, kc.kit_sku_id, kc.component_sku_id, kc.quantity, kc.created_at, kc.updated_at,
+			s.sku_code, s.product_name, COALESCE(i.quantity, 0), COALESCE(i.weighted_cost, 0),
+			`+reservedQuantitySQL+`
+		FROM kit_components kc
+		JOIN skus s ON kc.component_sku_id = s.id
+		LEFT JOIN inventory i ON i.organization_id = kc.organization_id AND i.sku_id = kc.component_sku_id
+		WHERE kc.organization_id = $1 AND kc.kit_sku_id = $2
+		ORDER BY s.sku_code
+	`, organizationID, kitID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	kit.Components = make([]*models.KitComponent, 0)
+	for rows.Next() {
+		component := &models.KitComponent{}
+		var onHand, reserved int
+		err := rows.Scan(
+			&component.ID,
+			&component.KitSKUID,
+			&component.ComponentSKUID,
+			&component.Quantity,
+			&component.CreatedAt,
+			&component.UpdatedAt,
+			&component.SKUCode,
+			&component.ProductName,
+			&onHand,
+			&component.WeightedCost,
+			&reserved,
+		)
+		if err != nil {
+			return nil, err
+		}
+		component.AvailableQuantity = onHand - reserved
+
+		// The scarcest component limits how many kits can be built
+		buildable := max(component.AvailableQuantity, 0) / component.Quantity
+		if len(kit.Components) == 0 || buildable < kit.BuildableQuantity {
+			kit.BuildableQuantity = buildable
+		}
+		kit.ComponentCost += component.WeightedCost * float64(component.Quantity)
+		kit.Components = append(kit.Components, component)
+	}
+
+	return kit, rows.Err()
+}
+
+// SetKitComponents replaces a kit's bill of materials
+func (p *PostgresService) SetKitComponents(ctx context.Context, organizationID, kitID string, req models.SetKitComponentsRequest) (*models.Kit, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var isVariantParent bool
+	err = tx.QueryRowContext(ctx, `SELECT variant_attributes IS NOT NULL FROM skus WHERE organization_id = $1 AND id = $2`, organizationID, kitID).Scan(&isVariantParent)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("SKU not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if isVariantParent {
+		return nil, fmt.Errorf("invalid kit components: a SKU with variants cannot be a kit")
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM kit_components WHERE organization_id = $1 AND kit_sku_id = $2`, organizationID, kitID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool, len(req.Components))
+	for _, component := range req.Components {
+		if component.ComponentSKUID == kitID {
+			return nil, fmt.Errorf("invalid kit components: a kit cannot contain itself")
+		}
+		if seen[component.ComponentSKUID] {
+			return nil, fmt.Errorf("invalid kit components: component %s is listed twice", component.ComponentSKUID)
+		}
+		seen[component.ComponentSKUID] = true
+
+		var componentIsVariantParent bool
+		err := tx.QueryRowContext(ctx, `SELECT variant_attributes IS NOT NULL FROM skus WHERE organization_id = $1 AND id = $2`, organizationID, component.ComponentSKUID).Scan(&componentIsVariantParent)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invalid kit components: SKU not found: %s", component.ComponentSKUID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if componentIsVariantParent {
+			return nil, fmt.Errorf("invalid kit components: %s has variants, use a variant instead", component.ComponentSKUID)
+		}
+
+		// A component must not be built, directly or further down, from this kit
+		var cycle bool
+		err = tx.QueryRowContext(ctx, `
+			WITH RECURSIVE parts AS (
+				SELECT component_sku_id FROM kit_components WHERE organization_id = $1 AND kit_sku_id = $2
+				UNION
+				SELECT kc.component_sku_id FROM kit_components kc JOIN parts ON kc.kit_sku_id = parts.component_sku_id
+			)
+			SELECT EXISTS (SELECT 1 FROM parts WHERE component_sku_id = $3)
+		`, organizationID, component.ComponentSKUID, kitID).Scan(&cycle)
+		if err != nil {
+			return nil, err
+		}
+		if cycle {
+			return nil, fmt.Errorf("invalid kit components: %s is itself built from this kit", component.ComponentSKUID)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO kit_components (organization_id, kit_sku_id, component_sku_id, quantity, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $5)
+		`, organizationID, kitID, component.ComponentSKUID, component.Quantity, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	kit, err := getKit(ctx, tx, organizationID, kitID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeSKUEvent(ctx, tx, organizationID, "sku.updated", kitID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return kit, nil
+}
+
+// AssembleKit issues the components for req.Quantity kits at their weighted costs and
+// receives the kits at the total cost of what was issued, all in one transaction
+func (p *PostgresService) AssembleKit(ctx context.Context, organizationID, userID, kitID string, req models.KitAssemblyRequest) (*models.KitAssemblyResponse, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	kit, err := getKit(ctx, tx, organizationID, kitID)
+	if err != nil {
+		return nil, err
+	}
+	if len(kit.Components) == 0 {
+		return nil, fmt.Errorf("SKU is not a kit")
+	}
+
+	notes := req.Notes
+	if notes == nil {
+		note := fmt.Sprintf("Assembly of %d x %s", req.Quantity, kit.SKUCode)
+		notes = &note
+	}
+
+	transactions := make([]*models.Transaction, 0, len(kit.Components)+1)
+	var totalCost float64
+	for _, component := range kit.Components {
+		inventory, err := getInventoryForUpdate(ctx, tx, organizationID, component.ComponentSKUID)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("insufficient inventory: no stock of %s", component.SKUCode)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		transaction, err := createTransactionTx(ctx, tx, organizationID, userID, models.CreateTransactionRequest{
+			SKUID:           component.ComponentSKUID,
+			TransactionType: "out",
+			Quantity:        component.Quantity * req.Quantity,
+			UnitCost:        inventory.WeightedCost,
+			ReferenceNumber: req.ReferenceNumber,
+			Notes:           notes,
+		})
+		if err != nil {
+			return nil, err
+		}
+		totalCost += transaction.TotalCost
+		transactions = append(transactions, transaction)
+	}
+
+	transaction, err := createTransactionTx(ctx, tx, organizationID, userID, models.CreateTransactionRequest{
+		SKUID:           kitID,
+		TransactionType: "in",
+		Quantity:        req.Quantity,
+		UnitCost:        totalCost / float64(req.Quantity),
+		ReferenceNumber: req.ReferenceNumber,
+		Notes:           notes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	transactions = append(transactions, transaction)
+
+	return commitKitAssembly(ctx, tx, organizationID, kitID, transactions)
+}
+
+// DisassembleKit issues req.Quantity kits at the kit's weighted cost and receives the
+// components back. The kit value is split across components in proportion to their
+// current weighted costs, or by quantity when none of them has a cost yet.
+func (p *PostgresService) DisassembleKit(ctx context.Context, organizationID, userID, kitID string, req models.KitAssemblyRequest) (*models.KitAssemblyResponse, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	kit, err := getKit(ctx, tx, organizationID, kitID)
+	if err != nil {
+		return nil, err
+	}
+	if len(kit.Components) == 0 {
+		return nil, fmt.Errorf("SKU is not a kit")
+	}
+
+	inventory, err := getInventoryForUpdate(ctx, tx, organizationID, kitID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("insufficient inventory: no stock of %s", kit.SKUCode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	notes := req.Notes
+	if notes == nil {
+		note := fmt.Sprintf("Disassembly of %d x %s", req.Quantity, kit.SKUCode)
+		notes = &note
+	}
+
+	transactions := make([]*models.Transaction, 0, len(kit.Components)+1)
+	transaction, err := createTransactionTx(ctx, tx, organizationID, userID, models.CreateTransactionRequest{
+		SKUID:           kitID,
+		TransactionType: "out",
+		Quantity:        req.Quantity,
+		UnitCost:        inventory.WeightedCost,
+		ReferenceNumber: req.ReferenceNumber,
+		Notes:           notes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	transactions = append(transactions, transaction)
+	kitValue := transaction.TotalCost
+
+	unitsPerKit := 0
+	for _, component := range kit.Components {
+		unitsPerKit += component.Quantity
+	}
+
+	for _, component := range kit.Components {
+		share := float64(component.Quantity) / float64(unitsPerKit)
+		if kit.ComponentCost > 0 {
+			share = component.WeightedCost * float64(component.Quantity) / kit.ComponentCost
+		}
+		quantity := component.Quantity * req.Quantity
+
+		transaction, err := createTransactionTx(ctx, tx, organizationID, userID, models.CreateTransactionRequest{
+			SKUID:           component.ComponentSKUID,
+			TransactionType: "in",
+			Quantity:        quantity,
+			UnitCost:        kitValue * share / float64(quantity),
+			ReferenceNumber: req.ReferenceNumber,
+			Notes:           notes,
+		})
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	return commitKitAssembly(ctx, tx, organizationID, kitID, transactions)
+}
+
+func commitKitAssembly(ctx context.Context, tx *sql.Tx, organizationID, kitID string, transactions []*models.Transaction) (*models.KitAssemblyResponse, error) {
+	kit, err := getKit(ctx, tx, organizationID, kitID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &models.KitAssemblyResponse{
+		Kit:          kit,
+		Transactions: transactions,
+	}, nil
+}
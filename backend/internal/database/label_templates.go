@@ -0,0 +1,324 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/labels"
+	"flex-erp-poc/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// labelDPIs are the printer resolutions ZPL output can target
+var labelDPIs = []int{203, 300, 600}
+
+const labelTemplateColumns = `id, organization_id, name, width_mm, height_mm, fields, price_prefix, dpi, page_size,
+	margin_mm, gap_mm, is_default, created_at, updated_at`
+
+func scanLabelTemplate(row rowScanner) (*models.LabelTemplate, error) {
+	t := &models.LabelTemplate{}
+	err := row.Scan(
+		&t.ID,
+		&t.OrganizationID,
+		&t.Name,
+		&t.WidthMM,
+		&t.HeightMM,
+		pq.Array(&t.Fields),
+		&t.PricePrefix,
+		&t.DPI,
+		&t.PageSize,
+		&t.MarginMM,
+		&t.GapMM,
+		&t.IsDefault,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if t.Fields == nil {
+		t.Fields = []string{}
+	}
+	return t, nil
+}
+
+// Label Template Methods
+
+func (p *PostgresService) GetLabelTemplates(ctx context.Context, organizationID string) ([]*models.LabelTemplate, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT `+labelTemplateColumns+`
+		FROM label_templates
+		WHERE organization_id = $1
+		ORDER BY is_default DESC, name
+	`, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := make([]*models.LabelTemplate, 0)
+	for rows.Next() {
+		t, err := scanLabelTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// GetLabelTemplate returns a template by ID. Without an ID it returns the
+// organization's default template, or the built-in one when there is none.
+func (p *PostgresService) GetLabelTemplate(ctx context.Context, organizationID, templateID string) (*models.LabelTemplate, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if templateID == "" {
+		t, err := scanLabelTemplate(p.DB.QueryRowContext(ctx, `
+			SELECT `+labelTemplateColumns+` FROM label_templates WHERE organization_id = $1 AND is_default
+		`, organizationID))
+		if err == sql.ErrNoRows {
+			return models.DefaultLabelTemplate(), nil
+		}
+		return t, err
+	}
+
+	if !isUUID(templateID) {
+		return nil, fmt.Errorf("label template not found")
+	}
+	t, err := scanLabelTemplate(p.DB.QueryRowContext(ctx, `
+		SELECT `+labelTemplateColumns+` FROM label_templates WHERE organization_id = $1 AND id = $2
+	`, organizationID, templateID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("label template not found")
+	}
+	return t, err
+}
+
+func (p *PostgresService) CreateLabelTemplate(ctx context.Context, organizationID string, req models.CreateLabelTemplateRequest) (*models.LabelTemplate, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	defaults := models.DefaultLabelTemplate()
+	t := &models.LabelTemplate{
+		Name:        strings.TrimSpace(req.Name),
+		WidthMM:     req.WidthMM,
+		HeightMM:    req.HeightMM,
+		Fields:      req.Fields,
+		PricePrefix: req.PricePrefix,
+		DPI:         req.DPI,
+		PageSize:    req.PageSize,
+		MarginMM:    defaults.MarginMM,
+		GapMM:       req.GapMM,
+		IsDefault:   req.IsDefault,
+	}
+	if t.Fields == nil {
+		t.Fields = defaults.Fields
+	}
+	if t.DPI == 0 {
+		t.DPI = defaults.DPI
+	}
+	if t.PageSize == "" {
+		t.PageSize = defaults.PageSize
+	}
+	if req.MarginMM != nil {
+		t.MarginMM = *req.MarginMM
+	}
+	if err := validateLabelTemplate(t); err != nil {
+		return nil, err
+	}
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if t.IsDefault {
+		if err := clearDefaultLabelTemplate(ctx, tx, organizationID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	t, err = scanLabelTemplate(tx.QueryRowContext(ctx, `
+		INSERT INTO label_templates (organization_id, name, width_mm, height_mm, fields, price_prefix, dpi, page_size,
+			margin_mm, gap_mm, is_default, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12)
+		RETURNING `+labelTemplateColumns,
+		organizationID, t.Name, t.WidthMM, t.HeightMM, pq.Array(t.Fields), t.PricePrefix, t.DPI, t.PageSize,
+		t.MarginMM, t.GapMM, t.IsDefault, now))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (p *PostgresService) UpdateLabelTemplate(ctx context.Context, organizationID, templateID string, req models.UpdateLabelTemplateRequest) (*models.LabelTemplate, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if templateID == "" {
+		return nil, fmt.Errorf("label template not found")
+	}
+	t, err := p.GetLabelTemplate(ctx, organizationID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		t.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.WidthMM != nil {
+		t.WidthMM = *req.WidthMM
+	}
+	if req.HeightMM != nil {
+		t.HeightMM = *req.HeightMM
+	}
+	if req.Fields != nil {
+		t.Fields = req.Fields
+	}
+	if req.PricePrefix != nil {
+		t.PricePrefix = *req.PricePrefix
+	}
+	if req.DPI != nil {
+		t.DPI = *req.DPI
+	}
+	if req.PageSize != nil {
+		t.PageSize = *req.PageSize
+	}
+	if req.MarginMM != nil {
+		t.MarginMM = *req.MarginMM
+	}
+	if req.GapMM != nil {
+		t.GapMM = *req.GapMM
+	}
+	if req.IsDefault != nil {
+		t.IsDefault = *req.IsDefault
+	}
+	if err := validateLabelTemplate(t); err != nil {
+		return nil, err
+	}
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if t.IsDefault {
+		if err := clearDefaultLabelTemplate(ctx, tx, organizationID); err != nil {
+			return nil, err
+		}
+	}
+
+	t, err = scanLabelTemplate(tx.QueryRowContext(ctx, `
+		UPDATE label_templates
+		SET name = $3, width_mm = $4, height_mm = $5, fields = $6, price_prefix = $7, dpi = $8, page_size = $9,
+			margin_mm = $10, gap_mm = $11, is_default = $12, updated_at = $13
+		WHERE organization_id = $1 AND id = $2
+		RETURNING `+labelTemplateColumns,
+		organizationID, templateID, t.Name, t.WidthMM, t.HeightMM, pq.Array(t.Fields), t.PricePrefix, t.DPI, t.PageSize,
+		t.MarginMM, t.GapMM, t.IsDefault, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (p *PostgresService) DeleteLabelTemplate(ctx context.Context, organizationID, templateID string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if !isUUID(templateID) {
+		return fmt.Errorf("label template not found")
+	}
+	result, err := p.DB.ExecContext(ctx, `DELETE FROM label_templates WHERE organization_id = $1 AND id = $2`, organizationID, templateID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("label template not found")
+	}
+	return nil
+}
+
+// clearDefaultLabelTemplate unsets the current default before another template takes over
+func clearDefaultLabelTemplate(ctx context.Context, tx *sql.Tx, organizationID string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE label_templates SET is_default = false, updated_at = $2
+		WHERE organization_id = $1 AND is_default
+	`, organizationID, time.Now())
+	return err
+}
+
+func validateLabelTemplate(t *models.LabelTemplate) error {
+	if t.Name == "" || len(t.Name) > 100 {
+		return fmt.Errorf("invalid label template: name is required and must be at most 100 characters")
+	}
+	if t.WidthMM < 10 || t.WidthMM > 250 || t.HeightMM < 5 || t.HeightMM > 250 {
+		return fmt.Errorf("invalid label template: labels must be 10-250 mm wide and 5-250 mm high")
+	}
+	if len(t.PricePrefix) > 10 {
+		return fmt.Errorf("invalid label template: price prefix must be at most 10 characters")
+	}
+	if t.MarginMM < 0 || t.MarginMM > 50 || t.GapMM < 0 || t.GapMM > 50 {
+		return fmt.Errorf("invalid label template: margin and gap must be between 0 and 50 mm")
+	}
+
+	dpiOK := false
+	for _, dpi := range labelDPIs {
+		if t.DPI == dpi {
+			dpiOK = true
+		}
+	}
+	if !dpiOK {
+		return fmt.Errorf("invalid label template: dpi must be 203, 300 or 600")
+	}
+
+	if len(t.Fields) == 0 {
+		return fmt.Errorf("invalid label template: at least one field is required")
+	}
+	seen := make(map[string]bool, len(t.Fields))
+	for _, field := range t.Fields {
+		if !containsString(models.LabelFields, field) || seen[field] {
+			return fmt.Errorf("invalid label template: fields must be unique and one of %s", strings.Join(models.LabelFields, ", "))
+		}
+		seen[field] = true
+	}
+
+	if _, _, _, _, err := labels.Sheet(t); err != nil {
+		return fmt.Errorf("invalid label template: %s", err.Error())
+	}
+	return nil
+}
+
+// GetSKUsByID returns the organization's SKUs with the given IDs, keyed by ID.
+// IDs that are not SKUs of the organization are left out.
+func (p *PostgresService) GetSKUsByID(ctx context.Context, organizationID string, ids []string) (map[string]*models.SKU, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	valid := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if isUUID(id) {
+			valid = append(valid, id)
+		}
+	}
+	return getSKUsByID(ctx, p.DB, organizationID, valid)
+}
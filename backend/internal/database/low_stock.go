@@ -0,0 +1,72 @@
+package database
+
+import "context"
+
+// lowStockThresholdSQL is the organization's low stock threshold, 0 when unset
+const lowStockThresholdSQL = `COALESCE((o.business_rules->>'low_stock_threshold')::int, 0)`
+
+// EvaluateLowStock records inventory.low_stock for active SKUs at or below their
+// organization's threshold that were not reported yet, which catches stock that
+// became low without an issue taking it there, and clears the mark on stock back
+// above the threshold. It returns how many SKUs it reported.
+func (p *PostgresService) EvaluateLowStock(ctx context.Context) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE inventory i SET low_stock_since = NULL
+		FROM organizations o
+		WHERE o.id = i.organization_id AND i.low_stock_since IS NOT NULL
+			AND (`+lowStockThresholdSQL+` <= 0 OR i.quantity > `+lowStockThresholdSQL+`)
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT i.organization_id, i.sku_id, `+lowStockThresholdSQL+`
+		FROM inventory i
+		JOIN organizations o ON o.id = i.organization_id
+		JOIN skus s ON s.id = i.sku_id
+		WHERE i.low_stock_since IS NULL AND s.is_active AND s.archived_at IS NULL
+			AND `+lowStockThresholdSQL+` > 0 AND i.quantity <= `+lowStockThresholdSQL+`
+		ORDER BY i.organization_id, i.sku_id
+		FOR UPDATE OF i
+	`)
+	if err != nil {
+		return 0, err
+	}
+	type lowStock struct {
+		organizationID, skuID string
+		threshold             int
+	}
+	var low []lowStock
+	for rows.Next() {
+		var l lowStock
+		if err := rows.Scan(&l.organizationID, &l.skuID, &l.threshold); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		low = append(low, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, l := range low {
+		if err := recordLowStock(ctx, tx, l.organizationID, l.skuID, l.threshold); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(low), nil
+}
@@ -0,0 +1,518 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"flex-erp-poc/internal/models"
+)
+
+// MemoryStore keeps the core aggregates in memory, for tests that exercise
+// handlers without Postgres. It follows PostgresService's results and errors,
+// with these differences:
+//   - no custom fields are defined, so custom field values and filters are
+//     rejected as unknown fields, as in an organization that has defined none
+//   - categories are only the paths SKUs were given; suppliers are names only,
+//     so supplier IDs are never found
+//   - there are no reservations, variants, translations or outbox events
+//   - search matches substrings and ranks by where they matched, and text sorts
+//     by bytes rather than collation
+//   - operations never block, so they ignore their context and never time out
+type MemoryStore struct {
+	mu sync.RWMutex
+
+	organizations []*Organization
+	businessRules map[string]models.BusinessRules
+	locales       map[string]string // organization default locales, "en" when unset
+	users         []*models.UserWithDetails
+	categories    []*memoryCategory
+	skus          []*memorySKU
+	inventory     []*models.Inventory
+	transactions  []*models.Transaction
+	fieldAliases  []*models.FieldAlias
+	changeLogs    []*models.ChangeLog
+	changeLogSeq  int // last change log ID, IDs are not reused
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		businessRules: make(map[string]models.BusinessRules),
+		locales:       make(map[string]string),
+	}
+}
+
+// AddOrganization creates an organization to add users and SKUs to
+func (m *MemoryStore) AddOrganization(name string) *Organization {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	org := &Organization{ID: newMemoryID(), Name: name, CreatedAt: now, UpdatedAt: now}
+	m.organizations = append(m.organizations, org)
+	return org
+}
+
+// SetBusinessRules sets the rules CreateTransaction enforces for an organization
+func (m *MemoryStore) SetBusinessRules(organizationID string, rules models.BusinessRules) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.businessRules[organizationID] = rules
+}
+
+// SetDefaultLocale sets an organization's default locale, "en" until set
+func (m *MemoryStore) SetDefaultLocale(organizationID, locale string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.locales[organizationID] = locale
+}
+
+// newMemoryID returns a random version 4 UUID
+func newMemoryID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Organization and User Methods
+
+func (m *MemoryStore) organization(id string) *Organization {
+	for _, org := range m.organizations {
+		if org.ID == id {
+			return org
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) organizationName(id string) string {
+	if org := m.organization(id); org != nil {
+		return org.Name
+	}
+	return ""
+}
+
+func (m *MemoryStore) GetOrganizationByID(ctx context.Context, id string) (*Organization, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	org := m.organization(id)
+	if org == nil {
+		return nil, sql.ErrNoRows
+	}
+	copied := *org
+	return &copied, nil
+}
+
+func (m *MemoryStore) GetDefaultOrganizationID(ctx context.Context) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.organizations) == 0 {
+		return "", sql.ErrNoRows
+	}
+	return m.organizations[0].ID, nil
+}
+
+func (m *MemoryStore) findUser(match func(*models.UserWithDetails) bool) (*User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if match(user) {
+			return &User{
+				ID:             user.ID,
+				OrganizationID: user.OrganizationID,
+				Email:          user.Email,
+				Name:           user.Name,
+				Role:           user.Role,
+				CreatedAt:      user.CreatedAt,
+				UpdatedAt:      user.UpdatedAt,
+			}, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MemoryStore) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	return m.findUser(func(user *models.UserWithDetails) bool { return user.Email == email })
+}
+
+func (m *MemoryStore) GetUserByID(ctx context.Context, id string) (*User, error) {
+	return m.findUser(func(user *models.UserWithDetails) bool { return user.ID == id })
+}
+
+func (m *MemoryStore) userWithDetails(user *models.UserWithDetails) *models.UserWithDetails {
+	copied := *user
+	copied.OrganizationName = m.organizationName(user.OrganizationID)
+	return &copied
+}
+
+func (m *MemoryStore) GetUsersWithDetails(ctx context.Context, organizationID string, params models.UserListParams) ([]*models.UserWithDetails, *models.PageInfo, error) {
+	page, err := newPageQuery(userSorts, params.Sort, params.Cursor, params.Limit, pageOffset(params.Page, params.Limit))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	users := make([]*models.UserWithDetails, 0)
+	for _, user := range m.users {
+		if user.OrganizationID != organizationID ||
+			(params.Role != nil && *params.Role != "" && user.Role != *params.Role) ||
+			(params.IsActive != nil && user.IsActive != *params.IsActive) ||
+			(params.Search != nil && *params.Search != "" && !containsFold(*params.Search, user.Name, user.Email)) {
+			continue
+		}
+		users = append(users, m.userWithDetails(user))
+	}
+
+	return memoryPage(page, users, func(user *models.UserWithDetails, key string) []interface{} {
+		switch key {
+		case "name":
+			return []interface{}{user.Name}
+		case "email":
+			return []interface{}{user.Email}
+		case "role":
+			return []interface{}{user.Role}
+		case "last_login_at":
+			if user.LastLoginAt == nil {
+				return []interface{}{time.Time{}}
+			}
+			return []interface{}{*user.LastLoginAt}
+		}
+		return []interface{}{user.CreatedAt}
+	}, func(user *models.UserWithDetails) interface{} { return user.ID })
+}
+
+func (m *MemoryStore) CreateUser(ctx context.Context, organizationID string, req models.CreateUserRequest) (*models.UserWithDetails, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, user := range m.users {
+		if user.Email == req.Email {
+			return nil, fmt.Errorf("user with this email already exists")
+		}
+	}
+
+	now := time.Now()
+	user := &models.UserWithDetails{
+		ID:             newMemoryID(),
+		OrganizationID: organizationID,
+		Email:          req.Email,
+		Name:           req.Name,
+		Role:           req.Role,
+		IsActive:       true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	m.users = append(m.users, user)
+	return m.userWithDetails(user), nil
+}
+
+func (m *MemoryStore) UpdateUser(ctx context.Context, organizationID, userID string, req models.UpdateUserRequest) (*models.UserWithDetails, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, user := range m.users {
+		if user.OrganizationID != organizationID || user.ID != userID {
+			continue
+		}
+		user.Name, user.Role, user.UpdatedAt = req.Name, req.Role, time.Now()
+		if req.IsActive != nil {
+			user.IsActive = *req.IsActive
+		}
+		if req.PreferredLocale != nil {
+			user.PreferredLocale = nil
+			if *req.PreferredLocale != "" {
+				locale := *req.PreferredLocale
+				user.PreferredLocale = &locale
+			}
+		}
+		return m.userWithDetails(user), nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MemoryStore) DeleteUser(ctx context.Context, organizationID, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, user := range m.users {
+		if user.OrganizationID == organizationID && user.ID == userID {
+			m.users = append(m.users[:i], m.users[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("user not found")
+}
+
+// Locale Preference Methods
+
+func (m *MemoryStore) GetOrganizationDefaultLocale(ctx context.Context, organizationID string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.organization(organizationID) == nil {
+		return "", sql.ErrNoRows
+	}
+	if locale, ok := m.locales[organizationID]; ok {
+		return locale, nil
+	}
+	return "en", nil
+}
+
+func (m *MemoryStore) GetUserPreferredLocale(ctx context.Context, userID string) (*string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if user.ID == userID {
+			if user.PreferredLocale == nil {
+				return nil, nil
+			}
+			locale := *user.PreferredLocale
+			return &locale, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MemoryStore) CheckUserPermission(ctx context.Context, userID string, resource, action string) (bool, error) {
+	user, err := m.GetUserByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	role := models.GetRoleByName(user.Role)
+	if role == nil {
+		return false, nil
+	}
+	return role.HasPermission(resource, action), nil
+}
+
+// Paging
+
+// memoryPage sorts, filters and pages rows the way the list queries do. key
+// returns a row's values for a sort key and id its ID; values are strings, ints,
+// float64s or times.
+func memoryPage[T any](page *pageQuery, rows []T, key func(row T, name string) []interface{}, id func(row T) interface{}) ([]T, *models.PageInfo, error) {
+	total := len(rows)
+	name := strings.TrimPrefix(page.sort, "-")
+	desc := page.desc != page.reversed()
+
+	type keyedRow struct {
+		row    T
+		values []interface{}
+	}
+	keyed := make([]keyedRow, 0, len(rows))
+	var position []interface{}
+	for _, row := range rows {
+		values := append(key(row, name), id(row))
+		if page.cursor != nil {
+			if position == nil {
+				var err error
+				if position, err = parseCursorValues(page.cursor, values); err != nil {
+					return nil, nil, err
+				}
+			}
+			// Only rows past the cursor in the direction of the query
+			if c := compareSortValues(values, position); c == 0 || (c < 0) != desc {
+				continue
+			}
+		}
+		keyed = append(keyed, keyedRow{row, values})
+	}
+
+	sort.SliceStable(keyed, func(i, j int) bool {
+		c := compareSortValues(keyed[i].values, keyed[j].values)
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	keyed = keyed[min(page.offset, len(keyed)):]
+	if page.limit > 0 && len(keyed) > page.limit+1 {
+		keyed = keyed[:page.limit+1]
+	}
+
+	items := make([]T, 0, len(keyed))
+	keys := make([]*listCursor, 0, len(keyed))
+	for _, row := range keyed {
+		cursor := &listCursor{Sort: page.sort}
+		for _, value := range row.values[:len(row.values)-1] {
+			cursor.Values = append(cursor.Values, formatSortValue(value))
+		}
+		cursor.ID = formatSortValue(row.values[len(row.values)-1])
+		items = append(items, row.row)
+		keys = append(keys, cursor)
+	}
+
+	items, info := finishPage(page, items, keys, total)
+	return items, info, nil
+}
+
+func formatSortValue(value interface{}) string {
+	switch v := value.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(value)
+}
+
+// parseCursorValues reads a cursor's position back as the types of like
+func parseCursorValues(cursor *listCursor, like []interface{}) ([]interface{}, error) {
+	texts := append(append([]string{}, cursor.Values...), cursor.ID)
+	values := make([]interface{}, len(texts))
+	for i, text := range texts {
+		var err error
+		switch like[i].(type) {
+		case int:
+			values[i], err = strconv.Atoi(text)
+		case float64:
+			values[i], err = strconv.ParseFloat(text, 64)
+		case time.Time:
+			values[i], err = time.Parse(time.RFC3339Nano, text)
+		default:
+			values[i] = text
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid cursor")
+		}
+	}
+	return values, nil
+}
+
+func compareSortValues(a, b []interface{}) int {
+	for i := range a {
+		var c int
+		switch v := a[i].(type) {
+		case int:
+			c = compareOrdered(v, b[i].(int))
+		case float64:
+			c = compareOrdered(v, b[i].(float64))
+		case time.Time:
+			c = v.Compare(b[i].(time.Time))
+		default:
+			c = strings.Compare(fmt.Sprint(v), fmt.Sprint(b[i]))
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func compareOrdered[T int | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Filtering
+
+// containsFold reports whether any of the texts contains term, ignoring case
+func containsFold(term string, texts ...string) bool {
+	term = strings.ToLower(term)
+	for _, text := range texts {
+		if strings.Contains(strings.ToLower(text), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// memorySearch matches term the way the list searches do, minus the fuzzy and
+// full-text matching: an exact match of one of the exact fields ranks first,
+// then each field containing the term adds searchTextWeight. Only highlighted
+// fields are marked in the result. It returns nil when nothing matched.
+func memorySearch(term string, exact, highlighted map[string]*string, plain ...*string) *models.SearchMatch {
+	var rank float64
+	for name, value := range exact {
+		if value == nil || !strings.EqualFold(*value, term) {
+			continue
+		}
+		if name == "barcode" {
+			rank += searchBarcodeWeight
+		} else {
+			rank += searchExactWeight
+		}
+	}
+
+	headlines := make(map[string]string)
+	lowerTerm := strings.ToLower(term)
+	for name, value := range highlighted {
+		if value == nil {
+			continue
+		}
+		lower := strings.ToLower(*value)
+		i := strings.Index(lower, lowerTerm)
+		if i < 0 {
+			continue
+		}
+		rank += searchTextWeight
+		// Offsets in the lowercase text only carry over when lowering kept the length
+		if len(lower) == len(*value) {
+			end := i + len(lowerTerm)
+			headlines[name] = (*value)[:i] + "<mark>" + (*value)[i:end] + "</mark>" + (*value)[end:]
+		}
+	}
+	for _, value := range plain {
+		if value != nil && containsFold(term, *value) {
+			rank += searchTextWeight
+		}
+	}
+
+	if rank == 0 {
+		return nil
+	}
+	return newSearchMatch(rank, headlines)
+}
+
+// memoryCustomFieldFilters rejects every filter: the store defines no custom fields
+func memoryCustomFieldFilters(filters map[string]string) error {
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+	name := keys[0]
+	if i := strings.LastIndex(name, "."); i > 0 {
+		name = name[:i]
+	}
+	return fmt.Errorf("invalid custom field filter: unknown field %s", name)
+}
+
+// memoryCustomFields checks custom field changes and returns the resulting
+// values. With no fields defined every change names an unknown field.
+func memoryCustomFields(changes models.CustomFieldValues) (models.CustomFieldValues, error) {
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		return nil, fmt.Errorf("invalid custom field values: unknown field %s", names[0])
+	}
+	return models.CustomFieldValues{}, nil
+}
@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"flex-erp-poc/internal/models"
+)
+
+// Change Log Methods
+
+func (m *MemoryStore) addChangeLog(organizationID string, userID string, req models.CreateChangeLogRequest) *models.ChangeLog {
+	m.changeLogSeq++
+	changeLog := &models.ChangeLog{
+		ID:             m.changeLogSeq,
+		OrganizationID: organizationID,
+		UserID:         userID,
+		EntityType:     req.EntityType,
+		EntityID:       req.EntityID,
+		SkuID:          req.SkuID,
+		ChangeType:     req.ChangeType,
+		FieldName:      req.FieldName,
+		OldValue:       req.OldValue,
+		NewValue:       req.NewValue,
+		Reason:         req.Reason,
+		Metadata:       req.Metadata,
+		CreatedAt:      time.Now(),
+	}
+	m.changeLogs = append(m.changeLogs, changeLog)
+	copied := *changeLog
+	return &copied
+}
+
+// withNames copies an entry with the user and SKU names the queries join in
+func (m *MemoryStore) withNames(changeLog *models.ChangeLog) *models.ChangeLog {
+	copied := *changeLog
+	for _, user := range m.users {
+		if user.ID == changeLog.UserID {
+			name := user.Name
+			copied.UserName = &name
+		}
+	}
+	if changeLog.SkuID != nil {
+		for _, stored := range m.skus {
+			if stored.sku.ID == *changeLog.SkuID {
+				code, name := stored.sku.SKUCode, stored.sku.ProductName
+				copied.SkuCode, copied.SkuName = &code, &name
+			}
+		}
+	}
+	return &copied
+}
+
+func (m *MemoryStore) CreateChangeLog(ctx context.Context, organizationID string, userID string, req models.CreateChangeLogRequest) (*models.ChangeLog, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.addChangeLog(organizationID, userID, req), nil
+}
+
+func (m *MemoryStore) LogChange(ctx context.Context, organizationID string, userID string, req models.CreateChangeLogRequest) error {
+	_, err := m.CreateChangeLog(ctx, organizationID, userID, req)
+	return err
+}
+
+func (m *MemoryStore) GetChangeLogs(ctx context.Context, organizationID string, params models.ChangeLogListParams) ([]*models.ChangeLog, *models.PageInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.listChangeLogs(organizationID, params)
+}
+
+func (m *MemoryStore) listChangeLogs(organizationID string, params models.ChangeLogListParams) ([]*models.ChangeLog, *models.PageInfo, error) {
+	page, err := newPageQuery(changeLogSorts, params.Sort, params.Cursor, params.Limit, params.Offset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	equal := func(value *string, filter *string) bool {
+		return filter == nil || (value != nil && *value == *filter)
+	}
+	changeLogs := make([]*models.ChangeLog, 0)
+	for _, changeLog := range m.changeLogs {
+		if changeLog.OrganizationID != organizationID ||
+			!equal(&changeLog.EntityType, params.EntityType) ||
+			!equal(changeLog.EntityID, params.EntityID) ||
+			!equal(changeLog.SkuID, params.SkuID) ||
+			!equal(&changeLog.UserID, params.UserID) ||
+			!equal(&changeLog.ChangeType, params.ChangeType) ||
+			(params.LastDays != nil && changeLog.CreatedAt.Before(time.Now().AddDate(0, 0, -*params.LastDays))) ||
+			(params.DateFrom != nil && changeLog.CreatedAt.Before(*params.DateFrom)) ||
+			(params.DateTo != nil && changeLog.CreatedAt.After(*params.DateTo)) {
+			continue
+		}
+		if params.BatchID != nil {
+			var metadata struct {
+				BatchID *string `json:"batch_id"`
+			}
+			if json.Unmarshal(changeLog.Metadata, &metadata) != nil || !equal(metadata.BatchID, params.BatchID) {
+				continue
+			}
+		}
+		changeLogs = append(changeLogs, m.withNames(changeLog))
+	}
+
+	return memoryPage(page, changeLogs, func(changeLog *models.ChangeLog, key string) []interface{} {
+		switch key {
+		case "entity_type":
+			return []interface{}{changeLog.EntityType}
+		case "change_type":
+			return []interface{}{changeLog.ChangeType}
+		}
+		return []interface{}{changeLog.CreatedAt}
+	}, func(changeLog *models.ChangeLog) interface{} { return changeLog.ID })
+}
+
+// GetSKUChangeLogs returns the newest 100 entries about a SKU from the last days
+func (m *MemoryStore) GetSKUChangeLogs(ctx context.Context, organizationID string, skuID string, lastDays int) ([]*models.ChangeLog, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	since := time.Now().AddDate(0, 0, -lastDays)
+	var changeLogs []*models.ChangeLog
+	for i := len(m.changeLogs) - 1; i >= 0 && len(changeLogs) < 100; i-- {
+		changeLog := m.changeLogs[i]
+		about := (changeLog.SkuID != nil && *changeLog.SkuID == skuID) ||
+			(changeLog.EntityID != nil && *changeLog.EntityID == skuID && changeLog.EntityType == "sku")
+		if changeLog.OrganizationID == organizationID && about && !changeLog.CreatedAt.Before(since) {
+			changeLogs = append(changeLogs, m.withNames(changeLog))
+		}
+	}
+	return changeLogs, nil
+}
+
+func (m *MemoryStore) GetActivitySummary(ctx context.Context, organizationID string, lastDays int) (*models.ActivitySummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	summary := &models.ActivitySummary{ChangesByType: make(map[string]int)}
+	users := make(map[string]*models.UserActivitySummary)
+	var userOrder []string
+	for _, changeLog := range m.changeLogs {
+		if changeLog.OrganizationID != organizationID {
+			continue
+		}
+		summary.TotalChanges++
+		if !changeLog.CreatedAt.Before(now.AddDate(0, 0, -1)) {
+			summary.RecentChanges++
+		}
+		if changeLog.CreatedAt.Before(now.AddDate(0, 0, -lastDays)) {
+			continue
+		}
+		summary.ChangesByType[changeLog.ChangeType]++
+
+		user, ok := users[changeLog.UserID]
+		if !ok {
+			user = &models.UserActivitySummary{UserID: changeLog.UserID}
+			if named := m.withNames(changeLog); named.UserName != nil {
+				user.UserName = *named.UserName
+			}
+			users[changeLog.UserID] = user
+			userOrder = append(userOrder, changeLog.UserID)
+		}
+		user.Changes++
+	}
+
+	sort.SliceStable(userOrder, func(i, j int) bool { return users[userOrder[i]].Changes > users[userOrder[j]].Changes })
+	for _, userID := range userOrder[:min(len(userOrder), 5)] {
+		summary.TopUsers = append(summary.TopUsers, *users[userID])
+	}
+
+	recentActivity, _, err := m.listChangeLogs(organizationID, models.ChangeLogListParams{LastDays: &lastDays, Limit: 20})
+	if err != nil {
+		return nil, err
+	}
+	summary.RecentActivity = recentActivity
+	return summary, nil
+}
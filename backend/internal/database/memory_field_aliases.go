@@ -0,0 +1,207 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"flex-erp-poc/internal/models"
+)
+
+// Field Aliases Methods
+
+func (m *MemoryStore) GetFieldAliases(ctx context.Context, organizationID string, params models.FieldAliasListParams) ([]*models.FieldAlias, *models.PageInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.listFieldAliases(organizationID, params)
+}
+
+// listFieldAliases lists aliases with their own labels; there are no translations
+// to resolve params.Locales through
+func (m *MemoryStore) listFieldAliases(organizationID string, params models.FieldAliasListParams) ([]*models.FieldAlias, *models.PageInfo, error) {
+	page, err := newPageQuery(fieldAliasSorts, params.Sort, params.Cursor, params.Limit, params.Offset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aliases := make([]*models.FieldAlias, 0)
+	for _, alias := range m.fieldAliases {
+		if alias.OrganizationID != organizationID ||
+			(params.TableName != nil && alias.TableName != *params.TableName) ||
+			(params.IsHidden != nil && alias.IsHidden != *params.IsHidden) {
+			continue
+		}
+		copied := *alias
+		aliases = append(aliases, &copied)
+	}
+
+	return memoryPage(page, aliases, func(alias *models.FieldAlias, key string) []interface{} {
+		switch key {
+		case "display_name":
+			return []interface{}{alias.DisplayName}
+		case "updated_at":
+			return []interface{}{alias.UpdatedAt}
+		}
+		return []interface{}{alias.TableName, alias.SortOrder, alias.FieldName}
+	}, func(alias *models.FieldAlias) interface{} { return alias.ID })
+}
+
+func (m *MemoryStore) CreateFieldAlias(ctx context.Context, organizationID string, req models.CreateFieldAliasRequest) (*models.FieldAlias, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.createFieldAlias(organizationID, req)
+}
+
+func (m *MemoryStore) createFieldAlias(organizationID string, req models.CreateFieldAliasRequest) (*models.FieldAlias, error) {
+	for _, alias := range m.fieldAliases {
+		if alias.OrganizationID == organizationID && alias.TableName == req.TableName && alias.FieldName == req.FieldName {
+			return nil, fmt.Errorf("duplicate key value violates unique constraint")
+		}
+	}
+
+	now := time.Now()
+	alias := &models.FieldAlias{
+		ID:             newMemoryID(),
+		OrganizationID: organizationID,
+		TableName:      req.TableName,
+		FieldName:      req.FieldName,
+		DisplayName:    req.DisplayName,
+		Description:    req.Description,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if req.IsHidden != nil {
+		alias.IsHidden = *req.IsHidden
+	}
+	if req.SortOrder != nil {
+		alias.SortOrder = *req.SortOrder
+	}
+	m.fieldAliases = append(m.fieldAliases, alias)
+
+	copied := *alias
+	return &copied, nil
+}
+
+func (m *MemoryStore) UpdateFieldAlias(ctx context.Context, organizationID, aliasID string, req models.UpdateFieldAliasRequest) (*models.FieldAlias, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, alias := range m.fieldAliases {
+		if alias.OrganizationID != organizationID || alias.ID != aliasID {
+			continue
+		}
+		if req.DisplayName != nil {
+			alias.DisplayName = *req.DisplayName
+		}
+		if req.Description != nil {
+			description := *req.Description
+			alias.Description = &description
+		}
+		if req.IsHidden != nil {
+			alias.IsHidden = *req.IsHidden
+		}
+		if req.SortOrder != nil {
+			alias.SortOrder = *req.SortOrder
+		}
+		alias.UpdatedAt = time.Now()
+
+		copied := *alias
+		return &copied, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MemoryStore) DeleteFieldAlias(ctx context.Context, organizationID, aliasID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, alias := range m.fieldAliases {
+		if alias.OrganizationID == organizationID && alias.ID == aliasID {
+			m.fieldAliases = append(m.fieldAliases[:i], m.fieldAliases[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("field alias not found")
+}
+
+// GetTableFields returns a table's aliases with the metadata PostgresService
+// computes. No custom fields are defined.
+func (m *MemoryStore) GetTableFields(ctx context.Context, organizationID string, tableName string, locales []string) (*models.TableFieldsResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	aliases, _, err := m.listFieldAliases(organizationID, models.FieldAliasListParams{TableName: &tableName})
+	if err != nil {
+		return nil, err
+	}
+
+	hiddenFields := 0
+	customAliases := 0
+	var lastUpdated *time.Time
+	for _, alias := range aliases {
+		if alias.IsHidden {
+			hiddenFields++
+		}
+		for _, defaultField := range models.DefaultTableFields[tableName] {
+			if defaultField.FieldName == alias.FieldName && defaultField.DisplayName != alias.DisplayName {
+				customAliases++
+				break
+			}
+		}
+		if lastUpdated == nil || alias.UpdatedAt.After(*lastUpdated) {
+			lastUpdated = &alias.UpdatedAt
+		}
+	}
+
+	var locale string
+	if len(locales) > 0 {
+		locale = locales[0]
+	}
+
+	return &models.TableFieldsResponse{
+		TableName:    tableName,
+		Locale:       locale,
+		Fields:       aliases,
+		CustomFields: make([]*models.CustomField, 0),
+		Metadata: &models.TableFieldsMetadata{
+			TotalFields:   len(aliases),
+			HiddenFields:  hiddenFields,
+			CustomAliases: customAliases,
+			LastUpdated:   lastUpdated,
+		},
+	}, nil
+}
+
+func (m *MemoryStore) InitializeDefaultFieldAliases(ctx context.Context, organizationID string, tableName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, _, err := m.listFieldAliases(organizationID, models.FieldAliasListParams{TableName: &tableName, Limit: 1})
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return nil // Already initialized
+	}
+
+	defaultFields, exists := models.DefaultTableFields[tableName]
+	if !exists {
+		return fmt.Errorf("no default fields defined for table: %s", tableName)
+	}
+
+	for _, field := range defaultFields {
+		description, sortOrder := field.Description, field.SortOrder
+		_, err := m.createFieldAlias(organizationID, models.CreateFieldAliasRequest{
+			TableName:   tableName,
+			FieldName:   field.FieldName,
+			DisplayName: field.DisplayName,
+			Description: &description,
+			SortOrder:   &sortOrder,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create default alias for %s.%s: %w", tableName, field.FieldName, err)
+		}
+	}
+	return nil
+}
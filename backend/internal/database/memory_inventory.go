@@ -0,0 +1,506 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"flex-erp-poc/internal/models"
+)
+
+func (m *MemoryStore) findInventory(organizationID, skuID string) *models.Inventory {
+	for _, inventory := range m.inventory {
+		if inventory.OrganizationID == organizationID && inventory.SKUID == skuID {
+			return inventory
+		}
+	}
+	return nil
+}
+
+// cloneInventory copies an inventory record. Without reservations all of it is available.
+func cloneInventory(inventory *models.Inventory) *models.Inventory {
+	copied := *inventory
+	copied.CustomFields = models.CustomFieldValues{}
+	for name, value := range inventory.CustomFields {
+		copied.CustomFields[name] = value
+	}
+	copied.ReservedQuantity = 0
+	copied.AvailableQuantity = copied.Quantity
+	return &copied
+}
+
+// Inventory Methods
+
+func (m *MemoryStore) GetInventoryWithSKUs(ctx context.Context, organizationID string, params models.InventoryListParams) ([]*models.InventoryWithSKU, *models.PageInfo, error) {
+	searching := params.Search != nil && *params.Search != ""
+	list := inventorySorts
+	if searching {
+		list = list.withKey("relevance", sortColumn{"search_rank", "float8"})
+		list.defaultKey = "-relevance"
+	}
+
+	page, err := newPageQuery(list, params.Sort, params.Cursor, params.Limit, pageOffset(params.Page, params.Limit))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := memoryCustomFieldFilters(params.CustomFields); err != nil {
+		return nil, nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	items := make([]*models.InventoryWithSKU, 0)
+	for _, inventory := range m.inventory {
+		stored := m.findSKU(organizationID, inventory.SKUID)
+		if inventory.OrganizationID != organizationID || stored == nil || !stored.sku.IsActive ||
+			(params.MaxQuantity != nil && inventory.Quantity > *params.MaxQuantity) ||
+			!m.inCategory(organizationID, stored.sku.Category, params.CategoryID, params.Category) {
+			continue
+		}
+		record, sku := cloneInventory(inventory), cloneSKU(&stored.sku)
+		item := &models.InventoryWithSKU{
+			ID:                record.ID,
+			OrganizationID:    record.OrganizationID,
+			SKUID:             record.SKUID,
+			Quantity:          record.Quantity,
+			WeightedCost:      record.WeightedCost,
+			TotalValue:        record.TotalValue,
+			IsManualCost:      record.IsManualCost,
+			CreatedAt:         record.CreatedAt,
+			UpdatedAt:         record.UpdatedAt,
+			CustomFields:      record.CustomFields,
+			ReservedQuantity:  record.ReservedQuantity,
+			AvailableQuantity: record.AvailableQuantity,
+			SKUCode:           sku.SKUCode,
+			ProductName:       sku.ProductName,
+			Description:       sku.Description,
+			Category:          sku.Category,
+			Supplier:          sku.Supplier,
+			Barcode:           sku.Barcode,
+			IsActive:          sku.IsActive,
+		}
+		if searching {
+			if item.Search = skuSearch(sku, *params.Search); item.Search == nil {
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+
+	return memoryPage(page, items, func(item *models.InventoryWithSKU, key string) []interface{} {
+		switch key {
+		case "updated_at":
+			return []interface{}{item.UpdatedAt}
+		case "sku_code":
+			return []interface{}{item.SKUCode}
+		case "product_name":
+			return []interface{}{item.ProductName}
+		case "quantity":
+			return []interface{}{item.Quantity}
+		case "weighted_cost":
+			return []interface{}{item.WeightedCost}
+		case "total_value":
+			return []interface{}{item.TotalValue}
+		case "relevance":
+			return []interface{}{item.Search.Rank}
+		}
+		return []interface{}{item.CreatedAt}
+	}, func(item *models.InventoryWithSKU) interface{} { return item.ID })
+}
+
+// GetParentInventory rolls inventory up by parent SKU like PostgresService.GetParentInventory
+func (m *MemoryStore) GetParentInventory(ctx context.Context, organizationID string, params models.InventoryListParams) ([]*models.ParentInventory, error) {
+	if err := memoryCustomFieldFilters(params.CustomFields); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	groups := make(map[string]*models.ParentInventory)
+	firstCreated := make(map[string]time.Time)
+	order := make([]string, 0)
+	for _, inventory := range m.inventory {
+		stored := m.findSKU(organizationID, inventory.SKUID)
+		if inventory.OrganizationID != organizationID || stored == nil || !stored.sku.IsActive {
+			continue
+		}
+		parent := stored
+		if stored.sku.ParentSKUID != nil {
+			if parent = m.findSKU(organizationID, *stored.sku.ParentSKUID); parent == nil {
+				continue
+			}
+		}
+		if !m.inCategory(organizationID, parent.sku.Category, params.CategoryID, params.Category) {
+			continue
+		}
+		if params.Search != nil && *params.Search != "" &&
+			skuSearch(&parent.sku, *params.Search) == nil && skuSearch(&stored.sku, *params.Search) == nil {
+			continue
+		}
+
+		group, ok := groups[parent.sku.ID]
+		if !ok {
+			group = &models.ParentInventory{
+				SKUID:       parent.sku.ID,
+				SKUCode:     parent.sku.SKUCode,
+				ProductName: parent.sku.ProductName,
+				Category:    parent.sku.Category,
+			}
+			groups[parent.sku.ID] = group
+			firstCreated[parent.sku.ID] = inventory.CreatedAt
+			order = append(order, parent.sku.ID)
+		}
+		if stored.sku.ParentSKUID != nil {
+			group.VariantCount++
+		}
+		group.Quantity += inventory.Quantity
+		group.TotalValue += inventory.TotalValue
+		if inventory.CreatedAt.Before(firstCreated[parent.sku.ID]) {
+			firstCreated[parent.sku.ID] = inventory.CreatedAt
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool { return firstCreated[order[i]].After(firstCreated[order[j]]) })
+	if params.Limit > 0 {
+		offset := min(pageOffset(params.Page, params.Limit), len(order))
+		order = order[offset:min(offset+params.Limit, len(order))]
+	}
+
+	inventory := make([]*models.ParentInventory, 0, len(order))
+	for _, id := range order {
+		item := groups[id]
+		item.AvailableQuantity = item.Quantity - item.ReservedQuantity
+		if item.Quantity > 0 {
+			item.WeightedCost = item.TotalValue / float64(item.Quantity)
+		}
+		inventory = append(inventory, item)
+	}
+	return inventory, nil
+}
+
+func (m *MemoryStore) GetInventoryBySKUID(ctx context.Context, organizationID, skuID string) (*models.Inventory, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	inventory := m.findInventory(organizationID, skuID)
+	if inventory == nil {
+		return nil, sql.ErrNoRows
+	}
+	return cloneInventory(inventory), nil
+}
+
+func (m *MemoryStore) CreateInventoryForSKU(ctx context.Context, organizationID string, req models.CreateInventoryRequest) (*models.Inventory, error) {
+	customFields, err := memoryCustomFields(req.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.findSKU(organizationID, req.SKUID) == nil {
+		return nil, fmt.Errorf("SKU not found: %v", sql.ErrNoRows)
+	}
+	inventory, err := m.createInventory(organizationID, req.SKUID, req.Quantity, req.WeightedCost)
+	if err != nil {
+		return nil, err
+	}
+	inventory.CustomFields = customFields
+	return cloneInventory(inventory), nil
+}
+
+func (m *MemoryStore) createInventory(organizationID, skuID string, quantity int, weightedCost float64) (*models.Inventory, error) {
+	if m.findInventory(organizationID, skuID) != nil {
+		return nil, fmt.Errorf("duplicate key value violates unique constraint \"inventory_organization_id_sku_id_key\"")
+	}
+
+	now := time.Now()
+	inventory := &models.Inventory{
+		ID:             newMemoryID(),
+		OrganizationID: organizationID,
+		SKUID:          skuID,
+		Quantity:       quantity,
+		WeightedCost:   weightedCost,
+		TotalValue:     float64(quantity) * weightedCost,
+		CustomFields:   models.CustomFieldValues{},
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	m.inventory = append(m.inventory, inventory)
+	return inventory, nil
+}
+
+func (m *MemoryStore) UpdateManualCost(ctx context.Context, organizationID, skuID string, req models.UpdateManualCostRequest) (*models.Inventory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inventory := m.findInventory(organizationID, skuID)
+	if inventory == nil {
+		return nil, sql.ErrNoRows
+	}
+	inventory.WeightedCost = req.WeightedCost
+	inventory.TotalValue = float64(inventory.Quantity) * req.WeightedCost
+	inventory.IsManualCost = true
+	inventory.UpdatedAt = time.Now()
+	return cloneInventory(inventory), nil
+}
+
+func (m *MemoryStore) UpdateInventoryCustomFields(ctx context.Context, organizationID, skuID string, values models.CustomFieldValues) (*models.Inventory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	inventory := m.findInventory(organizationID, skuID)
+	if inventory == nil {
+		return nil, sql.ErrNoRows
+	}
+	customFields, err := memoryCustomFields(values)
+	if err != nil {
+		return nil, err
+	}
+	inventory.CustomFields = customFields
+	inventory.UpdatedAt = time.Now()
+	return cloneInventory(inventory), nil
+}
+
+// Transaction Methods
+
+// transactionFilters selects transactions like the SQL filters shared by the
+// transaction list and summary
+func (m *MemoryStore) transactionFilters(organizationID string, params models.TransactionListParams) (func(*models.Transaction, *models.SKU) bool, error) {
+	if err := memoryCustomFieldFilters(params.CustomFields); err != nil {
+		return nil, err
+	}
+	var start, end *time.Time
+	for _, bound := range []struct {
+		value *string
+		time  **time.Time
+	}{{params.StartDate, &start}, {params.EndDate, &end}} {
+		if bound.value == nil || *bound.value == "" {
+			continue
+		}
+		t, err := parseMemoryDate(*bound.value)
+		if err != nil {
+			return nil, err
+		}
+		*bound.time = &t
+	}
+
+	return func(transaction *models.Transaction, sku *models.SKU) bool {
+		return transaction.OrganizationID == organizationID &&
+			(params.SKUID == nil || *params.SKUID == "" || transaction.SKUID == *params.SKUID) &&
+			m.inCategory(organizationID, sku.Category, params.CategoryID, params.Category) &&
+			(start == nil || !transaction.CreatedAt.Before(*start)) &&
+			(end == nil || !transaction.CreatedAt.After(*end))
+	}, nil
+}
+
+// parseMemoryDate reads a date filter as Postgres reads a timestamp, in the forms clients send
+func parseMemoryDate(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid input syntax for type timestamp: %q", value)
+}
+
+func transactionSearch(transaction *models.Transaction, sku *models.SKU, term string) *models.SearchMatch {
+	plain := []*string{&sku.SKUCode, sku.Description, transaction.ReferenceNumber}
+	for _, values := range []models.CustomFieldValues{sku.CustomFields, transaction.CustomFields} {
+		for _, value := range values {
+			text := fmt.Sprint(value)
+			plain = append(plain, &text)
+		}
+	}
+	return memorySearch(term,
+		map[string]*string{"barcode": sku.Barcode, "sku_code": &sku.SKUCode, "reference_number": transaction.ReferenceNumber},
+		map[string]*string{"product_name": &sku.ProductName, "notes": transaction.Notes},
+		plain...)
+}
+
+func (m *MemoryStore) GetTransactionsWithDetails(ctx context.Context, organizationID string, params models.TransactionListParams) ([]*models.TransactionWithSKU, *models.PageInfo, error) {
+	searching := params.Search != nil && *params.Search != ""
+	list := transactionSorts
+	if searching {
+		list = list.withKey("relevance", sortColumn{"search_rank", "float8"})
+		list.defaultKey = "-relevance"
+	}
+
+	page, err := newPageQuery(list, params.Sort, params.Cursor, params.Limit, pageOffset(params.Page, params.Limit))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matches, err := m.transactionFilters(organizationID, params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transactions := make([]*models.TransactionWithSKU, 0)
+	for _, transaction := range m.transactions {
+		stored := m.findSKU(organizationID, transaction.SKUID)
+		if stored == nil || !matches(transaction, &stored.sku) ||
+			(params.TransactionType != nil && *params.TransactionType != "" && transaction.TransactionType != *params.TransactionType) {
+			continue
+		}
+		item := &models.TransactionWithSKU{
+			ID:              transaction.ID,
+			OrganizationID:  transaction.OrganizationID,
+			SKUID:           transaction.SKUID,
+			TransactionType: transaction.TransactionType,
+			Quantity:        transaction.Quantity,
+			UnitCost:        transaction.UnitCost,
+			TotalCost:       transaction.TotalCost,
+			ReferenceNumber: transaction.ReferenceNumber,
+			Notes:           transaction.Notes,
+			CreatedBy:       transaction.CreatedBy,
+			CreatedAt:       transaction.CreatedAt,
+			UpdatedAt:       transaction.UpdatedAt,
+			CustomFields:    transaction.CustomFields,
+			SKUCode:         stored.sku.SKUCode,
+			ProductName:     stored.sku.ProductName,
+			Description:     stored.sku.Description,
+			Category:        stored.sku.Category,
+		}
+		for _, user := range m.users {
+			if user.ID == transaction.CreatedBy {
+				item.CreatedByName = user.Name
+			}
+		}
+		if searching {
+			if item.Search = transactionSearch(transaction, &stored.sku, *params.Search); item.Search == nil {
+				continue
+			}
+		}
+		transactions = append(transactions, item)
+	}
+
+	return memoryPage(page, transactions, func(item *models.TransactionWithSKU, key string) []interface{} {
+		switch key {
+		case "transaction_type":
+			return []interface{}{item.TransactionType}
+		case "quantity":
+			return []interface{}{item.Quantity}
+		case "total_cost":
+			return []interface{}{item.TotalCost}
+		case "sku_code":
+			return []interface{}{item.SKUCode}
+		case "relevance":
+			return []interface{}{item.Search.Rank}
+		}
+		return []interface{}{item.CreatedAt}
+	}, func(item *models.TransactionWithSKU) interface{} { return item.ID })
+}
+
+// CreateTransaction records a transaction and applies it to inventory like
+// PostgresService.CreateTransaction, weighted average cost included
+func (m *MemoryStore) CreateTransaction(ctx context.Context, organizationID, userID string, req models.CreateTransactionRequest) (*models.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rules := m.businessRules[organizationID]
+	if err := applyBusinessRules(&rules, req); err != nil {
+		return nil, err
+	}
+	customFields, err := memoryCustomFields(req.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.findSKU(organizationID, req.SKUID) == nil {
+		return nil, fmt.Errorf("SKU not found: %v", sql.ErrNoRows)
+	}
+
+	inventory := m.findInventory(organizationID, req.SKUID)
+	if req.TransactionType == "out" {
+		if inventory == nil {
+			return nil, fmt.Errorf("insufficient inventory: no inventory record found")
+		}
+		if inventory.Quantity < req.Quantity {
+			return nil, fmt.Errorf("insufficient inventory: have %d, requested %d", inventory.Quantity, req.Quantity)
+		}
+		if req.ReservationID != nil {
+			return nil, fmt.Errorf("reservation not found")
+		}
+	}
+
+	now := time.Now()
+	transaction := &models.Transaction{
+		ID:              newMemoryID(),
+		OrganizationID:  organizationID,
+		SKUID:           req.SKUID,
+		TransactionType: req.TransactionType,
+		Quantity:        req.Quantity,
+		UnitCost:        req.UnitCost,
+		TotalCost:       float64(req.Quantity) * req.UnitCost,
+		ReferenceNumber: req.ReferenceNumber,
+		Notes:           req.Notes,
+		CreatedBy:       userID,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		CustomFields:    customFields,
+	}
+
+	if inventory == nil {
+		if _, err := m.createInventory(organizationID, req.SKUID, req.Quantity, req.UnitCost); err != nil {
+			return nil, err
+		}
+	} else {
+		if req.TransactionType == "in" {
+			newQuantity := inventory.Quantity + req.Quantity
+			if newQuantity > 0 {
+				inventory.WeightedCost = (float64(inventory.Quantity)*inventory.WeightedCost + float64(req.Quantity)*req.UnitCost) / float64(newQuantity)
+			}
+			inventory.Quantity = newQuantity
+		} else {
+			inventory.Quantity -= req.Quantity
+		}
+		inventory.TotalValue = float64(inventory.Quantity) * inventory.WeightedCost
+		inventory.UpdatedAt = now
+	}
+
+	m.transactions = append(m.transactions, transaction)
+	copied := *transaction
+	return &copied, nil
+}
+
+func (m *MemoryStore) GetTransactionSummary(ctx context.Context, organizationID string, params models.TransactionListParams) ([]*models.TransactionSummary, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	matches, err := m.transactionFilters(organizationID, params)
+	if err != nil {
+		return nil, err
+	}
+
+	byType := make(map[string]*models.TransactionSummary)
+	for _, transaction := range m.transactions {
+		stored := m.findSKU(organizationID, transaction.SKUID)
+		if stored == nil || !matches(transaction, &stored.sku) ||
+			(params.Search != nil && *params.Search != "" && transactionSearch(transaction, &stored.sku, *params.Search) == nil) {
+			continue
+		}
+		summary, ok := byType[transaction.TransactionType]
+		if !ok {
+			summary = &models.TransactionSummary{TransactionType: transaction.TransactionType}
+			byType[transaction.TransactionType] = summary
+		}
+		summary.TotalTransactions++
+		summary.TotalQuantity += transaction.Quantity
+		summary.TotalValue += transaction.TotalCost
+	}
+
+	summaries := make([]*models.TransactionSummary, 0, len(byType))
+	for _, summary := range byType {
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].TransactionType < summaries[j].TransactionType })
+	return summaries, nil
+}
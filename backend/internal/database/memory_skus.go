@@ -0,0 +1,507 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/models"
+	"flex-erp-poc/internal/utils"
+)
+
+type memorySKU struct {
+	sku      models.SKU
+	archived bool
+}
+
+type memoryCategory struct {
+	id             string
+	organizationID string
+	path           string
+}
+
+// cloneSKU copies a SKU so callers cannot change the stored one
+func cloneSKU(sku *models.SKU) *models.SKU {
+	copied := *sku
+	copied.CustomFields = models.CustomFieldValues{}
+	for name, value := range sku.CustomFields {
+		copied.CustomFields[name] = value
+	}
+	return &copied
+}
+
+func (m *MemoryStore) findSKU(organizationID, id string) *memorySKU {
+	for _, stored := range m.skus {
+		if stored.sku.OrganizationID == organizationID && stored.sku.ID == id {
+			return stored
+		}
+	}
+	return nil
+}
+
+// resolveCategory returns the category ID and path for a SKU, adding a category
+// for a path not seen before
+func (m *MemoryStore) resolveCategory(organizationID string, categoryID, category *string) (*string, *string, error) {
+	if categoryID != nil && *categoryID != "" {
+		for _, node := range m.categories {
+			if node.organizationID == organizationID && node.id == *categoryID {
+				return &node.id, &node.path, nil
+			}
+		}
+		return nil, nil, fmt.Errorf("category not found")
+	}
+
+	if category == nil || normalizeCategoryPath(*category) == "" {
+		return nil, nil, nil
+	}
+	path := normalizeCategoryPath(*category)
+	for _, node := range m.categories {
+		if node.organizationID == organizationID && strings.EqualFold(node.path, path) {
+			return &node.id, &node.path, nil
+		}
+	}
+	node := &memoryCategory{id: newMemoryID(), organizationID: organizationID, path: path}
+	m.categories = append(m.categories, node)
+	return &node.id, &node.path, nil
+}
+
+// inCategory reports whether a category path passes the category filters, which
+// include descendant categories
+func (m *MemoryStore) inCategory(organizationID string, path *string, categoryID, category *string) bool {
+	under := func(filter string) bool {
+		if path == nil {
+			return false
+		}
+		lower, filter := strings.ToLower(*path), strings.ToLower(filter)
+		return lower == filter || strings.HasPrefix(lower, filter+models.CategoryPathSeparator)
+	}
+
+	if categoryID != nil && *categoryID != "" {
+		found := false
+		for _, node := range m.categories {
+			if node.organizationID == organizationID && node.id == *categoryID {
+				found = under(node.path)
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if category != nil && *category != "" && !under(normalizeCategoryPath(*category)) {
+		return false
+	}
+	return true
+}
+
+// checkBarcode validates a SKU's barcode and rejects one another SKU already has
+func (m *MemoryStore) checkBarcode(organizationID, skuID string, barcode *string) (*string, error) {
+	if barcode == nil || strings.TrimSpace(*barcode) == "" {
+		return nil, nil
+	}
+
+	code, err := utils.ValidateBarcode(*barcode)
+	if err != nil {
+		return nil, err
+	}
+	keys := utils.BarcodeLookupKeys(code)
+	for _, stored := range m.skus {
+		sku := stored.sku
+		if sku.OrganizationID == organizationID && sku.ID != skuID && sku.Barcode != nil && containsString(keys, *sku.Barcode) {
+			return nil, fmt.Errorf("duplicate barcode: %s is already used by SKU %s", code, sku.SKUCode)
+		}
+	}
+	return &code, nil
+}
+
+// supplierName trims a free-text supplier, which is kept as given
+func supplierName(supplier *string) *string {
+	if supplier == nil || strings.TrimSpace(*supplier) == "" {
+		return nil
+	}
+	name := strings.TrimSpace(*supplier)
+	return &name
+}
+
+func skuSearch(sku *models.SKU, term string) *models.SearchMatch {
+	plain := []*string{&sku.SKUCode}
+	for _, value := range sku.CustomFields {
+		text := fmt.Sprint(value)
+		plain = append(plain, &text)
+	}
+	return memorySearch(term,
+		map[string]*string{"barcode": sku.Barcode, "sku_code": &sku.SKUCode},
+		map[string]*string{"product_name": &sku.ProductName, "description": sku.Description},
+		plain...)
+}
+
+// SKU Methods
+
+func (m *MemoryStore) GetSKUs(ctx context.Context, organizationID string, params models.SKUListParams) ([]*models.SKU, *models.PageInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.listSKUs(organizationID, params)
+}
+
+func (m *MemoryStore) listSKUs(organizationID string, params models.SKUListParams) ([]*models.SKU, *models.PageInfo, error) {
+	searching := params.Search != nil && *params.Search != ""
+	list := skuSorts
+	if searching {
+		list = list.withKey("relevance", sortColumn{"search_rank", "float8"})
+		list.defaultKey = "-relevance"
+	}
+
+	page, err := newPageQuery(list, params.Sort, params.Cursor, params.Limit, pageOffset(params.Page, params.Limit))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := memoryCustomFieldFilters(params.CustomFields); err != nil {
+		return nil, nil, err
+	}
+
+	skus := make([]*models.SKU, 0)
+	for _, stored := range m.skus {
+		sku := stored.sku
+		if sku.OrganizationID != organizationID ||
+			(!params.IncludeDeactivated && !sku.IsActive) ||
+			(!params.IncludeArchived && stored.archived) ||
+			(params.SupplierID != nil && *params.SupplierID != "") ||
+			!m.inCategory(organizationID, sku.Category, params.CategoryID, params.Category) {
+			continue
+		}
+		listed := cloneSKU(&sku)
+		if searching {
+			if listed.Search = skuSearch(listed, *params.Search); listed.Search == nil {
+				continue
+			}
+		}
+		skus = append(skus, listed)
+	}
+
+	return memoryPage(page, skus, func(sku *models.SKU, key string) []interface{} {
+		switch key {
+		case "updated_at":
+			return []interface{}{sku.UpdatedAt}
+		case "sku_code":
+			return []interface{}{sku.SKUCode}
+		case "product_name":
+			return []interface{}{sku.ProductName}
+		case "relevance":
+			return []interface{}{sku.Search.Rank}
+		}
+		return []interface{}{sku.CreatedAt}
+	}, func(sku *models.SKU) interface{} { return sku.ID })
+}
+
+func (m *MemoryStore) GetSKUByID(ctx context.Context, organizationID, id string) (*models.SKU, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored := m.findSKU(organizationID, id)
+	if stored == nil {
+		return nil, sql.ErrNoRows
+	}
+	return cloneSKU(&stored.sku), nil
+}
+
+func (m *MemoryStore) GetSKUsByID(ctx context.Context, organizationID string, ids []string) (map[string]*models.SKU, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	skus := make(map[string]*models.SKU, len(ids))
+	for _, id := range ids {
+		if stored := m.findSKU(organizationID, id); stored != nil {
+			skus[id] = cloneSKU(&stored.sku)
+		}
+	}
+	return skus, nil
+}
+
+func (m *MemoryStore) CreateSKU(ctx context.Context, organizationID string, req models.CreateSKURequest) (*models.SKU, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, stored := range m.skus {
+		if stored.sku.OrganizationID == organizationID && stored.sku.SKUCode == req.SKUCode {
+			return nil, fmt.Errorf("duplicate key value violates unique constraint \"skus_organization_id_sku_code_key\"")
+		}
+	}
+
+	categoryID, category, err := m.resolveCategory(organizationID, req.CategoryID, req.Category)
+	if err != nil {
+		return nil, err
+	}
+	customFields, err := memoryCustomFields(req.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+	barcode, err := m.checkBarcode(organizationID, "", req.Barcode)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stored := &memorySKU{sku: models.SKU{
+		ID:             newMemoryID(),
+		OrganizationID: organizationID,
+		SKUCode:        req.SKUCode,
+		ProductName:    req.ProductName,
+		Description:    req.Description,
+		Category:       category,
+		CategoryID:     categoryID,
+		Supplier:       supplierName(req.Supplier),
+		Barcode:        barcode,
+		IsActive:       true,
+		CustomFields:   customFields,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}}
+	m.skus = append(m.skus, stored)
+	return cloneSKU(&stored.sku), nil
+}
+
+func (m *MemoryStore) UpdateSKU(ctx context.Context, organizationID, id string, req models.UpdateSKURequest) (*models.SKU, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := m.findSKU(organizationID, id)
+	if stored == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	categoryID, category, err := m.resolveCategory(organizationID, req.CategoryID, req.Category)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := memoryCustomFields(req.CustomFields); err != nil {
+		return nil, err
+	}
+	barcode, err := m.checkBarcode(organizationID, id, req.Barcode)
+	if err != nil {
+		return nil, err
+	}
+
+	sku := &stored.sku
+	sku.ProductName, sku.Description = req.ProductName, req.Description
+	sku.Category, sku.CategoryID = category, categoryID
+	sku.Supplier, sku.Barcode = supplierName(req.Supplier), barcode
+	sku.UpdatedAt = time.Now()
+	return cloneSKU(sku), nil
+}
+
+func (m *MemoryStore) UpdateSKUStatus(ctx context.Context, organizationID, id string, isActive bool) (*models.SKU, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := m.findSKU(organizationID, id)
+	if stored == nil {
+		return nil, sql.ErrNoRows
+	}
+	stored.sku.IsActive, stored.sku.UpdatedAt = isActive, time.Now()
+	return cloneSKU(&stored.sku), nil
+}
+
+// Bulk SKU Methods
+
+// BulkUpdateSKUs applies one action to many SKUs like PostgresService.BulkUpdateSKUs.
+// A failing item changes nothing; with AllOrNothing a failure restores every SKU.
+func (m *MemoryStore) BulkUpdateSKUs(ctx context.Context, organizationID, userID string, req models.BulkSKURequest, filter *models.SKUListParams) (*models.BulkSKUResponse, error) {
+	if !containsString(models.BulkSKUActions, req.Action) {
+		return nil, fmt.Errorf("invalid bulk request: unsupported action %s, expected one of %s", req.Action, strings.Join(models.BulkSKUActions, ", "))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	skuIDs, err := m.bulkSKUIDs(organizationID, req.SKUIDs, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	savedSKUs := make([]memorySKU, len(m.skus))
+	for i, stored := range m.skus {
+		savedSKUs[i] = *stored
+	}
+	savedCategories, savedChangeLogs := len(m.categories), len(m.changeLogs)
+
+	response := &models.BulkSKUResponse{BatchID: newMemoryID(), Action: req.Action, Results: make([]*models.BulkSKUResult, 0, len(skuIDs))}
+	change, err := m.resolveBulkSKUChange(organizationID, req, response.BatchID)
+	if err != nil {
+		m.categories = m.categories[:savedCategories]
+		return nil, err
+	}
+
+	for _, skuID := range skuIDs {
+		result := &models.BulkSKUResult{SKUID: skuID}
+		changed, err := m.applyBulkSKUChange(organizationID, userID, skuID, change, result)
+		switch {
+		case err != nil:
+			result.Status = "failed"
+			result.Error = bulkItemMessage(skuID, err)
+			response.Failed++
+		case changed:
+			result.Status = "updated"
+			response.Updated++
+		default:
+			result.Status = "unchanged"
+			response.Unchanged++
+		}
+		response.Results = append(response.Results, result)
+	}
+
+	if req.AllOrNothing && response.Failed > 0 {
+		for i, stored := range m.skus {
+			*stored = savedSKUs[i]
+		}
+		m.categories, m.changeLogs = m.categories[:savedCategories], m.changeLogs[:savedChangeLogs]
+		return response, nil
+	}
+	response.Applied = true
+	return response, nil
+}
+
+func (m *MemoryStore) bulkSKUIDs(organizationID string, ids []string, filter *models.SKUListParams) ([]string, error) {
+	if len(ids) > 0 && filter != nil {
+		return nil, fmt.Errorf("invalid bulk request: give either sku_ids or filter, not both")
+	}
+
+	if filter == nil {
+		if len(ids) == 0 {
+			return nil, fmt.Errorf("invalid bulk request: sku_ids or filter is required")
+		}
+		unique := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if !containsString(unique, id) {
+				unique = append(unique, id)
+			}
+		}
+		if len(unique) > maxBulkSKUs {
+			return nil, fmt.Errorf("invalid bulk request: at most %d SKUs per request", maxBulkSKUs)
+		}
+		return unique, nil
+	}
+
+	params := *filter
+	params.Sort, params.Cursor, params.Page, params.Limit = "", "", 1, maxBulkSKUs
+	skus, page, err := m.listSKUs(organizationID, params)
+	if err != nil {
+		return nil, err
+	}
+	if page.Total > maxBulkSKUs {
+		return nil, fmt.Errorf("invalid bulk request: filter matches %d SKUs, at most %d per request", page.Total, maxBulkSKUs)
+	}
+
+	skuIDs := make([]string, 0, len(skus))
+	for _, sku := range skus {
+		skuIDs = append(skuIDs, sku.ID)
+	}
+	return skuIDs, nil
+}
+
+func (m *MemoryStore) resolveBulkSKUChange(organizationID string, req models.BulkSKURequest, batchID string) (*bulkSKUChange, error) {
+	change := &bulkSKUChange{action: req.Action, reason: req.Reason}
+
+	switch req.Action {
+	case "set_category":
+		categoryID, categoryPath, err := m.resolveCategory(organizationID, req.CategoryID, req.Category)
+		if err != nil {
+			return nil, err
+		}
+		if categoryID == nil {
+			return nil, fmt.Errorf("invalid bulk request: category_id or category is required for set_category")
+		}
+		change.categoryID, change.categoryPath = categoryID, categoryPath
+	case "set_supplier":
+		switch {
+		case req.SupplierID != nil && *req.SupplierID != "":
+			return nil, fmt.Errorf("supplier not found")
+		case req.Supplier != nil && strings.TrimSpace(*req.Supplier) != "":
+			change.supplier = strings.TrimSpace(*req.Supplier)
+		default:
+			return nil, fmt.Errorf("invalid bulk request: supplier_id or supplier is required for set_supplier")
+		}
+	}
+
+	if change.reason == nil || *change.reason == "" {
+		reason := "Bulk " + strings.ReplaceAll(req.Action, "_", " ")
+		change.reason = &reason
+	}
+
+	metadata, err := json.Marshal(map[string]string{"batch_id": batchID, "bulk_action": req.Action})
+	if err != nil {
+		return nil, err
+	}
+	change.metadata = metadata
+	return change, nil
+}
+
+func (m *MemoryStore) applyBulkSKUChange(organizationID, userID, skuID string, change *bulkSKUChange, result *models.BulkSKUResult) (bool, error) {
+	stored := m.findSKU(organizationID, skuID)
+	if stored == nil {
+		return false, bulkItemError("SKU not found")
+	}
+	sku := &stored.sku
+	code := sku.SKUCode
+	result.SKUCode = &code
+
+	logReq := models.NewSKUChangeLog(organizationID, userID, sku.ID, change.action)
+	now := time.Now()
+
+	switch change.action {
+	case "activate":
+		if sku.IsActive && !stored.archived {
+			return false, nil
+		}
+		sku.IsActive, stored.archived = true, false
+
+	case "deactivate":
+		if !sku.IsActive {
+			return false, nil
+		}
+		sku.IsActive = false
+
+	case "archive":
+		if stored.archived {
+			return false, nil
+		}
+		if inventory := m.findInventory(organizationID, sku.ID); inventory != nil && inventory.Quantity > 0 {
+			return false, bulkItemError(fmt.Sprintf("SKU has %d units on hand", inventory.Quantity))
+		}
+		sku.IsActive, stored.archived = false, true
+
+	case "set_category", "set_supplier":
+		if sku.ParentSKUID != nil {
+			return false, bulkItemError("variants take their category and supplier from the parent SKU")
+		}
+
+		fieldName := "category"
+		oldValue, newValue := sku.Category, change.categoryPath
+		if change.action == "set_category" {
+			if sku.CategoryID != nil && *sku.CategoryID == *change.categoryID {
+				return false, nil
+			}
+			sku.Category, sku.CategoryID = change.categoryPath, change.categoryID
+		} else {
+			fieldName = "supplier"
+			oldValue, newValue = sku.Supplier, &change.supplier
+			if sku.Supplier != nil && *sku.Supplier == change.supplier {
+				return false, nil
+			}
+			supplier := change.supplier
+			sku.Supplier = &supplier
+		}
+
+		logReq.ChangeType = "update"
+		logReq.FieldName = &fieldName
+		logReq.OldValue, logReq.NewValue = oldValue, newValue
+	}
+	sku.UpdatedAt = now
+
+	logReq.Reason = change.reason
+	logReq.Metadata = change.metadata
+	m.addChangeLog(organizationID, userID, *logReq)
+	return true, nil
+}
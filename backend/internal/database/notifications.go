@@ -0,0 +1,416 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/models"
+
+	"github.com/lib/pq"
+)
+
+const (
+	defaultNotifications = 50
+	maxNotifications     = 200
+	maxDigestItems       = 200 // per email, the rest wait for the next digest
+)
+
+const notificationColumns = `id, notification_type, event_id, title, body, data, read_at, created_at`
+
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	n := &models.Notification{}
+	err := row.Scan(&n.ID, &n.Type, &n.EventID, &n.Title, &n.Body, &n.Data, &n.ReadAt, &n.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	n.Read = n.ReadAt != nil
+	return n, nil
+}
+
+// Notification Preference Methods
+
+// GetNotificationPreferences returns the user's preference for every notification
+// type, the default where the user has not chosen
+func (p *PostgresService) GetNotificationPreferences(ctx context.Context, organizationID, userID string) ([]models.NotificationPreference, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT notification_type, in_app, email FROM notification_preferences
+		WHERE organization_id = $1 AND user_id = $2
+	`, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	chosen := make(map[string]models.NotificationPreference)
+	for rows.Next() {
+		var pref models.NotificationPreference
+		if err := rows.Scan(&pref.Type, &pref.InApp, &pref.Email); err != nil {
+			return nil, err
+		}
+		chosen[pref.Type] = pref
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	preferences := make([]models.NotificationPreference, 0, len(models.NotificationTypes))
+	for _, notificationType := range models.NotificationTypes {
+		pref, ok := chosen[notificationType]
+		if !ok {
+			pref = models.DefaultNotificationPreferences[notificationType]
+		}
+		preferences = append(preferences, pref)
+	}
+	return preferences, nil
+}
+
+// UpdateNotificationPreferences saves the listed preferences; types left out keep
+// their current setting
+func (p *PostgresService) UpdateNotificationPreferences(ctx context.Context, organizationID, userID string, req models.UpdateNotificationPreferencesRequest) ([]models.NotificationPreference, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	seen := make(map[string]bool, len(req.Preferences))
+	for _, pref := range req.Preferences {
+		if !containsString(models.NotificationTypes, pref.Type) || seen[pref.Type] {
+			return nil, fmt.Errorf("invalid notification preferences: types must be unique and one of %s", strings.Join(models.NotificationTypes, ", "))
+		}
+		if !containsString(models.NotificationEmailModes, pref.Email) {
+			return nil, fmt.Errorf("invalid notification preferences: email must be one of %s", strings.Join(models.NotificationEmailModes, ", "))
+		}
+		seen[pref.Type] = true
+	}
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	for _, pref := range req.Preferences {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO notification_preferences (user_id, organization_id, notification_type, in_app, email, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (user_id, notification_type) DO UPDATE SET in_app = $4, email = $5, updated_at = $6
+		`, userID, organizationID, pref.Type, pref.InApp, pref.Email, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return p.GetNotificationPreferences(ctx, organizationID, userID)
+}
+
+// GetNotificationRecipients returns the organization's active users with one of
+// the roles, or the one user when userID is set, with their preference for the type
+func (p *PostgresService) GetNotificationRecipients(ctx context.Context, organizationID, notificationType string, roles []string, userID string) ([]models.NotificationRecipient, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	defaults := models.DefaultNotificationPreferences[notificationType]
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT u.id, u.email, u.name, COALESCE(np.in_app, $3), COALESCE(np.email, $4)
+		FROM users u
+		LEFT JOIN notification_preferences np ON np.user_id = u.id AND np.notification_type = $2
+		WHERE u.organization_id = $1 AND u.is_active AND (u.role = ANY($5) OR u.id::text = $6)
+		ORDER BY u.name
+	`, organizationID, notificationType, defaults.InApp, defaults.Email, pq.Array(roles), userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipients := make([]models.NotificationRecipient, 0)
+	for rows.Next() {
+		recipient := models.NotificationRecipient{Preference: models.NotificationPreference{Type: notificationType}}
+		err := rows.Scan(&recipient.UserID, &recipient.Email, &recipient.Name, &recipient.Preference.InApp, &recipient.Preference.Email)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, rows.Err()
+}
+
+// Notification Methods
+
+// CreateNotification records a notification as the recipient's preference asks:
+// in the inbox, as an email queued now, held for the digest, or any of these
+// together. A notification already recorded for the event is left as it is.
+func (p *PostgresService) CreateNotification(ctx context.Context, n models.NewNotification) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	pref := n.Recipient.Preference
+	if !pref.InApp && pref.Email != "immediate" && pref.Email != "digest" {
+		return nil
+	}
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	data := n.Data
+	if data == nil {
+		data = []byte("{}")
+	}
+	var id string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO notifications (organization_id, user_id, notification_type, event_id, title, body, data, in_app, digest_pending)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (user_id, event_id, notification_type) DO NOTHING
+		RETURNING id
+	`, n.OrganizationID, n.Recipient.UserID, n.Type, n.EventID, n.Title, n.Body, []byte(data), pref.InApp, pref.Email == "digest").Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if pref.Email == "immediate" {
+		err := queueNotificationEmail(ctx, tx, n.OrganizationID, n.Recipient.UserID, n.Recipient.Email, n.EmailSubject, n.EmailBody)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetNotifications lists the user's inbox, newest first
+func (p *PostgresService) GetNotifications(ctx context.Context, organizationID, userID string, params models.NotificationListParams) ([]*models.Notification, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE organization_id = $1 AND user_id = $2 AND in_app`
+	args := []interface{}{organizationID, userID}
+	if params.Unread {
+		query += " AND read_at IS NULL"
+	}
+	if params.Before != nil {
+		args = append(args, *params.Before)
+		query += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultNotifications
+	}
+	args = append(args, min(limit, maxNotifications))
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id LIMIT $%d", len(args))
+
+	rows, err := p.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := make([]*models.Notification, 0)
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+func (p *PostgresService) GetUnreadNotificationCount(ctx context.Context, organizationID, userID string) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var count int
+	err := p.DB.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM notifications WHERE organization_id = $1 AND user_id = $2 AND in_app AND read_at IS NULL
+	`, organizationID, userID).Scan(&count)
+	return count, err
+}
+
+// SetNotificationRead marks one of the user's notifications read or unread
+func (p *PostgresService) SetNotificationRead(ctx context.Context, organizationID, userID, notificationID string, read bool) (*models.Notification, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if !isUUID(notificationID) {
+		return nil, fmt.Errorf("notification not found")
+	}
+	n, err := scanNotification(p.DB.QueryRowContext(ctx, `
+		UPDATE notifications SET read_at = CASE WHEN $4 THEN COALESCE(read_at, now()) END
+		WHERE organization_id = $1 AND user_id = $2 AND id = $3 AND in_app
+		RETURNING `+notificationColumns,
+		organizationID, userID, notificationID, read))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("notification not found")
+	}
+	return n, err
+}
+
+// MarkAllNotificationsRead marks the user's unread notifications read and returns
+// how many there were
+func (p *PostgresService) MarkAllNotificationsRead(ctx context.Context, organizationID, userID string) (int64, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	result, err := p.DB.ExecContext(ctx, `
+		UPDATE notifications SET read_at = now()
+		WHERE organization_id = $1 AND user_id = $2 AND in_app AND read_at IS NULL
+	`, organizationID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Notification Digest Methods
+
+// GetNotificationDigests returns, per user, the notifications created before
+// cutoff that are waiting for the digest
+func (p *PostgresService) GetNotificationDigests(ctx context.Context, cutoff time.Time) ([]*models.NotificationDigest, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT n.organization_id, u.id, u.email, u.name,
+			n.id, n.notification_type, n.event_id, n.title, n.body, n.data, n.read_at, n.created_at
+		FROM notifications n
+		JOIN users u ON u.id = n.user_id
+		WHERE n.digest_pending AND n.created_at < $1 AND u.is_active
+		ORDER BY u.id, n.created_at
+	`, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	digests := make([]*models.NotificationDigest, 0)
+	var current *models.NotificationDigest
+	for rows.Next() {
+		var digest models.NotificationDigest
+		n := &models.Notification{}
+		err := rows.Scan(&digest.OrganizationID, &digest.UserID, &digest.Email, &digest.Name,
+			&n.ID, &n.Type, &n.EventID, &n.Title, &n.Body, &n.Data, &n.ReadAt, &n.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		n.Read = n.ReadAt != nil
+
+		if current == nil || current.UserID != digest.UserID {
+			current = &digest
+			digests = append(digests, current)
+		}
+		if len(current.Notifications) < maxDigestItems {
+			current.Notifications = append(current.Notifications, n)
+		}
+	}
+	return digests, rows.Err()
+}
+
+// QueueNotificationDigest queues the digest email and takes its notifications off
+// the digest. It does nothing when another sender has already taken them.
+func (p *PostgresService) QueueNotificationDigest(ctx context.Context, digest *models.NotificationDigest, subject, body string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	ids := make([]string, 0, len(digest.Notifications))
+	for _, n := range digest.Notifications {
+		ids = append(ids, n.ID)
+	}
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `UPDATE notifications SET digest_pending = false WHERE id = ANY($1) AND digest_pending`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	if taken, err := result.RowsAffected(); err != nil || taken < int64(len(ids)) {
+		return err
+	}
+
+	if err := queueNotificationEmail(ctx, tx, digest.OrganizationID, digest.UserID, digest.Email, subject, body); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Notification Email Methods
+
+func queueNotificationEmail(ctx context.Context, q queryer, organizationID, userID, to, subject, body string) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO notification_emails (organization_id, user_id, to_address, subject, body, status, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, 'pending', now())
+	`, organizationID, userID, to, subject, body)
+	return err
+}
+
+// ClaimNotificationEmails picks up to limit due emails for sending and counts the
+// attempt. Claimed emails are not due again until lease has passed, so a sender
+// that dies mid-send has them retried instead of lost.
+func (p *PostgresService) ClaimNotificationEmails(ctx context.Context, limit int, lease time.Duration) ([]*models.PendingNotificationEmail, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, `
+		UPDATE notification_emails SET attempts = attempts + 1, next_attempt_at = now() + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM notification_emails
+			WHERE status = 'pending' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, to_address, subject, body, attempts
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	emails := make([]*models.PendingNotificationEmail, 0)
+	for rows.Next() {
+		email := &models.PendingNotificationEmail{}
+		if err := rows.Scan(&email.ID, &email.To, &email.Subject, &email.Body, &email.Attempts); err != nil {
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+	return emails, rows.Err()
+}
+
+// RecordNotificationEmailAttempt stores how sending went. A failed email is tried
+// again at nextAttemptAt, or marked failed when that is nil.
+func (p *PostgresService) RecordNotificationEmailAttempt(ctx context.Context, emailID string, sendErr error, nextAttemptAt *time.Time) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var err error
+	switch {
+	case sendErr == nil:
+		_, err = p.DB.ExecContext(ctx, `
+			UPDATE notification_emails SET status = 'sent', sent_at = now(), next_attempt_at = NULL, last_error = NULL
+			WHERE id = $1
+		`, emailID)
+	case nextAttemptAt != nil:
+		_, err = p.DB.ExecContext(ctx, `UPDATE notification_emails SET next_attempt_at = $2, last_error = $3 WHERE id = $1`,
+			emailID, *nextAttemptAt, sendErr.Error())
+	default:
+		_, err = p.DB.ExecContext(ctx, `UPDATE notification_emails SET status = 'failed', next_attempt_at = NULL, last_error = $2 WHERE id = $1`,
+			emailID, sendErr.Error())
+	}
+	return err
+}
@@ -0,0 +1,329 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"flex-erp-poc/internal/models"
+
+	"github.com/lib/pq"
+)
+
+const outboxEventColumns = `sequence, id, organization_id, event_type, aggregate_type, aggregate_id, data, created_at, attempts`
+
+// Events from transactions older than every running one can no longer be joined
+// by an earlier event, so only those are published
+const outboxVisible = `published_at IS NULL AND txid < pg_snapshot_xmin(pg_current_snapshot())`
+
+func scanOutboxEvent(row rowScanner, extra ...interface{}) (*models.OutboxEvent, error) {
+	event := &models.OutboxEvent{}
+	dest := []interface{}{
+		&event.Sequence,
+		&event.ID,
+		&event.OrganizationID,
+		&event.Type,
+		&event.AggregateType,
+		&event.AggregateID,
+		&event.Data,
+		&event.CreatedAt,
+		&event.Attempts,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// writeOutboxEvent records a domain event in the transaction making the change,
+// so the event is published exactly when the change commits
+func writeOutboxEvent(ctx context.Context, q queryer, organizationID, eventType, aggregateType, aggregateID string, data interface{}) error {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO outbox_events (organization_id, event_type, aggregate_type, aggregate_id, data)
+		VALUES ($1, $2, $3, $4, $5)
+	`, organizationID, eventType, aggregateType, aggregateID, dataBytes)
+	return err
+}
+
+// writeSKUEvent records a SKU event with the SKU as the transaction leaves it
+func writeSKUEvent(ctx context.Context, tx *sql.Tx, organizationID, eventType, skuID string) error {
+	sku, err := scanSKU(tx.QueryRowContext(ctx, `SELECT `+skuColumns+` FROM skus WHERE organization_id = $1 AND id = $2`, organizationID, skuID))
+	if err != nil {
+		return err
+	}
+	return writeOutboxEvent(ctx, tx, organizationID, eventType, "sku", sku.ID, map[string]interface{}{"sku": sku})
+}
+
+// writeInventoryEvent records inventory.updated with the SKU's stock as the
+// transaction leaves it
+func writeInventoryEvent(ctx context.Context, tx *sql.Tx, organizationID, skuID string) error {
+	inventory, err := getInventoryBySKUID(ctx, tx, organizationID, skuID)
+	if err != nil {
+		return err
+	}
+	return writeOutboxEvent(ctx, tx, organizationID, "inventory.updated", "inventory", skuID, map[string]interface{}{"inventory": inventory})
+}
+
+// writeLowStockEvent records inventory.low_stock when an issue takes a SKU from
+// above the organization's threshold to at or below it, so it fires once per dip
+func writeLowStockEvent(ctx context.Context, tx *sql.Tx, organizationID, skuID string, before, issued int) error {
+	rules, err := getBusinessRules(ctx, tx, organizationID)
+	if err != nil || rules.LowStockThreshold <= 0 {
+		return err
+	}
+	if before <= rules.LowStockThreshold || before-issued > rules.LowStockThreshold {
+		return nil
+	}
+	return recordLowStock(ctx, tx, organizationID, skuID, rules.LowStockThreshold)
+}
+
+// recordLowStock records inventory.low_stock for a SKU and marks its stock as
+// reported, so EvaluateLowStock does not report the same dip again
+func recordLowStock(ctx context.Context, tx *sql.Tx, organizationID, skuID string, threshold int) error {
+	_, err := tx.ExecContext(ctx, `UPDATE inventory SET low_stock_since = now() WHERE organization_id = $1 AND sku_id = $2`, organizationID, skuID)
+	if err != nil {
+		return err
+	}
+
+	inventory, err := getInventoryBySKUID(ctx, tx, organizationID, skuID)
+	if err != nil {
+		return err
+	}
+	skus, err := getSKUsByID(ctx, tx, organizationID, []string{skuID})
+	if err != nil {
+		return err
+	}
+	return writeOutboxEvent(ctx, tx, organizationID, "inventory.low_stock", "sku", skuID, map[string]interface{}{
+		"sku":       skus[skuID],
+		"inventory": inventory,
+		"threshold": threshold,
+	})
+}
+
+// DueOutboxOrganizations returns the organizations whose next event to publish
+// is due
+func (p *PostgresService) DueOutboxOrganizations(ctx context.Context) ([]string, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT organization_id FROM (
+			SELECT DISTINCT ON (organization_id) organization_id, next_attempt_at
+			FROM outbox_events
+			WHERE `+outboxVisible+`
+			ORDER BY organization_id, txid, sequence
+		) heads
+		WHERE next_attempt_at <= now()
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	organizationIDs := make([]string, 0)
+	for rows.Next() {
+		var organizationID string
+		if err := rows.Scan(&organizationID); err != nil {
+			return nil, err
+		}
+		organizationIDs = append(organizationIDs, organizationID)
+	}
+	return organizationIDs, rows.Err()
+}
+
+// ClaimOutboxEvents picks up to limit of the organization's pending events, in
+// publish order, and takes their positions. It returns none when the first of
+// them is not due, which includes while another claim holds the organization:
+// the first event is not due again until lease has passed, so events are
+// published in order by one dispatcher at a time, and a dispatcher that dies
+// mid-batch has them published again rather than lost. The advisory lock only
+// keeps two claims from picking the same events at once.
+func (p *PostgresService) ClaimOutboxEvents(ctx context.Context, organizationID string, limit int, lease time.Duration) ([]*models.OutboxEvent, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	err = tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock(hashtext('outbox_events'), hashtext($1))`, organizationID).Scan(&locked)
+	if err != nil || !locked {
+		return nil, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+outboxEventColumns+`, next_attempt_at
+		FROM outbox_events
+		WHERE organization_id = $1 AND `+outboxVisible+`
+		ORDER BY txid, sequence
+		LIMIT $2
+	`, organizationID, limit)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*models.OutboxEvent, 0)
+	var headDue time.Time
+	for rows.Next() {
+		var nextAttemptAt time.Time
+		event, err := scanOutboxEvent(rows, &nextAttemptAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if len(events) == 0 {
+			headDue = nextAttemptAt
+		}
+		events = append(events, event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(events) == 0 || headDue.After(time.Now()) {
+		return nil, nil
+	}
+
+	// Positions are taken in publish order; an event that is not published leaves a gap
+	for _, event := range events {
+		if err := tx.QueryRowContext(ctx, `SELECT nextval('outbox_events_position_seq')`).Scan(&event.Position); err != nil {
+			return nil, err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE outbox_events SET next_attempt_at = now() + make_interval(secs => $2) WHERE sequence = $1
+	`, events[0].Sequence, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+	return events, nil
+}
+
+// MarkOutboxEventsPublished records claimed events as published at the positions
+// their claim took. An event a second claim published first keeps its position.
+func (p *PostgresService) MarkOutboxEventsPublished(ctx context.Context, events []*models.OutboxEvent) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, event := range events {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE outbox_events SET attempts = attempts + 1, last_error = NULL, published_at = now(), position = $2
+			WHERE sequence = $1 AND published_at IS NULL
+		`, event.Sequence, event.Position)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to mark outbox events published: %w", err)
+	}
+	return nil
+}
+
+// RetryOutboxEvent makes a claimed event that was not published due again at the
+// given time, ahead of the rest of its organization's events. publishErr is the
+// failure that stopped the batch at it, nil when the batch stopped short of it.
+func (p *PostgresService) RetryOutboxEvent(ctx context.Context, event *models.OutboxEvent, publishErr error, at time.Time) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if publishErr == nil {
+		_, err := p.DB.ExecContext(ctx, `
+			UPDATE outbox_events SET next_attempt_at = $2 WHERE sequence = $1 AND published_at IS NULL
+		`, event.Sequence, at)
+		return err
+	}
+	_, err := p.DB.ExecContext(ctx, `
+		UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE sequence = $1 AND published_at IS NULL
+	`, event.Sequence, publishErr.Error(), at)
+	return err
+}
+
+// DeletePublishedOutboxEvents removes events published before the cutoff
+func (p *PostgresService) DeletePublishedOutboxEvents(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	result, err := p.DB.ExecContext(ctx, `DELETE FROM outbox_events WHERE published_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetLatestEventPosition returns the position of the organization's most recently
+// published event, or 0 when there is none
+func (p *PostgresService) GetLatestEventPosition(ctx context.Context, organizationID string) (int64, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var position int64
+	err := p.DB.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(position), 0) FROM outbox_events WHERE organization_id = $1 AND position IS NOT NULL
+	`, organizationID).Scan(&position)
+	return position, err
+}
+
+// HasEventPosition reports whether position is one of the organization's
+// published events. Deleted events are gone, so a client that last saw one
+// cannot be told exactly what it missed.
+func (p *PostgresService) HasEventPosition(ctx context.Context, organizationID string, position int64) (bool, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var exists bool
+	err := p.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM outbox_events WHERE organization_id = $1 AND position = $2)`,
+		organizationID, position).Scan(&exists)
+	return exists, err
+}
+
+// GetPublishedEvents returns up to limit of the organization's events of the given
+// types published after position, in publish order
+func (p *PostgresService) GetPublishedEvents(ctx context.Context, organizationID string, position int64, eventTypes []string, limit int) ([]*models.OutboxEvent, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT `+outboxEventColumns+`, position
+		FROM outbox_events
+		WHERE organization_id = $1 AND position > $2 AND event_type = ANY($3)
+		ORDER BY position
+		LIMIT $4
+	`, organizationID, position, pq.Array(eventTypes), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]*models.OutboxEvent, 0)
+	for rows.Next() {
+		var position int64
+		event, err := scanOutboxEvent(rows, &position)
+		if err != nil {
+			return nil, err
+		}
+		event.Position = position
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
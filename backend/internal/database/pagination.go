@@ -0,0 +1,323 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"flex-erp-poc/internal/models"
+)
+
+// sortColumn is one column of a sort key. expr must never be NULL so keyset
+// comparisons stay total; cast is the type a cursor value is read back as.
+type sortColumn struct {
+	expr string
+	cast string
+}
+
+// listSort is the allow-list of sort keys for a list query. Every key is made
+// unique by a final comparison on the row ID.
+type listSort struct {
+	keys       map[string][]sortColumn
+	defaultKey string // "-" prefix for descending
+	idColumn   string
+	idCast     string
+}
+
+// withKey returns a copy of s that also allows name
+func (s listSort) withKey(name string, columns ...sortColumn) listSort {
+	keys := make(map[string][]sortColumn, len(s.keys)+1)
+	for key, value := range s.keys {
+		keys[key] = value
+	}
+	keys[name] = columns
+	s.keys = keys
+	return s
+}
+
+// Sort allow-lists of the list endpoints
+var (
+	skuSorts = listSort{
+		keys: map[string][]sortColumn{
+			"created_at":   {{"created_at", "timestamptz"}},
+			"updated_at":   {{"updated_at", "timestamptz"}},
+			"sku_code":     {{"sku_code", "text"}},
+			"product_name": {{"product_name", "text"}},
+		},
+		defaultKey: "-created_at",
+		idColumn:   "id",
+		idCast:     "uuid",
+	}
+
+	inventorySorts = listSort{
+		keys: map[string][]sortColumn{
+			"created_at":    {{"i.created_at", "timestamptz"}},
+			"updated_at":    {{"i.updated_at", "timestamptz"}},
+			"sku_code":      {{"s.sku_code", "text"}},
+			"product_name":  {{"s.product_name", "text"}},
+			"quantity":      {{"i.quantity", "bigint"}},
+			"weighted_cost": {{"i.weighted_cost", "numeric"}},
+			"total_value":   {{"i.total_value", "numeric"}},
+		},
+		defaultKey: "-created_at",
+		idColumn:   "i.id",
+		idCast:     "uuid",
+	}
+
+	transactionSorts = listSort{
+		keys: map[string][]sortColumn{
+			"created_at":       {{"t.created_at", "timestamptz"}},
+			"transaction_type": {{"t.transaction_type", "text"}},
+			"quantity":         {{"t.quantity", "bigint"}},
+			"total_cost":       {{"t.total_cost", "numeric"}},
+			"sku_code":         {{"s.sku_code", "text"}},
+		},
+		defaultKey: "-created_at",
+		idColumn:   "t.id",
+		idCast:     "uuid",
+	}
+
+	userSorts = listSort{
+		keys: map[string][]sortColumn{
+			"created_at":    {{"u.created_at", "timestamptz"}},
+			"name":          {{"u.name", "text"}},
+			"email":         {{"u.email", "text"}},
+			"role":          {{"u.role", "text"}},
+			"last_login_at": {{"COALESCE(u.last_login_at, '-infinity')", "timestamptz"}},
+		},
+		defaultKey: "-created_at",
+		idColumn:   "u.id",
+		idCast:     "uuid",
+	}
+
+	fieldAliasSorts = listSort{
+		keys: map[string][]sortColumn{
+			"position":     {{"table_name", "text"}, {"sort_order", "integer"}, {"field_name", "text"}},
+			"display_name": {{"display_name", "text"}},
+			"updated_at":   {{"updated_at", "timestamptz"}},
+		},
+		defaultKey: "position",
+		idColumn:   "id",
+		idCast:     "uuid",
+	}
+
+	changeLogSorts = listSort{
+		keys: map[string][]sortColumn{
+			"created_at":  {{"cl.created_at", "timestamptz"}},
+			"entity_type": {{"cl.entity_type", "text"}},
+			"change_type": {{"cl.change_type", "text"}},
+		},
+		defaultKey: "-created_at",
+		idColumn:   "cl.id",
+		idCast:     "integer",
+	}
+)
+
+// pageOffset converts a 1-based page number into a row offset
+func pageOffset(page, limit int) int {
+	if page > 1 && limit > 0 {
+		return (page - 1) * limit
+	}
+	return 0
+}
+
+// listCursor marks a position in a sorted list
+type listCursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	ID     string   `json:"id"`
+	Prev   bool     `json:"p,omitempty"` // page before the position rather than after
+}
+
+func encodeCursor(cursor listCursor) *string {
+	data, _ := json.Marshal(cursor)
+	encoded := base64.RawURLEncoding.EncodeToString(data)
+	return &encoded
+}
+
+func decodeCursor(encoded string) (*listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	cursor := &listCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return cursor, nil
+}
+
+// pageQuery is a resolved sort and position for one page of a list query
+type pageQuery struct {
+	list    listSort
+	sort    string
+	columns []sortColumn
+	desc    bool
+	cursor  *listCursor
+	limit   int // 0 returns every row
+	offset  int // used when there is no cursor
+}
+
+func newPageQuery(list listSort, sortParam, cursorParam string, limit, offset int) (*pageQuery, error) {
+	if sortParam == "" {
+		sortParam = list.defaultKey
+	}
+	name := strings.TrimPrefix(sortParam, "-")
+	columns, ok := list.keys[name]
+	if !ok {
+		allowed := make([]string, 0, len(list.keys))
+		for key := range list.keys {
+			allowed = append(allowed, key)
+		}
+		sort.Strings(allowed)
+		return nil, fmt.Errorf("invalid sort: %s is not one of %s", name, strings.Join(allowed, ", "))
+	}
+
+	page := &pageQuery{
+		list:    list,
+		sort:    sortParam,
+		columns: columns,
+		desc:    strings.HasPrefix(sortParam, "-"),
+		limit:   limit,
+		offset:  offset,
+	}
+
+	if cursorParam != "" {
+		cursor, err := decodeCursor(cursorParam)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Sort != sortParam || len(cursor.Values) != len(columns) {
+			return nil, fmt.Errorf("invalid cursor: it was issued for a different sort")
+		}
+		page.cursor = cursor
+		page.offset = 0
+	}
+
+	return page, nil
+}
+
+// selectColumns are the extra columns a paged query selects, read back by scanKeys
+func (p *pageQuery) selectColumns() string {
+	columns := make([]string, 0, len(p.columns)+1)
+	for _, column := range p.columns {
+		columns = append(columns, fmt.Sprintf("(%s)::text", column.expr))
+	}
+	columns = append(columns, fmt.Sprintf("(%s)::text", p.list.idColumn))
+	return ", " + strings.Join(columns, ", ")
+}
+
+// scanKeys returns scan destinations for selectColumns and the key they fill in
+func (p *pageQuery) scanKeys() ([]interface{}, *listCursor) {
+	key := &listCursor{Sort: p.sort, Values: make([]string, len(p.columns))}
+	dest := make([]interface{}, 0, len(p.columns)+1)
+	for i := range key.Values {
+		dest = append(dest, &key.Values[i])
+	}
+	dest = append(dest, &key.ID)
+	return dest, key
+}
+
+// reversed reports whether rows come back in the opposite of the requested order
+func (p *pageQuery) reversed() bool {
+	return p.cursor != nil && p.cursor.Prev
+}
+
+// keysetSQL is the condition selecting rows past the cursor, for the WHERE clause
+func (p *pageQuery) keysetSQL(args []interface{}, argIndex int) (string, []interface{}, int) {
+	if p.cursor == nil {
+		return "", args, argIndex
+	}
+
+	exprs := make([]string, 0, len(p.columns)+1)
+	params := make([]string, 0, len(p.columns)+1)
+	for i, column := range p.columns {
+		exprs = append(exprs, column.expr)
+		params = append(params, fmt.Sprintf("$%d::%s", argIndex, column.cast))
+		args = append(args, p.cursor.Values[i])
+		argIndex++
+	}
+	exprs = append(exprs, p.list.idColumn)
+	params = append(params, fmt.Sprintf("$%d::%s", argIndex, p.list.idCast))
+	args = append(args, p.cursor.ID)
+	argIndex++
+
+	op := ">"
+	if p.desc != p.reversed() {
+		op = "<"
+	}
+	return fmt.Sprintf(" AND (%s) %s (%s)", strings.Join(exprs, ", "), op, strings.Join(params, ", ")), args, argIndex
+}
+
+// orderSQL orders and limits the query, fetching one extra row to detect another page
+func (p *pageQuery) orderSQL(args []interface{}, argIndex int) (string, []interface{}, int) {
+	direction := "ASC"
+	if p.desc != p.reversed() {
+		direction = "DESC"
+	}
+
+	order := make([]string, 0, len(p.columns)+1)
+	for _, column := range p.columns {
+		order = append(order, column.expr+" "+direction)
+	}
+	order = append(order, p.list.idColumn+" "+direction)
+	query := " ORDER BY " + strings.Join(order, ", ")
+
+	if p.limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, p.limit+1)
+		argIndex++
+
+		if p.offset > 0 {
+			query += fmt.Sprintf(" OFFSET $%d", argIndex)
+			args = append(args, p.offset)
+			argIndex++
+		}
+	}
+	return query, args, argIndex
+}
+
+// countRows counts the rows a list query matches, ignoring the page. from is the
+// query from its FROM clause on, with filters but no keyset, order or limit.
+func countRows(ctx context.Context, q queryer, from string, args []interface{}) (int, error) {
+	var total int
+	err := q.QueryRowContext(ctx, "SELECT COUNT(*) "+from, args...).Scan(&total)
+	return total, err
+}
+
+// finishPage puts the rows of a page in the requested order, drops the extra row
+// and builds the cursors. keys[i] is the sort key of items[i].
+func finishPage[T any](p *pageQuery, items []T, keys []*listCursor, total int) ([]T, *models.PageInfo) {
+	info := &models.PageInfo{Total: total, Limit: p.limit, Sort: p.sort}
+
+	more := p.limit > 0 && len(items) > p.limit
+	if more {
+		items, keys = items[:p.limit], keys[:p.limit]
+	}
+
+	hasNext, hasPrev := more, p.cursor != nil || p.offset > 0
+	if p.reversed() {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+		hasNext, hasPrev = true, more
+	}
+
+	if len(items) > 0 {
+		if hasNext {
+			next := *keys[len(keys)-1]
+			info.NextCursor = encodeCursor(next)
+		}
+		if hasPrev {
+			prev := *keys[0]
+			prev.Prev = true
+			info.PrevCursor = encodeCursor(prev)
+		}
+	}
+
+	return items, info
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursors := []listCursor{
+		{Sort: "-created_at", Values: []string{"2024-01-02 03:04:05.678+00"}, ID: "0b7e4a3c-2a6f-4f1e-9a51-3c1d5e7f9a20"},
+		{Sort: "position", Values: []string{"skus", "3", "sku_code"}, ID: "0b7e4a3c-2a6f-4f1e-9a51-3c1d5e7f9a20", Prev: true},
+		{Sort: "product_name", Values: []string{`Bolt "M6" / 10 ünits, 50% off`}, ID: "42"},
+	}
+	for _, cursor := range cursors {
+		encoded := encodeCursor(cursor)
+		if strings.ContainsAny(*encoded, "+/=") {
+			t.Errorf("cursor %q is not URL safe", *encoded)
+		}
+		decoded, err := decodeCursor(*encoded)
+		if err != nil {
+			t.Fatalf("decodeCursor(%q): %v", *encoded, err)
+		}
+		if !reflect.DeepEqual(*decoded, cursor) {
+			t.Errorf("round trip = %+v, want %+v", *decoded, cursor)
+		}
+	}
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	valid := *encodeCursor(listCursor{Sort: "sku_code", Values: []string{"A-1"}, ID: "x"})
+	tests := []struct {
+		name, cursor string
+	}{
+		{"not base64", "!!!not-a-cursor!!!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"s":"sku_code","v":["A-1"],"id":"x"}`))},
+		{"not JSON", base64.RawURLEncoding.EncodeToString([]byte("sku_code:A-1"))},
+		{"wrong types", base64.RawURLEncoding.EncodeToString([]byte(`{"s":1,"v":"A-1"}`))},
+		{"truncated", valid[:len(valid)-4]},
+	}
+	for _, tt := range tests {
+		if _, err := decodeCursor(tt.cursor); err == nil || err.Error() != "invalid cursor" {
+			t.Errorf("%s: error = %v, want invalid cursor", tt.name, err)
+		}
+	}
+}
+
+func TestNewPageQuery(t *testing.T) {
+	page, err := newPageQuery(skuSorts, "", "", 25, 50)
+	if err != nil {
+		t.Fatalf("newPageQuery: %v", err)
+	}
+	if page.sort != "-created_at" || !page.desc || page.cursor != nil || page.limit != 25 || page.offset != 50 {
+		t.Errorf("default page = %+v", page)
+	}
+
+	page, err = newPageQuery(skuSorts, "sku_code", "", 25, 0)
+	if err != nil || page.desc || page.columns[0].expr != "sku_code" {
+		t.Errorf("sku_code page = %+v, %v", page, err)
+	}
+
+	// A cursor replaces the offset
+	cursor := listCursor{Sort: "-sku_code", Values: []string{"B-2"}, ID: "0b7e4a3c-2a6f-4f1e-9a51-3c1d5e7f9a20", Prev: true}
+	page, err = newPageQuery(skuSorts, "-sku_code", *encodeCursor(cursor), 25, 50)
+	if err != nil {
+		t.Fatalf("newPageQuery with cursor: %v", err)
+	}
+	if !reflect.DeepEqual(*page.cursor, cursor) || page.offset != 0 || !page.reversed() {
+		t.Errorf("cursor page = %+v", page)
+	}
+
+	// Rows past a previous-page cursor come back in the opposite order
+	keyset, args, argIndex := page.keysetSQL([]interface{}{"org"}, 2)
+	if want := " AND (sku_code, id) > ($2::text, $3::uuid)"; keyset != want {
+		t.Errorf("keysetSQL = %q, want %q", keyset, want)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"org", "B-2", cursor.ID}) || argIndex != 4 {
+		t.Errorf("keysetSQL args = %v, next $%d", args, argIndex)
+	}
+	order, args, _ := page.orderSQL(args, argIndex)
+	if want := " ORDER BY sku_code ASC, id ASC LIMIT $4"; order != want || args[len(args)-1] != 26 {
+		t.Errorf("orderSQL = %q with %v, want %q", order, args, want)
+	}
+}
+
+func TestNewPageQueryErrors(t *testing.T) {
+	skuCursor := *encodeCursor(listCursor{Sort: "sku_code", Values: []string{"A-1"}, ID: "x"})
+	positionCursor := *encodeCursor(listCursor{Sort: "position", Values: []string{"skus", "1", "sku_code"}, ID: "x"})
+	shortCursor := *encodeCursor(listCursor{Sort: "position", Values: []string{"skus"}, ID: "x"})
+
+	tests := []struct {
+		name, sort, cursor, wantErr string
+		list                        listSort
+	}{
+		{"unknown sort", "price", "", "invalid sort: price is not one of created_at, product_name, sku_code, updated_at", skuSorts},
+		{"unknown descending sort", "-price", "", "invalid sort: price", skuSorts},
+		{"garbage cursor", "sku_code", "garbage!", "invalid cursor", skuSorts},
+		{"cursor of the other direction", "-sku_code", skuCursor, "issued for a different sort", skuSorts},
+		{"cursor of another key", "product_name", skuCursor, "issued for a different sort", skuSorts},
+		{"cursor of the default sort", "", skuCursor, "issued for a different sort", skuSorts},
+		{"cursor of another list", "position", skuCursor, "issued for a different sort", fieldAliasSorts},
+		{"cursor missing values", "position", shortCursor, "issued for a different sort", fieldAliasSorts},
+	}
+	for _, tt := range tests {
+		if _, err := newPageQuery(tt.list, tt.sort, tt.cursor, 10, 0); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	if _, err := newPageQuery(fieldAliasSorts, "", positionCursor, 10, 0); err != nil {
+		t.Errorf("cursor of a multi-column sort: %v", err)
+	}
+}
@@ -1,9 +1,11 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,6 +14,17 @@ import (
 
 type PostgresService struct {
 	DB *sql.DB
+	// QueryTimeout bounds each operation and ReportTimeout the statements of
+	// report queries; DefaultQueryTimeout and DefaultReportTimeout when zero
+	QueryTimeout  time.Duration
+	ReportTimeout time.Duration
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx so helpers can run inside or outside a transaction
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 type User struct {
@@ -31,14 +44,17 @@ type Organization struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-func (p *PostgresService) GetUserByEmail(email string) (*User, error) {
+func (p *PostgresService) GetUserByEmail(ctx context.Context, email string) (*User, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	user := &User{}
 	query := `
 		SELECT id, organization_id, email, name, role, created_at, updated_at 
 		FROM users 
 		WHERE email = $1
 	`
-	err := p.DB.QueryRow(query, email).Scan(
+	err := p.DB.QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.OrganizationID,
 		&user.Email,
@@ -53,14 +69,17 @@ func (p *PostgresService) GetUserByEmail(email string) (*User, error) {
 	return user, nil
 }
 
-func (p *PostgresService) GetUserByID(id string) (*User, error) {
+func (p *PostgresService) GetUserByID(ctx context.Context, id string) (*User, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	user := &User{}
 	query := `
 		SELECT id, organization_id, email, name, role, created_at, updated_at 
 		FROM users 
 		WHERE id = $1
 	`
-	err := p.DB.QueryRow(query, id).Scan(
+	err := p.DB.QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.OrganizationID,
 		&user.Email,
@@ -75,14 +94,17 @@ func (p *PostgresService) GetUserByID(id string) (*User, error) {
 	return user, nil
 }
 
-func (p *PostgresService) GetOrganizationByID(id string) (*Organization, error) {
+func (p *PostgresService) GetOrganizationByID(ctx context.Context, id string) (*Organization, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	org := &Organization{}
 	query := `
 		SELECT id, name, created_at, updated_at 
 		FROM organizations 
 		WHERE id = $1
 	`
-	err := p.DB.QueryRow(query, id).Scan(
+	err := p.DB.QueryRowContext(ctx, query, id).Scan(
 		&org.ID,
 		&org.Name,
 		&org.CreatedAt,
@@ -94,16 +116,55 @@ func (p *PostgresService) GetOrganizationByID(id string) (*Organization, error)
 	return org, nil
 }
 
+// GetDefaultOrganizationID returns an organization for logins by unknown users
+func (p *PostgresService) GetDefaultOrganizationID(ctx context.Context) (string, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var id string
+	err := p.DB.QueryRowContext(ctx, `SELECT id FROM organizations ORDER BY created_at LIMIT 1`).Scan(&id)
+	return id, err
+}
+
 // SKU Methods
 
-func (p *PostgresService) GetSKUs(organizationID string, params models.SKUListParams) ([]*models.SKU, error) {
+func (p *PostgresService) GetSKUs(ctx context.Context, organizationID string, params models.SKUListParams) ([]*models.SKU, *models.PageInfo, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	args := []interface{}{organizationID}
+	argIndex := 2
+
+	// The search term comes first so the ranking columns can refer to it
+	searching := params.Search != nil && *params.Search != ""
+	searchColumns := ""
+	list := skuSorts
+	if searching {
+		rankSQL := skuSearchRankSQL("", argIndex)
+		searchColumns = fmt.Sprintf(", %s AS search_rank, %s, %s",
+			rankSQL, searchHeadlineSQL("product_name", argIndex), searchHeadlineSQL("description", argIndex))
+		args = append(args, searchArgs(*params.Search)...)
+		argIndex += 2
+		list = list.withKey("relevance", sortColumn{rankSQL, "float8"})
+		list.defaultKey = "-relevance"
+	}
+
+	page, err := newPageQuery(list, params.Sort, params.Cursor, params.Limit, pageOffset(params.Page, params.Limit))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := `
+		SELECT id, organization_id, sku_code, product_name, description, category, category_id, supplier, barcode, is_active, created_at, updated_at, parent_sku_id, custom_fields` + searchColumns + page.selectColumns()
 	query := `
-		SELECT id, organization_id, sku_code, product_name, description, category, supplier, barcode, is_active, created_at, updated_at
 		FROM skus 
 		WHERE organization_id = $1
 	`
-	args := []interface{}{organizationID}
-	argIndex := 2
+
+	// Add search filter
+	if searching {
+		query += " AND " + skuSearchSQL("", 2)
+	}
 
 	// Add active filter
 	if !params.IncludeDeactivated {
@@ -112,86 +173,107 @@ func (p *PostgresService) GetSKUs(organizationID string, params models.SKUListPa
 		argIndex++
 	}
 
-	// Add category filter
-	if params.Category != nil && *params.Category != "" {
-		query += fmt.Sprintf(" AND category = $%d", argIndex)
-		args = append(args, *params.Category)
-		argIndex++
+	if !params.IncludeArchived {
+		query += " AND archived_at IS NULL"
 	}
 
-	// Add search filter
-	if params.Search != nil && *params.Search != "" {
-		searchTerm := "%" + strings.ToLower(*params.Search) + "%"
-		query += fmt.Sprintf(" AND (LOWER(sku_code) LIKE $%d OR LOWER(product_name) LIKE $%d OR LOWER(description) LIKE $%d)", argIndex, argIndex, argIndex)
-		args = append(args, searchTerm)
+	// Add category filter, including descendant categories
+	query, args, argIndex = addCategoryFilters(query, args, argIndex, "category_id", params.CategoryID, params.Category)
+
+	// Add supplier filter; a malformed supplier ID matches no SKUs
+	if params.SupplierID != nil && *params.SupplierID != "" && !isUUID(*params.SupplierID) {
+		query += " AND FALSE"
+	} else if params.SupplierID != nil && *params.SupplierID != "" {
+		query += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM sku_suppliers ss WHERE ss.sku_id = skus.id AND ss.supplier_id = $%d)", argIndex)
+		args = append(args, *params.SupplierID)
 		argIndex++
 	}
 
-	query += " ORDER BY created_at DESC"
-
-	// Add pagination
-	if params.Limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d", argIndex)
-		args = append(args, params.Limit)
-		argIndex++
+	// Add custom field filters
+	query, args, argIndex, err = addCustomFieldFilters(ctx, p.DB, organizationID, "skus", query, args, argIndex, "custom_fields", params.CustomFields)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		if params.Page > 0 {
-			offset := (params.Page - 1) * params.Limit
-			query += fmt.Sprintf(" OFFSET $%d", argIndex)
-			args = append(args, offset)
-		}
+	total, err := countRows(ctx, p.DB, query, args)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	rows, err := p.DB.Query(query, args...)
+	keyset, args, argIndex := page.keysetSQL(args, argIndex)
+	order, args, _ := page.orderSQL(args, argIndex)
+
+	rows, err := p.DB.QueryContext(ctx, columns+query+keyset+order, args...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
 	skus := make([]*models.SKU, 0)
+	keys := make([]*listCursor, 0)
 	for rows.Next() {
 		sku := &models.SKU{}
-		err := rows.Scan(
+		dest := []interface{}{
 			&sku.ID,
 			&sku.OrganizationID,
 			&sku.SKUCode,
 			&sku.ProductName,
 			&sku.Description,
 			&sku.Category,
+			&sku.CategoryID,
 			&sku.Supplier,
 			&sku.Barcode,
 			&sku.IsActive,
 			&sku.CreatedAt,
 			&sku.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
+			&sku.ParentSKUID,
+			&sku.CustomFields,
+		}
+		var rank float64
+		var nameHeadline, descriptionHeadline string
+		if searching {
+			dest = append(dest, &rank, &nameHeadline, &descriptionHeadline)
+		}
+		keyDest, key := page.scanKeys()
+		if err := rows.Scan(append(dest, keyDest...)...); err != nil {
+			return nil, nil, err
+		}
+		if searching {
+			sku.Search = newSearchMatch(rank, map[string]string{"product_name": nameHeadline, "description": descriptionHeadline})
 		}
 		skus = append(skus, sku)
+		keys = append(keys, key)
 	}
 
-	return skus, nil
+	skus, info := finishPage(page, skus, keys, total)
+	return skus, info, nil
 }
 
-func (p *PostgresService) GetSKUByID(organizationID, id string) (*models.SKU, error) {
+func (p *PostgresService) GetSKUByID(ctx context.Context, organizationID, id string) (*models.SKU, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	sku := &models.SKU{}
 	query := `
-		SELECT id, organization_id, sku_code, product_name, description, category, supplier, barcode, is_active, created_at, updated_at
+		SELECT id, organization_id, sku_code, product_name, description, category, category_id, supplier, barcode, is_active, created_at, updated_at, parent_sku_id, custom_fields
 		FROM skus 
 		WHERE organization_id = $1 AND id = $2
 	`
-	err := p.DB.QueryRow(query, organizationID, id).Scan(
+	err := p.DB.QueryRowContext(ctx, query, organizationID, id).Scan(
 		&sku.ID,
 		&sku.OrganizationID,
 		&sku.SKUCode,
 		&sku.ProductName,
 		&sku.Description,
 		&sku.Category,
+		&sku.CategoryID,
 		&sku.Supplier,
 		&sku.Barcode,
 		&sku.IsActive,
 		&sku.CreatedAt,
 		&sku.UpdatedAt,
+		&sku.ParentSKUID,
+		&sku.CustomFields,
 	)
 	if err != nil {
 		return nil, err
@@ -199,24 +281,50 @@ func (p *PostgresService) GetSKUByID(organizationID, id string) (*models.SKU, er
 	return sku, nil
 }
 
-func (p *PostgresService) CreateSKU(organizationID string, req models.CreateSKURequest) (*models.SKU, error) {
+func (p *PostgresService) CreateSKU(ctx context.Context, organizationID string, req models.CreateSKURequest) (*models.SKU, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	sku := &models.SKU{}
 	query := `
-		INSERT INTO skus (organization_id, sku_code, product_name, description, category, supplier, barcode, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		RETURNING id, organization_id, sku_code, product_name, description, category, supplier, barcode, is_active, created_at, updated_at
+		INSERT INTO skus (organization_id, sku_code, product_name, description, category, category_id, supplier, barcode, is_active, custom_fields, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, organization_id, sku_code, product_name, description, category, category_id, supplier, barcode, is_active, created_at, updated_at, parent_sku_id, custom_fields
 	`
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	categoryID, category, err := resolveSKUCategory(ctx, tx, organizationID, req.CategoryID, req.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	customFields, err := resolveCustomFieldValues(ctx, tx, organizationID, "skus", nil, req.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+
+	barcode, err := normalizeSKUBarcode(ctx, tx, organizationID, nil, req.Barcode)
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
-	err := p.DB.QueryRow(
+	err = tx.QueryRowContext(ctx,
 		query,
 		organizationID,
 		req.SKUCode,
 		req.ProductName,
 		req.Description,
-		req.Category,
+		category,
+		categoryID,
 		req.Supplier,
-		req.Barcode,
+		barcode,
 		true, // default to active
+		customFields,
 		now,
 		now,
 	).Scan(
@@ -226,36 +334,90 @@ func (p *PostgresService) CreateSKU(organizationID string, req models.CreateSKUR
 		&sku.ProductName,
 		&sku.Description,
 		&sku.Category,
+		&sku.CategoryID,
 		&sku.Supplier,
 		&sku.Barcode,
 		&sku.IsActive,
 		&sku.CreatedAt,
 		&sku.UpdatedAt,
+		&sku.ParentSKUID,
+		&sku.CustomFields,
 	)
 	if err != nil {
 		return nil, err
 	}
+
+	// Free-text suppliers are resolved to a supplier record and linked
+	if req.Supplier != nil {
+		if err := linkSupplierByName(ctx, tx, organizationID, sku.ID, *req.Supplier); err != nil {
+			return nil, err
+		}
+		if err := tx.QueryRowContext(ctx, `SELECT supplier FROM skus WHERE id = $1`, sku.ID).Scan(&sku.Supplier); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeSKUEvent(ctx, tx, organizationID, "sku.created", sku.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 	return sku, nil
 }
 
-func (p *PostgresService) UpdateSKU(organizationID, id string, req models.UpdateSKURequest) (*models.SKU, error) {
+func (p *PostgresService) UpdateSKU(ctx context.Context, organizationID, id string, req models.UpdateSKURequest) (*models.SKU, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	sku := &models.SKU{}
 	query := `
 		UPDATE skus 
-		SET product_name = $3, description = $4, category = $5, supplier = $6, barcode = $7, updated_at = $8
+		SET product_name = $3, description = $4, category = $5, category_id = $6, supplier = $7, barcode = $8, custom_fields = $9, updated_at = $10
 		WHERE organization_id = $1 AND id = $2
-		RETURNING id, organization_id, sku_code, product_name, description, category, supplier, barcode, is_active, created_at, updated_at
+		RETURNING id, organization_id, sku_code, product_name, description, category, category_id, supplier, barcode, is_active, created_at, updated_at, parent_sku_id, custom_fields
 	`
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var existingFields models.CustomFieldValues
+	err = tx.QueryRowContext(ctx, `SELECT custom_fields FROM skus WHERE organization_id = $1 AND id = $2 FOR UPDATE`, organizationID, id).Scan(&existingFields)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryID, category, err := resolveSKUCategory(ctx, tx, organizationID, req.CategoryID, req.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the custom fields in the request change, a null value clears one
+	customFields, err := resolveCustomFieldValues(ctx, tx, organizationID, "skus", existingFields, req.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+
+	barcode, err := normalizeSKUBarcode(ctx, tx, organizationID, &id, req.Barcode)
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now()
-	err := p.DB.QueryRow(
+	err = tx.QueryRowContext(ctx,
 		query,
 		organizationID,
 		id,
 		req.ProductName,
 		req.Description,
-		req.Category,
+		category,
+		categoryID,
 		req.Supplier,
-		req.Barcode,
+		barcode,
+		customFields,
 		now,
 	).Scan(
 		&sku.ID,
@@ -264,100 +426,171 @@ func (p *PostgresService) UpdateSKU(organizationID, id string, req models.Update
 		&sku.ProductName,
 		&sku.Description,
 		&sku.Category,
+		&sku.CategoryID,
 		&sku.Supplier,
 		&sku.Barcode,
 		&sku.IsActive,
 		&sku.CreatedAt,
 		&sku.UpdatedAt,
+		&sku.ParentSKUID,
+		&sku.CustomFields,
 	)
 	if err != nil {
 		return nil, err
 	}
+
+	// Free-text suppliers are resolved to a supplier record and linked
+	if req.Supplier != nil {
+		if err := linkSupplierByName(ctx, tx, organizationID, sku.ID, *req.Supplier); err != nil {
+			return nil, err
+		}
+		if err := tx.QueryRowContext(ctx, `SELECT supplier FROM skus WHERE id = $1`, sku.ID).Scan(&sku.Supplier); err != nil {
+			return nil, err
+		}
+	}
+
+	// Variants inherit the parent's shared fields
+	if err := propagateVariantFields(ctx, tx, organizationID, sku); err != nil {
+		return nil, err
+	}
+
+	if err := writeSKUEvent(ctx, tx, organizationID, "sku.updated", sku.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 	return sku, nil
 }
 
-func (p *PostgresService) UpdateSKUStatus(organizationID, id string, isActive bool) (*models.SKU, error) {
+func (p *PostgresService) UpdateSKUStatus(ctx context.Context, organizationID, id string, isActive bool) (*models.SKU, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	sku := &models.SKU{}
 	query := `
 		UPDATE skus 
 		SET is_active = $3, updated_at = $4
 		WHERE organization_id = $1 AND id = $2
-		RETURNING id, organization_id, sku_code, product_name, description, category, supplier, barcode, is_active, created_at, updated_at
+		RETURNING id, organization_id, sku_code, product_name, description, category, category_id, supplier, barcode, is_active, created_at, updated_at, parent_sku_id, custom_fields
 	`
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	now := time.Now()
-	err := p.DB.QueryRow(query, organizationID, id, isActive, now).Scan(
+	err = tx.QueryRowContext(ctx, query, organizationID, id, isActive, now).Scan(
 		&sku.ID,
 		&sku.OrganizationID,
 		&sku.SKUCode,
 		&sku.ProductName,
 		&sku.Description,
 		&sku.Category,
+		&sku.CategoryID,
 		&sku.Supplier,
 		&sku.Barcode,
 		&sku.IsActive,
 		&sku.CreatedAt,
 		&sku.UpdatedAt,
+		&sku.ParentSKUID,
+		&sku.CustomFields,
 	)
 	if err != nil {
 		return nil, err
 	}
+
+	if err := writeSKUEvent(ctx, tx, organizationID, "sku.updated", sku.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 	return sku, nil
 }
 
 // Inventory Methods
 
-func (p *PostgresService) GetInventoryWithSKUs(organizationID string, params models.InventoryListParams) ([]*models.InventoryWithSKU, error) {
-	query := `
+func (p *PostgresService) GetInventoryWithSKUs(ctx context.Context, organizationID string, params models.InventoryListParams) ([]*models.InventoryWithSKU, *models.PageInfo, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	args := []interface{}{organizationID}
+	argIndex := 2
+
+	// The search term comes first so the ranking columns can refer to it
+	searching := params.Search != nil && *params.Search != ""
+	searchColumns := ""
+	list := inventorySorts
+	if searching {
+		rankSQL := skuSearchRankSQL("s.", argIndex)
+		searchColumns = fmt.Sprintf(", %s AS search_rank, %s, %s",
+			rankSQL, searchHeadlineSQL("s.product_name", argIndex), searchHeadlineSQL("s.description", argIndex))
+		args = append(args, searchArgs(*params.Search)...)
+		argIndex += 2
+		list = list.withKey("relevance", sortColumn{rankSQL, "float8"})
+		list.defaultKey = "-relevance"
+	}
+
+	page, err := newPageQuery(list, params.Sort, params.Cursor, params.Limit, pageOffset(params.Page, params.Limit))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := `
 		SELECT 
-			i.id, i.organization_id, i.sku_id, i.quantity, i.weighted_cost, i.total_value, i.is_manual_cost, i.created_at, i.updated_at,
-			s.sku_code, s.product_name, s.description, s.category, s.supplier, s.barcode, s.is_active
+			i.id, i.organization_id, i.sku_id, i.quantity, i.weighted_cost, i.total_value, i.is_manual_cost, i.created_at, i.updated_at, i.custom_fields,
+			` + reservedQuantitySQL + `,
+			s.sku_code, s.product_name, s.description, s.category, s.supplier, s.barcode, s.is_active` + searchColumns + page.selectColumns()
+	query := `
 		FROM inventory i
 		JOIN skus s ON i.sku_id = s.id
 		WHERE i.organization_id = $1 AND s.is_active = true
 	`
-	args := []interface{}{organizationID}
-	argIndex := 2
 
-	// Add category filter
-	if params.Category != nil && *params.Category != "" {
-		query += fmt.Sprintf(" AND s.category = $%d", argIndex)
-		args = append(args, *params.Category)
-		argIndex++
+	// Add search filter, covering the SKU's and the inventory record's custom fields
+	if searching {
+		query += fmt.Sprintf(" AND (%s OR %s)", skuSearchSQL("s.", 2), customFieldSearchSQL("i.custom_fields", 3))
 	}
 
-	// Add search filter
-	if params.Search != nil && *params.Search != "" {
-		searchTerm := "%" + strings.ToLower(*params.Search) + "%"
-		query += fmt.Sprintf(" AND (LOWER(s.sku_code) LIKE $%d OR LOWER(s.product_name) LIKE $%d OR LOWER(s.description) LIKE $%d)", argIndex, argIndex, argIndex)
-		args = append(args, searchTerm)
+	// Add category filter, including descendant categories
+	query, args, argIndex = addCategoryFilters(query, args, argIndex, "s.category_id", params.CategoryID, params.Category)
+
+	// Add low stock filter
+	if params.MaxQuantity != nil {
+		query += fmt.Sprintf(" AND i.quantity <= $%d", argIndex)
+		args = append(args, *params.MaxQuantity)
 		argIndex++
 	}
 
-	query += " ORDER BY i.created_at DESC"
-
-	// Add pagination
-	if params.Limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d", argIndex)
-		args = append(args, params.Limit)
-		argIndex++
+	// Add custom field filters
+	query, args, argIndex, err = addCustomFieldFilters(ctx, p.DB, organizationID, "inventory", query, args, argIndex, "i.custom_fields", params.CustomFields)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		if params.Page > 0 {
-			offset := (params.Page - 1) * params.Limit
-			query += fmt.Sprintf(" OFFSET $%d", argIndex)
-			args = append(args, offset)
-		}
+	total, err := countRows(ctx, p.DB, query, args)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	rows, err := p.DB.Query(query, args...)
+	keyset, args, argIndex := page.keysetSQL(args, argIndex)
+	order, args, _ := page.orderSQL(args, argIndex)
+
+	rows, err := p.DB.QueryContext(ctx, columns+query+keyset+order, args...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
 	inventory := make([]*models.InventoryWithSKU, 0)
+	keys := make([]*listCursor, 0)
 	for rows.Next() {
 		item := &models.InventoryWithSKU{}
-		err := rows.Scan(
+		dest := []interface{}{
 			&item.ID,
 			&item.OrganizationID,
 			&item.SKUID,
@@ -367,6 +600,8 @@ func (p *PostgresService) GetInventoryWithSKUs(organizationID string, params mod
 			&item.IsManualCost,
 			&item.CreatedAt,
 			&item.UpdatedAt,
+			&item.CustomFields,
+			&item.ReservedQuantity,
 			&item.SKUCode,
 			&item.ProductName,
 			&item.Description,
@@ -374,24 +609,73 @@ func (p *PostgresService) GetInventoryWithSKUs(organizationID string, params mod
 			&item.Supplier,
 			&item.Barcode,
 			&item.IsActive,
-		)
-		if err != nil {
-			return nil, err
 		}
+		var rank float64
+		var nameHeadline, descriptionHeadline string
+		if searching {
+			dest = append(dest, &rank, &nameHeadline, &descriptionHeadline)
+		}
+		keyDest, key := page.scanKeys()
+		if err := rows.Scan(append(dest, keyDest...)...); err != nil {
+			return nil, nil, err
+		}
+		if searching {
+			item.Search = newSearchMatch(rank, map[string]string{"product_name": nameHeadline, "description": descriptionHeadline})
+		}
+		item.AvailableQuantity = item.Quantity - item.ReservedQuantity
 		inventory = append(inventory, item)
+		keys = append(keys, key)
 	}
 
+	inventory, info := finishPage(page, inventory, keys, total)
+	return inventory, info, nil
+}
+
+func (p *PostgresService) GetInventoryBySKUID(ctx context.Context, organizationID, skuID string) (*models.Inventory, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return getInventoryBySKUID(ctx, p.DB, organizationID, skuID)
+}
+
+func getInventoryBySKUID(ctx context.Context, q queryer, organizationID, skuID string) (*models.Inventory, error) {
+	inventory := &models.Inventory{}
+	query := `
+		SELECT i.id, i.organization_id, i.sku_id, i.quantity, i.weighted_cost, i.total_value, i.is_manual_cost, i.created_at, i.updated_at, i.custom_fields,
+			` + reservedQuantitySQL + `
+		FROM inventory i
+		WHERE i.organization_id = $1 AND i.sku_id = $2
+	`
+	err := q.QueryRowContext(ctx, query, organizationID, skuID).Scan(
+		&inventory.ID,
+		&inventory.OrganizationID,
+		&inventory.SKUID,
+		&inventory.Quantity,
+		&inventory.WeightedCost,
+		&inventory.TotalValue,
+		&inventory.IsManualCost,
+		&inventory.CreatedAt,
+		&inventory.UpdatedAt,
+		&inventory.CustomFields,
+		&inventory.ReservedQuantity,
+	)
+	if err != nil {
+		return nil, err
+	}
+	inventory.AvailableQuantity = inventory.Quantity - inventory.ReservedQuantity
 	return inventory, nil
 }
 
-func (p *PostgresService) GetInventoryBySKUID(organizationID, skuID string) (*models.Inventory, error) {
+// getInventoryForUpdate locks the inventory row of a SKU for the rest of tx
+func getInventoryForUpdate(ctx context.Context, tx *sql.Tx, organizationID, skuID string) (*models.Inventory, error) {
 	inventory := &models.Inventory{}
 	query := `
-		SELECT id, organization_id, sku_id, quantity, weighted_cost, total_value, is_manual_cost, created_at, updated_at
+		SELECT id, organization_id, sku_id, quantity, weighted_cost, total_value, is_manual_cost, created_at, updated_at, custom_fields
 		FROM inventory 
 		WHERE organization_id = $1 AND sku_id = $2
+		FOR UPDATE
 	`
-	err := p.DB.QueryRow(query, organizationID, skuID).Scan(
+	err := tx.QueryRowContext(ctx, query, organizationID, skuID).Scan(
 		&inventory.ID,
 		&inventory.OrganizationID,
 		&inventory.SKUID,
@@ -401,6 +685,7 @@ func (p *PostgresService) GetInventoryBySKUID(organizationID, skuID string) (*mo
 		&inventory.IsManualCost,
 		&inventory.CreatedAt,
 		&inventory.UpdatedAt,
+		&inventory.CustomFields,
 	)
 	if err != nil {
 		return nil, err
@@ -408,11 +693,14 @@ func (p *PostgresService) GetInventoryBySKUID(organizationID, skuID string) (*mo
 	return inventory, nil
 }
 
-func (p *PostgresService) UpdateManualCost(organizationID, skuID string, req models.UpdateManualCostRequest) (*models.Inventory, error) {
+func (p *PostgresService) UpdateManualCost(ctx context.Context, organizationID, skuID string, req models.UpdateManualCostRequest) (*models.Inventory, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	inventory := &models.Inventory{}
 
 	// First get current inventory data
-	currentInventory, err := p.GetInventoryBySKUID(organizationID, skuID)
+	currentInventory, err := p.GetInventoryBySKUID(ctx, organizationID, skuID)
 	if err != nil {
 		return nil, err
 	}
@@ -421,13 +709,14 @@ func (p *PostgresService) UpdateManualCost(organizationID, skuID string, req mod
 	newTotalValue := float64(currentInventory.Quantity) * req.WeightedCost
 
 	query := `
-		UPDATE inventory 
+		UPDATE inventory i
 		SET weighted_cost = $3, total_value = $4, is_manual_cost = $5, updated_at = $6
-		WHERE organization_id = $1 AND sku_id = $2
-		RETURNING id, organization_id, sku_id, quantity, weighted_cost, total_value, is_manual_cost, created_at, updated_at
+		WHERE i.organization_id = $1 AND i.sku_id = $2
+		RETURNING i.id, i.organization_id, i.sku_id, i.quantity, i.weighted_cost, i.total_value, i.is_manual_cost, i.created_at, i.updated_at, i.custom_fields,
+			` + reservedQuantitySQL + `
 	`
 	now := time.Now()
-	err = p.DB.QueryRow(
+	err = p.DB.QueryRowContext(ctx,
 		query,
 		organizationID,
 		skuID,
@@ -445,24 +734,91 @@ func (p *PostgresService) UpdateManualCost(organizationID, skuID string, req mod
 		&inventory.IsManualCost,
 		&inventory.CreatedAt,
 		&inventory.UpdatedAt,
+		&inventory.CustomFields,
+		&inventory.ReservedQuantity,
 	)
 	if err != nil {
 		return nil, err
 	}
+	inventory.AvailableQuantity = inventory.Quantity - inventory.ReservedQuantity
 	return inventory, nil
 }
 
-func (p *PostgresService) CreateInventoryForSKU(organizationID, skuID string, quantity int, weightedCost float64) (*models.Inventory, error) {
+func (p *PostgresService) CreateInventoryForSKU(ctx context.Context, organizationID string, req models.CreateInventoryRequest) (*models.Inventory, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	customFields, err := resolveCustomFieldValues(ctx, tx, organizationID, "inventory", nil, req.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory, err := createInventory(ctx, tx, organizationID, req.SKUID, req.Quantity, req.WeightedCost, customFields)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeInventoryEvent(ctx, tx, organizationID, req.SKUID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return inventory, nil
+}
+
+// UpdateInventoryCustomFields changes the custom fields in values, a null value clears one
+func (p *PostgresService) UpdateInventoryCustomFields(ctx context.Context, organizationID, skuID string, values models.CustomFieldValues) (*models.Inventory, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	inventory, err := getInventoryForUpdate(ctx, tx, organizationID, skuID)
+	if err != nil {
+		return nil, err
+	}
+
+	customFields, err := resolveCustomFieldValues(ctx, tx, organizationID, "inventory", inventory.CustomFields, values)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE inventory SET custom_fields = $3, updated_at = $4 WHERE organization_id = $1 AND sku_id = $2`, organizationID, skuID, customFields, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return p.GetInventoryBySKUID(ctx, organizationID, skuID)
+}
+
+// createInventory inserts an inventory record. Records created implicitly by an IN
+// transaction start without custom fields; required ones are enforced on explicit writes.
+func createInventory(ctx context.Context, q queryer, organizationID, skuID string, quantity int, weightedCost float64, customFields models.CustomFieldValues) (*models.Inventory, error) {
 	inventory := &models.Inventory{}
 	totalValue := float64(quantity) * weightedCost
 
 	query := `
-		INSERT INTO inventory (organization_id, sku_id, quantity, weighted_cost, total_value, is_manual_cost, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, organization_id, sku_id, quantity, weighted_cost, total_value, is_manual_cost, created_at, updated_at
+		INSERT INTO inventory (organization_id, sku_id, quantity, weighted_cost, total_value, is_manual_cost, custom_fields, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, organization_id, sku_id, quantity, weighted_cost, total_value, is_manual_cost, created_at, updated_at, custom_fields
 	`
 	now := time.Now()
-	err := p.DB.QueryRow(
+	err := q.QueryRowContext(ctx,
 		query,
 		organizationID,
 		skuID,
@@ -470,6 +826,7 @@ func (p *PostgresService) CreateInventoryForSKU(organizationID, skuID string, qu
 		weightedCost,
 		totalValue,
 		false, // default to not manual cost
+		customFields,
 		now,
 		now,
 	).Scan(
@@ -482,30 +839,62 @@ func (p *PostgresService) CreateInventoryForSKU(organizationID, skuID string, qu
 		&inventory.IsManualCost,
 		&inventory.CreatedAt,
 		&inventory.UpdatedAt,
+		&inventory.CustomFields,
 	)
 	if err != nil {
 		return nil, err
 	}
+	// A new inventory record cannot have reservations yet
+	inventory.AvailableQuantity = inventory.Quantity
 	return inventory, nil
 }
 
 // Transaction Methods
 
-func (p *PostgresService) GetTransactionsWithDetails(organizationID string, params models.TransactionListParams) ([]*models.TransactionWithSKU, error) {
-	query := `
+func (p *PostgresService) GetTransactionsWithDetails(ctx context.Context, organizationID string, params models.TransactionListParams) ([]*models.TransactionWithSKU, *models.PageInfo, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	args := []interface{}{organizationID}
+	argIndex := 2
+
+	// The search term comes first so the ranking columns can refer to it
+	searching := params.Search != nil && *params.Search != ""
+	searchColumns := ""
+	list := transactionSorts
+	if searching {
+		rankSQL := transactionSearchRankSQL(argIndex)
+		searchColumns = fmt.Sprintf(", %s AS search_rank, %s, %s",
+			rankSQL, searchHeadlineSQL("s.product_name", argIndex), searchHeadlineSQL("t.notes", argIndex))
+		args = append(args, searchArgs(*params.Search)...)
+		argIndex += 2
+		list = list.withKey("relevance", sortColumn{rankSQL, "float8"})
+		list.defaultKey = "-relevance"
+	}
+
+	page, err := newPageQuery(list, params.Sort, params.Cursor, params.Limit, pageOffset(params.Page, params.Limit))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := `
 		SELECT 
 			t.id, t.organization_id, t.sku_id, t.transaction_type, t.quantity, 
 			t.unit_cost, t.total_cost, t.reference_number, t.notes, t.created_by, 
-			t.created_at, t.updated_at,
+			t.created_at, t.updated_at, t.custom_fields,
 			s.sku_code, s.product_name, s.description, s.category,
-			u.name as created_by_name
+			u.name as created_by_name` + searchColumns + page.selectColumns()
+	query := `
 		FROM transactions t
 		JOIN skus s ON t.sku_id = s.id
 		JOIN users u ON t.created_by = u.id
 		WHERE t.organization_id = $1
 	`
-	args := []interface{}{organizationID}
-	argIndex := 2
+
+	// Add search filter
+	if searching {
+		query += " AND " + transactionSearchSQL(2)
+	}
 
 	// Add transaction type filter
 	if params.TransactionType != nil && *params.TransactionType != "" {
@@ -521,19 +910,13 @@ func (p *PostgresService) GetTransactionsWithDetails(organizationID string, para
 		argIndex++
 	}
 
-	// Add category filter
-	if params.Category != nil && *params.Category != "" {
-		query += fmt.Sprintf(" AND s.category = $%d", argIndex)
-		args = append(args, *params.Category)
-		argIndex++
-	}
+	// Add category filter, including descendant categories
+	query, args, argIndex = addCategoryFilters(query, args, argIndex, "s.category_id", params.CategoryID, params.Category)
 
-	// Add search filter
-	if params.Search != nil && *params.Search != "" {
-		searchTerm := "%" + strings.ToLower(*params.Search) + "%"
-		query += fmt.Sprintf(" AND (LOWER(s.sku_code) LIKE $%d OR LOWER(s.product_name) LIKE $%d OR LOWER(t.reference_number) LIKE $%d OR LOWER(t.notes) LIKE $%d)", argIndex, argIndex, argIndex, argIndex)
-		args = append(args, searchTerm)
-		argIndex++
+	// Add custom field filters
+	query, args, argIndex, err = addCustomFieldFilters(ctx, p.DB, organizationID, "inventory_transactions", query, args, argIndex, "t.custom_fields", params.CustomFields)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Add date range filters
@@ -549,31 +932,25 @@ func (p *PostgresService) GetTransactionsWithDetails(organizationID string, para
 		argIndex++
 	}
 
-	query += " ORDER BY t.created_at DESC"
-
-	// Add pagination
-	if params.Limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d", argIndex)
-		args = append(args, params.Limit)
-		argIndex++
-
-		if params.Page > 0 {
-			offset := (params.Page - 1) * params.Limit
-			query += fmt.Sprintf(" OFFSET $%d", argIndex)
-			args = append(args, offset)
-		}
+	total, err := countRows(ctx, p.DB, query, args)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	rows, err := p.DB.Query(query, args...)
+	keyset, args, argIndex := page.keysetSQL(args, argIndex)
+	order, args, _ := page.orderSQL(args, argIndex)
+
+	rows, err := p.DB.QueryContext(ctx, columns+query+keyset+order, args...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
 	transactions := make([]*models.TransactionWithSKU, 0)
+	keys := make([]*listCursor, 0)
 	for rows.Next() {
 		tx := &models.TransactionWithSKU{}
-		err := rows.Scan(
+		dest := []interface{}{
 			&tx.ID,
 			&tx.OrganizationID,
 			&tx.SKUID,
@@ -586,35 +963,135 @@ func (p *PostgresService) GetTransactionsWithDetails(organizationID string, para
 			&tx.CreatedBy,
 			&tx.CreatedAt,
 			&tx.UpdatedAt,
+			&tx.CustomFields,
 			&tx.SKUCode,
 			&tx.ProductName,
 			&tx.Description,
 			&tx.Category,
 			&tx.CreatedByName,
-		)
-		if err != nil {
-			return nil, err
+		}
+		var rank float64
+		var nameHeadline, notesHeadline string
+		if searching {
+			dest = append(dest, &rank, &nameHeadline, &notesHeadline)
+		}
+		keyDest, key := page.scanKeys()
+		if err := rows.Scan(append(dest, keyDest...)...); err != nil {
+			return nil, nil, err
+		}
+		if searching {
+			tx.Search = newSearchMatch(rank, map[string]string{"product_name": nameHeadline, "notes": notesHeadline})
 		}
 		transactions = append(transactions, tx)
+		keys = append(keys, key)
+	}
+
+	transactions, info := finishPage(page, transactions, keys, total)
+	return transactions, info, nil
+}
+
+func (p *PostgresService) CreateTransaction(ctx context.Context, organizationID, userID string, req models.CreateTransactionRequest) (*models.Transaction, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Business rules and required custom fields are only enforced on transactions
+	// entered directly, not on postings made by orders and kits
+	if err := checkBusinessRules(ctx, tx, organizationID, req); err != nil {
+		return nil, err
 	}
 
-	return transactions, nil
+	req.CustomFields, err = resolveCustomFieldValues(ctx, tx, organizationID, "inventory_transactions", nil, req.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := createTransactionTx(ctx, tx, organizationID, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return transaction, nil
 }
 
-func (p *PostgresService) CreateTransaction(organizationID, userID string, req models.CreateTransactionRequest) (*models.Transaction, error) {
+func (p *PostgresService) GetTransactionByID(ctx context.Context, organizationID, id string) (*models.Transaction, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if !isUUID(id) {
+		return nil, fmt.Errorf("transaction not found")
+	}
+	transaction := &models.Transaction{}
+	query := `
+		SELECT id, organization_id, sku_id, transaction_type, quantity, unit_cost, total_cost, reference_number, notes, created_by, created_at, updated_at, custom_fields
+		FROM transactions
+		WHERE organization_id = $1 AND id = $2
+	`
+	err := p.DB.QueryRowContext(ctx, query, organizationID, id).Scan(
+		&transaction.ID,
+		&transaction.OrganizationID,
+		&transaction.SKUID,
+		&transaction.TransactionType,
+		&transaction.Quantity,
+		&transaction.UnitCost,
+		&transaction.TotalCost,
+		&transaction.ReferenceNumber,
+		&transaction.Notes,
+		&transaction.CreatedBy,
+		&transaction.CreatedAt,
+		&transaction.UpdatedAt,
+		&transaction.CustomFields,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("transaction not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// createTransactionTx records a transaction and applies it to inventory inside tx.
+// Every stock movement goes through here so the weighted cost is computed in one place.
+func createTransactionTx(ctx context.Context, tx *sql.Tx, organizationID, userID string, req models.CreateTransactionRequest) (*models.Transaction, error) {
 	// First, validate that the SKU exists and belongs to this organization
-	_, err := p.GetSKUByID(organizationID, req.SKUID)
+	var skuExists, isVariantParent bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM skus WHERE organization_id = $1 AND id = $2),
+			EXISTS (SELECT 1 FROM skus WHERE organization_id = $1 AND id = $2 AND variant_attributes IS NOT NULL)
+	`, organizationID, req.SKUID).Scan(&skuExists, &isVariantParent)
 	if err != nil {
-		return nil, fmt.Errorf("SKU not found: %v", err)
+		return nil, fmt.Errorf("SKU not found: %w", err)
+	}
+	if !skuExists {
+		return nil, fmt.Errorf("SKU not found: %v", sql.ErrNoRows)
+	}
+
+	// Stock is held on the variants, never on the parent that groups them
+	if isVariantParent {
+		return nil, fmt.Errorf("SKU has variants: post transactions to a variant instead")
 	}
 
 	// Calculate total cost
 	totalCost := float64(req.Quantity) * req.UnitCost
 
+	// Lock the inventory row so concurrent movements and reservations see the same quantity
+	inventory, err := getInventoryForUpdate(ctx, tx, organizationID, req.SKUID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
 	// For 'out' transactions, check if there's enough inventory
 	if req.TransactionType == "out" {
-		inventory, err := p.GetInventoryBySKUID(organizationID, req.SKUID)
-		if err != nil {
+		if inventory == nil {
 			// If no inventory record exists, we can't do an 'out' transaction
 			return nil, fmt.Errorf("insufficient inventory: no inventory record found")
 		}
@@ -622,17 +1099,32 @@ func (p *PostgresService) CreateTransaction(organizationID, userID string, req m
 		if inventory.Quantity < req.Quantity {
 			return nil, fmt.Errorf("insufficient inventory: have %d, requested %d", inventory.Quantity, req.Quantity)
 		}
+
+		if req.ReservationID != nil {
+			if _, err := consumeReservationTx(ctx, tx, organizationID, req.SKUID, *req.ReservationID, req.Quantity); err != nil {
+				return nil, err
+			}
+		}
+
+		// Stock held by other reservations can't be issued
+		reserved, err := reservedQuantity(ctx, tx, organizationID, req.SKUID)
+		if err != nil {
+			return nil, err
+		}
+		if available := inventory.Quantity - reserved; available < req.Quantity {
+			return nil, fmt.Errorf("insufficient inventory: available %d, requested %d", available, req.Quantity)
+		}
 	}
 
 	// Create the transaction
 	transaction := &models.Transaction{}
 	query := `
-		INSERT INTO transactions (organization_id, sku_id, transaction_type, quantity, unit_cost, total_cost, reference_number, notes, created_by, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
-		RETURNING id, organization_id, sku_id, transaction_type, quantity, unit_cost, total_cost, reference_number, notes, created_by, created_at, updated_at
+		INSERT INTO transactions (organization_id, sku_id, transaction_type, quantity, unit_cost, total_cost, reference_number, notes, custom_fields, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, organization_id, sku_id, transaction_type, quantity, unit_cost, total_cost, reference_number, notes, created_by, created_at, updated_at, custom_fields
 	`
 	now := time.Now()
-	err = p.DB.QueryRow(
+	err = tx.QueryRowContext(ctx,
 		query,
 		organizationID,
 		req.SKUID,
@@ -642,6 +1134,7 @@ func (p *PostgresService) CreateTransaction(organizationID, userID string, req m
 		totalCost,
 		req.ReferenceNumber,
 		req.Notes,
+		req.CustomFields,
 		userID,
 		now,
 		now,
@@ -658,29 +1151,40 @@ func (p *PostgresService) CreateTransaction(organizationID, userID string, req m
 		&transaction.CreatedBy,
 		&transaction.CreatedAt,
 		&transaction.UpdatedAt,
+		&transaction.CustomFields,
 	)
 	if err != nil {
 		return nil, err
 	}
 
 	// Update inventory based on transaction type
-	err = p.updateInventoryFromTransaction(organizationID, req.SKUID, req.TransactionType, req.Quantity, req.UnitCost)
+	err = updateInventoryFromTransaction(ctx, tx, organizationID, req.SKUID, inventory, req.TransactionType, req.Quantity, req.UnitCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update inventory: %w", err)
+	}
+
+	err = writeOutboxEvent(ctx, tx, organizationID, "transaction.created", "transaction", transaction.ID,
+		map[string]interface{}{"transaction": transaction})
 	if err != nil {
-		// Log error but don't fail the transaction creation
-		// In a real system, this should be handled with database transactions
-		fmt.Printf("Warning: Failed to update inventory: %v\n", err)
+		return nil, err
+	}
+	if err := writeInventoryEvent(ctx, tx, organizationID, req.SKUID); err != nil {
+		return nil, err
+	}
+	if req.TransactionType == "out" {
+		if err := writeLowStockEvent(ctx, tx, organizationID, req.SKUID, inventory.Quantity, req.Quantity); err != nil {
+			return nil, err
+		}
 	}
 
 	return transaction, nil
 }
 
-func (p *PostgresService) updateInventoryFromTransaction(organizationID, skuID string, transactionType string, quantity int, unitCost float64) error {
-	// Get current inventory
-	inventory, err := p.GetInventoryBySKUID(organizationID, skuID)
-	if err != nil {
+func updateInventoryFromTransaction(ctx context.Context, tx *sql.Tx, organizationID, skuID string, inventory *models.Inventory, transactionType string, quantity int, unitCost float64) error {
+	if inventory == nil {
 		// If no inventory exists and this is an 'in' transaction, create it
 		if transactionType == "in" {
-			_, err = p.CreateInventoryForSKU(organizationID, skuID, quantity, unitCost)
+			_, err := createInventory(ctx, tx, organizationID, skuID, quantity, unitCost, nil)
 			return err
 		}
 		return fmt.Errorf("inventory not found for SKU %s", skuID)
@@ -712,11 +1216,19 @@ func (p *PostgresService) updateInventoryFromTransaction(organizationID, skuID s
 		SET quantity = $3, weighted_cost = $4, total_value = $5, updated_at = $6
 		WHERE organization_id = $1 AND sku_id = $2
 	`
-	_, err = p.DB.Exec(query, organizationID, skuID, newQuantity, newWeightedCost, newTotalValue, time.Now())
+	_, err := tx.ExecContext(ctx, query, organizationID, skuID, newQuantity, newWeightedCost, newTotalValue, time.Now())
 	return err
 }
 
-func (p *PostgresService) GetTransactionSummary(organizationID string, params models.TransactionListParams) ([]*models.TransactionSummary, error) {
+func (p *PostgresService) GetTransactionSummary(ctx context.Context, organizationID string, params models.TransactionListParams) ([]*models.TransactionSummary, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.beginReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 	query := `
 		SELECT 
 			t.transaction_type,
@@ -737,10 +1249,17 @@ func (p *PostgresService) GetTransactionSummary(organizationID string, params mo
 		argIndex++
 	}
 
-	if params.Category != nil && *params.Category != "" {
-		query += fmt.Sprintf(" AND s.category = $%d", argIndex)
-		args = append(args, *params.Category)
-		argIndex++
+	query, args, argIndex = addCategoryFilters(query, args, argIndex, "s.category_id", params.CategoryID, params.Category)
+
+	query, args, argIndex, err = addCustomFieldFilters(ctx, tx, organizationID, "inventory_transactions", query, args, argIndex, "t.custom_fields", params.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.Search != nil && *params.Search != "" {
+		query += " AND " + transactionSearchSQL(argIndex)
+		args = append(args, searchArgs(*params.Search)...)
+		argIndex += 2
 	}
 
 	if params.StartDate != nil && *params.StartDate != "" {
@@ -757,7 +1276,7 @@ func (p *PostgresService) GetTransactionSummary(organizationID string, params mo
 
 	query += " GROUP BY t.transaction_type ORDER BY t.transaction_type"
 
-	rows, err := p.DB.Query(query, args...)
+	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -783,12 +1302,21 @@ func (p *PostgresService) GetTransactionSummary(organizationID string, params mo
 
 // User Management Methods
 
-func (p *PostgresService) GetUsersWithDetails(organizationID string, params models.UserListParams) ([]*models.UserWithDetails, error) {
-	query := `
+func (p *PostgresService) GetUsersWithDetails(ctx context.Context, organizationID string, params models.UserListParams) ([]*models.UserWithDetails, *models.PageInfo, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	page, err := newPageQuery(userSorts, params.Sort, params.Cursor, params.Limit, pageOffset(params.Page, params.Limit))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := `
 		SELECT 
 			u.id, u.organization_id, u.email, u.name, u.role, u.is_active, 
-			u.last_login_at, u.created_at, u.updated_at,
-			o.name as organization_name
+			u.last_login_at, u.preferred_locale, u.created_at, u.updated_at,
+			o.name as organization_name` + page.selectColumns()
+	query := `
 		FROM users u
 		JOIN organizations o ON u.organization_id = o.id
 		WHERE u.organization_id = $1
@@ -818,31 +1346,26 @@ func (p *PostgresService) GetUsersWithDetails(organizationID string, params mode
 		argIndex++
 	}
 
-	query += " ORDER BY u.created_at DESC"
-
-	// Add pagination
-	if params.Limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d", argIndex)
-		args = append(args, params.Limit)
-		argIndex++
-
-		if params.Page > 0 {
-			offset := (params.Page - 1) * params.Limit
-			query += fmt.Sprintf(" OFFSET $%d", argIndex)
-			args = append(args, offset)
-		}
+	total, err := countRows(ctx, p.DB, query, args)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	rows, err := p.DB.Query(query, args...)
+	keyset, args, argIndex := page.keysetSQL(args, argIndex)
+	order, args, _ := page.orderSQL(args, argIndex)
+
+	rows, err := p.DB.QueryContext(ctx, columns+query+keyset+order, args...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
 	users := make([]*models.UserWithDetails, 0)
+	keys := make([]*listCursor, 0)
 	for rows.Next() {
 		user := &models.UserWithDetails{}
-		err := rows.Scan(
+		keyDest, key := page.scanKeys()
+		dest := []interface{}{
 			&user.ID,
 			&user.OrganizationID,
 			&user.Email,
@@ -850,31 +1373,37 @@ func (p *PostgresService) GetUsersWithDetails(organizationID string, params mode
 			&user.Role,
 			&user.IsActive,
 			&user.LastLoginAt,
+			&user.PreferredLocale,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 			&user.OrganizationName,
-		)
-		if err != nil {
-			return nil, err
+		}
+		if err := rows.Scan(append(dest, keyDest...)...); err != nil {
+			return nil, nil, err
 		}
 		users = append(users, user)
+		keys = append(keys, key)
 	}
 
-	return users, nil
+	users, info := finishPage(page, users, keys, total)
+	return users, info, nil
 }
 
-func (p *PostgresService) GetUserWithDetails(organizationID, userID string) (*models.UserWithDetails, error) {
+func (p *PostgresService) GetUserWithDetails(ctx context.Context, organizationID, userID string) (*models.UserWithDetails, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	user := &models.UserWithDetails{}
 	query := `
 		SELECT 
 			u.id, u.organization_id, u.email, u.name, u.role, u.is_active, 
-			u.last_login_at, u.created_at, u.updated_at,
+			u.last_login_at, u.preferred_locale, u.created_at, u.updated_at,
 			o.name as organization_name
 		FROM users u
 		JOIN organizations o ON u.organization_id = o.id
 		WHERE u.organization_id = $1 AND u.id = $2
 	`
-	err := p.DB.QueryRow(query, organizationID, userID).Scan(
+	err := p.DB.QueryRowContext(ctx, query, organizationID, userID).Scan(
 		&user.ID,
 		&user.OrganizationID,
 		&user.Email,
@@ -882,6 +1411,7 @@ func (p *PostgresService) GetUserWithDetails(organizationID, userID string) (*mo
 		&user.Role,
 		&user.IsActive,
 		&user.LastLoginAt,
+		&user.PreferredLocale,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&user.OrganizationName,
@@ -892,15 +1422,24 @@ func (p *PostgresService) GetUserWithDetails(organizationID, userID string) (*mo
 	return user, nil
 }
 
-func (p *PostgresService) CreateUser(organizationID string, req models.CreateUserRequest) (*models.UserWithDetails, error) {
+func (p *PostgresService) CreateUser(ctx context.Context, organizationID string, req models.CreateUserRequest) (*models.UserWithDetails, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	user := &models.UserWithDetails{}
 	query := `
 		INSERT INTO users (organization_id, email, name, role, is_active, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, organization_id, email, name, role, is_active, last_login_at, created_at, updated_at
+		RETURNING id, organization_id, email, name, role, is_active, last_login_at, preferred_locale, created_at, updated_at
 	`
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	now := time.Now()
-	err := p.DB.QueryRow(
+	err = tx.QueryRowContext(ctx,
 		query,
 		organizationID,
 		req.Email,
@@ -917,6 +1456,7 @@ func (p *PostgresService) CreateUser(organizationID string, req models.CreateUse
 		&user.Role,
 		&user.IsActive,
 		&user.LastLoginAt,
+		&user.PreferredLocale,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -926,15 +1466,25 @@ func (p *PostgresService) CreateUser(organizationID string, req models.CreateUse
 
 	// Get the organization name
 	orgQuery := `SELECT name FROM organizations WHERE id = $1`
-	err = p.DB.QueryRow(orgQuery, organizationID).Scan(&user.OrganizationName)
+	err = tx.QueryRowContext(ctx, orgQuery, organizationID).Scan(&user.OrganizationName)
 	if err != nil {
 		user.OrganizationName = ""
 	}
 
+	if err := writeOutboxEvent(ctx, tx, organizationID, "user.created", "user", user.ID, map[string]interface{}{"user": user}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 	return user, nil
 }
 
-func (p *PostgresService) UpdateUser(organizationID, userID string, req models.UpdateUserRequest) (*models.UserWithDetails, error) {
+func (p *PostgresService) UpdateUser(ctx context.Context, organizationID, userID string, req models.UpdateUserRequest) (*models.UserWithDetails, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	user := &models.UserWithDetails{}
 
 	// Build dynamic query based on provided fields
@@ -956,14 +1506,21 @@ func (p *PostgresService) UpdateUser(organizationID, userID string, req models.U
 		argIndex++
 	}
 
+	// An empty preferred locale clears it
+	if req.PreferredLocale != nil {
+		setParts = append(setParts, fmt.Sprintf("preferred_locale = NULLIF($%d, '')", argIndex))
+		args = append(args, *req.PreferredLocale)
+		argIndex++
+	}
+
 	query := fmt.Sprintf(`
 		UPDATE users 
 		SET %s
 		WHERE organization_id = $1 AND id = $2
-		RETURNING id, organization_id, email, name, role, is_active, last_login_at, created_at, updated_at
+		RETURNING id, organization_id, email, name, role, is_active, last_login_at, preferred_locale, created_at, updated_at
 	`, strings.Join(setParts, ", "))
 
-	err := p.DB.QueryRow(query, args...).Scan(
+	err := p.DB.QueryRowContext(ctx, query, args...).Scan(
 		&user.ID,
 		&user.OrganizationID,
 		&user.Email,
@@ -971,6 +1528,7 @@ func (p *PostgresService) UpdateUser(organizationID, userID string, req models.U
 		&user.Role,
 		&user.IsActive,
 		&user.LastLoginAt,
+		&user.PreferredLocale,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -980,7 +1538,7 @@ func (p *PostgresService) UpdateUser(organizationID, userID string, req models.U
 
 	// Get the organization name
 	orgQuery := `SELECT name FROM organizations WHERE id = $1`
-	err = p.DB.QueryRow(orgQuery, organizationID).Scan(&user.OrganizationName)
+	err = p.DB.QueryRowContext(ctx, orgQuery, organizationID).Scan(&user.OrganizationName)
 	if err != nil {
 		user.OrganizationName = ""
 	}
@@ -988,9 +1546,12 @@ func (p *PostgresService) UpdateUser(organizationID, userID string, req models.U
 	return user, nil
 }
 
-func (p *PostgresService) DeleteUser(organizationID, userID string) error {
+func (p *PostgresService) DeleteUser(ctx context.Context, organizationID, userID string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM users WHERE organization_id = $1 AND id = $2`
-	result, err := p.DB.Exec(query, organizationID, userID)
+	result, err := p.DB.ExecContext(ctx, query, organizationID, userID)
 	if err != nil {
 		return err
 	}
@@ -1007,18 +1568,24 @@ func (p *PostgresService) DeleteUser(organizationID, userID string) error {
 	return nil
 }
 
-func (p *PostgresService) UpdateUserLoginTime(userID string) error {
+func (p *PostgresService) UpdateUserLoginTime(ctx context.Context, userID string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	query := `UPDATE users SET last_login_at = $1 WHERE id = $2`
-	_, err := p.DB.Exec(query, time.Now(), userID)
+	_, err := p.DB.ExecContext(ctx, query, time.Now(), userID)
 	return err
 }
 
 // Helper function to check user permissions
-func (p *PostgresService) CheckUserPermission(userID string, resource, action string) (bool, error) {
+func (p *PostgresService) CheckUserPermission(ctx context.Context, userID string, resource, action string) (bool, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	// Get user role
 	var role string
 	query := `SELECT role FROM users WHERE id = $1`
-	err := p.DB.QueryRow(query, userID).Scan(&role)
+	err := p.DB.QueryRowContext(ctx, query, userID).Scan(&role)
 	if err != nil {
 		return false, err
 	}
@@ -1034,7 +1601,15 @@ func (p *PostgresService) CheckUserPermission(userID string, resource, action st
 
 // Field Aliases Methods
 
-func (p *PostgresService) GetFieldAliases(organizationID string, params models.FieldAliasListParams) ([]*models.FieldAlias, error) {
+func (p *PostgresService) GetFieldAliases(ctx context.Context, organizationID string, params models.FieldAliasListParams) ([]*models.FieldAlias, *models.PageInfo, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	page, err := newPageQuery(fieldAliasSorts, params.Sort, params.Cursor, params.Limit, params.Offset)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var conditions []string
 	var args []interface{}
 	argIndex := 2
@@ -1054,31 +1629,34 @@ func (p *PostgresService) GetFieldAliases(organizationID string, params models.F
 		argIndex++
 	}
 
-	query := fmt.Sprintf(`
+	columns := `
 		SELECT id, organization_id, table_name, field_name, display_name, 
-		       description, is_hidden, sort_order, created_at, updated_at
+		       description, is_hidden, sort_order, created_at, updated_at` + page.selectColumns()
+	query := fmt.Sprintf(`
 		FROM field_aliases
 		WHERE %s
-		ORDER BY table_name, sort_order, field_name
 	`, strings.Join(conditions, " AND "))
 
-	if params.Limit > 0 {
-		query += fmt.Sprintf(" LIMIT %d", params.Limit)
-	}
-	if params.Offset > 0 {
-		query += fmt.Sprintf(" OFFSET %d", params.Offset)
+	total, err := countRows(ctx, p.DB, query, args)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	rows, err := p.DB.Query(query, args...)
+	keyset, args, argIndex := page.keysetSQL(args, argIndex)
+	order, args, _ := page.orderSQL(args, argIndex)
+
+	rows, err := p.DB.QueryContext(ctx, columns+query+keyset+order, args...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
 	aliases := make([]*models.FieldAlias, 0)
+	keys := make([]*listCursor, 0)
 	for rows.Next() {
 		alias := &models.FieldAlias{}
-		err := rows.Scan(
+		keyDest, key := page.scanKeys()
+		dest := []interface{}{
 			&alias.ID,
 			&alias.OrganizationID,
 			&alias.TableName,
@@ -1089,17 +1667,27 @@ func (p *PostgresService) GetFieldAliases(organizationID string, params models.F
 			&alias.SortOrder,
 			&alias.CreatedAt,
 			&alias.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
+		}
+		if err := rows.Scan(append(dest, keyDest...)...); err != nil {
+			return nil, nil, err
 		}
 		aliases = append(aliases, alias)
+		keys = append(keys, key)
+	}
+
+	aliases, info := finishPage(page, aliases, keys, total)
+
+	if err := localizeFieldAliases(ctx, p.DB, organizationID, aliases, params.Locales); err != nil {
+		return nil, nil, err
 	}
 
-	return aliases, nil
+	return aliases, info, nil
 }
 
-func (p *PostgresService) CreateFieldAlias(organizationID string, req models.CreateFieldAliasRequest) (*models.FieldAlias, error) {
+func (p *PostgresService) CreateFieldAlias(ctx context.Context, organizationID string, req models.CreateFieldAliasRequest) (*models.FieldAlias, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	alias := &models.FieldAlias{}
 
 	// Set defaults
@@ -1120,7 +1708,7 @@ func (p *PostgresService) CreateFieldAlias(organizationID string, req models.Cre
 	`
 
 	now := time.Now()
-	err := p.DB.QueryRow(
+	err := p.DB.QueryRowContext(ctx,
 		query,
 		organizationID,
 		req.TableName,
@@ -1150,7 +1738,10 @@ func (p *PostgresService) CreateFieldAlias(organizationID string, req models.Cre
 	return alias, nil
 }
 
-func (p *PostgresService) UpdateFieldAlias(organizationID, aliasID string, req models.UpdateFieldAliasRequest) (*models.FieldAlias, error) {
+func (p *PostgresService) UpdateFieldAlias(ctx context.Context, organizationID, aliasID string, req models.UpdateFieldAliasRequest) (*models.FieldAlias, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	alias := &models.FieldAlias{}
 
 	// Build dynamic query based on provided fields
@@ -1189,7 +1780,7 @@ func (p *PostgresService) UpdateFieldAlias(organizationID, aliasID string, req m
 		RETURNING id, organization_id, table_name, field_name, display_name, description, is_hidden, sort_order, created_at, updated_at
 	`, strings.Join(setParts, ", "))
 
-	err := p.DB.QueryRow(query, args...).Scan(
+	err := p.DB.QueryRowContext(ctx, query, args...).Scan(
 		&alias.ID,
 		&alias.OrganizationID,
 		&alias.TableName,
@@ -1209,9 +1800,12 @@ func (p *PostgresService) UpdateFieldAlias(organizationID, aliasID string, req m
 	return alias, nil
 }
 
-func (p *PostgresService) DeleteFieldAlias(organizationID, aliasID string) error {
+func (p *PostgresService) DeleteFieldAlias(ctx context.Context, organizationID, aliasID string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	query := `DELETE FROM field_aliases WHERE organization_id = $1 AND id = $2`
-	result, err := p.DB.Exec(query, organizationID, aliasID)
+	result, err := p.DB.ExecContext(ctx, query, organizationID, aliasID)
 	if err != nil {
 		return err
 	}
@@ -1228,12 +1822,17 @@ func (p *PostgresService) DeleteFieldAlias(organizationID, aliasID string) error
 	return nil
 }
 
-func (p *PostgresService) GetTableFields(organizationID string, tableName string) (*models.TableFieldsResponse, error) {
+// GetTableFields returns a table's fields with labels resolved through locales.
+// Aliases are counted as custom against their untranslated label.
+func (p *PostgresService) GetTableFields(ctx context.Context, organizationID string, tableName string, locales []string) (*models.TableFieldsResponse, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	// Get aliases for this table
 	params := models.FieldAliasListParams{
 		TableName: &tableName,
 	}
-	aliases, err := p.GetFieldAliases(organizationID, params)
+	aliases, _, err := p.GetFieldAliases(ctx, organizationID, params)
 	if err != nil {
 		return nil, err
 	}
@@ -1262,25 +1861,54 @@ func (p *PostgresService) GetTableFields(organizationID string, tableName string
 		}
 	}
 
+	// Organization-defined fields are listed alongside the built-in ones
+	customFields, err := p.GetCustomFields(ctx, organizationID, tableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range customFields {
+		if lastUpdated == nil || field.UpdatedAt.After(*lastUpdated) {
+			lastUpdated = &field.UpdatedAt
+		}
+	}
+
+	if err := localizeFieldAliases(ctx, p.DB, organizationID, aliases, locales); err != nil {
+		return nil, err
+	}
+	if err := localizeCustomFields(ctx, p.DB, organizationID, customFields, locales); err != nil {
+		return nil, err
+	}
+
+	var locale string
+	if len(locales) > 0 {
+		locale = locales[0]
+	}
+
 	return &models.TableFieldsResponse{
-		TableName: tableName,
-		Fields:    aliases,
+		TableName:    tableName,
+		Locale:       locale,
+		Fields:       aliases,
+		CustomFields: customFields,
 		Metadata: &models.TableFieldsMetadata{
-			TotalFields:   totalFields,
+			TotalFields:   totalFields + len(customFields),
 			HiddenFields:  hiddenFields,
 			CustomAliases: customAliases,
+			CustomFields:  len(customFields),
 			LastUpdated:   lastUpdated,
 		},
 	}, nil
 }
 
-func (p *PostgresService) InitializeDefaultFieldAliases(organizationID string, tableName string) error {
+func (p *PostgresService) InitializeDefaultFieldAliases(ctx context.Context, organizationID string, tableName string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	// Check if aliases already exist for this table
 	params := models.FieldAliasListParams{
 		TableName: &tableName,
 		Limit:     1,
 	}
-	existing, err := p.GetFieldAliases(organizationID, params)
+	existing, _, err := p.GetFieldAliases(ctx, organizationID, params)
 	if err != nil {
 		return err
 	}
@@ -1305,7 +1933,7 @@ func (p *PostgresService) InitializeDefaultFieldAliases(organizationID string, t
 			SortOrder:   &field.SortOrder,
 		}
 
-		_, err := p.CreateFieldAlias(organizationID, req)
+		_, err := p.CreateFieldAlias(ctx, organizationID, req)
 		if err != nil {
 			return fmt.Errorf("failed to create default alias for %s.%s: %w", tableName, field.FieldName, err)
 		}
@@ -1316,7 +1944,28 @@ func (p *PostgresService) InitializeDefaultFieldAliases(organizationID string, t
 
 // Change Log Methods
 
-func (p *PostgresService) CreateChangeLog(organizationID string, userID string, req models.CreateChangeLogRequest) (*models.ChangeLog, error) {
+func (p *PostgresService) CreateChangeLog(ctx context.Context, organizationID string, userID string, req models.CreateChangeLogRequest) (*models.ChangeLog, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	changeLog, err := createChangeLog(ctx, tx, organizationID, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return changeLog, nil
+}
+
+// createChangeLog lets callers write the audit entry in the same database transaction as the change
+func createChangeLog(ctx context.Context, q queryer, organizationID string, userID string, req models.CreateChangeLogRequest) (*models.ChangeLog, error) {
 	var metadataBytes []byte
 
 	if req.Metadata != nil {
@@ -1330,7 +1979,7 @@ func (p *PostgresService) CreateChangeLog(organizationID string, userID string,
 	`
 
 	changeLog := &models.ChangeLog{}
-	err := p.DB.QueryRow(
+	err := q.QueryRowContext(ctx,
 		query,
 		organizationID,
 		userID,
@@ -1364,14 +2013,29 @@ func (p *PostgresService) CreateChangeLog(organizationID string, userID string,
 		return nil, fmt.Errorf("failed to create change log: %w", err)
 	}
 
+	err = writeOutboxEvent(ctx, q, organizationID, "change_log.created", "change_log", strconv.Itoa(changeLog.ID),
+		map[string]interface{}{"change_log": changeLog})
+	if err != nil {
+		return nil, err
+	}
+
 	return changeLog, nil
 }
 
-func (p *PostgresService) GetChangeLogs(organizationID string, params models.ChangeLogListParams) ([]*models.ChangeLog, error) {
-	query := `
+func (p *PostgresService) GetChangeLogs(ctx context.Context, organizationID string, params models.ChangeLogListParams) ([]*models.ChangeLog, *models.PageInfo, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	page, err := newPageQuery(changeLogSorts, params.Sort, params.Cursor, params.Limit, params.Offset)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := `
 		SELECT cl.id, cl.organization_id, cl.user_id, cl.entity_type, cl.entity_id, cl.sku_id, 
 			   cl.change_type, cl.field_name, cl.old_value, cl.new_value, cl.reason, cl.metadata, cl.created_at,
-			   u.name as user_name, s.sku_code, s.product_name as sku_name
+			   u.name as user_name, s.sku_code, s.product_name as sku_name` + page.selectColumns()
+	query := `
 		FROM change_logs cl
 		LEFT JOIN users u ON cl.user_id = u.id
 		LEFT JOIN skus s ON cl.sku_id = s.id
@@ -1412,6 +2076,12 @@ func (p *PostgresService) GetChangeLogs(organizationID string, params models.Cha
 		argIndex++
 	}
 
+	if params.BatchID != nil {
+		query += fmt.Sprintf(" AND cl.metadata->>'batch_id' = $%d", argIndex)
+		args = append(args, *params.BatchID)
+		argIndex++
+	}
+
 	if params.LastDays != nil {
 		query += fmt.Sprintf(" AND cl.created_at >= NOW() - INTERVAL '%d days'", *params.LastDays)
 	}
@@ -1428,33 +2098,28 @@ func (p *PostgresService) GetChangeLogs(organizationID string, params models.Cha
 		argIndex++
 	}
 
-	// Order by most recent first
-	query += " ORDER BY cl.created_at DESC"
-
-	// Add pagination
-	if params.Limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d", argIndex)
-		args = append(args, params.Limit)
-		argIndex++
-
-		if params.Offset > 0 {
-			query += fmt.Sprintf(" OFFSET $%d", argIndex)
-			args = append(args, params.Offset)
-		}
+	total, err := countRows(ctx, p.DB, query, args)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to count change logs: %w", err)
 	}
 
-	rows, err := p.DB.Query(query, args...)
+	keyset, args, argIndex := page.keysetSQL(args, argIndex)
+	order, args, _ := page.orderSQL(args, argIndex)
+
+	rows, err := p.DB.QueryContext(ctx, columns+query+keyset+order, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query change logs: %w", err)
+		return nil, nil, fmt.Errorf("failed to query change logs: %w", err)
 	}
 	defer rows.Close()
 
-	var changeLogs []*models.ChangeLog
+	changeLogs := make([]*models.ChangeLog, 0)
+	keys := make([]*listCursor, 0)
 
 	for rows.Next() {
 		cl := &models.ChangeLog{}
 		var metadata sql.NullString
-		err := rows.Scan(
+		keyDest, key := page.scanKeys()
+		dest := []interface{}{
 			&cl.ID,
 			&cl.OrganizationID,
 			&cl.UserID,
@@ -1471,9 +2136,9 @@ func (p *PostgresService) GetChangeLogs(organizationID string, params models.Cha
 			&cl.UserName,
 			&cl.SkuCode,
 			&cl.SkuName,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan change log: %w", err)
+		}
+		if err := rows.Scan(append(dest, keyDest...)...); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan change log: %w", err)
 		}
 
 		// Handle nullable metadata
@@ -1482,16 +2147,21 @@ func (p *PostgresService) GetChangeLogs(organizationID string, params models.Cha
 		}
 
 		changeLogs = append(changeLogs, cl)
+		keys = append(keys, key)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating change logs: %w", err)
+		return nil, nil, fmt.Errorf("error iterating change logs: %w", err)
 	}
 
-	return changeLogs, nil
+	changeLogs, info := finishPage(page, changeLogs, keys, total)
+	return changeLogs, info, nil
 }
 
-func (p *PostgresService) GetSKUChangeLogs(organizationID string, skuID string, lastDays int) ([]*models.ChangeLog, error) {
+func (p *PostgresService) GetSKUChangeLogs(ctx context.Context, organizationID string, skuID string, lastDays int) ([]*models.ChangeLog, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
 	// For SKU change logs, we need to find logs where:
 	// 1. sku_id matches the SKU ID (for transaction/inventory logs that reference the SKU)
 	// 2. entity_id matches the SKU ID AND entity_type is "sku" (for direct SKU changes)
@@ -1509,7 +2179,7 @@ func (p *PostgresService) GetSKUChangeLogs(organizationID string, skuID string,
 		LIMIT 100
 	`
 	
-	rows, err := p.DB.Query(fmt.Sprintf(query, lastDays), organizationID, skuID)
+	rows, err := p.DB.QueryContext(ctx, fmt.Sprintf(query, lastDays), organizationID, skuID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query SKU change logs: %w", err)
 	}
@@ -1557,11 +2227,20 @@ func (p *PostgresService) GetSKUChangeLogs(organizationID string, skuID string,
 	return changeLogs, nil
 }
 
-func (p *PostgresService) GetActivitySummary(organizationID string, lastDays int) (*models.ActivitySummary, error) {
+func (p *PostgresService) GetActivitySummary(ctx context.Context, organizationID string, lastDays int) (*models.ActivitySummary, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.beginReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	// Get total changes
 	totalQuery := `SELECT COUNT(*) FROM change_logs WHERE organization_id = $1`
 	var totalChanges int
-	err := p.DB.QueryRow(totalQuery, organizationID).Scan(&totalChanges)
+	err = tx.QueryRowContext(ctx, totalQuery, organizationID).Scan(&totalChanges)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get total changes: %w", err)
 	}
@@ -1569,7 +2248,7 @@ func (p *PostgresService) GetActivitySummary(organizationID string, lastDays int
 	// Get recent changes (last 24h)
 	recentQuery := `SELECT COUNT(*) FROM change_logs WHERE organization_id = $1 AND created_at >= NOW() - INTERVAL '1 day'`
 	var recentChanges int
-	err = p.DB.QueryRow(recentQuery, organizationID).Scan(&recentChanges)
+	err = tx.QueryRowContext(ctx, recentQuery, organizationID).Scan(&recentChanges)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get recent changes: %w", err)
 	}
@@ -1581,7 +2260,7 @@ func (p *PostgresService) GetActivitySummary(organizationID string, lastDays int
 		WHERE organization_id = $1 AND created_at >= NOW() - INTERVAL '%d days'
 		GROUP BY change_type
 	`
-	typeRows, err := p.DB.Query(fmt.Sprintf(typeQuery, lastDays), organizationID)
+	typeRows, err := tx.QueryContext(ctx, fmt.Sprintf(typeQuery, lastDays), organizationID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get changes by type: %w", err)
 	}
@@ -1608,7 +2287,7 @@ func (p *PostgresService) GetActivitySummary(organizationID string, lastDays int
 		ORDER BY changes DESC
 		LIMIT 5
 	`
-	userRows, err := p.DB.Query(fmt.Sprintf(userQuery, lastDays), organizationID)
+	userRows, err := tx.QueryContext(ctx, fmt.Sprintf(userQuery, lastDays), organizationID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get top users: %w", err)
 	}
@@ -1629,7 +2308,7 @@ func (p *PostgresService) GetActivitySummary(organizationID string, lastDays int
 	}
 
 	// Get recent activity
-	recentActivity, err := p.GetChangeLogs(organizationID, models.ChangeLogListParams{
+	recentActivity, _, err := p.GetChangeLogs(ctx, organizationID, models.ChangeLogListParams{
 		LastDays: &lastDays,
 		Limit:    20,
 	})
@@ -1647,7 +2326,10 @@ func (p *PostgresService) GetActivitySummary(organizationID string, lastDays int
 }
 
 // Helper function to log changes - used by other handlers
-func (p *PostgresService) LogChange(organizationID string, userID string, req models.CreateChangeLogRequest) error {
-	_, err := p.CreateChangeLog(organizationID, userID, req)
+func (p *PostgresService) LogChange(ctx context.Context, organizationID string, userID string, req models.CreateChangeLogRequest) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	_, err := p.CreateChangeLog(ctx, organizationID, userID, req)
 	return err
 }
@@ -0,0 +1,402 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/models"
+)
+
+// purchaseOrderSelect returns purchase order headers with their line totals
+const purchaseOrderSelect = `
+	SELECT po.id, po.organization_id, po.po_number, po.supplier, po.supplier_id, po.status, po.expected_date, po.notes,
+		po.created_by, po.created_at, po.updated_at,
+		COALESCE(SUM(l.ordered_quantity), 0), COALESCE(SUM(l.received_quantity), 0),
+		COALESCE(SUM(l.ordered_quantity * l.unit_cost), 0)
+	FROM purchase_orders po
+	LEFT JOIN purchase_order_lines l ON l.purchase_order_id = po.id
+`
+
+const purchaseOrderGroupBy = " GROUP BY po.id"
+
+func scanPurchaseOrder(row rowScanner) (*models.PurchaseOrder, error) {
+	po := &models.PurchaseOrder{}
+	err := row.Scan(
+		&po.ID,
+		&po.OrganizationID,
+		&po.PONumber,
+		&po.Supplier,
+		&po.SupplierID,
+		&po.Status,
+		&po.ExpectedDate,
+		&po.Notes,
+		&po.CreatedBy,
+		&po.CreatedAt,
+		&po.UpdatedAt,
+		&po.OrderedQuantity,
+		&po.ReceivedQuantity,
+		&po.TotalCost,
+	)
+	if err != nil {
+		return nil, err
+	}
+	po.OutstandingQuantity = po.OrderedQuantity - po.ReceivedQuantity
+	return po, nil
+}
+
+// Purchase Order Methods
+
+func (p *PostgresService) GetPurchaseOrders(ctx context.Context, organizationID string, params models.PurchaseOrderListParams) ([]*models.PurchaseOrder, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := purchaseOrderSelect + " WHERE po.organization_id = $1"
+	args := []interface{}{organizationID}
+	argIndex := 2
+
+	if params.Status != nil && *params.Status != "" {
+		query += fmt.Sprintf(" AND po.status = $%d", argIndex)
+		args = append(args, *params.Status)
+		argIndex++
+	}
+
+	if params.Supplier != nil && *params.Supplier != "" {
+		query += fmt.Sprintf(" AND po.supplier = $%d", argIndex)
+		args = append(args, *params.Supplier)
+		argIndex++
+	}
+
+	if params.SupplierID != nil && *params.SupplierID != "" {
+		query += fmt.Sprintf(" AND po.supplier_id = $%d", argIndex)
+		args = append(args, *params.SupplierID)
+		argIndex++
+	}
+
+	if params.Search != nil && *params.Search != "" {
+		searchTerm := "%" + strings.ToLower(*params.Search) + "%"
+		query += fmt.Sprintf(" AND (LOWER(po.po_number) LIKE $%d OR LOWER(po.supplier) LIKE $%d)", argIndex, argIndex)
+		args = append(args, searchTerm)
+		argIndex++
+	}
+
+	query += purchaseOrderGroupBy + " ORDER BY po.created_at DESC"
+
+	// Add pagination
+	if params.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, params.Limit)
+		argIndex++
+
+		if params.Page > 0 {
+			offset := (params.Page - 1) * params.Limit
+			query += fmt.Sprintf(" OFFSET $%d", argIndex)
+			args = append(args, offset)
+		}
+	}
+
+	rows, err := p.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]*models.PurchaseOrder, 0)
+	for rows.Next() {
+		po, err := scanPurchaseOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, po)
+	}
+
+	return orders, rows.Err()
+}
+
+func (p *PostgresService) GetPurchaseOrderByID(ctx context.Context, organizationID, id string) (*models.PurchaseOrder, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return getPurchaseOrder(ctx, p.DB, organizationID, id)
+}
+
+func getPurchaseOrder(ctx context.Context, q queryer, organizationID, id string) (*models.PurchaseOrder, error) {
+	query := purchaseOrderSelect + " WHERE po.organization_id = $1 AND po.id = $2" + purchaseOrderGroupBy
+	po, err := scanPurchaseOrder(q.QueryRowContext(ctx, query, organizationID, id))
+	if err != nil {
+		return nil, err
+	}
+
+	po.Lines, err = getPurchaseOrderLines(ctx, q, po.ID)
+	if err != nil {
+		return nil, err
+	}
+	return po, nil
+}
+
+func getPurchaseOrderLines(ctx context.Context, q queryer, purchaseOrderID string) ([]*models.PurchaseOrderLine, error) {
+	query := `
+		SELECT l.id, l.purchase_order_id, l.sku_id, l.ordered_quantity, l.received_quantity, l.unit_cost,
+			l.created_at, l.updated_at, s.sku_code, s.product_name
+		FROM purchase_order_lines l
+		JOIN skus s ON l.sku_id = s.id
+		WHERE l.purchase_order_id = $1
+		ORDER BY l.created_at, s.sku_code
+	`
+	rows, err := q.QueryContext(ctx, query, purchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := make([]*models.PurchaseOrderLine, 0)
+	for rows.Next() {
+		line := &models.PurchaseOrderLine{}
+		err := rows.Scan(
+			&line.ID,
+			&line.PurchaseOrderID,
+			&line.SKUID,
+			&line.OrderedQuantity,
+			&line.ReceivedQuantity,
+			&line.UnitCost,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+			&line.SKUCode,
+			&line.ProductName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		line.OutstandingQuantity = line.OrderedQuantity - line.ReceivedQuantity
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+func (p *PostgresService) CreatePurchaseOrder(ctx context.Context, organizationID, userID string, req models.CreatePurchaseOrderRequest) (*models.PurchaseOrder, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Link the order to a supplier record, either the one given or one matching the name
+	var supplier *models.Supplier
+	if req.SupplierID != nil && *req.SupplierID != "" {
+		supplier, err = getSupplier(ctx, tx, organizationID, *req.SupplierID)
+	} else {
+		supplier, err = findSupplierByName(ctx, tx, organizationID, req.Supplier)
+		if err == sql.ErrNoRows {
+			supplier, err = nil, nil
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	supplierName := req.Supplier
+	expectedDate := req.ExpectedDate
+	var supplierID *string
+	if supplier != nil {
+		supplierID = &supplier.ID
+		if supplierName == "" {
+			supplierName = supplier.Name
+		}
+		if expectedDate == nil && supplier.LeadTimeDays != nil {
+			expected := now.AddDate(0, 0, *supplier.LeadTimeDays)
+			expectedDate = &expected
+		}
+	}
+
+	var purchaseOrderID string
+	query := `
+		INSERT INTO purchase_orders (organization_id, po_number, supplier, supplier_id, status, expected_date, notes, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 'draft', $5, $6, $7, $8, $8)
+		RETURNING id
+	`
+	err = tx.QueryRowContext(ctx, query, organizationID, req.PONumber, supplierName, supplierID, expectedDate, req.Notes, userID, now).Scan(&purchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range req.Lines {
+		var skuExists bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM skus WHERE organization_id = $1 AND id = $2)`, organizationID, line.SKUID).Scan(&skuExists)
+		if err != nil {
+			return nil, err
+		}
+		if !skuExists {
+			return nil, fmt.Errorf("SKU not found: %s", line.SKUID)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO purchase_order_lines (purchase_order_id, sku_id, ordered_quantity, unit_cost, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $5)
+		`, purchaseOrderID, line.SKUID, line.OrderedQuantity, line.UnitCost, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	po, err := getPurchaseOrder(ctx, tx, organizationID, purchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return po, nil
+}
+
+func (p *PostgresService) UpdatePurchaseOrderStatus(ctx context.Context, organizationID, id, status string) (*models.PurchaseOrder, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var currentStatus string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM purchase_orders WHERE organization_id = $1 AND id = $2 FOR UPDATE`, organizationID, id).Scan(&currentStatus)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("purchase order not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !models.CanTransitionPurchaseOrder(currentStatus, status) {
+		return nil, fmt.Errorf("invalid status transition: %s to %s", currentStatus, status)
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE purchase_orders SET status = $3, updated_at = $4 WHERE organization_id = $1 AND id = $2`, organizationID, id, status, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	po, err := getPurchaseOrder(ctx, tx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return po, nil
+}
+
+// ReceivePurchaseOrder posts an IN transaction for every received line at the line's agreed
+// unit cost and moves the order to partially_received or received. Quantities beyond what
+// is outstanding on a line are rejected and nothing is posted.
+func (p *PostgresService) ReceivePurchaseOrder(ctx context.Context, organizationID, userID, id string, req models.ReceivePurchaseOrderRequest) (*models.ReceivePurchaseOrderResponse, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var poNumber, status string
+	var supplierID *string
+	err = tx.QueryRowContext(ctx, `SELECT po_number, status, supplier_id FROM purchase_orders WHERE organization_id = $1 AND id = $2 FOR UPDATE`, organizationID, id).Scan(&poNumber, &status, &supplierID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("purchase order not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if status != "sent" && status != "partially_received" {
+		return nil, fmt.Errorf("purchase order cannot be received in status %s", status)
+	}
+
+	transactions := make([]*models.Transaction, 0, len(req.Lines))
+	now := time.Now()
+	for _, received := range req.Lines {
+		var skuID string
+		var orderedQuantity, receivedQuantity int
+		var unitCost float64
+		err := tx.QueryRowContext(ctx, `
+			SELECT sku_id, ordered_quantity, received_quantity, unit_cost
+			FROM purchase_order_lines
+			WHERE purchase_order_id = $1 AND id = $2
+			FOR UPDATE
+		`, id, received.LineID).Scan(&skuID, &orderedQuantity, &receivedQuantity, &unitCost)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("purchase order line not found: %s", received.LineID)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if outstanding := orderedQuantity - receivedQuantity; received.Quantity > outstanding {
+			return nil, fmt.Errorf("over-receipt: line %s has %d outstanding, received %d", received.LineID, outstanding, received.Quantity)
+		}
+
+		transaction, err := createTransactionTx(ctx, tx, organizationID, userID, models.CreateTransactionRequest{
+			SKUID:           skuID,
+			TransactionType: "in",
+			Quantity:        received.Quantity,
+			UnitCost:        unitCost,
+			ReferenceNumber: &poNumber,
+			Notes:           req.Notes,
+		})
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+
+		if supplierID != nil {
+			if err := recordPurchasePrice(ctx, tx, skuID, *supplierID, unitCost); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE purchase_order_lines SET received_quantity = received_quantity + $3, updated_at = $4
+			WHERE purchase_order_id = $1 AND id = $2
+		`, id, received.LineID, received.Quantity, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The order is received once nothing is outstanding on any line
+	_, err = tx.ExecContext(ctx, `
+		UPDATE purchase_orders
+		SET status = CASE
+				WHEN EXISTS (SELECT 1 FROM purchase_order_lines WHERE purchase_order_id = $2 AND received_quantity < ordered_quantity)
+				THEN 'partially_received' ELSE 'received' END,
+			updated_at = $3
+		WHERE organization_id = $1 AND id = $2
+	`, organizationID, id, now)
+	if err != nil {
+		return nil, err
+	}
+
+	po, err := getPurchaseOrder(ctx, tx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &models.ReceivePurchaseOrderResponse{
+		PurchaseOrder: po,
+		Transactions:  transactions,
+	}, nil
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+
+	"flex-erp-poc/internal/models"
+)
+
+// Repositories of the core aggregates. PostgresService implements them for the
+// server and MemoryStore for tests; handlers of these aggregates depend only on
+// the interfaces.
+
+type SKURepository interface {
+	GetSKUs(ctx context.Context, organizationID string, params models.SKUListParams) ([]*models.SKU, *models.PageInfo, error)
+	GetSKUByID(ctx context.Context, organizationID, id string) (*models.SKU, error) // sql.ErrNoRows when missing
+	GetSKUsByID(ctx context.Context, organizationID string, ids []string) (map[string]*models.SKU, error)
+	CreateSKU(ctx context.Context, organizationID string, req models.CreateSKURequest) (*models.SKU, error)
+	UpdateSKU(ctx context.Context, organizationID, id string, req models.UpdateSKURequest) (*models.SKU, error)
+	UpdateSKUStatus(ctx context.Context, organizationID, id string, isActive bool) (*models.SKU, error)
+	BulkUpdateSKUs(ctx context.Context, organizationID, userID string, req models.BulkSKURequest, filter *models.SKUListParams) (*models.BulkSKUResponse, error)
+}
+
+type InventoryRepository interface {
+	GetInventoryWithSKUs(ctx context.Context, organizationID string, params models.InventoryListParams) ([]*models.InventoryWithSKU, *models.PageInfo, error)
+	GetParentInventory(ctx context.Context, organizationID string, params models.InventoryListParams) ([]*models.ParentInventory, error)
+	GetInventoryBySKUID(ctx context.Context, organizationID, skuID string) (*models.Inventory, error) // sql.ErrNoRows when missing
+	CreateInventoryForSKU(ctx context.Context, organizationID string, req models.CreateInventoryRequest) (*models.Inventory, error)
+	UpdateManualCost(ctx context.Context, organizationID, skuID string, req models.UpdateManualCostRequest) (*models.Inventory, error)
+	UpdateInventoryCustomFields(ctx context.Context, organizationID, skuID string, values models.CustomFieldValues) (*models.Inventory, error)
+}
+
+type TransactionRepository interface {
+	GetTransactionsWithDetails(ctx context.Context, organizationID string, params models.TransactionListParams) ([]*models.TransactionWithSKU, *models.PageInfo, error)
+	CreateTransaction(ctx context.Context, organizationID, userID string, req models.CreateTransactionRequest) (*models.Transaction, error)
+	GetTransactionSummary(ctx context.Context, organizationID string, params models.TransactionListParams) ([]*models.TransactionSummary, error)
+}
+
+type UserRepository interface {
+	GetUserByEmail(ctx context.Context, email string) (*User, error)
+	GetUserByID(ctx context.Context, id string) (*User, error)
+	GetOrganizationByID(ctx context.Context, id string) (*Organization, error)
+	GetDefaultOrganizationID(ctx context.Context) (string, error)
+	GetUsersWithDetails(ctx context.Context, organizationID string, params models.UserListParams) ([]*models.UserWithDetails, *models.PageInfo, error)
+	CreateUser(ctx context.Context, organizationID string, req models.CreateUserRequest) (*models.UserWithDetails, error)
+	UpdateUser(ctx context.Context, organizationID, userID string, req models.UpdateUserRequest) (*models.UserWithDetails, error)
+	DeleteUser(ctx context.Context, organizationID, userID string) error
+	CheckUserPermission(ctx context.Context, userID string, resource, action string) (bool, error)
+}
+
+type FieldAliasRepository interface {
+	GetFieldAliases(ctx context.Context, organizationID string, params models.FieldAliasListParams) ([]*models.FieldAlias, *models.PageInfo, error)
+	CreateFieldAlias(ctx context.Context, organizationID string, req models.CreateFieldAliasRequest) (*models.FieldAlias, error)
+	UpdateFieldAlias(ctx context.Context, organizationID, aliasID string, req models.UpdateFieldAliasRequest) (*models.FieldAlias, error)
+	DeleteFieldAlias(ctx context.Context, organizationID, aliasID string) error
+	GetTableFields(ctx context.Context, organizationID string, tableName string, locales []string) (*models.TableFieldsResponse, error)
+	InitializeDefaultFieldAliases(ctx context.Context, organizationID string, tableName string) error
+}
+
+type LocaleRepository interface {
+	GetOrganizationDefaultLocale(ctx context.Context, organizationID string) (string, error)
+	GetUserPreferredLocale(ctx context.Context, userID string) (*string, error) // nil when the user has none
+}
+
+type ChangeLogRepository interface {
+	CreateChangeLog(ctx context.Context, organizationID string, userID string, req models.CreateChangeLogRequest) (*models.ChangeLog, error)
+	LogChange(ctx context.Context, organizationID string, userID string, req models.CreateChangeLogRequest) error
+	GetChangeLogs(ctx context.Context, organizationID string, params models.ChangeLogListParams) ([]*models.ChangeLog, *models.PageInfo, error)
+	GetSKUChangeLogs(ctx context.Context, organizationID string, skuID string, lastDays int) ([]*models.ChangeLog, error)
+	GetActivitySummary(ctx context.Context, organizationID string, lastDays int) (*models.ActivitySummary, error)
+}
+
+// Store is every repository together
+type Store interface {
+	SKURepository
+	InventoryRepository
+	TransactionRepository
+	UserRepository
+	FieldAliasRepository
+	LocaleRepository
+	ChangeLogRepository
+}
+
+var (
+	_ Store = (*PostgresService)(nil)
+	_ Store = (*MemoryStore)(nil)
+)
@@ -0,0 +1,270 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"flex-erp-poc/internal/models"
+)
+
+// reservedQuantitySQL sums the unconsumed part of the active, unexpired reservations
+// for the inventory row aliased as "i"
+const reservedQuantitySQL = `COALESCE((
+				SELECT SUM(r.quantity - r.consumed_quantity)
+				FROM stock_reservations r
+				WHERE r.organization_id = i.organization_id AND r.sku_id = i.sku_id
+				  AND r.status = 'active' AND (r.expires_at IS NULL OR r.expires_at > now())
+			), 0) AS reserved_quantity`
+
+const reservationColumns = `id, organization_id, sku_id, quantity, consumed_quantity, reference_number, status, expires_at, notes, created_by, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanReservation(row rowScanner) (*models.Reservation, error) {
+	reservation := &models.Reservation{}
+	err := row.Scan(
+		&reservation.ID,
+		&reservation.OrganizationID,
+		&reservation.SKUID,
+		&reservation.Quantity,
+		&reservation.ConsumedQuantity,
+		&reservation.ReferenceNumber,
+		&reservation.Status,
+		&reservation.ExpiresAt,
+		&reservation.Notes,
+		&reservation.CreatedBy,
+		&reservation.CreatedAt,
+		&reservation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	reservation.RemainingQuantity = reservation.Quantity - reservation.ConsumedQuantity
+	return reservation, nil
+}
+
+// Reservation Methods
+
+func (p *PostgresService) GetReservations(ctx context.Context, organizationID string, params models.ReservationListParams) ([]*models.Reservation, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + reservationColumns + ` FROM stock_reservations WHERE organization_id = $1`
+	args := []interface{}{organizationID}
+	argIndex := 2
+
+	if params.SKUID != nil && *params.SKUID != "" {
+		query += fmt.Sprintf(" AND sku_id = $%d", argIndex)
+		args = append(args, *params.SKUID)
+		argIndex++
+	}
+
+	if params.Status != nil && *params.Status != "" {
+		query += fmt.Sprintf(" AND status = $%d", argIndex)
+		args = append(args, *params.Status)
+		argIndex++
+	}
+
+	if params.ReferenceNumber != nil && *params.ReferenceNumber != "" {
+		query += fmt.Sprintf(" AND reference_number = $%d", argIndex)
+		args = append(args, *params.ReferenceNumber)
+		argIndex++
+	}
+
+	query += " ORDER BY created_at DESC"
+
+	// Add pagination
+	if params.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, params.Limit)
+		argIndex++
+
+		if params.Page > 0 {
+			offset := (params.Page - 1) * params.Limit
+			query += fmt.Sprintf(" OFFSET $%d", argIndex)
+			args = append(args, offset)
+		}
+	}
+
+	rows, err := p.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservations := make([]*models.Reservation, 0)
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	return reservations, rows.Err()
+}
+
+func (p *PostgresService) GetReservationByID(ctx context.Context, organizationID, id string) (*models.Reservation, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + reservationColumns + ` FROM stock_reservations WHERE organization_id = $1 AND id = $2`
+	return scanReservation(p.DB.QueryRowContext(ctx, query, organizationID, id))
+}
+
+func (p *PostgresService) CreateReservation(ctx context.Context, organizationID, userID string, req models.CreateReservationRequest) (*models.Reservation, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	reservation, err := createReservationTx(ctx, tx, organizationID, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return reservation, nil
+}
+
+// createReservationTx holds stock for req inside tx, rejecting it when the SKU
+// doesn't have enough available (on-hand minus reserved) quantity
+func createReservationTx(ctx context.Context, tx *sql.Tx, organizationID, userID string, req models.CreateReservationRequest) (*models.Reservation, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("reservation expiry must be in the future")
+	}
+
+	// Lock the inventory row so two reservations can't both take the last units
+	inventory, err := getInventoryForUpdate(ctx, tx, organizationID, req.SKUID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("insufficient inventory: available 0, requested %d", req.Quantity)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reserved, err := reservedQuantity(ctx, tx, organizationID, req.SKUID)
+	if err != nil {
+		return nil, err
+	}
+	if available := inventory.Quantity - reserved; available < req.Quantity {
+		return nil, fmt.Errorf("insufficient inventory: available %d, requested %d", available, req.Quantity)
+	}
+
+	query := `
+		INSERT INTO stock_reservations (organization_id, sku_id, quantity, reference_number, status, expires_at, notes, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, 'active', $5, $6, $7, $8, $8)
+		RETURNING ` + reservationColumns
+	return scanReservation(tx.QueryRowContext(ctx,
+		query,
+		organizationID,
+		req.SKUID,
+		req.Quantity,
+		req.ReferenceNumber,
+		req.ExpiresAt,
+		req.Notes,
+		userID,
+		time.Now(),
+	))
+}
+
+func (p *PostgresService) ReleaseReservation(ctx context.Context, organizationID, id string) (*models.Reservation, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE stock_reservations
+		SET status = 'released', updated_at = $3
+		WHERE organization_id = $1 AND id = $2 AND status = 'active'
+		RETURNING ` + reservationColumns
+	reservation, err := scanReservation(p.DB.QueryRowContext(ctx, query, organizationID, id, time.Now()))
+	if err == sql.ErrNoRows {
+		if _, getErr := p.GetReservationByID(ctx, organizationID, id); getErr == sql.ErrNoRows {
+			return nil, fmt.Errorf("reservation not found")
+		}
+		return nil, fmt.Errorf("reservation is not active")
+	}
+	return reservation, err
+}
+
+// ReleaseExpiredReservations marks active reservations past their expiry as expired
+// and returns how many were released
+func (p *PostgresService) ReleaseExpiredReservations(ctx context.Context) (int64, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		UPDATE stock_reservations
+		SET status = 'expired', updated_at = now()
+		WHERE status = 'active' AND expires_at IS NOT NULL AND expires_at <= now()
+	`
+	result, err := p.DB.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// consumeReservationTx applies up to quantity units of an OUT transaction to a reservation
+// and returns how many units were taken from it
+func consumeReservationTx(ctx context.Context, tx *sql.Tx, organizationID, skuID, reservationID string, quantity int) (int, error) {
+	query := `SELECT ` + reservationColumns + ` FROM stock_reservations WHERE organization_id = $1 AND id = $2 FOR UPDATE`
+	reservation, err := scanReservation(tx.QueryRowContext(ctx, query, organizationID, reservationID))
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("reservation not found")
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if reservation.SKUID != skuID {
+		return 0, fmt.Errorf("reservation is for a different SKU")
+	}
+	if reservation.Status != "active" || (reservation.ExpiresAt != nil && !reservation.ExpiresAt.After(time.Now())) {
+		return 0, fmt.Errorf("reservation is not active")
+	}
+
+	consumed := reservation.RemainingQuantity
+	if quantity < consumed {
+		consumed = quantity
+	}
+
+	status := "active"
+	if reservation.ConsumedQuantity+consumed >= reservation.Quantity {
+		status = "consumed"
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE stock_reservations
+		SET consumed_quantity = consumed_quantity + $3, status = $4, updated_at = $5
+		WHERE organization_id = $1 AND id = $2
+	`, organizationID, reservationID, consumed, status, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return consumed, nil
+}
+
+// reservedQuantity returns the quantity of a SKU held by active, unexpired reservations
+func reservedQuantity(ctx context.Context, q queryer, organizationID, skuID string) (int, error) {
+	var reserved int
+	query := `
+		SELECT COALESCE(SUM(quantity - consumed_quantity), 0)
+		FROM stock_reservations
+		WHERE organization_id = $1 AND sku_id = $2
+		  AND status = 'active' AND (expires_at IS NULL OR expires_at > now())
+	`
+	err := q.QueryRowContext(ctx, query, organizationID, skuID).Scan(&reserved)
+	return reserved, err
+}
@@ -0,0 +1,529 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/models"
+)
+
+// salesOrderSelect returns sales order headers with their line totals
+const salesOrderSelect = `
+	SELECT so.id, so.organization_id, so.order_number, so.customer, so.status, so.requested_date, so.notes,
+		so.created_by, so.created_at, so.updated_at,
+		COALESCE(SUM(l.ordered_quantity), 0), COALESCE(SUM(l.shipped_quantity), 0),
+		COALESCE(SUM(l.ordered_quantity * l.unit_price), 0)
+	FROM sales_orders so
+	LEFT JOIN sales_order_lines l ON l.sales_order_id = so.id
+`
+
+const salesOrderGroupBy = " GROUP BY so.id"
+
+func scanSalesOrder(row rowScanner) (*models.SalesOrder, error) {
+	so := &models.SalesOrder{}
+	err := row.Scan(
+		&so.ID,
+		&so.OrganizationID,
+		&so.OrderNumber,
+		&so.Customer,
+		&so.Status,
+		&so.RequestedDate,
+		&so.Notes,
+		&so.CreatedBy,
+		&so.CreatedAt,
+		&so.UpdatedAt,
+		&so.OrderedQuantity,
+		&so.ShippedQuantity,
+		&so.TotalPrice,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return so, nil
+}
+
+// Sales Order Methods
+
+func (p *PostgresService) GetSalesOrders(ctx context.Context, organizationID string, params models.SalesOrderListParams) ([]*models.SalesOrder, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := salesOrderSelect + " WHERE so.organization_id = $1"
+	args := []interface{}{organizationID}
+	argIndex := 2
+
+	if params.Status != nil && *params.Status != "" {
+		query += fmt.Sprintf(" AND so.status = $%d", argIndex)
+		args = append(args, *params.Status)
+		argIndex++
+	}
+
+	if params.Customer != nil && *params.Customer != "" {
+		query += fmt.Sprintf(" AND so.customer = $%d", argIndex)
+		args = append(args, *params.Customer)
+		argIndex++
+	}
+
+	if params.Search != nil && *params.Search != "" {
+		searchTerm := "%" + strings.ToLower(*params.Search) + "%"
+		query += fmt.Sprintf(" AND (LOWER(so.order_number) LIKE $%d OR LOWER(so.customer) LIKE $%d)", argIndex, argIndex)
+		args = append(args, searchTerm)
+		argIndex++
+	}
+
+	query += salesOrderGroupBy + " ORDER BY so.created_at DESC"
+
+	// Add pagination
+	if params.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, params.Limit)
+		argIndex++
+
+		if params.Page > 0 {
+			offset := (params.Page - 1) * params.Limit
+			query += fmt.Sprintf(" OFFSET $%d", argIndex)
+			args = append(args, offset)
+		}
+	}
+
+	rows, err := p.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := make([]*models.SalesOrder, 0)
+	for rows.Next() {
+		so, err := scanSalesOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, so)
+	}
+
+	return orders, rows.Err()
+}
+
+func (p *PostgresService) GetSalesOrderByID(ctx context.Context, organizationID, id string) (*models.SalesOrder, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return getSalesOrder(ctx, p.DB, organizationID, id)
+}
+
+func getSalesOrder(ctx context.Context, q queryer, organizationID, id string) (*models.SalesOrder, error) {
+	query := salesOrderSelect + " WHERE so.organization_id = $1 AND so.id = $2" + salesOrderGroupBy
+	so, err := scanSalesOrder(q.QueryRowContext(ctx, query, organizationID, id))
+	if err != nil {
+		return nil, err
+	}
+
+	so.Lines, err = getSalesOrderLines(ctx, q, so.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Open quantity that isn't covered by a reservation is backordered once the order is confirmed
+	if so.Status == "confirmed" || so.Status == "partially_shipped" {
+		for _, line := range so.Lines {
+			if backordered := line.OrderedQuantity - line.ShippedQuantity - line.ReservedQuantity; backordered > 0 {
+				line.BackorderedQuantity = backordered
+			}
+		}
+	}
+	return so, nil
+}
+
+func getSalesOrderLines(ctx context.Context, q queryer, salesOrderID string) ([]*models.SalesOrderLine, error) {
+	query := `
+		SELECT l.id, l.sales_order_id, l.sku_id, l.ordered_quantity, l.shipped_quantity, l.unit_price,
+			l.requested_date, l.reservation_id, l.created_at, l.updated_at,
+			COALESCE(r.quantity - r.consumed_quantity, 0),
+			s.sku_code, s.product_name
+		FROM sales_order_lines l
+		JOIN skus s ON l.sku_id = s.id
+		LEFT JOIN stock_reservations r ON r.id = l.reservation_id
+			AND r.status = 'active' AND (r.expires_at IS NULL OR r.expires_at > now())
+		WHERE l.sales_order_id = $1
+		ORDER BY l.created_at, s.sku_code
+	`
+	rows, err := q.QueryContext(ctx, query, salesOrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := make([]*models.SalesOrderLine, 0)
+	for rows.Next() {
+		line := &models.SalesOrderLine{}
+		err := rows.Scan(
+			&line.ID,
+			&line.SalesOrderID,
+			&line.SKUID,
+			&line.OrderedQuantity,
+			&line.ShippedQuantity,
+			&line.UnitPrice,
+			&line.RequestedDate,
+			&line.ReservationID,
+			&line.CreatedAt,
+			&line.UpdatedAt,
+			&line.ReservedQuantity,
+			&line.SKUCode,
+			&line.ProductName,
+		)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, rows.Err()
+}
+
+func (p *PostgresService) CreateSalesOrder(ctx context.Context, organizationID, userID string, req models.CreateSalesOrderRequest) (*models.SalesOrder, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var salesOrderID string
+	now := time.Now()
+	query := `
+		INSERT INTO sales_orders (organization_id, order_number, customer, status, requested_date, notes, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, 'draft', $4, $5, $6, $7, $7)
+		RETURNING id
+	`
+	err = tx.QueryRowContext(ctx, query, organizationID, req.OrderNumber, req.Customer, req.RequestedDate, req.Notes, userID, now).Scan(&salesOrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, line := range req.Lines {
+		var skuExists bool
+		err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM skus WHERE organization_id = $1 AND id = $2)`, organizationID, line.SKUID).Scan(&skuExists)
+		if err != nil {
+			return nil, err
+		}
+		if !skuExists {
+			return nil, fmt.Errorf("SKU not found: %s", line.SKUID)
+		}
+
+		requestedDate := line.RequestedDate
+		if requestedDate == nil {
+			requestedDate = req.RequestedDate
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO sales_order_lines (sales_order_id, sku_id, ordered_quantity, unit_price, requested_date, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $6)
+		`, salesOrderID, line.SKUID, line.OrderedQuantity, line.UnitPrice, requestedDate, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logReq := models.NewChangeLog(organizationID, userID, "sales_order", "create")
+	logReq.EntityID = &salesOrderID
+	reason := fmt.Sprintf("Sales order %s created for %s", req.OrderNumber, req.Customer)
+	logReq.Reason = &reason
+	if _, err := createChangeLog(ctx, tx, organizationID, userID, *logReq); err != nil {
+		return nil, err
+	}
+
+	return commitSalesOrder(ctx, tx, organizationID, salesOrderID)
+}
+
+// ConfirmSalesOrder reserves stock for every line. Lines that can't be fully reserved
+// keep the shortfall as a backorder until AllocateSalesOrder finds more stock.
+func (p *PostgresService) ConfirmSalesOrder(ctx context.Context, organizationID, userID, id string) (*models.SalesOrder, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	orderNumber, status, err := lockSalesOrder(ctx, tx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+	if status != "draft" {
+		return nil, fmt.Errorf("sales order cannot be confirmed in status %s", status)
+	}
+
+	if err := allocateSalesOrderTx(ctx, tx, organizationID, userID, id, orderNumber); err != nil {
+		return nil, err
+	}
+
+	if err := setSalesOrderStatusTx(ctx, tx, organizationID, userID, id, status, "confirmed"); err != nil {
+		return nil, err
+	}
+
+	return commitSalesOrder(ctx, tx, organizationID, id)
+}
+
+// AllocateSalesOrder tries to reserve stock for backordered quantities on an open order
+func (p *PostgresService) AllocateSalesOrder(ctx context.Context, organizationID, userID, id string) (*models.SalesOrder, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	orderNumber, status, err := lockSalesOrder(ctx, tx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+	if status != "confirmed" && status != "partially_shipped" {
+		return nil, fmt.Errorf("sales order cannot be allocated in status %s", status)
+	}
+
+	if err := allocateSalesOrderTx(ctx, tx, organizationID, userID, id, orderNumber); err != nil {
+		return nil, err
+	}
+
+	return commitSalesOrder(ctx, tx, organizationID, id)
+}
+
+// ShipSalesOrder posts an OUT transaction per shipped line, consuming the line's reservation
+// first. Shipping less than ordered leaves the order partially shipped.
+func (p *PostgresService) ShipSalesOrder(ctx context.Context, organizationID, userID, id string, req models.ShipSalesOrderRequest) (*models.ShipSalesOrderResponse, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	orderNumber, status, err := lockSalesOrder(ctx, tx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+	if status != "confirmed" && status != "partially_shipped" {
+		return nil, fmt.Errorf("sales order cannot be shipped in status %s", status)
+	}
+
+	transactions := make([]*models.Transaction, 0, len(req.Lines))
+	now := time.Now()
+	for _, shipped := range req.Lines {
+		var skuID string
+		var orderedQuantity, shippedQuantity int
+		var reservationID *string
+		err := tx.QueryRowContext(ctx, `
+			SELECT l.sku_id, l.ordered_quantity, l.shipped_quantity, r.id
+			FROM sales_order_lines l
+			LEFT JOIN stock_reservations r ON r.id = l.reservation_id
+				AND r.status = 'active' AND (r.expires_at IS NULL OR r.expires_at > now())
+			WHERE l.sales_order_id = $1 AND l.id = $2
+			FOR UPDATE OF l
+		`, id, shipped.LineID).Scan(&skuID, &orderedQuantity, &shippedQuantity, &reservationID)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("sales order line not found: %s", shipped.LineID)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if open := orderedQuantity - shippedQuantity; shipped.Quantity > open {
+			return nil, fmt.Errorf("over-shipment: line %s has %d open, shipped %d", shipped.LineID, open, shipped.Quantity)
+		}
+
+		// OUT transactions are valued at the current weighted cost
+		var unitCost float64
+		err = tx.QueryRowContext(ctx, `SELECT weighted_cost FROM inventory WHERE organization_id = $1 AND sku_id = $2`, organizationID, skuID).Scan(&unitCost)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+
+		transaction, err := createTransactionTx(ctx, tx, organizationID, userID, models.CreateTransactionRequest{
+			SKUID:           skuID,
+			TransactionType: "out",
+			Quantity:        shipped.Quantity,
+			UnitCost:        unitCost,
+			ReferenceNumber: &orderNumber,
+			Notes:           req.Notes,
+			ReservationID:   reservationID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE sales_order_lines SET shipped_quantity = shipped_quantity + $3, updated_at = $4
+			WHERE sales_order_id = $1 AND id = $2
+		`, id, shipped.LineID, shipped.Quantity, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var hasOpenLines bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM sales_order_lines WHERE sales_order_id = $1 AND shipped_quantity < ordered_quantity)`, id).Scan(&hasOpenLines)
+	if err != nil {
+		return nil, err
+	}
+
+	newStatus := "shipped"
+	if hasOpenLines {
+		newStatus = "partially_shipped"
+	}
+	if newStatus != status {
+		if err := setSalesOrderStatusTx(ctx, tx, organizationID, userID, id, status, newStatus); err != nil {
+			return nil, err
+		}
+	}
+
+	so, err := commitSalesOrder(ctx, tx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ShipSalesOrderResponse{
+		SalesOrder:   so,
+		Transactions: transactions,
+	}, nil
+}
+
+// CancelSalesOrder releases the order's remaining reservations. Already shipped quantities stay shipped.
+func (p *PostgresService) CancelSalesOrder(ctx context.Context, organizationID, userID, id string) (*models.SalesOrder, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, status, err := lockSalesOrder(ctx, tx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+	if status == "shipped" || status == "cancelled" {
+		return nil, fmt.Errorf("sales order cannot be cancelled in status %s", status)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE stock_reservations SET status = 'released', updated_at = $3
+		WHERE organization_id = $1 AND status = 'active'
+		  AND id IN (SELECT reservation_id FROM sales_order_lines WHERE sales_order_id = $2)
+	`, organizationID, id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setSalesOrderStatusTx(ctx, tx, organizationID, userID, id, status, "cancelled"); err != nil {
+		return nil, err
+	}
+
+	return commitSalesOrder(ctx, tx, organizationID, id)
+}
+
+func lockSalesOrder(ctx context.Context, tx *sql.Tx, organizationID, id string) (orderNumber, status string, err error) {
+	err = tx.QueryRowContext(ctx, `SELECT order_number, status FROM sales_orders WHERE organization_id = $1 AND id = $2 FOR UPDATE`, organizationID, id).Scan(&orderNumber, &status)
+	if err == sql.ErrNoRows {
+		return "", "", fmt.Errorf("sales order not found")
+	}
+	return orderNumber, status, err
+}
+
+func commitSalesOrder(ctx context.Context, tx *sql.Tx, organizationID, id string) (*models.SalesOrder, error) {
+	so, err := getSalesOrder(ctx, tx, organizationID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return so, nil
+}
+
+// setSalesOrderStatusTx changes the order status and records the transition in change_logs
+func setSalesOrderStatusTx(ctx context.Context, tx *sql.Tx, organizationID, userID, id, oldStatus, newStatus string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE sales_orders SET status = $3, updated_at = $4 WHERE organization_id = $1 AND id = $2`, organizationID, id, newStatus, time.Now())
+	if err != nil {
+		return err
+	}
+
+	logReq := models.NewSalesOrderStatusChangeLog(organizationID, userID, id, oldStatus, newStatus)
+	_, err = createChangeLog(ctx, tx, organizationID, userID, *logReq)
+	return err
+}
+
+// allocateSalesOrderTx tops up the reservation of every line with open quantity. A line's
+// reservation is replaced rather than stacked so each line holds at most one reservation.
+func allocateSalesOrderTx(ctx context.Context, tx *sql.Tx, organizationID, userID, id, orderNumber string) error {
+	lines, err := getSalesOrderLines(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		open := line.OrderedQuantity - line.ShippedQuantity
+		if line.ReservedQuantity >= open {
+			continue
+		}
+
+		inventory, err := getInventoryForUpdate(ctx, tx, organizationID, line.SKUID)
+		if err == sql.ErrNoRows {
+			continue // Nothing on hand, the whole line stays backordered
+		}
+		if err != nil {
+			return err
+		}
+
+		reserved, err := reservedQuantity(ctx, tx, organizationID, line.SKUID)
+		if err != nil {
+			return err
+		}
+
+		// The line's own reservation is released below, so it counts as available here
+		quantity := open
+		if available := inventory.Quantity - reserved + line.ReservedQuantity; available < quantity {
+			quantity = available
+		}
+		if quantity <= line.ReservedQuantity {
+			continue
+		}
+
+		if line.ReservationID != nil && line.ReservedQuantity > 0 {
+			_, err := tx.ExecContext(ctx, `UPDATE stock_reservations SET status = 'released', updated_at = $3 WHERE organization_id = $1 AND id = $2`,
+				organizationID, *line.ReservationID, time.Now())
+			if err != nil {
+				return err
+			}
+		}
+
+		reservation, err := createReservationTx(ctx, tx, organizationID, userID, models.CreateReservationRequest{
+			SKUID:           line.SKUID,
+			Quantity:        quantity,
+			ReferenceNumber: &orderNumber,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `UPDATE sales_order_lines SET reservation_id = $2, updated_at = $3 WHERE id = $1`, line.ID, reservation.ID, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,388 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// savedViewSorts are the sort allow-lists of the lists a view can be saved for
+var savedViewSorts = map[string]listSort{
+	"skus":         skuSorts,
+	"inventory":    inventorySorts,
+	"transactions": transactionSorts,
+	"users":        userSorts,
+}
+
+const maxSavedViewColumns = 50
+
+// savedViewDateFilters take relative dates, resolved when the view is applied
+var savedViewDateFilters = map[string]bool{
+	"start_date": true,
+	"end_date":   true,
+}
+
+var relativeDaysPattern = regexp.MustCompile(`^-([0-9]{1,4})d$`)
+
+const savedViewSelect = `
+	SELECT v.id, v.organization_id, v.user_id, u.name, v.resource, v.name, v.filters, v.sort, v.columns,
+		v.is_shared, v.created_at, v.updated_at
+	FROM saved_views v
+	JOIN users u ON u.id = v.user_id`
+
+func scanSavedView(row rowScanner) (*models.SavedView, error) {
+	view := &models.SavedView{}
+	var filters []byte
+	err := row.Scan(
+		&view.ID,
+		&view.OrganizationID,
+		&view.UserID,
+		&view.OwnerName,
+		&view.Resource,
+		&view.Name,
+		&filters,
+		&view.Sort,
+		pq.Array(&view.Columns),
+		&view.IsShared,
+		&view.CreatedAt,
+		&view.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(filters, &view.Filters); err != nil {
+		return nil, err
+	}
+	if view.Columns == nil {
+		view.Columns = []string{}
+	}
+	return view, nil
+}
+
+// Saved View Methods
+
+// GetSavedViews lists the views a user saved and the views shared with the
+// organization, for one resource or for all of them when resource is empty
+func (p *PostgresService) GetSavedViews(ctx context.Context, organizationID, userID, resource string) ([]*models.SavedView, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := savedViewSelect + ` WHERE v.organization_id = $1 AND (v.user_id = $2 OR v.is_shared)`
+	args := []interface{}{organizationID, userID}
+	if resource != "" {
+		query += ` AND v.resource = $3`
+		args = append(args, resource)
+	}
+	query += ` ORDER BY v.resource, v.name, v.id`
+
+	rows, err := p.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := make([]*models.SavedView, 0)
+	for rows.Next() {
+		view, err := scanSavedView(rows)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, view)
+	}
+
+	return views, rows.Err()
+}
+
+// GetSavedView returns a view the user saved or one shared with the organization
+func (p *PostgresService) GetSavedView(ctx context.Context, organizationID, userID, viewID string) (*models.SavedView, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	view, err := scanSavedView(p.DB.QueryRowContext(ctx, savedViewSelect+`
+		WHERE v.organization_id = $1 AND v.id = $3 AND (v.user_id = $2 OR v.is_shared)
+	`, organizationID, userID, viewID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("saved view not found")
+	}
+	return view, err
+}
+
+func (p *PostgresService) CreateSavedView(ctx context.Context, organizationID, userID string, req models.CreateSavedViewRequest) (*models.SavedView, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	view := &models.SavedView{
+		Resource: req.Resource,
+		Name:     strings.TrimSpace(req.Name),
+		Filters:  req.Filters,
+		Sort:     req.Sort,
+		Columns:  req.Columns,
+		IsShared: req.IsShared,
+	}
+	if err := validateSavedView(view); err != nil {
+		return nil, err
+	}
+
+	filters, err := json.Marshal(view.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var viewID string
+	now := time.Now()
+	err = p.DB.QueryRowContext(ctx, `
+		INSERT INTO saved_views (organization_id, user_id, resource, name, filters, sort, columns, is_shared, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		RETURNING id
+	`, organizationID, userID, view.Resource, view.Name, filters, view.Sort, pq.Array(view.Columns), view.IsShared, now).Scan(&viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.GetSavedView(ctx, organizationID, userID, viewID)
+}
+
+func (p *PostgresService) UpdateSavedView(ctx context.Context, organizationID, userID, viewID string, req models.UpdateSavedViewRequest) (*models.SavedView, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	view, err := p.getOwnSavedView(ctx, organizationID, userID, viewID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		view.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Filters != nil {
+		view.Filters = req.Filters
+	}
+	if req.Sort != nil {
+		view.Sort = *req.Sort
+	}
+	if req.Columns != nil {
+		view.Columns = req.Columns
+	}
+	if req.IsShared != nil {
+		view.IsShared = *req.IsShared
+	}
+	if err := validateSavedView(view); err != nil {
+		return nil, err
+	}
+
+	filters, err := json.Marshal(view.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.DB.ExecContext(ctx, `
+		UPDATE saved_views
+		SET name = $3, filters = $4, sort = $5, columns = $6, is_shared = $7, updated_at = $8
+		WHERE organization_id = $1 AND id = $2
+	`, organizationID, viewID, view.Name, filters, view.Sort, pq.Array(view.Columns), view.IsShared, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return p.GetSavedView(ctx, organizationID, userID, viewID)
+}
+
+func (p *PostgresService) DeleteSavedView(ctx context.Context, organizationID, userID, viewID string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := p.getOwnSavedView(ctx, organizationID, userID, viewID); err != nil {
+		return err
+	}
+
+	_, err := p.DB.ExecContext(ctx, `DELETE FROM saved_views WHERE organization_id = $1 AND id = $2`, organizationID, viewID)
+	return err
+}
+
+// getOwnSavedView returns a view the user may change. Shared views of other users
+// are visible but read-only.
+func (p *PostgresService) getOwnSavedView(ctx context.Context, organizationID, userID, viewID string) (*models.SavedView, error) {
+	view, err := p.GetSavedView(ctx, organizationID, userID, viewID)
+	if err != nil {
+		return nil, err
+	}
+	if view.UserID != userID {
+		return nil, fmt.Errorf("saved view can only be changed by its owner")
+	}
+	return view, nil
+}
+
+func validateSavedView(view *models.SavedView) error {
+	allowed, ok := models.SavedViewFilters[view.Resource]
+	if !ok {
+		return fmt.Errorf("invalid saved view: unsupported resource %s", view.Resource)
+	}
+	if view.Name == "" || len(view.Name) > 100 {
+		return fmt.Errorf("invalid saved view: name is required and must be at most 100 characters")
+	}
+
+	if view.Filters == nil {
+		view.Filters = map[string]string{}
+	}
+	hasCustomFields := isCustomFieldTable(models.SavedViewAliasTables[view.Resource])
+	for key, value := range view.Filters {
+		switch {
+		case strings.HasPrefix(key, "cf.") && len(key) > len("cf."):
+			if !hasCustomFields {
+				return fmt.Errorf("invalid saved view: %s has no custom fields to filter on", view.Resource)
+			}
+		case !containsString(allowed, key):
+			return fmt.Errorf("invalid saved view: unsupported filter %s, expected one of %s or cf.<field>", key, strings.Join(allowed, ", "))
+		}
+		if savedViewDateFilters[key] {
+			if _, err := resolveViewDate(value, key == "end_date", time.Now()); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Relevance only applies when the view searches
+	list := savedViewSorts[view.Resource]
+	if view.Filters["search"] != "" {
+		list = list.withKey("relevance")
+	}
+	if _, err := newPageQuery(list, view.Sort, "", 0, 0); err != nil {
+		return fmt.Errorf("invalid saved view: %s", err.Error())
+	}
+
+	if view.Columns == nil {
+		view.Columns = []string{}
+	}
+	if len(view.Columns) > maxSavedViewColumns {
+		return fmt.Errorf("invalid saved view: at most %d columns are allowed", maxSavedViewColumns)
+	}
+	seen := make(map[string]bool, len(view.Columns))
+	for _, column := range view.Columns {
+		if column == "" || seen[column] {
+			return fmt.Errorf("invalid saved view: columns must be unique field names")
+		}
+		seen[column] = true
+	}
+
+	return nil
+}
+
+func isCustomFieldTable(tableName string) bool {
+	_, ok := customFieldEntityTables[tableName]
+	return ok
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveSavedViewQuery returns the list query parameters of a view, with relative
+// dates resolved against now
+func ResolveSavedViewQuery(view *models.SavedView, now time.Time) (map[string]string, error) {
+	query := make(map[string]string, len(view.Filters)+1)
+	for key, value := range view.Filters {
+		if savedViewDateFilters[key] {
+			resolved, err := resolveViewDate(value, key == "end_date", now)
+			if err != nil {
+				return nil, err
+			}
+			value = resolved
+		}
+		query[key] = value
+	}
+	if view.Sort != "" {
+		query["sort"] = view.Sort
+	}
+	return query, nil
+}
+
+// resolveViewDate turns today, week_start, month_start and -<n>d into the start of
+// that day, or its end when end is set. Absolute dates are returned unchanged.
+func resolveViewDate(value string, end bool, now time.Time) (string, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var day time.Time
+	switch value {
+	case "today":
+		day = today
+	case "week_start":
+		// Weeks start on Monday
+		day = today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	case "month_start":
+		day = today.AddDate(0, 0, 1-today.Day())
+	default:
+		if match := relativeDaysPattern.FindStringSubmatch(value); match != nil {
+			days, _ := strconv.Atoi(match[1])
+			day = today.AddDate(0, 0, -days)
+			break
+		}
+		if _, err := time.Parse(time.DateOnly, value); err == nil {
+			return value, nil
+		}
+		if _, err := time.Parse(time.RFC3339, value); err == nil {
+			return value, nil
+		}
+		return "", fmt.Errorf("invalid saved view: %s is not a date, today, week_start, month_start or -<n>d", value)
+	}
+
+	if end {
+		day = day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return day.Format(time.RFC3339Nano), nil
+}
+
+// GetSavedViewColumns labels the columns of a view with the organization's field
+// aliases, or custom field names for cf.<field> columns, resolved through locales.
+// Columns without either keep their field name.
+func (p *PostgresService) GetSavedViewColumns(ctx context.Context, organizationID string, view *models.SavedView, locales []string) ([]*models.SavedViewColumn, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tableName := models.SavedViewAliasTables[view.Resource]
+	labels := make(map[string]string)
+
+	aliases, _, err := p.GetFieldAliases(ctx, organizationID, models.FieldAliasListParams{TableName: &tableName, Locales: locales})
+	if err != nil {
+		return nil, err
+	}
+	for _, alias := range aliases {
+		labels[alias.FieldName] = alias.DisplayName
+	}
+
+	if isCustomFieldTable(tableName) {
+		fields, err := getCustomFields(ctx, p.DB, organizationID, tableName)
+		if err != nil {
+			return nil, err
+		}
+		if err := localizeCustomFields(ctx, p.DB, organizationID, fields, locales); err != nil {
+			return nil, err
+		}
+		for _, field := range fields {
+			labels["cf."+field.FieldName] = field.DisplayName
+		}
+	}
+
+	columns := make([]*models.SavedViewColumn, 0, len(view.Columns))
+	for _, field := range view.Columns {
+		label, ok := labels[field]
+		if !ok {
+			label = field
+		}
+		columns = append(columns, &models.SavedViewColumn{Field: field, Label: label})
+	}
+	return columns, nil
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"flex-erp-poc/internal/models"
+)
+
+// List searches take two arguments: the raw term at termArg, used for barcode and
+// reference matches, full-text queries and trigram similarity, and a lowercase
+// LIKE pattern at termArg+1 for substring matches, with the term's own wildcards
+// escaped.
+
+const searchHighlightOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=5, MaxFragments=2"
+
+// Rank weights. A barcode match outranks everything else, followed by an exact
+// code or reference match; full-text rank and similarity order the rest.
+const (
+	searchBarcodeWeight = 1000
+	searchExactWeight   = 100
+	searchTextWeight    = 10
+)
+
+// likeEscaper escapes LIKE wildcards and the escape character itself, so a term
+// such as "50%" matches only itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func searchArgs(term string) []interface{} {
+	return []interface{}{term, "%" + likeEscaper.Replace(strings.ToLower(term)) + "%"}
+}
+
+func searchTermSQL(termArg int) string {
+	return fmt.Sprintf("$%d::text", termArg)
+}
+
+func searchQuerySQL(termArg int) string {
+	return fmt.Sprintf("websearch_to_tsquery('english', %s)", searchTermSQL(termArg))
+}
+
+// skuSearchSQL matches the SKU whose columns are qualified by prefix ("s." or "")
+func skuSearchSQL(prefix string, termArg int) string {
+	term := searchTermSQL(termArg)
+	return fmt.Sprintf(`(%[1]sbarcode = %[2]s
+		OR %[1]ssearch_vector @@ %[3]s
+		OR LOWER(%[1]ssku_code) %% LOWER(%[2]s)
+		OR LOWER(%[2]s) <%% LOWER(%[1]sproduct_name)
+		OR LOWER(%[1]ssku_code) LIKE $%[4]d ESCAPE '\' OR LOWER(%[1]sproduct_name) LIKE $%[4]d ESCAPE '\' OR LOWER(%[1]sdescription) LIKE $%[4]d ESCAPE '\'
+		OR %[5]s)`,
+		prefix, term, searchQuerySQL(termArg), termArg+1, customFieldSearchSQL(prefix+"custom_fields", termArg+1))
+}
+
+func skuSearchRankSQL(prefix string, termArg int) string {
+	term := searchTermSQL(termArg)
+	return fmt.Sprintf(`(CASE WHEN %[1]sbarcode = %[2]s THEN %[4]d WHEN LOWER(%[1]ssku_code) = LOWER(%[2]s) THEN %[5]d ELSE 0 END
+		+ ts_rank(%[1]ssearch_vector, %[3]s, 32) * %[6]d
+		+ GREATEST(similarity(LOWER(%[1]ssku_code), LOWER(%[2]s)), word_similarity(LOWER(%[2]s), LOWER(%[1]sproduct_name))))`,
+		prefix, term, searchQuerySQL(termArg), searchBarcodeWeight, searchExactWeight, searchTextWeight)
+}
+
+// transactionSearchSQL matches a transaction aliased t on its own columns or on its SKU aliased s
+func transactionSearchSQL(termArg int) string {
+	term := searchTermSQL(termArg)
+	return fmt.Sprintf(`(%[1]s
+		OR t.search_vector @@ %[3]s
+		OR LOWER(t.reference_number) %% LOWER(%[2]s)
+		OR LOWER(t.reference_number) LIKE $%[4]d ESCAPE '\' OR LOWER(t.notes) LIKE $%[4]d ESCAPE '\'
+		OR %[5]s)`,
+		skuSearchSQL("s.", termArg), term, searchQuerySQL(termArg), termArg+1, customFieldSearchSQL("t.custom_fields", termArg+1))
+}
+
+func transactionSearchRankSQL(termArg int) string {
+	term := searchTermSQL(termArg)
+	return fmt.Sprintf(`(%[1]s
+		+ CASE WHEN LOWER(t.reference_number) = LOWER(%[2]s) THEN %[4]d ELSE 0 END
+		+ ts_rank(t.search_vector, %[3]s, 32) * %[5]d
+		+ COALESCE(similarity(LOWER(t.reference_number), LOWER(%[2]s)), 0))`,
+		skuSearchRankSQL("s.", termArg), term, searchQuerySQL(termArg), searchExactWeight, searchTextWeight)
+}
+
+// searchHeadlineSQL returns column with the terms matched by the full-text query marked
+func searchHeadlineSQL(column string, termArg int) string {
+	return fmt.Sprintf("ts_headline('english', COALESCE(%s, ''), %s, '%s')", column, searchQuerySQL(termArg), searchHighlightOptions)
+}
+
+// newSearchMatch keeps the headlines that actually mark a match. Fuzzy matches have none.
+func newSearchMatch(rank float64, headlines map[string]string) *models.SearchMatch {
+	match := &models.SearchMatch{Rank: rank}
+	for field, headline := range headlines {
+		if strings.Contains(headline, "<mark>") {
+			if match.Highlights == nil {
+				match.Highlights = make(map[string]string)
+			}
+			match.Highlights[field] = headline
+		}
+	}
+	return match
+}
@@ -0,0 +1,23 @@
+package database
+
+import "testing"
+
+func TestSearchArgsEscapeLikeWildcards(t *testing.T) {
+	tests := []struct {
+		term, want string
+	}{
+		{"Bolt", "%bolt%"},
+		{"50%", `%50\%%`},
+		{"M6_X", `%m6\_x%`},
+		{`C:\Parts`, `%c:\\parts%`},
+	}
+	for _, tt := range tests {
+		args := searchArgs(tt.term)
+		if args[0] != tt.term {
+			t.Errorf("searchArgs(%q) term = %q, want it unchanged", tt.term, args[0])
+		}
+		if args[1] != tt.want {
+			t.Errorf("searchArgs(%q) pattern = %q, want %q", tt.term, args[1], tt.want)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"flex-erp-poc/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// minDuplicateScore is pg_trgm's default similarity threshold, below which the %
+// operator the finder relies on does not match
+const minDuplicateScore = 0.3
+
+// Duplicate SKU Methods
+
+// FindDuplicateSKUs returns pairs of SKUs that share a barcode or have similar
+// product names, best match first. Variants of the same parent are not paired, nor
+// is a variant with its parent.
+func (p *PostgresService) FindDuplicateSKUs(ctx context.Context, organizationID string, params models.SKUDuplicateParams) ([]*models.SKUDuplicate, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.beginReport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	if params.MinScore < minDuplicateScore || params.MinScore > 1 {
+		return nil, fmt.Errorf("invalid duplicate search: min_score must be between %.1f and 1", minDuplicateScore)
+	}
+
+	activeFilter := ""
+	if !params.IncludeInactive {
+		activeFilter = " AND a.is_active AND b.is_active"
+	}
+
+	query := `
+		SELECT a_id, b_id, same_barcode, name_score, code_score,
+			GREATEST(CASE WHEN same_barcode THEN 1 ELSE 0 END, name_score) AS score
+		FROM (
+			SELECT a.id AS a_id, b.id AS b_id,
+				COALESCE(a.barcode <> '' AND a.barcode = b.barcode, false) AS same_barcode,
+				similarity(LOWER(a.product_name), LOWER(b.product_name)) AS name_score,
+				similarity(LOWER(a.sku_code), LOWER(b.sku_code)) AS code_score
+			FROM skus a
+			JOIN skus b ON b.organization_id = a.organization_id AND a.id < b.id
+			WHERE a.organization_id = $1
+				AND a.merged_into_sku_id IS NULL AND b.merged_into_sku_id IS NULL
+				AND a.archived_at IS NULL AND b.archived_at IS NULL` + activeFilter + `
+				AND NOT (a.parent_sku_id IS NOT NULL AND a.parent_sku_id = b.parent_sku_id)
+				AND a.parent_sku_id IS DISTINCT FROM b.id AND b.parent_sku_id IS DISTINCT FROM a.id
+				AND ((a.barcode <> '' AND a.barcode = b.barcode) OR LOWER(a.product_name) % LOWER(b.product_name))
+		) pairs
+		WHERE same_barcode OR name_score >= $2
+		ORDER BY score DESC, name_score DESC, a_id, b_id
+		LIMIT $3
+	`
+
+	rows, err := tx.QueryContext(ctx, query, organizationID, params.MinScore, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	duplicates := make([]*models.SKUDuplicate, 0)
+	pairs := make([][2]string, 0)
+	skuIDs := make([]string, 0)
+	for rows.Next() {
+		var aID, bID string
+		var sameBarcode bool
+		duplicate := &models.SKUDuplicate{Reasons: []string{}}
+		if err := rows.Scan(&aID, &bID, &sameBarcode, &duplicate.NameSimilarity, &duplicate.CodeSimilarity, &duplicate.Score); err != nil {
+			return nil, err
+		}
+		if sameBarcode {
+			duplicate.Reasons = append(duplicate.Reasons, "barcode")
+		}
+		if duplicate.NameSimilarity >= params.MinScore {
+			duplicate.Reasons = append(duplicate.Reasons, "product_name")
+		}
+		if duplicate.CodeSimilarity >= params.MinScore {
+			duplicate.Reasons = append(duplicate.Reasons, "sku_code")
+		}
+		duplicates = append(duplicates, duplicate)
+		pairs = append(pairs, [2]string{aID, bID})
+		skuIDs = append(skuIDs, aID, bID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	skus, err := getSKUsByID(ctx, tx, organizationID, skuIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i, pair := range pairs {
+		duplicates[i].SKU, duplicates[i].Duplicate = skus[pair[0]], skus[pair[1]]
+	}
+
+	return duplicates, nil
+}
+
+func getSKUsByID(ctx context.Context, q queryer, organizationID string, ids []string) (map[string]*models.SKU, error) {
+	skus := make(map[string]*models.SKU, len(ids))
+	if len(ids) == 0 {
+		return skus, nil
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT `+skuColumns+` FROM skus WHERE organization_id = $1 AND id = ANY($2)`, organizationID, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		sku, err := scanSKU(rows)
+		if err != nil {
+			return nil, err
+		}
+		skus[sku.ID] = sku
+	}
+	return skus, rows.Err()
+}
+
+// SKU Merge Methods
+
+// MergeSKUs folds the source SKU into the survivor in one transaction. Stock is
+// combined at the quantity-weighted average of both costs; transactions, alternate
+// barcodes, attachments, reservations, order lines, supplier links, kit lines and
+// change logs move to the survivor; and the source is deactivated and marked as
+// merged. Both SKUs get a merge entry in the change log.
+func (p *PostgresService) MergeSKUs(ctx context.Context, organizationID, userID, survivorID string, req models.MergeSKURequest) (*models.SKUMergeResult, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	sourceID := req.SourceSKUID
+	if !isUUID(sourceID) {
+		return nil, fmt.Errorf("SKU not found")
+	}
+	if sourceID == survivorID {
+		return nil, fmt.Errorf("invalid merge: a SKU cannot be merged into itself")
+	}
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	survivor, source, err := lockMergeSKUs(ctx, tx, organizationID, survivorID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var hasVariants, survivorIsKit bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM skus WHERE organization_id = $1 AND parent_sku_id = $3),
+			EXISTS (SELECT 1 FROM kit_components WHERE kit_sku_id = $2 AND component_sku_id = $3)
+	`, organizationID, survivorID, sourceID).Scan(&hasVariants, &survivorIsKit)
+	if err != nil {
+		return nil, err
+	}
+	if hasVariants {
+		return nil, fmt.Errorf("invalid merge: source SKU has variants, merge them first")
+	}
+	if survivorIsKit {
+		return nil, fmt.Errorf("invalid merge: source SKU is a component of the surviving kit")
+	}
+
+	result := &models.SKUMergeResult{}
+	metadata := map[string]interface{}{
+		"source_sku_id":     source.ID,
+		"source_sku_code":   source.SKUCode,
+		"survivor_sku_id":   survivor.ID,
+		"survivor_sku_code": survivor.SKUCode,
+	}
+
+	if err := mergeSKUInventory(ctx, tx, organizationID, survivorID, sourceID, metadata); err != nil {
+		return nil, err
+	}
+
+	// Re-point the source's history and open documents
+	moves := []struct {
+		count *int
+		query string
+	}{
+		{&result.MovedTransactions, `UPDATE transactions SET sku_id = $2 WHERE organization_id = $1 AND sku_id = $3`},
+		{&result.MovedBarcodes, `UPDATE sku_barcodes SET sku_id = $2, updated_at = now() WHERE organization_id = $1 AND sku_id = $3`},
+		{&result.MovedAttachments, `UPDATE attachments SET entity_id = $2 WHERE organization_id = $1 AND entity_type = 'sku' AND entity_id = $3`},
+		{&result.MovedReservations, `UPDATE stock_reservations SET sku_id = $2 WHERE organization_id = $1 AND sku_id = $3`},
+		{&result.MovedOrderLines, `UPDATE purchase_order_lines l SET sku_id = $2 FROM purchase_orders o
+			WHERE o.id = l.purchase_order_id AND o.organization_id = $1 AND l.sku_id = $3`},
+		{&result.MovedOrderLines, `UPDATE sales_order_lines l SET sku_id = $2 FROM sales_orders o
+			WHERE o.id = l.sales_order_id AND o.organization_id = $1 AND l.sku_id = $3`},
+		// The source keeps its own link when the survivor already has that supplier
+		{&result.MovedSuppliers, `UPDATE sku_suppliers ss SET sku_id = $2, is_preferred = false FROM skus s
+			WHERE s.id = ss.sku_id AND s.organization_id = $1 AND ss.sku_id = $3
+			AND NOT EXISTS (SELECT 1 FROM sku_suppliers x WHERE x.sku_id = $2 AND x.supplier_id = ss.supplier_id)`},
+		// Kits using both SKUs take the source's quantity on the survivor's line
+		{nil, `UPDATE kit_components k SET quantity = k.quantity + l.quantity, updated_at = now() FROM kit_components l
+			WHERE k.organization_id = $1 AND k.component_sku_id = $2 AND l.component_sku_id = $3 AND l.kit_sku_id = k.kit_sku_id`},
+		{nil, `DELETE FROM kit_components l WHERE l.organization_id = $1 AND l.component_sku_id = $3
+			AND EXISTS (SELECT 1 FROM kit_components k WHERE k.kit_sku_id = l.kit_sku_id AND k.component_sku_id = $2)`},
+		{&result.MovedKitLines, `UPDATE kit_components SET component_sku_id = $2, updated_at = now() WHERE organization_id = $1 AND component_sku_id = $3`},
+		// A source kit's bill of materials moves only when the survivor has none
+		{&result.MovedKitLines, `UPDATE kit_components SET kit_sku_id = $2, updated_at = now()
+			WHERE organization_id = $1 AND kit_sku_id = $3
+			AND NOT EXISTS (SELECT 1 FROM kit_components WHERE kit_sku_id = $2)
+			AND NOT EXISTS (SELECT 1 FROM kit_components WHERE kit_sku_id = $3 AND component_sku_id = $2)`},
+		{&result.MovedChangeLogs, `UPDATE change_logs SET
+				sku_id = CASE WHEN sku_id = $3 THEN $2 ELSE sku_id END,
+				entity_id = CASE WHEN entity_type = 'sku' AND entity_id = $3 THEN $2 ELSE entity_id END,
+				metadata = COALESCE(metadata, '{}'::jsonb) || jsonb_build_object('merged_from_sku_id', CAST($3 AS uuid))
+			WHERE organization_id = $1 AND (sku_id = $3 OR (entity_type = 'sku' AND entity_id = $3))`},
+	}
+	for _, move := range moves {
+		res, err := tx.ExecContext(ctx, move.query, organizationID, survivorID, sourceID)
+		if err != nil {
+			return nil, err
+		}
+		if move.count != nil {
+			moved, err := res.RowsAffected()
+			if err != nil {
+				return nil, err
+			}
+			*move.count += int(moved)
+		}
+	}
+
+	// The source is marked merged first so the survivor can take over its barcode
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `UPDATE skus SET is_active = false, merged_into_sku_id = $2, updated_at = $4 WHERE organization_id = $1 AND id = $3`,
+		organizationID, survivorID, sourceID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// The survivor keeps its own details and takes the source's where it has none
+	_, err = tx.ExecContext(ctx, `
+		UPDATE skus s SET
+			description = COALESCE(NULLIF(s.description, ''), src.description),
+			barcode = COALESCE(NULLIF(s.barcode, ''), src.barcode),
+			custom_fields = src.custom_fields || s.custom_fields,
+			updated_at = $4
+		FROM skus src
+		WHERE s.organization_id = $1 AND s.id = $2 AND src.id = $3
+	`, organizationID, survivorID, sourceID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	skus, err := getSKUsByID(ctx, tx, organizationID, []string{survivorID, sourceID})
+	if err != nil {
+		return nil, err
+	}
+	result.Survivor, result.Merged = skus[survivorID], skus[sourceID]
+	if result.Survivor.ParentSKUID == nil {
+		if err := propagateVariantFields(ctx, tx, organizationID, result.Survivor); err != nil {
+			return nil, err
+		}
+	}
+
+	metadata["moved_transactions"] = result.MovedTransactions
+	metadata["moved_reservations"] = result.MovedReservations
+	metadata["moved_order_lines"] = result.MovedOrderLines
+	metadata["moved_change_logs"] = result.MovedChangeLogs
+	metadata["moved_suppliers"] = result.MovedSuppliers
+	metadata["moved_kit_lines"] = result.MovedKitLines
+	metadata["moved_barcodes"] = result.MovedBarcodes
+	metadata["moved_attachments"] = result.MovedAttachments
+	if err := logSKUMerge(ctx, tx, organizationID, userID, survivor, source, req.Reason, metadata); err != nil {
+		return nil, err
+	}
+
+	err = writeOutboxEvent(ctx, tx, organizationID, "sku.merged", "sku", sourceID,
+		map[string]interface{}{"sku": result.Merged, "survivor": result.Survivor})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeOutboxEvent(ctx, tx, organizationID, "sku.updated", "sku", survivorID, map[string]interface{}{"sku": result.Survivor}); err != nil {
+		return nil, err
+	}
+	if _, ok := metadata["source_quantity"]; ok {
+		if err := writeInventoryEvent(ctx, tx, organizationID, survivorID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	result.Inventory, err = p.GetInventoryBySKUID(ctx, organizationID, survivorID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return result, nil
+}
+
+// lockMergeSKUs locks both SKUs in id order so concurrent merges cannot deadlock
+func lockMergeSKUs(ctx context.Context, tx *sql.Tx, organizationID, survivorID, sourceID string) (*models.SKU, *models.SKU, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+skuColumns+`, merged_into_sku_id
+		FROM skus
+		WHERE organization_id = $1 AND id IN ($2, $3)
+		ORDER BY id
+		FOR UPDATE
+	`, organizationID, survivorID, sourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var survivor, source *models.SKU
+	var survivorMerged, sourceMerged *string
+	for rows.Next() {
+		var mergedInto *string
+		sku, err := scanSKU(rows, &mergedInto)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sku.ID == survivorID {
+			survivor, survivorMerged = sku, mergedInto
+		} else {
+			source, sourceMerged = sku, mergedInto
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if survivor == nil || source == nil {
+		return nil, nil, fmt.Errorf("SKU not found")
+	}
+	if sourceMerged != nil {
+		return nil, nil, fmt.Errorf("invalid merge: source SKU was already merged")
+	}
+	if survivorMerged != nil {
+		return nil, nil, fmt.Errorf("invalid merge: surviving SKU was merged into another SKU")
+	}
+	return survivor, source, nil
+}
+
+// mergeSKUInventory moves the source's stock onto the survivor. When both hold stock
+// the cost is the quantity-weighted average of the two, and the result counts as a
+// manual cost if either side was one.
+func mergeSKUInventory(ctx context.Context, tx *sql.Tx, organizationID, survivorID, sourceID string, metadata map[string]interface{}) error {
+	sourceInventory, err := getInventoryForUpdate(ctx, tx, organizationID, sourceID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	metadata["source_quantity"] = sourceInventory.Quantity
+	metadata["source_weighted_cost"] = sourceInventory.WeightedCost
+
+	survivorInventory, err := getInventoryForUpdate(ctx, tx, organizationID, survivorID)
+	if err == sql.ErrNoRows {
+		_, err = tx.ExecContext(ctx, `UPDATE inventory SET sku_id = $2, updated_at = $4 WHERE organization_id = $1 AND sku_id = $3`,
+			organizationID, survivorID, sourceID, time.Now())
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	metadata["survivor_quantity"] = survivorInventory.Quantity
+	metadata["survivor_weighted_cost"] = survivorInventory.WeightedCost
+
+	quantity := survivorInventory.Quantity + sourceInventory.Quantity
+	weightedCost := mergedWeightedCost(survivorInventory, sourceInventory)
+	metadata["merged_quantity"] = quantity
+	metadata["merged_weighted_cost"] = weightedCost
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE inventory i SET
+			quantity = $3, weighted_cost = $4, total_value = $5, is_manual_cost = $6,
+			custom_fields = src.custom_fields || i.custom_fields, updated_at = $8
+		FROM inventory src
+		WHERE i.organization_id = $1 AND i.sku_id = $2 AND src.organization_id = $1 AND src.sku_id = $7
+	`, organizationID, survivorID, quantity, weightedCost, float64(quantity)*weightedCost,
+		survivorInventory.IsManualCost || sourceInventory.IsManualCost, sourceID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM inventory WHERE organization_id = $1 AND sku_id = $2`, organizationID, sourceID)
+	return err
+}
+
+// mergedWeightedCost averages two costs by on-hand quantity. Negative stock carries
+// no weight; with no stock on either side the survivor's cost is kept, or the
+// source's when the survivor has none.
+func mergedWeightedCost(survivor, source *models.Inventory) float64 {
+	survivorQuantity, sourceQuantity := max(survivor.Quantity, 0), max(source.Quantity, 0)
+	if survivorQuantity+sourceQuantity == 0 {
+		if survivor.WeightedCost != 0 {
+			return survivor.WeightedCost
+		}
+		return source.WeightedCost
+	}
+	totalValue := float64(survivorQuantity)*survivor.WeightedCost + float64(sourceQuantity)*source.WeightedCost
+	return totalValue / float64(survivorQuantity+sourceQuantity)
+}
+
+// logSKUMerge writes a merge entry for each SKU, after their history was re-pointed
+func logSKUMerge(ctx context.Context, tx *sql.Tx, organizationID, userID string, survivor, source *models.SKU, reason *string, metadata map[string]interface{}) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	if reason == nil || *reason == "" {
+		text := fmt.Sprintf("Merged duplicate SKU %s into %s", source.SKUCode, survivor.SKUCode)
+		reason = &text
+	}
+
+	entries := []struct {
+		skuID, fieldName, value string
+	}{
+		{survivor.ID, "merged_sku_id", source.ID},
+		{source.ID, "merged_into_sku_id", survivor.ID},
+	}
+	for _, entry := range entries {
+		logReq := models.NewSKUChangeLog(organizationID, userID, entry.skuID, "merge")
+		fieldName, value := entry.fieldName, entry.value
+		logReq.FieldName = &fieldName
+		logReq.NewValue = &value
+		logReq.Reason = reason
+		logReq.Metadata = data
+		if _, err := createChangeLog(ctx, tx, organizationID, userID, *logReq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"math"
+	"testing"
+
+	"flex-erp-poc/internal/models"
+)
+
+func TestMergedWeightedCost(t *testing.T) {
+	tests := []struct {
+		name                             string
+		survivorQuantity, sourceQuantity int
+		survivorCost, sourceCost, want   float64
+	}{
+		{"both in stock", 10, 30, 4, 8, 7},
+		{"equal stock", 5, 5, 2.5, 3.5, 3},
+		{"only survivor in stock", 12, 0, 6, 100, 6},
+		{"only source in stock", 0, 12, 6, 9.25, 9.25},
+		{"negative survivor stock has no weight", -5, 10, 100, 8, 8},
+		{"negative source stock has no weight", 10, -20, 4, 100, 4},
+		{"no stock keeps the survivor's cost", 0, 0, 4, 8, 4},
+		{"no stock falls back to the source's cost", 0, 0, 0, 8, 8},
+		{"both negative keeps the survivor's cost", -3, -4, 5, 8, 5},
+		{"no stock and no costs", 0, 0, 0, 0, 0},
+		{"fractional average", 1, 2, 1, 2, 5.0 / 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergedWeightedCost(
+				&models.Inventory{Quantity: tt.survivorQuantity, WeightedCost: tt.survivorCost},
+				&models.Inventory{Quantity: tt.sourceQuantity, WeightedCost: tt.sourceCost},
+			)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("mergedWeightedCost = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,375 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/models"
+)
+
+const supplierColumns = `id, organization_id, name, contact_name, email, phone, address, lead_time_days, currency, notes, is_active, created_at, updated_at`
+
+func scanSupplier(row rowScanner) (*models.Supplier, error) {
+	supplier := &models.Supplier{}
+	err := row.Scan(
+		&supplier.ID,
+		&supplier.OrganizationID,
+		&supplier.Name,
+		&supplier.ContactName,
+		&supplier.Email,
+		&supplier.Phone,
+		&supplier.Address,
+		&supplier.LeadTimeDays,
+		&supplier.Currency,
+		&supplier.Notes,
+		&supplier.IsActive,
+		&supplier.CreatedAt,
+		&supplier.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	supplier.Currency = strings.TrimSpace(supplier.Currency)
+	return supplier, nil
+}
+
+// Supplier Methods
+
+func (p *PostgresService) GetSuppliers(ctx context.Context, organizationID string, params models.SupplierListParams) ([]*models.Supplier, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT ` + supplierColumns + ` FROM suppliers WHERE organization_id = $1`
+	args := []interface{}{organizationID}
+	argIndex := 2
+
+	if !params.IncludeInactive {
+		query += " AND is_active = true"
+	}
+
+	if params.Search != nil && *params.Search != "" {
+		searchTerm := "%" + strings.ToLower(*params.Search) + "%"
+		query += fmt.Sprintf(" AND (LOWER(name) LIKE $%d OR LOWER(contact_name) LIKE $%d OR LOWER(email) LIKE $%d)", argIndex, argIndex, argIndex)
+		args = append(args, searchTerm)
+		argIndex++
+	}
+
+	query += " ORDER BY name"
+
+	// Add pagination
+	if params.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, params.Limit)
+		argIndex++
+
+		if params.Page > 0 {
+			offset := (params.Page - 1) * params.Limit
+			query += fmt.Sprintf(" OFFSET $%d", argIndex)
+			args = append(args, offset)
+		}
+	}
+
+	rows, err := p.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppliers := make([]*models.Supplier, 0)
+	for rows.Next() {
+		supplier, err := scanSupplier(rows)
+		if err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, supplier)
+	}
+
+	return suppliers, rows.Err()
+}
+
+func (p *PostgresService) GetSupplierByID(ctx context.Context, organizationID, id string) (*models.Supplier, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return getSupplier(ctx, p.DB, organizationID, id)
+}
+
+func getSupplier(ctx context.Context, q queryer, organizationID, id string) (*models.Supplier, error) {
+	query := `SELECT ` + supplierColumns + ` FROM suppliers WHERE organization_id = $1 AND id = $2`
+	supplier, err := scanSupplier(q.QueryRowContext(ctx, query, organizationID, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("supplier not found")
+	}
+	return supplier, err
+}
+
+// findSupplierByName matches a free-text supplier name against existing suppliers
+// using the same normalized key the unique index is built on
+func findSupplierByName(ctx context.Context, q queryer, organizationID, name string) (*models.Supplier, error) {
+	query := `SELECT ` + supplierColumns + ` FROM suppliers WHERE organization_id = $1 AND supplier_name_key(name) = supplier_name_key($2)`
+	return scanSupplier(q.QueryRowContext(ctx, query, organizationID, name))
+}
+
+func (p *PostgresService) CreateSupplier(ctx context.Context, organizationID string, req models.CreateSupplierRequest) (*models.Supplier, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return createSupplier(ctx, p.DB, organizationID, req)
+}
+
+func createSupplier(ctx context.Context, q queryer, organizationID string, req models.CreateSupplierRequest) (*models.Supplier, error) {
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = models.DefaultSupplierCurrency
+	}
+
+	now := time.Now()
+	query := `
+		INSERT INTO suppliers (organization_id, name, contact_name, email, phone, address, lead_time_days, currency, notes, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, true, $10, $10)
+		RETURNING ` + supplierColumns
+	return scanSupplier(q.QueryRowContext(ctx,
+		query,
+		organizationID,
+		strings.TrimSpace(req.Name),
+		req.ContactName,
+		req.Email,
+		req.Phone,
+		req.Address,
+		req.LeadTimeDays,
+		currency,
+		req.Notes,
+		now,
+	))
+}
+
+func (p *PostgresService) UpdateSupplier(ctx context.Context, organizationID, id string, req models.UpdateSupplierRequest) (*models.Supplier, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = models.DefaultSupplierCurrency
+	}
+
+	query := `
+		UPDATE suppliers
+		SET name = $3, contact_name = $4, email = $5, phone = $6, address = $7, lead_time_days = $8,
+			currency = $9, notes = $10, is_active = COALESCE($11, is_active), updated_at = $12
+		WHERE organization_id = $1 AND id = $2
+		RETURNING ` + supplierColumns
+	supplier, err := scanSupplier(p.DB.QueryRowContext(ctx,
+		query,
+		organizationID,
+		id,
+		strings.TrimSpace(req.Name),
+		req.ContactName,
+		req.Email,
+		req.Phone,
+		req.Address,
+		req.LeadTimeDays,
+		currency,
+		req.Notes,
+		req.IsActive,
+		time.Now(),
+	))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("supplier not found")
+	}
+	return supplier, err
+}
+
+// SKU Supplier Methods
+
+func (p *PostgresService) GetSKUSuppliers(ctx context.Context, organizationID, skuID string) ([]*models.SKUSupplier, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT ss.id, ss.sku_id, ss.supplier_id, ss.supplier_item_code, ss.last_purchase_price, ss.minimum_order_quantity,
+			ss.is_preferred, ss.created_at, ss.updated_at, sup.name, sup.currency, sup.lead_time_days
+		FROM sku_suppliers ss
+		JOIN suppliers sup ON ss.supplier_id = sup.id
+		JOIN skus s ON ss.sku_id = s.id
+		WHERE s.organization_id = $1 AND ss.sku_id = $2
+		ORDER BY ss.is_preferred DESC, sup.name
+	`
+	rows, err := p.DB.QueryContext(ctx, query, organizationID, skuID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make([]*models.SKUSupplier, 0)
+	for rows.Next() {
+		link := &models.SKUSupplier{}
+		err := rows.Scan(
+			&link.ID,
+			&link.SKUID,
+			&link.SupplierID,
+			&link.SupplierItemCode,
+			&link.LastPurchasePrice,
+			&link.MinimumOrderQuantity,
+			&link.IsPreferred,
+			&link.CreatedAt,
+			&link.UpdatedAt,
+			&link.SupplierName,
+			&link.Currency,
+			&link.LeadTimeDays,
+		)
+		if err != nil {
+			return nil, err
+		}
+		link.Currency = strings.TrimSpace(link.Currency)
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+// UpsertSKUSupplier creates or replaces the link between a SKU and a supplier. Marking a
+// link preferred also copies the supplier name onto skus.supplier for older clients.
+func (p *PostgresService) UpsertSKUSupplier(ctx context.Context, organizationID, skuID, supplierID string, req models.UpsertSKUSupplierRequest) ([]*models.SKUSupplier, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var skuExists bool
+	err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM skus WHERE organization_id = $1 AND id = $2)`, organizationID, skuID).Scan(&skuExists)
+	if err != nil {
+		return nil, err
+	}
+	if !skuExists {
+		return nil, fmt.Errorf("SKU not found")
+	}
+
+	supplier, err := getSupplier(ctx, tx, organizationID, supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	minimumOrderQuantity := req.MinimumOrderQuantity
+	if minimumOrderQuantity <= 0 {
+		minimumOrderQuantity = 1
+	}
+
+	if req.IsPreferred {
+		if err := setPreferredSupplier(ctx, tx, skuID, supplier); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO sku_suppliers (sku_id, supplier_id, supplier_item_code, last_purchase_price, minimum_order_quantity, is_preferred, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		ON CONFLICT (sku_id, supplier_id) DO UPDATE
+		SET supplier_item_code = EXCLUDED.supplier_item_code,
+			last_purchase_price = COALESCE(EXCLUDED.last_purchase_price, sku_suppliers.last_purchase_price),
+			minimum_order_quantity = EXCLUDED.minimum_order_quantity,
+			is_preferred = EXCLUDED.is_preferred,
+			updated_at = EXCLUDED.updated_at
+	`, skuID, supplier.ID, req.SupplierItemCode, req.LastPurchasePrice, minimumOrderQuantity, req.IsPreferred, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return p.GetSKUSuppliers(ctx, organizationID, skuID)
+}
+
+func (p *PostgresService) DeleteSKUSupplier(ctx context.Context, organizationID, skuID, supplierID string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var isPreferred bool
+	err = tx.QueryRowContext(ctx, `
+		DELETE FROM sku_suppliers ss
+		USING skus s
+		WHERE ss.sku_id = s.id AND s.organization_id = $1 AND ss.sku_id = $2 AND ss.supplier_id = $3
+		RETURNING ss.is_preferred
+	`, organizationID, skuID, supplierID).Scan(&isPreferred)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("SKU supplier link not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if isPreferred {
+		_, err = tx.ExecContext(ctx, `UPDATE skus SET supplier = NULL, updated_at = $2 WHERE id = $1`, skuID, time.Now())
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// setPreferredSupplier clears the SKU's current preferred link and mirrors the new
+// supplier name onto skus.supplier
+func setPreferredSupplier(ctx context.Context, q queryer, skuID string, supplier *models.Supplier) error {
+	now := time.Now()
+	_, err := q.ExecContext(ctx, `UPDATE sku_suppliers SET is_preferred = false, updated_at = $3 WHERE sku_id = $1 AND supplier_id <> $2 AND is_preferred`, skuID, supplier.ID, now)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, `UPDATE skus SET supplier = $2, updated_at = $3 WHERE id = $1`, skuID, supplier.Name, now)
+	return err
+}
+
+// linkSupplierByName resolves a free-text supplier name to a supplier record, creating
+// one when no existing supplier matches, and makes it the SKU's preferred supplier
+func linkSupplierByName(ctx context.Context, q queryer, organizationID, skuID, name string) error {
+	if strings.TrimSpace(name) == "" {
+		_, err := q.ExecContext(ctx, `UPDATE sku_suppliers SET is_preferred = false, updated_at = $2 WHERE sku_id = $1 AND is_preferred`, skuID, time.Now())
+		return err
+	}
+
+	supplier, err := findSupplierByName(ctx, q, organizationID, name)
+	if err == sql.ErrNoRows {
+		supplier, err = createSupplier(ctx, q, organizationID, models.CreateSupplierRequest{Name: name})
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := setPreferredSupplier(ctx, q, skuID, supplier); err != nil {
+		return err
+	}
+
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO sku_suppliers (sku_id, supplier_id, is_preferred, created_at, updated_at)
+		VALUES ($1, $2, true, $3, $3)
+		ON CONFLICT (sku_id, supplier_id) DO UPDATE SET is_preferred = true, updated_at = EXCLUDED.updated_at
+	`, skuID, supplier.ID, time.Now())
+	return err
+}
+
+// recordPurchasePrice keeps the SKU's supplier link up to date with the price paid on
+// a received purchase order line
+func recordPurchasePrice(ctx context.Context, q queryer, skuID, supplierID string, unitCost float64) error {
+	now := time.Now()
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO sku_suppliers (sku_id, supplier_id, last_purchase_price, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (sku_id, supplier_id) DO UPDATE SET last_purchase_price = EXCLUDED.last_purchase_price, updated_at = EXCLUDED.updated_at
+	`, skuID, supplierID, unitCost, now)
+	return err
+}
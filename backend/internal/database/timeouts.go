@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// DefaultQueryTimeout bounds an operation when PostgresService.QueryTimeout is zero
+	DefaultQueryTimeout = 30 * time.Second
+	// DefaultReportTimeout is the statement timeout of report queries when
+	// PostgresService.ReportTimeout is zero
+	DefaultReportTimeout = 15 * time.Second
+)
+
+// Postgres cancels a statement with this code on a statement timeout and on a
+// cancel request, which lib/pq sends when the query's context is done
+const queryCanceledCode = "57014"
+
+var (
+	// ErrCanceled is an operation given up because its caller went away
+	ErrCanceled = errors.New("query canceled")
+	// ErrTimedOut is an operation that took longer than its timeout
+	ErrTimedOut = errors.New("query timed out")
+)
+
+// withTimeout bounds one operation by the service's query timeout
+func (p *PostgresService) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := p.QueryTimeout
+	if timeout <= 0 {
+		timeout = DefaultQueryTimeout
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// beginReport starts the read-only transaction a report runs in. Postgres itself
+// cancels its statements after the report timeout, so an expensive report stops
+// even when the connection to the client is lost.
+func (p *PostgresService) beginReport(ctx context.Context) (*sql.Tx, error) {
+	timeout := p.ReportTimeout
+	if timeout <= 0 {
+		timeout = DefaultReportTimeout
+	}
+
+	tx, err := p.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	// SET does not take parameters
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.Milliseconds())); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Interrupted tells an operation that was cancelled or timed out from one that
+// failed. It returns ErrCanceled when ctx, the context the operation was called
+// with, was cancelled, ErrTimedOut when a timeout stopped it, and nil otherwise.
+func Interrupted(ctx context.Context, err error) error {
+	var pqErr *pq.Error
+	if err == nil || !(errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		(errors.As(err, &pqErr) && pqErr.Code == queryCanceledCode)) {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return ErrCanceled
+	}
+	return ErrTimedOut
+}
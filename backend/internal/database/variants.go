@@ -0,0 +1,432 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/models"
+)
+
+const skuColumns = `id, organization_id, sku_code, product_name, description, category, category_id, supplier, barcode, is_active, created_at, updated_at, parent_sku_id, custom_fields`
+
+func scanSKU(row rowScanner, extra ...interface{}) (*models.SKU, error) {
+	sku := &models.SKU{}
+	dest := []interface{}{
+		&sku.ID,
+		&sku.OrganizationID,
+		&sku.SKUCode,
+		&sku.ProductName,
+		&sku.Description,
+		&sku.Category,
+		&sku.CategoryID,
+		&sku.Supplier,
+		&sku.Barcode,
+		&sku.IsActive,
+		&sku.CreatedAt,
+		&sku.UpdatedAt,
+		&sku.ParentSKUID,
+		&sku.CustomFields,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return sku, nil
+}
+
+// SKU Variant Methods
+
+func (p *PostgresService) GetSKUVariants(ctx context.Context, organizationID, parentID string) ([]*models.SKUVariant, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	return getSKUVariants(ctx, p.DB, organizationID, parentID)
+}
+
+func getSKUVariants(ctx context.Context, q queryer, organizationID, parentID string) ([]*models.SKUVariant, error) {
+	query := `SELECT ` + skuColumns + `, variant_values FROM skus WHERE organization_id = $1 AND parent_sku_id = $2 ORDER BY sku_code`
+	rows, err := q.QueryContext(ctx, query, organizationID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := make([]*models.SKUVariant, 0)
+	for rows.Next() {
+		var values []byte
+		sku, err := scanSKU(rows, &values)
+		if err != nil {
+			return nil, err
+		}
+		variant := &models.SKUVariant{SKU: *sku}
+		if err := json.Unmarshal(values, &variant.VariantValues); err != nil {
+			return nil, err
+		}
+		variants = append(variants, variant)
+	}
+
+	return variants, rows.Err()
+}
+
+// GenerateVariants stores the variant matrix on a parent SKU and creates a child SKU for
+// every attribute combination that does not exist yet. Children copy the parent's
+// description, category and supplier; running it again after adding values only creates
+// the new combinations.
+func (p *PostgresService) GenerateVariants(ctx context.Context, organizationID, parentID string, req models.GenerateVariantsRequest) (*models.GenerateVariantsResponse, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	attributes, err := normalizeVariantAttributes(req.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := strings.TrimSpace(req.CodePattern)
+	if pattern == "" {
+		pattern = defaultVariantCodePattern(attributes)
+	}
+	if err := validateVariantCodePattern(pattern, attributes); err != nil {
+		return nil, err
+	}
+
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	parent, err := scanSKU(tx.QueryRowContext(ctx, `SELECT `+skuColumns+` FROM skus WHERE organization_id = $1 AND id = $2 FOR UPDATE`, organizationID, parentID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("SKU not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if parent.ParentSKUID != nil {
+		return nil, fmt.Errorf("invalid variant attributes: a variant cannot have variants of its own")
+	}
+
+	// Stock lives on the children, so a SKU that already holds stock can't become a parent
+	var onHand int
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(quantity), 0) FROM inventory WHERE organization_id = $1 AND sku_id = $2`, organizationID, parentID).Scan(&onHand)
+	if err != nil {
+		return nil, err
+	}
+	if onHand > 0 {
+		return nil, fmt.Errorf("invalid variant attributes: SKU has %d units on hand", onHand)
+	}
+
+	attributesJSON, err := json.Marshal(attributes)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE skus SET variant_attributes = $3, variant_code_pattern = $4, updated_at = $5
+		WHERE organization_id = $1 AND id = $2
+	`, organizationID, parentID, attributesJSON, pattern, now)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := getSKUVariants(ctx, tx, organizationID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	existingByKey := make(map[string]*models.SKUVariant, len(existing))
+	for _, variant := range existing {
+		existingByKey[variantKey(attributes, variant.VariantValues)] = variant
+	}
+
+	response := &models.GenerateVariantsResponse{
+		Attributes:  attributes,
+		CodePattern: pattern,
+		Created:     make([]*models.SKUVariant, 0),
+		Existing:    make([]*models.SKUVariant, 0),
+	}
+
+	for _, values := range variantCombinations(attributes) {
+		if variant, ok := existingByKey[variantKey(attributes, values)]; ok {
+			response.Existing = append(response.Existing, variant)
+			continue
+		}
+
+		code := buildVariantCode(pattern, parent.SKUCode, values)
+		if len(code) > 50 {
+			return nil, fmt.Errorf("invalid variant attributes: generated code %s is longer than 50 characters", code)
+		}
+
+		labels := make([]string, 0, len(attributes))
+		for _, attribute := range attributes {
+			labels = append(labels, values[attribute.Name])
+		}
+		name := parent.ProductName + " - " + strings.Join(labels, " / ")
+
+		valuesJSON, err := json.Marshal(values)
+		if err != nil {
+			return nil, err
+		}
+
+		sku, err := scanSKU(tx.QueryRowContext(ctx, `
+			INSERT INTO skus (organization_id, sku_code, product_name, description, category, category_id, supplier, is_active, parent_sku_id, variant_values, custom_fields, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12)
+			RETURNING `+skuColumns,
+			organizationID, code, name, parent.Description, parent.Category, parent.CategoryID, parent.Supplier, parent.IsActive, parent.ID, valuesJSON, parent.CustomFields, now,
+		))
+		if err != nil {
+			return nil, err
+		}
+
+		// Children are bought from the same suppliers as the parent
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO sku_suppliers (sku_id, supplier_id, supplier_item_code, last_purchase_price, minimum_order_quantity, is_preferred, created_at, updated_at)
+			SELECT $2, supplier_id, supplier_item_code, last_purchase_price, minimum_order_quantity, is_preferred, $3, $3
+			FROM sku_suppliers WHERE sku_id = $1
+		`, parent.ID, sku.ID, now)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := writeOutboxEvent(ctx, tx, organizationID, "sku.created", "sku", sku.ID, map[string]interface{}{"sku": sku}); err != nil {
+			return nil, err
+		}
+
+		response.Created = append(response.Created, &models.SKUVariant{SKU: *sku, VariantValues: values})
+	}
+
+	response.Parent, err = scanSKU(tx.QueryRowContext(ctx, `SELECT `+skuColumns+` FROM skus WHERE id = $1`, parentID))
+	if err != nil {
+		return nil, err
+	}
+	err = writeOutboxEvent(ctx, tx, organizationID, "sku.updated", "sku", response.Parent.ID, map[string]interface{}{"sku": response.Parent})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// propagateVariantFields copies the fields children inherit from their parent
+func propagateVariantFields(ctx context.Context, q queryer, organizationID string, parent *models.SKU) error {
+	_, err := q.ExecContext(ctx, `
+		UPDATE skus SET description = $3, category = $4, category_id = $5, supplier = $6, updated_at = $7
+		WHERE organization_id = $1 AND parent_sku_id = $2
+	`, organizationID, parent.ID, parent.Description, parent.Category, parent.CategoryID, parent.Supplier, time.Now())
+	return err
+}
+
+// GetParentInventory returns inventory with variants rolled up into their parent SKU
+func (p *PostgresService) GetParentInventory(ctx context.Context, organizationID string, params models.InventoryListParams) ([]*models.ParentInventory, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT g.id, g.sku_code, g.product_name, g.category,
+			COUNT(*) FILTER (WHERE s.parent_sku_id IS NOT NULL),
+			COALESCE(SUM(inv.quantity), 0), COALESCE(SUM(inv.reserved_quantity), 0), COALESCE(SUM(inv.total_value), 0)
+		FROM (
+			SELECT i.sku_id, i.quantity, i.total_value, i.created_at, i.custom_fields,
+				` + reservedQuantitySQL + `
+			FROM inventory i
+			WHERE i.organization_id = $1
+		) inv
+		JOIN skus s ON inv.sku_id = s.id
+		JOIN skus g ON g.id = COALESCE(s.parent_sku_id, s.id)
+		WHERE s.organization_id = $1 AND s.is_active = true
+	`
+	args := []interface{}{organizationID}
+	argIndex := 2
+
+	// Add category filter, including descendant categories
+	query, args, argIndex = addCategoryFilters(query, args, argIndex, "g.category_id", params.CategoryID, params.Category)
+
+	// Add custom field filters
+	query, args, argIndex, err := addCustomFieldFilters(ctx, p.DB, organizationID, "inventory", query, args, argIndex, "inv.custom_fields", params.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add search filter
+	if params.Search != nil && *params.Search != "" {
+		query += fmt.Sprintf(" AND (%s OR %s)", skuSearchSQL("g.", argIndex), skuSearchSQL("s.", argIndex))
+		args = append(args, searchArgs(*params.Search)...)
+		argIndex += 2
+	}
+
+	query += " GROUP BY g.id, g.sku_code, g.product_name, g.category ORDER BY MIN(inv.created_at) DESC"
+
+	// Add pagination
+	if params.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, params.Limit)
+		argIndex++
+
+		if params.Page > 0 {
+			offset := (params.Page - 1) * params.Limit
+			query += fmt.Sprintf(" OFFSET $%d", argIndex)
+			args = append(args, offset)
+		}
+	}
+
+	rows, err := p.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inventory := make([]*models.ParentInventory, 0)
+	for rows.Next() {
+		item := &models.ParentInventory{}
+		err := rows.Scan(
+			&item.SKUID,
+			&item.SKUCode,
+			&item.ProductName,
+			&item.Category,
+			&item.VariantCount,
+			&item.Quantity,
+			&item.ReservedQuantity,
+			&item.TotalValue,
+		)
+		if err != nil {
+			return nil, err
+		}
+		item.AvailableQuantity = item.Quantity - item.ReservedQuantity
+		if item.Quantity > 0 {
+			item.WeightedCost = item.TotalValue / float64(item.Quantity)
+		}
+		inventory = append(inventory, item)
+	}
+
+	return inventory, rows.Err()
+}
+
+// normalizeVariantAttributes trims names and values and rejects empty or repeated entries
+func normalizeVariantAttributes(attributes []models.VariantAttribute) ([]models.VariantAttribute, error) {
+	if len(attributes) == 0 {
+		return nil, fmt.Errorf("invalid variant attributes: at least one attribute is required")
+	}
+
+	normalized := make([]models.VariantAttribute, 0, len(attributes))
+	seenNames := make(map[string]bool)
+	for _, attribute := range attributes {
+		name := strings.ToLower(strings.TrimSpace(attribute.Name))
+		if name == "" || name == "parent" || strings.ContainsAny(name, "{}") {
+			return nil, fmt.Errorf("invalid variant attributes: bad attribute name %q", attribute.Name)
+		}
+		if seenNames[name] {
+			return nil, fmt.Errorf("invalid variant attributes: attribute %s is repeated", name)
+		}
+		seenNames[name] = true
+
+		values := make([]string, 0, len(attribute.Values))
+		seenValues := make(map[string]bool)
+		for _, value := range attribute.Values {
+			value = strings.TrimSpace(value)
+			if value == "" || seenValues[strings.ToLower(value)] {
+				continue
+			}
+			seenValues[strings.ToLower(value)] = true
+			values = append(values, value)
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("invalid variant attributes: attribute %s has no values", name)
+		}
+
+		normalized = append(normalized, models.VariantAttribute{Name: name, Values: values})
+	}
+
+	return normalized, nil
+}
+
+func defaultVariantCodePattern(attributes []models.VariantAttribute) string {
+	parts := []string{models.VariantCodePlaceholderParent}
+	for _, attribute := range attributes {
+		parts = append(parts, "{"+attribute.Name+"}")
+	}
+	return strings.Join(parts, "-")
+}
+
+// validateVariantCodePattern makes sure every attribute appears in the pattern, otherwise
+// two combinations would produce the same code
+func validateVariantCodePattern(pattern string, attributes []models.VariantAttribute) error {
+	lower := strings.ToLower(pattern)
+	for _, attribute := range attributes {
+		if !strings.Contains(lower, "{"+attribute.Name+"}") {
+			return fmt.Errorf("invalid variant attributes: code pattern is missing {%s}", attribute.Name)
+		}
+	}
+	return nil
+}
+
+// variantCombinations returns the cartesian product of the attribute values in
+// attribute order, e.g. S/Red, S/Blue, M/Red, M/Blue
+func variantCombinations(attributes []models.VariantAttribute) []map[string]string {
+	combinations := []map[string]string{{}}
+	for _, attribute := range attributes {
+		next := make([]map[string]string, 0, len(combinations)*len(attribute.Values))
+		for _, combination := range combinations {
+			for _, value := range attribute.Values {
+				values := make(map[string]string, len(combination)+1)
+				for name, v := range combination {
+					values[name] = v
+				}
+				values[attribute.Name] = value
+				next = append(next, values)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+func variantKey(attributes []models.VariantAttribute, values map[string]string) string {
+	parts := make([]string, 0, len(attributes))
+	for _, attribute := range attributes {
+		parts = append(parts, attribute.Name+"="+strings.ToLower(values[attribute.Name]))
+	}
+	return strings.Join(parts, "|")
+}
+
+// buildVariantCode fills the pattern's placeholders. Attribute values are upper-cased and
+// stripped to letters and digits so "Navy Blue" becomes "NAVYBLUE".
+func buildVariantCode(pattern, parentCode string, values map[string]string) string {
+	code := replaceFold(pattern, models.VariantCodePlaceholderParent, parentCode)
+	for name, value := range values {
+		code = replaceFold(code, "{"+name+"}", variantCodeSegment(value))
+	}
+	return code
+}
+
+func variantCodeSegment(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(value) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// replaceFold replaces every case-insensitive occurrence of old in s
+func replaceFold(s, old, new string) string {
+	lower := strings.ToLower(s)
+	old = strings.ToLower(old)
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, old)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:i])
+		b.WriteString(new)
+		s = s[i+len(old):]
+		lower = lower[i+len(old):]
+	}
+}
@@ -0,0 +1,423 @@
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/models"
+	"flex-erp-poc/internal/utils"
+
+	"github.com/lib/pq"
+)
+
+const (
+	maxWebhookEndpoints      = 20 // per organization
+	defaultWebhookDeliveries = 50
+	maxWebhookDeliveries     = 200
+)
+
+const webhookEndpointColumns = `id, organization_id, url, description, events, is_active, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, endpoint_id, event_id, event_type, status, attempts, next_attempt_at, last_attempt_at,
+	response_status, response_body, error, redelivery_of, created_at, payload`
+
+func scanWebhookEndpoint(row rowScanner) (*models.WebhookEndpoint, error) {
+	endpoint := &models.WebhookEndpoint{}
+	err := row.Scan(
+		&endpoint.ID,
+		&endpoint.OrganizationID,
+		&endpoint.URL,
+		&endpoint.Description,
+		pq.Array(&endpoint.Events),
+		&endpoint.IsActive,
+		&endpoint.CreatedAt,
+		&endpoint.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Events == nil {
+		endpoint.Events = []string{}
+	}
+	return endpoint, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{}
+	var payload []byte
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.EndpointID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastAttemptAt,
+		&delivery.ResponseStatus,
+		&delivery.ResponseBody,
+		&delivery.Error,
+		&delivery.RedeliveryOf,
+		&delivery.CreatedAt,
+		&payload,
+	)
+	if err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+	return delivery, nil
+}
+
+// newWebhookSecret returns the key endpoints use to verify signatures
+func newWebhookSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// Webhook Endpoint Methods
+
+func (p *PostgresService) GetWebhookEndpoints(ctx context.Context, organizationID string) ([]*models.WebhookEndpoint, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, `
+		SELECT `+webhookEndpointColumns+`
+		FROM webhook_endpoints
+		WHERE organization_id = $1
+		ORDER BY created_at, id
+	`, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	endpoints := make([]*models.WebhookEndpoint, 0)
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, rows.Err()
+}
+
+func (p *PostgresService) GetWebhookEndpoint(ctx context.Context, organizationID, endpointID string) (*models.WebhookEndpoint, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if !isUUID(endpointID) {
+		return nil, fmt.Errorf("webhook endpoint not found")
+	}
+	endpoint, err := scanWebhookEndpoint(p.DB.QueryRowContext(ctx, `
+		SELECT `+webhookEndpointColumns+` FROM webhook_endpoints WHERE organization_id = $1 AND id = $2
+	`, organizationID, endpointID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("webhook endpoint not found")
+	}
+	return endpoint, err
+}
+
+// CreateWebhookEndpoint registers an endpoint and returns it with its signing
+// secret, which is not shown again
+func (p *PostgresService) CreateWebhookEndpoint(ctx context.Context, organizationID string, req models.CreateWebhookEndpointRequest) (*models.WebhookEndpoint, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	endpoint := &models.WebhookEndpoint{
+		URL:         strings.TrimSpace(req.URL),
+		Description: strings.TrimSpace(req.Description),
+		Events:      req.Events,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+	if err := validateWebhookEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	count, err := countRows(ctx, p.DB, `FROM webhook_endpoints WHERE organization_id = $1`, []interface{}{organizationID})
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxWebhookEndpoints {
+		return nil, fmt.Errorf("invalid webhook endpoint: at most %d endpoints are allowed", maxWebhookEndpoints)
+	}
+
+	secret := newWebhookSecret()
+	now := time.Now()
+	endpoint, err = scanWebhookEndpoint(p.DB.QueryRowContext(ctx, `
+		INSERT INTO webhook_endpoints (organization_id, url, description, events, secret, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		RETURNING `+webhookEndpointColumns,
+		organizationID, endpoint.URL, endpoint.Description, pq.Array(endpoint.Events), secret, endpoint.IsActive, now))
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = secret
+	return endpoint, nil
+}
+
+func (p *PostgresService) UpdateWebhookEndpoint(ctx context.Context, organizationID, endpointID string, req models.UpdateWebhookEndpointRequest) (*models.WebhookEndpoint, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	endpoint, err := p.GetWebhookEndpoint(ctx, organizationID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		endpoint.URL = strings.TrimSpace(*req.URL)
+	}
+	if req.Description != nil {
+		endpoint.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Events != nil {
+		endpoint.Events = req.Events
+	}
+	if req.IsActive != nil {
+		endpoint.IsActive = *req.IsActive
+	}
+	if err := validateWebhookEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	// An empty secret keeps the current one
+	secret := ""
+	if req.RotateSecret {
+		secret = newWebhookSecret()
+	}
+
+	endpoint, err = scanWebhookEndpoint(p.DB.QueryRowContext(ctx, `
+		UPDATE webhook_endpoints
+		SET url = $3, description = $4, events = $5, is_active = $6,
+			secret = COALESCE(NULLIF($7, ''), secret), updated_at = $8
+		WHERE organization_id = $1 AND id = $2
+		RETURNING `+webhookEndpointColumns,
+		organizationID, endpointID, endpoint.URL, endpoint.Description, pq.Array(endpoint.Events), endpoint.IsActive, secret, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = secret
+	return endpoint, nil
+}
+
+// DeleteWebhookEndpoint removes the endpoint along with its delivery log
+func (p *PostgresService) DeleteWebhookEndpoint(ctx context.Context, organizationID, endpointID string) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if !isUUID(endpointID) {
+		return fmt.Errorf("webhook endpoint not found")
+	}
+	result, err := p.DB.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE organization_id = $1 AND id = $2`, organizationID, endpointID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("webhook endpoint not found")
+	}
+	return nil
+}
+
+func validateWebhookEndpoint(endpoint *models.WebhookEndpoint) error {
+	parsed, err := url.Parse(endpoint.URL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return fmt.Errorf("invalid webhook endpoint: url must be an absolute http or https URL")
+	}
+	if parsed.User != nil {
+		return fmt.Errorf("invalid webhook endpoint: url must not contain credentials")
+	}
+	// Deliveries must not reach the server's own network; the dispatcher checks
+	// the resolved address again when it connects
+	if !utils.IsPublicHost(parsed.Hostname()) {
+		return fmt.Errorf("invalid webhook endpoint: url must not point to a private or local address")
+	}
+	if len(endpoint.URL) > 2000 {
+		return fmt.Errorf("invalid webhook endpoint: url must be at most 2000 characters")
+	}
+	if len(endpoint.Description) > 255 {
+		return fmt.Errorf("invalid webhook endpoint: description must be at most 255 characters")
+	}
+
+	if len(endpoint.Events) == 0 {
+		return fmt.Errorf("invalid webhook endpoint: at least one event is required")
+	}
+	seen := make(map[string]bool, len(endpoint.Events))
+	for _, event := range endpoint.Events {
+		if (event != "*" && !containsString(models.WebhookEvents, event)) || seen[event] {
+			return fmt.Errorf("invalid webhook endpoint: events must be unique and one of *, %s", strings.Join(models.WebhookEvents, ", "))
+		}
+		seen[event] = true
+	}
+	return nil
+}
+
+// Webhook Delivery Methods
+
+func (p *PostgresService) GetWebhookDeliveries(ctx context.Context, organizationID, endpointID string, params models.WebhookDeliveryListParams) ([]*models.WebhookDelivery, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := p.GetWebhookEndpoint(ctx, organizationID, endpointID); err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE organization_id = $1 AND endpoint_id = $2`
+	args := []interface{}{organizationID, endpointID}
+	if params.Status != nil {
+		if !containsString(models.WebhookDeliveryStatuses, *params.Status) {
+			return nil, fmt.Errorf("invalid status: expected one of %s", strings.Join(models.WebhookDeliveryStatuses, ", "))
+		}
+		args = append(args, *params.Status)
+		query += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultWebhookDeliveries
+	}
+	args = append(args, min(limit, maxWebhookDeliveries))
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id LIMIT $%d", len(args))
+
+	rows, err := p.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]*models.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// RedeliverWebhook queues the payload of an earlier delivery again, as a new
+// delivery the dispatcher sends on its next run
+func (p *PostgresService) RedeliverWebhook(ctx context.Context, organizationID, endpointID, deliveryID string) (*models.WebhookDelivery, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if !isUUID(endpointID) || !isUUID(deliveryID) {
+		return nil, fmt.Errorf("webhook delivery not found")
+	}
+	delivery, err := scanWebhookDelivery(p.DB.QueryRowContext(ctx, `
+		INSERT INTO webhook_deliveries (organization_id, endpoint_id, event_id, event_type, payload, status, next_attempt_at, redelivery_of)
+		SELECT organization_id, endpoint_id, event_id, event_type, payload, 'pending', now(), id
+		FROM webhook_deliveries
+		WHERE organization_id = $1 AND endpoint_id = $2 AND id = $3
+		RETURNING `+webhookDeliveryColumns,
+		organizationID, endpointID, deliveryID))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("webhook delivery not found")
+	}
+	return delivery, err
+}
+
+// ClaimWebhookDeliveries picks up to limit due deliveries for sending and counts
+// the attempt. Claimed deliveries are not due again until lease has passed, so a
+// dispatcher that dies mid-send has them retried instead of lost.
+func (p *PostgresService) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.PendingWebhookDelivery, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, `
+		WITH due AS (
+			SELECT d.id
+			FROM webhook_deliveries d
+			JOIN webhook_endpoints e ON e.id = d.endpoint_id AND e.is_active
+			WHERE d.status = 'pending' AND d.next_attempt_at <= now()
+			ORDER BY d.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1, last_attempt_at = now(), next_attempt_at = now() + make_interval(secs => $2)
+		FROM due, webhook_endpoints e
+		WHERE d.id = due.id AND e.id = d.endpoint_id
+		RETURNING d.id, d.event_type, d.payload, d.attempts, e.url, e.secret
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]*models.PendingWebhookDelivery, 0)
+	for rows.Next() {
+		delivery := &models.PendingWebhookDelivery{}
+		if err := rows.Scan(&delivery.ID, &delivery.EventType, &delivery.Payload, &delivery.Attempts, &delivery.URL, &delivery.Secret); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+// RecordWebhookAttempt stores the outcome of sending a claimed delivery
+func (p *PostgresService) RecordWebhookAttempt(ctx context.Context, deliveryID string, attempt models.WebhookAttempt) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	status := "pending"
+	switch {
+	case attempt.Succeeded:
+		status = "succeeded"
+	case attempt.NextAttemptAt == nil:
+		status = "failed"
+	}
+	if attempt.Succeeded {
+		attempt.NextAttemptAt = nil
+	}
+
+	_, err := p.DB.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, next_attempt_at = $3, response_status = $4, response_body = $5, error = $6
+		WHERE id = $1
+	`, deliveryID, status, attempt.NextAttemptAt, attempt.ResponseStatus, attempt.ResponseBody, attempt.Error)
+	return err
+}
+
+// Webhook Events
+
+// QueueWebhookEvent writes one pending delivery per active endpoint subscribed
+// to the event's type. It is called for each published outbox event, and an
+// event published again does not queue a second delivery.
+func (p *PostgresService) QueueWebhookEvent(ctx context.Context, outboxEvent *models.OutboxEvent) error {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	if !containsString(models.WebhookEvents, outboxEvent.Type) {
+		return nil
+	}
+	event := models.WebhookEvent{
+		ID:             outboxEvent.ID,
+		Type:           outboxEvent.Type,
+		OrganizationID: outboxEvent.OrganizationID,
+		CreatedAt:      outboxEvent.CreatedAt.UTC(),
+		Data:           outboxEvent.Data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.DB.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (organization_id, endpoint_id, event_id, event_type, payload, status, next_attempt_at)
+		SELECT $1, id, $2, $3, $4, 'pending', now()
+		FROM webhook_endpoints
+		WHERE organization_id = $1 AND is_active AND ($3 = ANY(events) OR '*' = ANY(events))
+		ON CONFLICT (endpoint_id, event_id) WHERE redelivery_of IS NULL DO NOTHING
+	`, event.OrganizationID, event.ID, event.Type, payload)
+	return err
+}
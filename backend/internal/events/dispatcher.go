@@ -0,0 +1,158 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"flex-erp-poc/internal/models"
+	"flex-erp-poc/internal/utils"
+)
+
+const (
+	batchSize = 100 // events per organization per claim
+
+	// claimLease is how long a claim holds an organization's events. A batch stops
+	// taking new events after batchTimeout, which leaves a sink that is slow to
+	// answer the rest of the lease to do so before the events are claimed again.
+	claimLease   = 2 * time.Minute
+	batchTimeout = time.Minute
+)
+
+// Retention is how long published events are kept, for troubleshooting and for
+// live streams to resume from, before the cleanup job deletes them
+const Retention = 7 * 24 * time.Hour
+
+// Outbox is the store of events to publish. PostgresService implements it.
+type Outbox interface {
+	DueOutboxOrganizations(ctx context.Context) ([]string, error)
+	ClaimOutboxEvents(ctx context.Context, organizationID string, limit int, lease time.Duration) ([]*models.OutboxEvent, error)
+	MarkOutboxEventsPublished(ctx context.Context, events []*models.OutboxEvent) error
+	RetryOutboxEvent(ctx context.Context, event *models.OutboxEvent, publishErr error, at time.Time) error
+}
+
+// Dispatcher publishes outbox events to every sink, in order per organization,
+// and then to the bus
+type Dispatcher struct {
+	DB    Outbox
+	Sinks []Sink
+	Bus   *Bus // optional
+}
+
+func NewDispatcher(db Outbox, bus *Bus, sinks ...Sink) *Dispatcher {
+	return &Dispatcher{DB: db, Bus: bus, Sinks: sinks}
+}
+
+// Run publishes pending events every interval until the process exits
+func (d *Dispatcher) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := d.PublishPending(context.Background()); err != nil {
+			log.Printf("Failed to publish outbox events: %v", err)
+		}
+	}
+}
+
+// PublishPending publishes the events that are due, round by round until none
+// are left, and returns how many it published
+func (d *Dispatcher) PublishPending(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		organizationIDs, err := d.DB.DueOutboxOrganizations(ctx)
+		if err != nil {
+			return published, err
+		}
+		round := 0
+		for _, organizationID := range organizationIDs {
+			n, err := d.publishOrganization(ctx, organizationID)
+			round += n
+			if err != nil {
+				return published + round, err
+			}
+		}
+		published += round
+		if round == 0 {
+			return published, nil
+		}
+	}
+}
+
+// publishOrganization claims a batch of the organization's events and publishes
+// them in order. The batch stops at the first failure, which is retried with
+// backoff before any later event of the organization is published. Sinks are
+// called outside any database transaction; the outcome is recorded afterwards,
+// and the bus only gets the events once it is.
+func (d *Dispatcher) publishOrganization(ctx context.Context, organizationID string) (int, error) {
+	events, err := d.DB.ClaimOutboxEvents(ctx, organizationID, batchSize, claimLease)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	deadline := time.Now().Add(batchTimeout)
+	published := len(events)
+	var publishErr error
+	for i, event := range events {
+		if ctx.Err() != nil || time.Now().After(deadline) {
+			published = i
+			break
+		}
+		if publishErr = d.publish(event); publishErr != nil {
+			published = i
+			break
+		}
+	}
+
+	// Record what was published even when ctx ended mid-batch
+	ctx = context.WithoutCancel(ctx)
+	if published > 0 {
+		if err := d.DB.MarkOutboxEventsPublished(ctx, events[:published]); err != nil {
+			return 0, err
+		}
+		d.fanOut(events[:published])
+	}
+	if published < len(events) {
+		retryAt := time.Now()
+		if publishErr != nil {
+			retryAt = retryAt.Add(utils.Backoff(retryBase, retryLimit, events[published].Attempts+1))
+		}
+		if err := d.DB.RetryOutboxEvent(ctx, events[published], publishErr, retryAt); err != nil {
+			return published, err
+		}
+	}
+	return published, nil
+}
+
+// An organization's events are published again after 5s, 10s, 20s and so on,
+// capped at 10m
+const (
+	retryBase  = 5 * time.Second
+	retryLimit = 10 * time.Minute
+)
+
+// fanOut hands published events to the bus. Its subscribers only miss out when
+// they fail, as the events are not published again.
+func (d *Dispatcher) fanOut(events []*models.OutboxEvent) {
+	if d.Bus == nil {
+		return
+	}
+	for _, event := range events {
+		if err := d.Bus.Publish(event); err != nil {
+			log.Printf("Failed to hand event %s (%s) to the bus: %v", event.ID, event.Type, err)
+		}
+	}
+}
+
+// publish hands the event to each sink in turn. A failure stops there, and the
+// retry offers the event to every sink again.
+func (d *Dispatcher) publish(event *models.OutboxEvent) error {
+	for _, sink := range d.Sinks {
+		if err := sink.Publish(event); err != nil {
+			log.Printf("Failed to publish event %s (%s) to %s: %v", event.ID, event.Type, sink.Name(), err)
+			return fmt.Errorf("%s: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"flex-erp-poc/internal/models"
+	"flex-erp-poc/internal/utils"
+)
+
+// memoryOutbox keeps each organization's pending events in publish order, and
+// when the first of them is next due
+type memoryOutbox struct {
+	pending   map[string][]*models.OutboxEvent
+	due       map[string]time.Time
+	position  int64
+	published []*models.OutboxEvent
+	errors    map[int64]string // last error by sequence
+	markErr   error
+}
+
+func newMemoryOutbox(events ...*models.OutboxEvent) *memoryOutbox {
+	o := &memoryOutbox{pending: map[string][]*models.OutboxEvent{}, due: map[string]time.Time{}, errors: map[int64]string{}}
+	for i, event := range events {
+		event.Sequence = int64(i + 1)
+		o.pending[event.OrganizationID] = append(o.pending[event.OrganizationID], event)
+	}
+	return o
+}
+
+func (o *memoryOutbox) DueOutboxOrganizations(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	organizationIDs := make([]string, 0)
+	for _, organizationID := range []string{"org-a", "org-b"} {
+		if len(o.pending[organizationID]) > 0 && !o.due[organizationID].After(time.Now()) {
+			organizationIDs = append(organizationIDs, organizationID)
+		}
+	}
+	return organizationIDs, nil
+}
+
+func (o *memoryOutbox) ClaimOutboxEvents(ctx context.Context, organizationID string, limit int, lease time.Duration) ([]*models.OutboxEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	pending := o.pending[organizationID]
+	if len(pending) == 0 || o.due[organizationID].After(time.Now()) {
+		return nil, nil
+	}
+	events := pending[:min(limit, len(pending))]
+	for _, event := range events {
+		o.position++
+		event.Position = o.position
+	}
+	o.due[organizationID] = time.Now().Add(lease)
+	return events, nil
+}
+
+func (o *memoryOutbox) MarkOutboxEventsPublished(ctx context.Context, events []*models.OutboxEvent) error {
+	if o.markErr != nil {
+		return o.markErr
+	}
+	for _, event := range events {
+		pending := o.pending[event.OrganizationID]
+		if len(pending) == 0 || pending[0] != event {
+			return fmt.Errorf("event %s marked out of order", event.ID)
+		}
+		o.pending[event.OrganizationID] = pending[1:]
+		o.published = append(o.published, event)
+	}
+	delete(o.due, events[0].OrganizationID)
+	return nil
+}
+
+func (o *memoryOutbox) RetryOutboxEvent(ctx context.Context, event *models.OutboxEvent, publishErr error, at time.Time) error {
+	if publishErr != nil {
+		event.Attempts++
+		o.errors[event.Sequence] = publishErr.Error()
+	}
+	o.due[event.OrganizationID] = at
+	return nil
+}
+
+// recordingSink records the IDs of the events it receives, failing those in fail
+type recordingSink struct {
+	received []string
+	fail     map[string]bool
+}
+
+func (s *recordingSink) Name() string {
+	return "recording"
+}
+
+func (s *recordingSink) Publish(event *models.OutboxEvent) error {
+	s.received = append(s.received, event.ID)
+	if s.fail[event.ID] {
+		return errors.New("unavailable")
+	}
+	return nil
+}
+
+func event(organizationID, id string) *models.OutboxEvent {
+	return &models.OutboxEvent{ID: id, OrganizationID: organizationID, Type: "sku.created"}
+}
+
+func ids(events []*models.OutboxEvent) []string {
+	ids := make([]string, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestPublishPendingInOrder(t *testing.T) {
+	outbox := newMemoryOutbox(event("org-a", "a1"), event("org-b", "b1"), event("org-a", "a2"), event("org-a", "a3"))
+	sink := &recordingSink{}
+
+	published, err := NewDispatcher(outbox, nil, sink).PublishPending(context.Background())
+	if err != nil {
+		t.Fatalf("PublishPending: %v", err)
+	}
+	if published != 4 {
+		t.Errorf("published %d, want 4", published)
+	}
+	if want := []string{"a1", "a2", "a3", "b1"}; !reflect.DeepEqual(sink.received, want) {
+		t.Errorf("sink received %v, want %v", sink.received, want)
+	}
+	for i, event := range outbox.published {
+		if i > 0 && event.Position <= outbox.published[i-1].Position {
+			t.Errorf("%s has position %d after %d", event.ID, event.Position, outbox.published[i-1].Position)
+		}
+	}
+}
+
+func TestPublishPendingStopsAtFailure(t *testing.T) {
+	outbox := newMemoryOutbox(event("org-a", "a1"), event("org-a", "a2"), event("org-a", "a3"), event("org-b", "b1"))
+	first := &recordingSink{}
+	second := &recordingSink{fail: map[string]bool{"a2": true}}
+	dispatcher := NewDispatcher(outbox, nil, first, second)
+
+	before := time.Now()
+	published, err := dispatcher.PublishPending(context.Background())
+	if err != nil {
+		t.Fatalf("PublishPending: %v", err)
+	}
+	if published != 2 {
+		t.Errorf("published %d, want 2", published)
+	}
+	// a3 waits for a2, whichever sink failed; org-b is not held up
+	if want := []string{"a1", "a2", "b1"}; !reflect.DeepEqual(first.received, want) {
+		t.Errorf("first sink received %v, want %v", first.received, want)
+	}
+	if want := []string{"a1", "b1"}; !reflect.DeepEqual(ids(outbox.published), want) {
+		t.Errorf("published %v, want %v", ids(outbox.published), want)
+	}
+	failed := outbox.pending["org-a"][0]
+	if failed.ID != "a2" || failed.Attempts != 1 || outbox.errors[failed.Sequence] != "recording: unavailable" {
+		t.Errorf("failed event %s attempts %d error %q", failed.ID, failed.Attempts, outbox.errors[failed.Sequence])
+	}
+	if due := outbox.due["org-a"]; due.Before(before.Add(utils.Backoff(retryBase, retryLimit, 1))) || due.After(time.Now().Add(utils.Backoff(retryBase, retryLimit, 1))) {
+		t.Errorf("org-a due in %v, want %v", due.Sub(before), utils.Backoff(retryBase, retryLimit, 1))
+	}
+
+	// Nothing more is published before the retry is due
+	published, err = dispatcher.PublishPending(context.Background())
+	if err != nil || published != 0 {
+		t.Errorf("PublishPending before the retry = %d, %v", published, err)
+	}
+
+	// The retry offers the failed event to every sink again, then carries on
+	outbox.due["org-a"] = time.Now()
+	second.fail = nil
+	published, err = dispatcher.PublishPending(context.Background())
+	if err != nil || published != 2 {
+		t.Errorf("PublishPending after the retry = %d, %v", published, err)
+	}
+	if want := []string{"a1", "b1", "a2", "a3"}; !reflect.DeepEqual(ids(outbox.published), want) {
+		t.Errorf("published %v, want %v", ids(outbox.published), want)
+	}
+	if want := []string{"a1", "a2", "b1", "a2", "a3"}; !reflect.DeepEqual(first.received, want) {
+		t.Errorf("first sink received %v, want %v", first.received, want)
+	}
+}
+
+func TestPublishPendingStopsWhenCanceled(t *testing.T) {
+	outbox := newMemoryOutbox(event("org-a", "a1"), event("org-a", "a2"))
+	ctx, cancel := context.WithCancel(context.Background())
+	sink := &recordingSink{}
+	canceling := sinkFunc(func(event *models.OutboxEvent) error {
+		cancel()
+		return nil
+	})
+
+	published, err := NewDispatcher(outbox, nil, sink, canceling).PublishPending(ctx)
+	if published != 1 || err == nil {
+		t.Errorf("PublishPending = %d, %v; want 1 and an error", published, err)
+	}
+	// a1 is recorded as published; a2 is due again at once, without an attempt counted
+	if want := []string{"a1"}; !reflect.DeepEqual(ids(outbox.published), want) {
+		t.Errorf("published %v, want %v", ids(outbox.published), want)
+	}
+	if next := outbox.pending["org-a"][0]; next.ID != "a2" || next.Attempts != 0 || outbox.due["org-a"].After(time.Now()) {
+		t.Errorf("next event %s attempts %d due %v", next.ID, next.Attempts, outbox.due["org-a"])
+	}
+}
+
+func TestBusGetsEventsOnceRecorded(t *testing.T) {
+	outbox := newMemoryOutbox(event("org-a", "a1"), event("org-a", "a2"), event("org-a", "a3"))
+	bus := NewBus()
+	var received []string
+	bus.Subscribe("*", func(event *models.OutboxEvent) error {
+		if len(outbox.published) == 0 || outbox.published[len(outbox.published)-1].Position < event.Position {
+			t.Errorf("bus got %s before it was recorded as published", event.ID)
+		}
+		received = append(received, event.ID)
+		return errors.New("subscriber failed") // logged, not retried
+	})
+	sink := &recordingSink{fail: map[string]bool{"a3": true}}
+	dispatcher := NewDispatcher(outbox, bus, sink)
+
+	outbox.markErr = errors.New("connection reset")
+	if _, err := dispatcher.PublishPending(context.Background()); err == nil {
+		t.Fatal("PublishPending succeeded without recording the events")
+	}
+	if len(received) != 0 {
+		t.Errorf("bus got %v of events that were not recorded", received)
+	}
+
+	// The claim has expired by the time the events are published again
+	outbox.markErr = nil
+	outbox.due["org-a"] = time.Now()
+	published, err := dispatcher.PublishPending(context.Background())
+	if err != nil || published != 2 {
+		t.Errorf("PublishPending = %d, %v", published, err)
+	}
+	if want := []string{"a1", "a2"}; !reflect.DeepEqual(received, want) {
+		t.Errorf("bus got %v, want %v", received, want)
+	}
+}
+
+type sinkFunc func(event *models.OutboxEvent) error
+
+func (f sinkFunc) Name() string {
+	return "func"
+}
+
+func (f sinkFunc) Publish(event *models.OutboxEvent) error {
+	return f(event)
+}
+
+func TestRetrySchedule(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{3, 20 * time.Second},
+		{7, 320 * time.Second},
+		{8, 10 * time.Minute},
+		{50, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := utils.Backoff(retryBase, retryLimit, tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Package events publishes the domain events recorded in the outbox. Events are
+// delivered at least once: a sink that fails has the event, and the ones after it
+// for the same organization, offered again later. Consumers should therefore skip
+// event IDs they have already handled.
+package events
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"flex-erp-poc/internal/models"
+)
+
+// Sink receives published events. Publish returns once the event is stored or
+// handed on; an error has it retried.
+type Sink interface {
+	Name() string
+	Publish(event *models.OutboxEvent) error
+}
+
+// Handler reacts to an event in-process. It runs on the dispatcher goroutine, so
+// slow work should be handed off rather than done inline.
+type Handler func(event *models.OutboxEvent) error
+
+// HandlerSink is a sink that calls a handler in-process, for work such as queueing
+// notifications that must happen for every event: a failure has the event retried
+type HandlerSink struct {
+	name    string
+	handler Handler
+}
+
+func NewHandlerSink(name string, handler Handler) *HandlerSink {
+	return &HandlerSink{name: name, handler: handler}
+}
+
+func (s *HandlerSink) Name() string {
+	return s.name
+}
+
+func (s *HandlerSink) Publish(event *models.OutboxEvent) error {
+	return s.handler(event)
+}
+
+type subscription struct {
+	eventType string
+	handler   Handler
+}
+
+// Bus fans events out to in-process subscribers, such as live streams. The
+// dispatcher hands it events once they are recorded as published, so a position
+// subscribers see can be resumed from, and does not retry subscribers that fail.
+type Bus struct {
+	mu            sync.RWMutex
+	nextID        int
+	subscriptions map[int]subscription
+}
+
+func NewBus() *Bus {
+	return &Bus{subscriptions: make(map[int]subscription)}
+}
+
+func (b *Bus) Name() string {
+	return "bus"
+}
+
+// Subscribe calls handler for events of eventType, or for every event with "*".
+// The returned function removes the subscription.
+func (b *Bus) Subscribe(eventType string, handler Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	b.subscriptions[id] = subscription{eventType: eventType, handler: handler}
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscriptions, id)
+	}
+}
+
+// Publish calls every matching subscriber, even after one fails, and returns
+// their errors together
+func (b *Bus) Publish(event *models.OutboxEvent) error {
+	b.mu.RLock()
+	handlers := make([]Handler, 0, len(b.subscriptions))
+	for _, sub := range b.subscriptions {
+		if sub.eventType == "*" || sub.eventType == event.Type {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("subscriber failed: %w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+	"flex-erp-poc/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// thumbnailSize is the longest side of generated thumbnails, in pixels
+const thumbnailSize = 256
+
+func (h *Handler) GetSKUAttachments(w http.ResponseWriter, r *http.Request) {
+	h.listAttachments(w, r, "sku", mux.Vars(r)["skuId"])
+}
+
+func (h *Handler) UploadSKUAttachment(w http.ResponseWriter, r *http.Request) {
+	h.uploadAttachment(w, r, "sku", mux.Vars(r)["skuId"])
+}
+
+func (h *Handler) GetSKUAttachmentContent(w http.ResponseWriter, r *http.Request) {
+	h.serveAttachment(w, r, "sku", mux.Vars(r)["skuId"], false)
+}
+
+func (h *Handler) GetSKUAttachmentThumbnail(w http.ResponseWriter, r *http.Request) {
+	h.serveAttachment(w, r, "sku", mux.Vars(r)["skuId"], true)
+}
+
+func (h *Handler) DeleteSKUAttachment(w http.ResponseWriter, r *http.Request) {
+	h.deleteAttachment(w, r, "sku", mux.Vars(r)["skuId"])
+}
+
+func (h *Handler) GetTransactionAttachments(w http.ResponseWriter, r *http.Request) {
+	h.listAttachments(w, r, "transaction", mux.Vars(r)["transactionId"])
+}
+
+func (h *Handler) UploadTransactionAttachment(w http.ResponseWriter, r *http.Request) {
+	h.uploadAttachment(w, r, "transaction", mux.Vars(r)["transactionId"])
+}
+
+func (h *Handler) GetTransactionAttachmentContent(w http.ResponseWriter, r *http.Request) {
+	h.serveAttachment(w, r, "transaction", mux.Vars(r)["transactionId"], false)
+}
+
+func (h *Handler) GetTransactionAttachmentThumbnail(w http.ResponseWriter, r *http.Request) {
+	h.serveAttachment(w, r, "transaction", mux.Vars(r)["transactionId"], true)
+}
+
+func (h *Handler) DeleteTransactionAttachment(w http.ResponseWriter, r *http.Request) {
+	h.deleteAttachment(w, r, "transaction", mux.Vars(r)["transactionId"])
+}
+
+func (h *Handler) respondWithAttachmentError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	switch err.Error() {
+	case "SKU not found", "transaction not found", "attachment not found":
+		message := err.Error()
+		h.respondWithError(w, http.StatusNotFound, strings.ToUpper(message[:1])+message[1:])
+	default:
+		h.respondWithServerError(w, r, err, fallback)
+	}
+}
+
+func (h *Handler) listAttachments(w http.ResponseWriter, r *http.Request, entityType, entityID string) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	attachments, err := h.DB.GetAttachments(r.Context(), organizationID, entityType, entityID)
+	if err != nil {
+		h.respondWithAttachmentError(w, r, err, "Failed to fetch attachments")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, attachments)
+}
+
+// uploadAttachment streams the "file" part of a multipart upload to storage. The
+// type is sniffed from the first bytes of the file, so a renamed executable is
+// rejected whatever the client claims it is.
+func (h *Handler) uploadAttachment(w http.ResponseWriter, r *http.Request, entityType, entityID string) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	if err := h.DB.CheckAttachmentEntity(r.Context(), organizationID, entityType, entityID); err != nil {
+		h.respondWithAttachmentError(w, r, err, "Failed to upload attachment")
+		return
+	}
+
+	maxBytes := h.MaxAttachmentBytes
+	if maxBytes <= 0 {
+		maxBytes = models.DefaultMaxAttachmentBytes
+	}
+	tooLarge := fmt.Sprintf("File exceeds the %d MB upload limit", maxBytes>>20)
+	// Leave room for the multipart headers around the file
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+1<<20)
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Expected a multipart/form-data upload with a file field")
+		return
+	}
+	var part io.Reader
+	var fileName string
+	for {
+		p, err := reader.NextPart()
+		if err != nil {
+			var maxErr *http.MaxBytesError
+			if errors.As(err, &maxErr) {
+				h.respondWithError(w, http.StatusRequestEntityTooLarge, tooLarge)
+				return
+			}
+			h.respondWithError(w, http.StatusBadRequest, "Expected a multipart/form-data upload with a file field")
+			return
+		}
+		if p.FormName() == "file" {
+			part, fileName = p, strings.TrimSpace(filepath.Base(p.FileName()))
+			break
+		}
+	}
+	if fileName == "" || fileName == "." || fileName == "/" {
+		fileName = "upload"
+	}
+	fileName = truncateFileName(fileName, 255)
+
+	buffered := bufio.NewReaderSize(part, 512)
+	head, _ := buffered.Peek(512)
+	if len(head) == 0 {
+		h.respondWithError(w, http.StatusBadRequest, "File is empty")
+		return
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !hasContentType(models.AttachmentContentTypes, contentType) {
+		h.respondWithError(w, http.StatusUnsupportedMediaType,
+			fmt.Sprintf("Unsupported file type %s, expected one of %s", contentType, strings.Join(models.AttachmentContentTypes, ", ")))
+		return
+	}
+
+	storageKey := fmt.Sprintf("%s/%s/%s", organizationID, entityType, newStorageKey())
+	checksum := sha256.New()
+	size, err := h.Storage.Put(storageKey, io.TeeReader(io.LimitReader(buffered, maxBytes+1), checksum))
+	if err != nil || size > maxBytes {
+		h.Storage.Delete(storageKey)
+		var maxErr *http.MaxBytesError
+		if size > maxBytes || errors.As(err, &maxErr) {
+			h.respondWithError(w, http.StatusRequestEntityTooLarge, tooLarge)
+			return
+		}
+		h.respondWithError(w, http.StatusInternalServerError, "Failed to store attachment")
+		return
+	}
+
+	attachment := &models.Attachment{
+		EntityType:  entityType,
+		EntityID:    entityID,
+		FileName:    fileName,
+		ContentType: contentType,
+		SizeBytes:   size,
+		Checksum:    hex.EncodeToString(checksum.Sum(nil)),
+		StorageKey:  storageKey,
+	}
+	if hasContentType(models.ThumbnailContentTypes, contentType) {
+		// A missing thumbnail is not worth failing the upload for
+		thumbnailKey := storageKey + ".thumb.jpg"
+		if err := h.storeThumbnail(storageKey, thumbnailKey); err != nil {
+			log.Printf("Failed to create thumbnail for %s: %v", storageKey, err)
+		} else {
+			attachment.ThumbnailKey = &thumbnailKey
+		}
+	}
+
+	attachment, err = h.DB.CreateAttachment(r.Context(), organizationID, userID, attachment)
+	if err != nil {
+		h.Storage.Delete(storageKey)
+		h.Storage.Delete(storageKey + ".thumb.jpg")
+		h.respondWithServerError(w, r, err, "Failed to save attachment")
+		return
+	}
+
+	reason := fmt.Sprintf("Attached %s (%s, %d bytes)", attachment.FileName, attachment.ContentType, attachment.SizeBytes)
+	h.logAttachmentChange(r.Context(), organizationID, userID, attachment, &attachment.FileName, nil, reason)
+
+	h.respondWithJSON(w, http.StatusCreated, attachment)
+}
+
+func (h *Handler) storeThumbnail(storageKey, thumbnailKey string) error {
+	file, err := h.Storage.Open(storageKey)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	thumbnail, err := utils.Thumbnail(data, thumbnailSize)
+	if err != nil {
+		return err
+	}
+	_, err = h.Storage.Put(thumbnailKey, bytes.NewReader(thumbnail))
+	return err
+}
+
+func (h *Handler) serveAttachment(w http.ResponseWriter, r *http.Request, entityType, entityID string, thumbnail bool) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	attachment, err := h.DB.GetAttachment(r.Context(), organizationID, entityType, entityID, mux.Vars(r)["attachmentId"])
+	if err != nil {
+		h.respondWithAttachmentError(w, r, err, "Failed to fetch attachment")
+		return
+	}
+
+	key, contentType, disposition := attachment.StorageKey, attachment.ContentType, "attachment"
+	if thumbnail {
+		if attachment.ThumbnailKey == nil {
+			h.respondWithError(w, http.StatusNotFound, "Attachment has no thumbnail")
+			return
+		}
+		key, contentType = *attachment.ThumbnailKey, "image/jpeg"
+	}
+	if strings.HasPrefix(contentType, "image/") || contentType == "application/pdf" {
+		disposition = "inline"
+	}
+
+	file, err := h.Storage.Open(key)
+	if err != nil {
+		log.Printf("Failed to open stored file %s: %v", key, err)
+		h.respondWithServerError(w, r, err, "Failed to read attachment")
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if !thumbnail {
+		w.Header().Set("Content-Length", strconv.FormatInt(attachment.SizeBytes, 10))
+	}
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, file)
+}
+
+func (h *Handler) deleteAttachment(w http.ResponseWriter, r *http.Request, entityType, entityID string) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	attachment, err := h.DB.DeleteAttachment(r.Context(), organizationID, entityType, entityID, mux.Vars(r)["attachmentId"])
+	if err != nil {
+		h.respondWithAttachmentError(w, r, err, "Failed to delete attachment")
+		return
+	}
+
+	// The metadata is gone, so leftover files are only logged
+	keys := []string{attachment.StorageKey}
+	if attachment.ThumbnailKey != nil {
+		keys = append(keys, *attachment.ThumbnailKey)
+	}
+	for _, key := range keys {
+		if err := h.Storage.Delete(key); err != nil {
+			log.Printf("Failed to delete stored file %s: %v", key, err)
+		}
+	}
+
+	h.logAttachmentChange(r.Context(), organizationID, userID, attachment, nil, &attachment.FileName, "Removed attachment "+attachment.FileName)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) logAttachmentChange(ctx context.Context, organizationID, userID string, attachment *models.Attachment, newValue, oldValue *string, reason string) {
+	var logReq *models.CreateChangeLogRequest
+	if attachment.EntityType == "sku" {
+		logReq = models.NewSKUChangeLog(organizationID, userID, attachment.EntityID, "update")
+	} else {
+		logReq = models.NewChangeLog(organizationID, userID, "transaction", "update")
+		logReq.EntityID = &attachment.EntityID
+	}
+	fieldName := "attachments"
+	logReq.FieldName = &fieldName
+	logReq.NewValue = newValue
+	logReq.OldValue = oldValue
+	logReq.Reason = &reason
+	h.Store.LogChange(ctx, organizationID, userID, *logReq)
+}
+
+// truncateFileName keeps the last max bytes of a file name, which hold its
+// extension, starting on a whole character. Invalid UTF-8 is replaced, as
+// Postgres would reject it.
+func truncateFileName(name string, max int) string {
+	name = strings.ToValidUTF8(name, "\uFFFD")
+	if len(name) <= max {
+		return name
+	}
+	start := len(name) - max
+	for start < len(name) && !utf8.RuneStart(name[start]) {
+		start++
+	}
+	return name[start:]
+}
+
+func hasContentType(types []string, contentType string) bool {
+	for _, allowed := range types {
+		if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// newStorageKey returns a random name, so stored files cannot be found by guessing
+func newStorageKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
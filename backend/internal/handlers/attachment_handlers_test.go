@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateFileName(t *testing.T) {
+	tests := []struct {
+		name, fileName string
+		max            int
+		want           string
+	}{
+		{"short", "photo.jpg", 255, "photo.jpg"},
+		{"ascii keeps the extension", "abcdef.png", 7, "def.png"},
+		{"cut on a character boundary", "ñandú.pdf", 9, "andú.pdf"},
+		{"cut inside a character moves past it", "ñandú.pdf", 10, "andú.pdf"},
+		{"cut inside the last character", "ñandú.pdf", 5, ".pdf"},
+		{"invalid UTF-8 is replaced", "bad\xff.txt", 255, "bad�.txt"},
+	}
+	for _, tt := range tests {
+		got := truncateFileName(tt.fileName, tt.max)
+		if got != tt.want {
+			t.Errorf("%s: truncateFileName(%q, %d) = %q, want %q", tt.name, tt.fileName, tt.max, got, tt.want)
+		}
+	}
+
+	long := strings.Repeat("日本", 100) + ".jpg" // 604 bytes
+	got := truncateFileName(long, 255)
+	if !utf8.ValidString(got) || len(got) > 255 || !strings.HasSuffix(got, ".jpg") {
+		t.Errorf("truncateFileName(long) = %q (%d bytes), want valid UTF-8 of at most 255 bytes ending in .jpg", got, len(got))
+	}
+}
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// barcodeConflict returns the message for a barcode already in use. The unique
+// indexes report it too when two saves of the same code race.
+func barcodeConflict(err error) (string, bool) {
+	switch {
+	case strings.HasPrefix(err.Error(), "duplicate barcode"):
+		return err.Error(), true
+	case strings.Contains(err.Error(), "idx_skus_org_barcode_unique"),
+		strings.Contains(err.Error(), "sku_barcodes_organization_id_barcode_key"):
+		return "Barcode is already used by another SKU", true
+	}
+	return "", false
+}
+
+func (h *Handler) LookupBarcode(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	match, err := h.DB.LookupBarcode(r.Context(), organizationID, mux.Vars(r)["barcode"])
+	if err != nil {
+		if err.Error() == "barcode not found" {
+			h.respondWithError(w, http.StatusNotFound, "Barcode not found")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to look up barcode")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, match)
+}
+
+func (h *Handler) GetSKUBarcodes(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	skuID := mux.Vars(r)["skuId"]
+	if skuID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid SKU ID")
+		return
+	}
+
+	barcodes, err := h.DB.GetSKUBarcodes(r.Context(), organizationID, skuID)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch barcodes")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, barcodes)
+}
+
+func (h *Handler) CreateSKUBarcode(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	skuID := mux.Vars(r)["skuId"]
+	if skuID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid SKU ID")
+		return
+	}
+
+	var req models.CreateSKUBarcodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	barcode, err := h.DB.CreateSKUBarcode(r.Context(), organizationID, skuID, req)
+	if err != nil {
+		if message, ok := barcodeConflict(err); ok {
+			h.respondWithError(w, http.StatusConflict, message)
+			return
+		}
+		switch {
+		case err.Error() == "SKU not found":
+			h.respondWithError(w, http.StatusNotFound, "SKU not found")
+		case strings.HasPrefix(err.Error(), "invalid barcode"):
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.respondWithServerError(w, r, err, "Failed to add barcode")
+		}
+		return
+	}
+
+	logReq := models.NewSKUChangeLog(organizationID, userID, skuID, "update")
+	fieldName := "barcodes"
+	reason := fmt.Sprintf("Alternate barcode %s added for %s x%d", barcode.Barcode, barcode.UnitOfMeasure, barcode.UnitsPerScan)
+	logReq.FieldName = &fieldName
+	logReq.NewValue = &barcode.Barcode
+	logReq.Reason = &reason
+	h.Store.LogChange(r.Context(), organizationID, userID, *logReq)
+
+	h.respondWithJSON(w, http.StatusCreated, barcode)
+}
+
+func (h *Handler) DeleteSKUBarcode(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	vars := mux.Vars(r)
+	skuID := vars["skuId"]
+	barcodeID := vars["barcodeId"]
+	if skuID == "" || barcodeID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid SKU or barcode ID")
+		return
+	}
+
+	if err := h.DB.DeleteSKUBarcode(r.Context(), organizationID, skuID, barcodeID); err != nil {
+		if err.Error() == "barcode not found" {
+			h.respondWithError(w, http.StatusNotFound, "Barcode not found")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to remove barcode")
+		return
+	}
+
+	logReq := models.NewSKUChangeLog(organizationID, userID, skuID, "update")
+	fieldName := "barcodes"
+	reason := "Alternate barcode removed"
+	logReq.FieldName = &fieldName
+	logReq.Reason = &reason
+	h.Store.LogChange(r.Context(), organizationID, userID, *logReq)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ScanIn(w http.ResponseWriter, r *http.Request) {
+	h.postScan(w, r, "in")
+}
+
+func (h *Handler) ScanOut(w http.ResponseWriter, r *http.Request) {
+	h.postScan(w, r, "out")
+}
+
+// postScan resolves a scanned barcode and posts the transaction for it through the
+// same path as a transaction entered by hand, so business rules still apply
+func (h *Handler) postScan(w http.ResponseWriter, r *http.Request, transactionType string) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req models.ScanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Barcode) == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Barcode is required")
+		return
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if req.Quantity < 0 {
+		h.respondWithError(w, http.StatusBadRequest, "Quantity must be positive")
+		return
+	}
+	if req.UnitCost != nil && *req.UnitCost < 0 {
+		h.respondWithError(w, http.StatusBadRequest, "Unit cost must be non-negative")
+		return
+	}
+	if req.ReservationID != nil && transactionType != "out" {
+		h.respondWithError(w, http.StatusBadRequest, "Only scan-out can consume a reservation")
+		return
+	}
+
+	match, err := h.DB.LookupBarcode(r.Context(), organizationID, req.Barcode)
+	if err != nil {
+		if err.Error() == "barcode not found" {
+			h.respondWithError(w, http.StatusNotFound, "Barcode not found")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to look up barcode")
+		return
+	}
+	if !match.SKU.IsActive {
+		h.respondWithError(w, http.StatusConflict, fmt.Sprintf("SKU %s is deactivated", match.SKU.SKUCode))
+		return
+	}
+
+	// Without a cost the scan moves stock at the current weighted cost
+	unitCost := 0.0
+	if req.UnitCost != nil {
+		unitCost = *req.UnitCost
+	} else if match.Inventory != nil {
+		unitCost = match.Inventory.WeightedCost
+	}
+
+	quantity := req.Quantity * match.UnitsPerScan
+	transaction, err := h.Store.CreateTransaction(r.Context(), organizationID, userID, models.CreateTransactionRequest{
+		SKUID:           match.SKU.ID,
+		TransactionType: transactionType,
+		Quantity:        quantity,
+		UnitCost:        unitCost,
+		ReferenceNumber: req.ReferenceNumber,
+		Notes:           req.Notes,
+		ReservationID:   req.ReservationID,
+		CustomFields:    req.CustomFields,
+	})
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "insufficient inventory") ||
+			strings.HasPrefix(err.Error(), "reservation") ||
+			strings.HasPrefix(err.Error(), "SKU has variants") ||
+			strings.HasPrefix(err.Error(), "invalid custom field values") ||
+			strings.HasPrefix(err.Error(), "business rule violated") {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+		} else {
+			h.respondWithServerError(w, r, err, "Failed to create transaction")
+		}
+		return
+	}
+
+	logReq := models.NewTransactionChangeLog(organizationID, userID, transaction.ID, match.SKU.ID)
+	reason := fmt.Sprintf("%s transaction - %d units scanned as %s (%d x %s)",
+		strings.ToUpper(transactionType), quantity, match.Barcode, req.Quantity, match.UnitOfMeasure)
+	if req.Notes != nil {
+		reason = fmt.Sprintf("%s: %s", reason, *req.Notes)
+	}
+	logReq.Reason = &reason
+	h.Store.LogChange(r.Context(), organizationID, userID, *logReq)
+
+	h.respondWithJSON(w, http.StatusCreated, models.ScanResponse{Match: match, Transaction: transaction})
+}
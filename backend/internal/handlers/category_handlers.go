@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) GetCategories(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var categories []*models.Category
+	var err error
+	if r.URL.Query().Get("tree") == "true" {
+		categories, err = h.DB.GetCategoryTree(r.Context(), organizationID)
+	} else {
+		categories, err = h.DB.GetCategories(r.Context(), organizationID)
+	}
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch categories")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, categories)
+}
+
+func (h *Handler) GetCategory(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	categoryID := mux.Vars(r)["categoryId"]
+	if categoryID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+
+	category, err := h.DB.GetCategoryByID(r.Context(), organizationID, categoryID)
+	if err != nil {
+		if err.Error() == "category not found" {
+			h.respondWithError(w, http.StatusNotFound, "Category not found")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to fetch category")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, category)
+}
+
+func (h *Handler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.CreateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Basic validation
+	if strings.TrimSpace(req.Name) == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Category name is required")
+		return
+	}
+
+	category, err := h.DB.CreateCategory(r.Context(), organizationID, req)
+	if err != nil {
+		h.respondWithCategoryError(w, r, err, "Failed to create category")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, category)
+}
+
+func (h *Handler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	categoryID := mux.Vars(r)["categoryId"]
+	if categoryID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+
+	var req models.UpdateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Basic validation
+	if strings.TrimSpace(req.Name) == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Category name is required")
+		return
+	}
+
+	category, err := h.DB.UpdateCategory(r.Context(), organizationID, userID, categoryID, req)
+	if err != nil {
+		h.respondWithCategoryError(w, r, err, "Failed to update category")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, category)
+}
+
+func (h *Handler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	categoryID := mux.Vars(r)["categoryId"]
+	if categoryID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+
+	if err := h.DB.DeleteCategory(r.Context(), organizationID, categoryID); err != nil {
+		h.respondWithCategoryError(w, r, err, "Failed to delete category")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondWithCategoryError maps category tree errors to responses
+func (h *Handler) respondWithCategoryError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	switch {
+	case err.Error() == "category not found":
+		h.respondWithError(w, http.StatusNotFound, "Category not found")
+	case err.Error() == "parent category not found", err.Error() == "invalid category name":
+		h.respondWithError(w, http.StatusBadRequest, err.Error())
+	case err.Error() == "category has subcategories", err.Error() == "category cannot be moved under itself":
+		h.respondWithError(w, http.StatusConflict, err.Error())
+	case strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint"):
+		h.respondWithError(w, http.StatusConflict, "Category already exists at this path")
+	default:
+		h.respondWithServerError(w, r, err, fallback)
+	}
+}
+
+func (h *Handler) GetCategoryInventoryRollup(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	rollups, err := h.DB.GetCategoryInventoryRollup(r.Context(), organizationID, parseCategoryRollupParams(r))
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch inventory rollup")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, rollups)
+}
+
+func (h *Handler) GetCategoryTransactionRollup(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	rollups, err := h.DB.GetCategoryTransactionRollup(r.Context(), organizationID, parseCategoryRollupParams(r))
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch transaction rollup")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, rollups)
+}
+
+func parseCategoryRollupParams(r *http.Request) models.CategoryRollupParams {
+	params := models.CategoryRollupParams{}
+
+	query := r.URL.Query()
+	if levelStr := query.Get("level"); levelStr != "" {
+		if level, err := strconv.Atoi(levelStr); err == nil && level >= 0 {
+			params.Level = level
+		}
+	}
+	if parentID := query.Get("parent_id"); parentID != "" {
+		params.ParentID = &parentID
+	}
+	if startDate := query.Get("start_date"); startDate != "" {
+		params.StartDate = &startDate
+	}
+	if endDate := query.Get("end_date"); endDate != "" {
+		params.EndDate = &endDate
+	}
+
+	return params
+}
@@ -42,6 +42,10 @@ func (h *Handler) GetChangeLogs(w http.ResponseWriter, r *http.Request) {
 		params.ChangeType = &changeType
 	}
 
+	if batchID := r.URL.Query().Get("batch_id"); batchID != "" {
+		params.BatchID = &batchID
+	}
+
 	if lastDaysStr := r.URL.Query().Get("last_days"); lastDaysStr != "" {
 		if lastDays, err := strconv.Atoi(lastDaysStr); err == nil && lastDays > 0 {
 			params.LastDays = &lastDays
@@ -74,14 +78,21 @@ func (h *Handler) GetChangeLogs(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	changeLogs, err := h.DB.GetChangeLogs(organizationID, params)
+	params.Sort = r.URL.Query().Get("sort")
+	params.Cursor = r.URL.Query().Get("cursor")
+
+	changeLogs, page, err := h.Store.GetChangeLogs(r.Context(), organizationID, params)
 	if err != nil {
-		http.Error(w, "Failed to fetch change logs", http.StatusInternalServerError)
+		if isListParamError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to fetch change logs")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(changeLogs)
+	json.NewEncoder(w).Encode(models.ListResponse{Items: changeLogs, PageInfo: *page})
 }
 
 func (h *Handler) GetSKUChangeLogs(w http.ResponseWriter, r *http.Request) {
@@ -106,9 +117,9 @@ func (h *Handler) GetSKUChangeLogs(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	changeLogs, err := h.DB.GetSKUChangeLogs(orgID, skuIDStr, lastDays)
+	changeLogs, err := h.Store.GetSKUChangeLogs(r.Context(), orgID, skuIDStr, lastDays)
 	if err != nil {
-		http.Error(w, "Failed to fetch SKU change logs", http.StatusInternalServerError)
+		h.respondWithServerError(w, r, err, "Failed to fetch SKU change logs")
 		return
 	}
 
@@ -132,9 +143,9 @@ func (h *Handler) GetActivitySummary(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	summary, err := h.DB.GetActivitySummary(orgID, lastDays)
+	summary, err := h.Store.GetActivitySummary(r.Context(), orgID, lastDays)
 	if err != nil {
-		http.Error(w, "Failed to fetch activity summary", http.StatusInternalServerError)
+		h.respondWithServerError(w, r, err, "Failed to fetch activity summary")
 		return
 	}
 
@@ -199,9 +210,9 @@ func (h *Handler) CreateChangeLog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	changeLog, err := h.DB.CreateChangeLog(orgID, userID, req)
+	changeLog, err := h.Store.CreateChangeLog(r.Context(), orgID, userID, req)
 	if err != nil {
-		http.Error(w, "Failed to create change log", http.StatusInternalServerError)
+		h.respondWithServerError(w, r, err, "Failed to create change log")
 		return
 	}
 
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+)
+
+func (h *Handler) GetBusinessRules(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	rules, err := h.DB.GetBusinessRules(r.Context(), organizationID)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch business rules")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, rules)
+}
+
+func (h *Handler) UpdateBusinessRules(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.BusinessRules
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	rules, err := h.DB.UpdateBusinessRules(r.Context(), organizationID, req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid business rules") {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to update business rules")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, rules)
+}
+
+// GET /config/export
+func (h *Handler) ExportConfigBundle(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	bundle, err := h.DB.ExportConfigBundle(r.Context(), organizationID)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to export configuration")
+		return
+	}
+
+	filename := fmt.Sprintf("config-bundle-%s.json", bundle.ExportedAt.Format(time.DateOnly))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	h.respondWithJSON(w, http.StatusOK, bundle)
+}
+
+// POST /config/import returns the diff, and applies it when "apply" is set
+func (h *Handler) ImportConfigBundle(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.ImportConfigBundleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.DB.ImportConfigBundle(r.Context(), organizationID, req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid config bundle") {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to import configuration")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, result)
+}
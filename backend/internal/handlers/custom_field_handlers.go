@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// customFieldFilterPrefix marks list query parameters that filter on custom fields,
+// e.g. cf.shelf=A1 or cf.weight.min=5
+const customFieldFilterPrefix = "cf."
+
+func parseCustomFieldFilters(query url.Values) map[string]string {
+	filters := make(map[string]string)
+	for key, values := range query {
+		if name := strings.TrimPrefix(key, customFieldFilterPrefix); name != key && name != "" && len(values) > 0 {
+			filters[name] = values[0]
+		}
+	}
+	if len(filters) == 0 {
+		return nil
+	}
+	return filters
+}
+
+func (h *Handler) GetCustomFields(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	fields, err := h.DB.GetCustomFields(r.Context(), organizationID, r.URL.Query().Get("table_name"))
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch custom fields")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, fields)
+}
+
+func (h *Handler) GetCustomField(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	fieldID := mux.Vars(r)["fieldId"]
+	if fieldID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid custom field ID")
+		return
+	}
+
+	field, err := h.DB.GetCustomFieldByID(r.Context(), organizationID, fieldID)
+	if err != nil {
+		if err.Error() == "custom field not found" {
+			h.respondWithError(w, http.StatusNotFound, "Custom field not found")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to fetch custom field")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, field)
+}
+
+func (h *Handler) CreateCustomField(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.CreateCustomFieldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate required fields
+	if req.TableName == "" || req.FieldName == "" || req.DisplayName == "" || req.FieldType == "" {
+		h.respondWithError(w, http.StatusBadRequest, "table_name, field_name, display_name and field_type are required")
+		return
+	}
+
+	field, err := h.DB.CreateCustomField(r.Context(), organizationID, req)
+	if err != nil {
+		switch {
+		case strings.HasPrefix(err.Error(), "invalid custom field"):
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+		case strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint"):
+			h.respondWithError(w, http.StatusConflict, "A custom field with this name already exists for this table")
+		default:
+			h.respondWithServerError(w, r, err, "Failed to create custom field")
+		}
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, field)
+}
+
+func (h *Handler) UpdateCustomField(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	fieldID := mux.Vars(r)["fieldId"]
+	if fieldID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid custom field ID")
+		return
+	}
+
+	var req models.UpdateCustomFieldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	field, err := h.DB.UpdateCustomField(r.Context(), organizationID, fieldID, req)
+	if err != nil {
+		switch {
+		case err.Error() == "custom field not found":
+			h.respondWithError(w, http.StatusNotFound, "Custom field not found")
+		case strings.HasPrefix(err.Error(), "invalid custom field"):
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.respondWithServerError(w, r, err, "Failed to update custom field")
+		}
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, field)
+}
+
+func (h *Handler) DeleteCustomField(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	fieldID := mux.Vars(r)["fieldId"]
+	if fieldID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid custom field ID")
+		return
+	}
+
+	if err := h.DB.DeleteCustomField(r.Context(), organizationID, fieldID); err != nil {
+		if err.Error() == "custom field not found" {
+			h.respondWithError(w, http.StatusNotFound, "Custom field not found")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to delete custom field")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+)
+
+// streamEventResources are the events a live stream carries and the resource a
+// subscriber needs read access to for each
+var streamEventResources = map[string]string{
+	"inventory.updated":   "inventory",
+	"transaction.created": "transactions",
+	"change_log.created":  "logs",
+}
+
+const (
+	streamBuffer       = 256 // live events held for a slow client before it catches up from the database
+	streamReplayLimit  = 1000
+	streamPollInterval = 5 * time.Second // catch-up for events published by other server processes
+	streamKeepAlive    = 25 * time.Second
+	streamRetry        = 3 * time.Second
+)
+
+// streamEventTypes returns the stream events a role may receive
+func streamEventTypes(roleName string) []string {
+	role := models.GetRoleByName(roleName)
+	types := make([]string, 0, len(streamEventResources))
+	for eventType, resource := range streamEventResources {
+		if role != nil && role.HasPermission(resource, "read") {
+			types = append(types, eventType)
+		}
+	}
+	return types
+}
+
+// GET /events/stream sends inventory changes, new transactions and new change log
+// entries as Server-Sent Events once they are committed. Each event's id is its
+// stream position: a client that reconnects with Last-Event-ID (or last_event_id
+// in the query) is sent what it missed. When that is no longer possible a "reset"
+// event tells the client to reload its data before the stream carries on.
+func (h *Handler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	roleName, _ := middleware.GetUserRoleFromContext(r.Context())
+	types := streamEventTypes(roleName)
+	if len(types) == 0 {
+		h.respondWithError(w, http.StatusForbidden, "Insufficient permissions")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.respondWithError(w, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	var position int64
+	if lastEventID != "" {
+		parsed, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || parsed < 0 {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid last event ID")
+			return
+		}
+		position = parsed
+	}
+
+	// Subscribe before reading the backlog so nothing published in between is lost.
+	// A client too slow to keep up drops live events and catches up from the database.
+	allowed := make(map[string]bool, len(types))
+	for _, eventType := range types {
+		allowed[eventType] = true
+	}
+	live := make(chan *models.OutboxEvent, streamBuffer)
+	lagging := make(chan struct{}, 1)
+	unsubscribe := h.Events.Subscribe("*", func(event *models.OutboxEvent) error {
+		if event.OrganizationID != organizationID || !allowed[event.Type] {
+			return nil
+		}
+		select {
+		case live <- event:
+		default:
+			select {
+			case lagging <- struct{}{}:
+			default:
+			}
+		}
+		return nil
+	})
+	defer unsubscribe()
+
+	// Without a last event ID, or with one that has been deleted, the stream starts now
+	reset := position == 0
+	if !reset {
+		exists, err := h.DB.HasEventPosition(r.Context(), organizationID, position)
+		if err != nil {
+			h.respondWithServerError(w, r, err, "Failed to open event stream")
+			return
+		}
+		reset = !exists
+	}
+	if reset {
+		latest, err := h.DB.GetLatestEventPosition(r.Context(), organizationID)
+		if err != nil {
+			h.respondWithServerError(w, r, err, "Failed to open event stream")
+			return
+		}
+		position = latest
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // stop proxies from holding events back
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds())
+	if reset && lastEventID != "" {
+		writeStreamReset(w, position)
+	}
+
+	send := func(event *models.OutboxEvent) {
+		// Skip what the database catch-up already sent
+		if event.Position > position {
+			writeStreamEvent(w, event)
+			position = event.Position
+		}
+	}
+
+	// catchUp sends the live events already received, which were published before
+	// any the database has that this stream has not sent, then the database's. A
+	// client that missed too many is told to reset instead.
+	catchUp := func() error {
+		for {
+			select {
+			case event := <-live:
+				send(event)
+				continue
+			default:
+			}
+
+			backlog, err := h.DB.GetPublishedEvents(r.Context(), organizationID, position, types, streamReplayLimit)
+			if err != nil {
+				return err
+			}
+			if len(backlog) == streamReplayLimit {
+				latest, err := h.DB.GetLatestEventPosition(r.Context(), organizationID)
+				if err != nil {
+					return err
+				}
+				position = latest
+				writeStreamReset(w, position)
+				return nil
+			}
+			for _, event := range backlog {
+				send(event)
+			}
+			return nil
+		}
+	}
+	if !reset {
+		if err := catchUp(); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	poll := time.NewTicker(streamPollInterval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-live:
+			send(event)
+		case <-lagging:
+			if err := catchUp(); err != nil {
+				return
+			}
+		case <-poll.C:
+			if err := catchUp(); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		flusher.Flush()
+	}
+}
+
+// writeStreamReset tells the client it missed events that can no longer be sent,
+// so it should reload what it shows. The stream carries on from position.
+func writeStreamReset(w http.ResponseWriter, position int64) {
+	fmt.Fprintf(w, "id: %d\nevent: reset\ndata: {}\n\n", position)
+}
+
+func writeStreamEvent(w http.ResponseWriter, event *models.OutboxEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Position, event.Type, data)
+}
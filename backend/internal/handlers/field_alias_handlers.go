@@ -44,14 +44,29 @@ func (h *Handler) GetFieldAliases(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	aliases, err := h.DB.GetFieldAliases(orgID, params)
+	params.Sort = r.URL.Query().Get("sort")
+	params.Cursor = r.URL.Query().Get("cursor")
+
+	locales, ok := h.requestLocales(r, orgID)
+	if !ok {
+		http.Error(w, "Invalid locale", http.StatusBadRequest)
+		return
+	}
+	params.Locales = locales
+
+	aliases, page, err := h.Store.GetFieldAliases(r.Context(), orgID, params)
 	if err != nil {
-		http.Error(w, "Failed to fetch field aliases", http.StatusInternalServerError)
+		if isListParamError(err) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to fetch field aliases")
 		return
 	}
 
+	setContentLanguage(w, locales)
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(aliases)
+	json.NewEncoder(w).Encode(models.ListResponse{Items: aliases, PageInfo: *page})
 }
 
 func (h *Handler) CreateFieldAlias(w http.ResponseWriter, r *http.Request) {
@@ -95,13 +110,13 @@ func (h *Handler) CreateFieldAlias(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	alias, err := h.DB.CreateFieldAlias(orgID, req)
+	alias, err := h.Store.CreateFieldAlias(r.Context(), orgID, req)
 	if err != nil {
 		if err.Error() == "duplicate key value violates unique constraint" {
 			http.Error(w, "field alias already exists for this table and field", http.StatusConflict)
 			return
 		}
-		http.Error(w, "Failed to create field alias", http.StatusInternalServerError)
+		h.respondWithServerError(w, r, err, "Failed to create field alias")
 		return
 	}
 
@@ -130,13 +145,13 @@ func (h *Handler) UpdateFieldAlias(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	alias, err := h.DB.UpdateFieldAlias(orgID, aliasID, req)
+	alias, err := h.Store.UpdateFieldAlias(r.Context(), orgID, aliasID, req)
 	if err != nil {
 		if err.Error() == "field alias not found" {
 			http.Error(w, "Field alias not found", http.StatusNotFound)
 			return
 		}
-		http.Error(w, "Failed to update field alias", http.StatusInternalServerError)
+		h.respondWithServerError(w, r, err, "Failed to update field alias")
 		return
 	}
 
@@ -158,13 +173,13 @@ func (h *Handler) DeleteFieldAlias(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.DB.DeleteFieldAlias(orgID, aliasID)
+	err := h.Store.DeleteFieldAlias(r.Context(), orgID, aliasID)
 	if err != nil {
 		if err.Error() == "field alias not found" {
 			http.Error(w, "Field alias not found", http.StatusNotFound)
 			return
 		}
-		http.Error(w, "Failed to delete field alias", http.StatusInternalServerError)
+		h.respondWithServerError(w, r, err, "Failed to delete field alias")
 		return
 	}
 
@@ -198,12 +213,19 @@ func (h *Handler) GetTableFields(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tableFields, err := h.DB.GetTableFields(orgID, tableName)
+	locales, ok := h.requestLocales(r, orgID)
+	if !ok {
+		http.Error(w, "Invalid locale", http.StatusBadRequest)
+		return
+	}
+
+	tableFields, err := h.Store.GetTableFields(r.Context(), orgID, tableName, locales)
 	if err != nil {
-		http.Error(w, "Failed to fetch table fields", http.StatusInternalServerError)
+		h.respondWithServerError(w, r, err, "Failed to fetch table fields")
 		return
 	}
 
+	setContentLanguage(w, locales)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(tableFields)
 }
@@ -235,19 +257,26 @@ func (h *Handler) InitializeTableFields(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	err := h.DB.InitializeDefaultFieldAliases(orgID, tableName)
+	locales, ok := h.requestLocales(r, orgID)
+	if !ok {
+		http.Error(w, "Invalid locale", http.StatusBadRequest)
+		return
+	}
+
+	err := h.Store.InitializeDefaultFieldAliases(r.Context(), orgID, tableName)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to initialize table fields: %s", err.Error()), http.StatusInternalServerError)
+		h.respondWithServerError(w, r, err, fmt.Sprintf("Failed to initialize table fields: %s", err.Error()))
 		return
 	}
 
 	// Return the initialized fields
-	tableFields, err := h.DB.GetTableFields(orgID, tableName)
+	tableFields, err := h.Store.GetTableFields(r.Context(), orgID, tableName, locales)
 	if err != nil {
-		http.Error(w, "Failed to fetch initialized table fields", http.StatusInternalServerError)
+		h.respondWithServerError(w, r, err, "Failed to fetch initialized table fields")
 		return
 	}
 
+	setContentLanguage(w, locales)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(tableFields)
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+	"flex-erp-poc/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// requestLocales builds the chain field labels are resolved through: an explicit
+// ?locale=, then the user's preferred locale, then Accept-Language, then the
+// organization default. Preference lookups that fail are skipped rather than
+// failing the request, since the untranslated label is always available.
+func (h *Handler) requestLocales(r *http.Request, organizationID string) ([]string, bool) {
+	var locales []string
+
+	if locale := r.URL.Query().Get("locale"); locale != "" {
+		normalized, ok := utils.NormalizeLocale(locale)
+		if !ok {
+			return nil, false
+		}
+		locales = append(locales, normalized)
+	}
+
+	if userID, ok := middleware.GetUserIDFromContext(r.Context()); ok {
+		if preferred, err := h.Store.GetUserPreferredLocale(r.Context(), userID); err == nil && preferred != nil {
+			locales = append(locales, *preferred)
+		}
+	}
+
+	locales = append(locales, utils.ParseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+
+	if defaultLocale, err := h.Store.GetOrganizationDefaultLocale(r.Context(), organizationID); err == nil {
+		locales = append(locales, defaultLocale)
+	}
+
+	return utils.LocaleFallbackChain(locales...), true
+}
+
+// setContentLanguage reports the most preferred locale labels were resolved for
+func setContentLanguage(w http.ResponseWriter, locales []string) {
+	if len(locales) > 0 {
+		w.Header().Set("Content-Language", locales[0])
+	}
+}
+
+func (h *Handler) GetFieldAliasTranslations(w http.ResponseWriter, r *http.Request) {
+	h.getFieldTranslations(w, r, models.FieldTranslationKindAlias, mux.Vars(r)["aliasId"])
+}
+
+func (h *Handler) UpsertFieldAliasTranslation(w http.ResponseWriter, r *http.Request) {
+	h.upsertFieldTranslation(w, r, models.FieldTranslationKindAlias, mux.Vars(r)["aliasId"])
+}
+
+func (h *Handler) DeleteFieldAliasTranslation(w http.ResponseWriter, r *http.Request) {
+	h.deleteFieldTranslation(w, r, models.FieldTranslationKindAlias, mux.Vars(r)["aliasId"])
+}
+
+func (h *Handler) GetCustomFieldTranslations(w http.ResponseWriter, r *http.Request) {
+	h.getFieldTranslations(w, r, models.FieldTranslationKindCustomField, mux.Vars(r)["fieldId"])
+}
+
+func (h *Handler) UpsertCustomFieldTranslation(w http.ResponseWriter, r *http.Request) {
+	h.upsertFieldTranslation(w, r, models.FieldTranslationKindCustomField, mux.Vars(r)["fieldId"])
+}
+
+func (h *Handler) DeleteCustomFieldTranslation(w http.ResponseWriter, r *http.Request) {
+	h.deleteFieldTranslation(w, r, models.FieldTranslationKindCustomField, mux.Vars(r)["fieldId"])
+}
+
+func (h *Handler) getFieldTranslations(w http.ResponseWriter, r *http.Request, kind, ownerID string) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	translations, err := h.DB.GetFieldTranslations(r.Context(), organizationID, kind, ownerID)
+	if err != nil {
+		if strings.HasSuffix(err.Error(), "not found") {
+			h.respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to fetch translations")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, translations)
+}
+
+func (h *Handler) upsertFieldTranslation(w http.ResponseWriter, r *http.Request, kind, ownerID string) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	locale, ok := utils.NormalizeLocale(mux.Vars(r)["locale"])
+	if !ok {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid locale")
+		return
+	}
+
+	var req models.UpsertFieldTranslationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if strings.TrimSpace(req.DisplayName) == "" {
+		h.respondWithError(w, http.StatusBadRequest, "display_name is required")
+		return
+	}
+
+	translation, err := h.DB.UpsertFieldTranslation(r.Context(), organizationID, kind, ownerID, locale, req)
+	if err != nil {
+		if strings.HasSuffix(err.Error(), "not found") {
+			h.respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to save translation")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, translation)
+}
+
+func (h *Handler) deleteFieldTranslation(w http.ResponseWriter, r *http.Request, kind, ownerID string) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	locale, ok := utils.NormalizeLocale(mux.Vars(r)["locale"])
+	if !ok {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid locale")
+		return
+	}
+
+	if err := h.DB.DeleteFieldTranslation(r.Context(), organizationID, kind, ownerID, locale); err != nil {
+		if err.Error() == "translation not found" {
+			h.respondWithError(w, http.StatusNotFound, "Translation not found")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to delete translation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /field-translations/missing?locale=es&table_name=skus
+func (h *Handler) GetMissingFieldTranslations(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	locale, ok := utils.NormalizeLocale(r.URL.Query().Get("locale"))
+	if !ok {
+		h.respondWithError(w, http.StatusBadRequest, "A valid locale is required")
+		return
+	}
+
+	report, err := h.DB.GetMissingFieldTranslations(r.Context(), organizationID, locale, r.URL.Query().Get("table_name"))
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch missing translations")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, report)
+}
+
+func (h *Handler) GetDefaultLocale(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	locale, err := h.Store.GetOrganizationDefaultLocale(r.Context(), organizationID)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch default locale")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, models.UpdateDefaultLocaleRequest{Locale: locale})
+}
+
+func (h *Handler) UpdateDefaultLocale(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.UpdateDefaultLocaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	locale, ok := utils.NormalizeLocale(req.Locale)
+	if !ok {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid locale")
+		return
+	}
+
+	if err := h.DB.UpdateOrganizationDefaultLocale(r.Context(), organizationID, locale); err != nil {
+		h.respondWithServerError(w, r, err, "Failed to update default locale")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, models.UpdateDefaultLocaleRequest{Locale: locale})
+}
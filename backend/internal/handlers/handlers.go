@@ -3,16 +3,24 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
 
 	"flex-erp-poc/internal/database"
+	"flex-erp-poc/internal/events"
 	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/storage"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type Handler struct {
-	DB *database.PostgresService
+	DB      *database.PostgresService
+	Store   database.Store  // SKUs, inventory, transactions, users, field aliases, locales and change logs
+	Storage storage.Storage // attachment contents
+	// MaxAttachmentBytes limits uploads, models.DefaultMaxAttachmentBytes when zero
+	MaxAttachmentBytes int64
+	Events             *events.Bus // published outbox events, for live streams
 }
 
 type LoginRequest struct {
@@ -59,7 +67,7 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// For POC, get the actual user from database or return mock user with correct org ID
-	realUser, err := h.DB.GetUserByEmail(loginReq.Email)
+	realUser, err := h.Store.GetUserByEmail(r.Context(), loginReq.Email)
 	var user *database.User
 	var organization *database.Organization
 
@@ -68,8 +76,7 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		user = realUser
 	} else {
 		// Mock user with actual organization ID from database
-		var orgId string
-		err = h.DB.DB.QueryRow("SELECT id FROM organizations LIMIT 1").Scan(&orgId)
+		orgId, err := h.Store.GetDefaultOrganizationID(r.Context())
 		if err != nil {
 			orgId = "1100401179193344001" // fallback
 		}
@@ -86,17 +93,14 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get organization details
-	var orgName string
-	err = h.DB.DB.QueryRow("SELECT name FROM organizations WHERE id = $1", user.OrganizationID).Scan(&orgName)
+	organization, err = h.Store.GetOrganizationByID(r.Context(), user.OrganizationID)
 	if err != nil {
-		orgName = "Test Organization"
-	}
-
-	organization = &database.Organization{
-		ID:        user.OrganizationID,
-		Name:      orgName,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		organization = &database.Organization{
+			ID:        user.OrganizationID,
+			Name:      "Test Organization",
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
 	}
 
 	// Generate JWT token
@@ -145,7 +149,7 @@ func (h *Handler) Me(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Try to get user from database first
-	user, err := h.DB.GetUserByID(userID)
+	user, err := h.Store.GetUserByID(r.Context(), userID)
 	var organization *database.Organization
 
 	if err != nil {
@@ -177,18 +181,12 @@ func (h *Handler) Me(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get organization details
-	organization, err = h.DB.GetOrganizationByID(organizationID)
+	organization, err = h.Store.GetOrganizationByID(r.Context(), organizationID)
 	if err != nil {
 		// If organization not found, create mock organization
-		var orgName string
-		err = h.DB.DB.QueryRow("SELECT name FROM organizations WHERE id = $1", organizationID).Scan(&orgName)
-		if err != nil {
-			orgName = "Test Organization"
-		}
-
 		organization = &database.Organization{
 			ID:        organizationID,
-			Name:      orgName,
+			Name:      "Test Organization",
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		}
@@ -211,6 +209,32 @@ func (h *Handler) respondWithError(w http.ResponseWriter, code int, message stri
 	json.NewEncoder(w).Encode(ErrorResponse{Error: message})
 }
 
+// StatusClientClosedRequest is the status, borrowed from nginx, of a request the
+// client went away from. The client never sees it, but logs and metrics do.
+const StatusClientClosedRequest = 499
+
+// respondWithServerError responds to an operation that failed for reasons other
+// than the request: 499 when the client went away, 503 when the database took too
+// long, so that clients may retry, and otherwise 500 with the fallback message
+func (h *Handler) respondWithServerError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	switch database.Interrupted(r.Context(), err) {
+	case database.ErrCanceled:
+		h.respondWithError(w, StatusClientClosedRequest, "Request canceled")
+	case database.ErrTimedOut:
+		h.respondWithError(w, http.StatusServiceUnavailable, "Request timed out, please try again")
+	default:
+		h.respondWithError(w, http.StatusInternalServerError, fallback)
+	}
+}
+
+// isListParamError reports whether a list query was rejected for its filter, sort or cursor
+func isListParamError(err error) bool {
+	message := err.Error()
+	return strings.HasPrefix(message, "invalid custom field filter") ||
+		strings.HasPrefix(message, "invalid sort") ||
+		strings.HasPrefix(message, "invalid cursor")
+}
+
 func (h *Handler) respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	response, _ := json.Marshal(payload)
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,462 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"flex-erp-poc/internal/database"
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// testServer routes requests to handlers backed by a MemoryStore, with the
+// middleware cmd/server puts in front of them
+type testServer struct {
+	store  *database.MemoryStore
+	org    *database.Organization
+	router *mux.Router
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	store := database.NewMemoryStore()
+	s := &testServer{store: store, org: store.AddOrganization("Acme"), router: mux.NewRouter()}
+
+	h := &Handler{Store: store}
+	pm := middleware.NewPermissionMiddleware(store)
+
+	s.router.HandleFunc("/auth/login", h.Login).Methods("POST")
+	s.router.Handle("/auth/me", middleware.AuthMiddleware(http.HandlerFunc(h.Me))).Methods("GET")
+
+	api := s.router.PathPrefix("/api/v1").Subrouter()
+	api.Use(middleware.AuthMiddleware)
+	api.HandleFunc("/orgs/{orgId:[0-9a-f-]+}/skus", h.GetSKUs).Methods("GET")
+	api.HandleFunc("/orgs/{orgId:[0-9a-f-]+}/skus", h.CreateSKU).Methods("POST")
+	api.HandleFunc("/orgs/{orgId:[0-9a-f-]+}/inventory/sku/{skuId:[0-9a-f-]+}", h.GetInventoryBySKU).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/transactions",
+		pm.RequirePermission("transactions", "create")(http.HandlerFunc(h.CreateTransaction))).Methods("POST")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/field-aliases",
+		pm.RequirePermission("settings", "read")(http.HandlerFunc(h.GetFieldAliases))).Methods("GET")
+	api.Handle("/orgs/{orgId:[0-9a-f-]+}/tables/{tableName}/fields",
+		pm.RequirePermission("settings", "read")(http.HandlerFunc(h.GetTableFields))).Methods("GET")
+	return s
+}
+
+// addUser adds a user with the role to the server's organization
+func (s *testServer) addUser(t *testing.T, email, role string) *models.UserWithDetails {
+	t.Helper()
+	user, err := s.store.CreateUser(context.Background(), s.org.ID, models.CreateUserRequest{Email: email, Name: "Test " + role, Role: role})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	return user
+}
+
+func (s *testServer) do(t *testing.T, method, path, token string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+// login logs in as email and returns the token
+func (s *testServer) login(t *testing.T, email string) string {
+	t.Helper()
+	w := s.do(t, "POST", "/auth/login", "", LoginRequest{Email: email})
+	if w.Code != http.StatusOK {
+		t.Fatalf("login %s: status %d: %s", email, w.Code, w.Body)
+	}
+	var response LoginResponse
+	decode(t, w, &response)
+	return response.Token
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %q: %v", w.Body, err)
+	}
+}
+
+func (s *testServer) orgPath(path string) string {
+	return "/api/v1/orgs/" + s.org.ID + path
+}
+
+func TestLoginAndMe(t *testing.T) {
+	s := newTestServer(t)
+	manager := s.addUser(t, "manager@example.com", "manager")
+
+	tests := []struct {
+		name     string
+		email    string
+		wantID   string
+		wantRole string
+	}{
+		{"known user", "manager@example.com", manager.ID, "manager"},
+		{"unknown user logs in as a mock admin", "someone@example.com", "1", "admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := s.do(t, "POST", "/auth/login", "", LoginRequest{Email: tt.email})
+			if w.Code != http.StatusOK {
+				t.Fatalf("login: status %d: %s", w.Code, w.Body)
+			}
+			var login LoginResponse
+			decode(t, w, &login)
+			if login.Token == "" {
+				t.Fatal("login returned no token")
+			}
+			if login.User.ID != tt.wantID || login.User.Role != tt.wantRole || login.User.OrganizationID != s.org.ID {
+				t.Errorf("login user = %+v, want id %s role %s in %s", login.User, tt.wantID, tt.wantRole, s.org.ID)
+			}
+			if login.Organization.Name != "Acme" {
+				t.Errorf("login organization = %q, want Acme", login.Organization.Name)
+			}
+
+			w = s.do(t, "GET", "/auth/me", login.Token, nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("me: status %d: %s", w.Code, w.Body)
+			}
+			var me LoginResponse
+			decode(t, w, &me)
+			if me.Token != "" {
+				t.Error("me returned a token")
+			}
+			if me.User.ID != tt.wantID || me.User.Email != tt.email || me.User.Role != tt.wantRole {
+				t.Errorf("me user = %+v, want id %s email %s role %s", me.User, tt.wantID, tt.email, tt.wantRole)
+			}
+			if me.Organization.ID != s.org.ID || me.Organization.Name != "Acme" {
+				t.Errorf("me organization = %+v, want %s Acme", me.Organization, s.org.ID)
+			}
+		})
+	}
+}
+
+func TestLoginRejectsBadRequests(t *testing.T) {
+	s := newTestServer(t)
+	for _, body := range []interface{}{"not an object", LoginRequest{}} {
+		if w := s.do(t, "POST", "/auth/login", "", body); w.Code != http.StatusBadRequest {
+			t.Errorf("login %v: status %d, want 400", body, w.Code)
+		}
+	}
+	if w := s.do(t, "GET", "/auth/me", "garbage", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("me with a bad token: status %d, want 401", w.Code)
+	}
+}
+
+// createSKU creates a SKU through the API and returns it
+func (s *testServer) createSKU(t *testing.T, token, code string) *models.SKU {
+	t.Helper()
+	w := s.do(t, "POST", s.orgPath("/skus"), token, models.CreateSKURequest{SKUCode: code, ProductName: "Product " + code})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create SKU %s: status %d: %s", code, w.Code, w.Body)
+	}
+	var sku models.SKU
+	decode(t, w, &sku)
+	return &sku
+}
+
+type skuPage struct {
+	Items []*models.SKU `json:"items"`
+	models.PageInfo
+}
+
+func TestGetSKUsPaging(t *testing.T) {
+	s := newTestServer(t)
+	token := s.login(t, "admin@example.com")
+	for _, code := range []string{"C-3", "A-1", "E-5", "B-2", "D-4"} {
+		s.createSKU(t, token, code)
+	}
+
+	// list follows a cursor from page to page and returns the SKU codes seen
+	list := func(t *testing.T, query url.Values) ([]string, *models.PageInfo) {
+		var codes []string
+		var first *models.PageInfo
+		for page := 0; ; page++ {
+			w := s.do(t, "GET", s.orgPath("/skus?"+query.Encode()), token, nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("page %d: status %d: %s", page, w.Code, w.Body)
+			}
+			var response skuPage
+			decode(t, w, &response)
+			if first == nil {
+				first = &response.PageInfo
+				if first.PrevCursor != nil {
+					t.Error("first page has a previous cursor")
+				}
+			}
+			for _, sku := range response.Items {
+				codes = append(codes, sku.SKUCode)
+			}
+			if response.NextCursor == nil {
+				return codes, first
+			}
+			query.Set("cursor", *response.NextCursor)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		query     url.Values
+		wantCodes []string
+		wantSort  string
+	}{
+		{"by code", url.Values{"sort": {"sku_code"}, "limit": {"2"}}, []string{"A-1", "B-2", "C-3", "D-4", "E-5"}, "sku_code"},
+		{"by code descending", url.Values{"sort": {"-sku_code"}, "limit": {"2"}}, []string{"E-5", "D-4", "C-3", "B-2", "A-1"}, "-sku_code"},
+		{"one page", url.Values{"sort": {"sku_code"}, "limit": {"10"}}, []string{"A-1", "B-2", "C-3", "D-4", "E-5"}, "sku_code"},
+		{"search", url.Values{"search": {"D-4"}}, []string{"D-4"}, "-relevance"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codes, page := list(t, tt.query)
+			if len(codes) != len(tt.wantCodes) {
+				t.Fatalf("codes = %v, want %v", codes, tt.wantCodes)
+			}
+			for i := range codes {
+				if codes[i] != tt.wantCodes[i] {
+					t.Fatalf("codes = %v, want %v", codes, tt.wantCodes)
+				}
+			}
+			if page.Total != len(tt.wantCodes) || page.Sort != tt.wantSort {
+				t.Errorf("page info = total %d sort %q, want total %d sort %q", page.Total, page.Sort, len(tt.wantCodes), tt.wantSort)
+			}
+		})
+	}
+
+	t.Run("previous cursor", func(t *testing.T) {
+		w := s.do(t, "GET", s.orgPath("/skus?sort=sku_code&limit=2"), token, nil)
+		var first skuPage
+		decode(t, w, &first)
+		w = s.do(t, "GET", s.orgPath("/skus?sort=sku_code&limit=2&cursor="+url.QueryEscape(*first.NextCursor)), token, nil)
+		var second skuPage
+		decode(t, w, &second)
+		if second.PrevCursor == nil {
+			t.Fatal("second page has no previous cursor")
+		}
+		w = s.do(t, "GET", s.orgPath("/skus?sort=sku_code&limit=2&cursor="+url.QueryEscape(*second.PrevCursor)), token, nil)
+		var back skuPage
+		decode(t, w, &back)
+		if len(back.Items) != 2 || back.Items[0].SKUCode != "A-1" || back.Items[1].SKUCode != "B-2" {
+			t.Errorf("previous page = %+v, want A-1 and B-2", back.Items)
+		}
+	})
+
+	for _, query := range []string{"sort=unknown", "cursor=garbage", "sort=sku_code&cursor="} {
+		t.Run("rejects "+query, func(t *testing.T) {
+			if query == "sort=sku_code&cursor=" {
+				// A cursor from another sort order
+				w := s.do(t, "GET", s.orgPath("/skus?sort=-created_at&limit=1"), token, nil)
+				var page skuPage
+				decode(t, w, &page)
+				query += url.QueryEscape(*page.NextCursor)
+			}
+			if w := s.do(t, "GET", s.orgPath("/skus?"+query), token, nil); w.Code != http.StatusBadRequest {
+				t.Errorf("status %d, want 400: %s", w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestCreateTransaction(t *testing.T) {
+	type step struct {
+		transactionType string
+		quantity        int
+		unitCost        float64
+		wantStatus      int
+	}
+	tests := []struct {
+		name         string
+		steps        []step
+		wantQuantity int
+		wantCost     float64
+	}{
+		{
+			name:         "first receipt sets the cost",
+			steps:        []step{{"in", 10, 4, http.StatusCreated}},
+			wantQuantity: 10,
+			wantCost:     4,
+		},
+		{
+			name:         "receipts average the cost by quantity",
+			steps:        []step{{"in", 10, 4, http.StatusCreated}, {"in", 30, 8, http.StatusCreated}},
+			wantQuantity: 40,
+			wantCost:     7,
+		},
+		{
+			name:         "issues keep the cost",
+			steps:        []step{{"in", 10, 4, http.StatusCreated}, {"out", 6, 0, http.StatusCreated}, {"in", 4, 10, http.StatusCreated}},
+			wantQuantity: 8,
+			wantCost:     7,
+		},
+		{
+			name:         "issue beyond stock",
+			steps:        []step{{"in", 5, 2, http.StatusCreated}, {"out", 6, 0, http.StatusBadRequest}},
+			wantQuantity: 5,
+			wantCost:     2,
+		},
+		{
+			name:         "issue without inventory",
+			steps:        []step{{"out", 1, 0, http.StatusBadRequest}},
+			wantQuantity: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			s.addUser(t, "clerk@example.com", "user")
+			token := s.login(t, "clerk@example.com")
+			sku := s.createSKU(t, token, "W-1")
+
+			for i, step := range tt.steps {
+				w := s.do(t, "POST", s.orgPath("/transactions"), token, models.CreateTransactionRequest{
+					SKUID:           sku.ID,
+					TransactionType: step.transactionType,
+					Quantity:        step.quantity,
+					UnitCost:        step.unitCost,
+				})
+				if w.Code != step.wantStatus {
+					t.Fatalf("step %d: status %d, want %d: %s", i, w.Code, step.wantStatus, w.Body)
+				}
+			}
+
+			w := s.do(t, "GET", s.orgPath("/inventory/sku/"+sku.ID), token, nil)
+			if tt.wantQuantity < 0 {
+				if w.Code != http.StatusNotFound {
+					t.Errorf("inventory: status %d, want 404", w.Code)
+				}
+				return
+			}
+			var inventory models.Inventory
+			decode(t, w, &inventory)
+			if inventory.Quantity != tt.wantQuantity || inventory.WeightedCost != tt.wantCost {
+				t.Errorf("inventory = %d at %v, want %d at %v", inventory.Quantity, inventory.WeightedCost, tt.wantQuantity, tt.wantCost)
+			}
+			if want := float64(tt.wantQuantity) * tt.wantCost; inventory.TotalValue != want {
+				t.Errorf("total value = %v, want %v", inventory.TotalValue, want)
+			}
+		})
+	}
+}
+
+func TestCreateTransactionErrors(t *testing.T) {
+	s := newTestServer(t)
+	s.addUser(t, "viewer@example.com", "viewer")
+	adminToken := s.login(t, "admin@example.com")
+	sku := s.createSKU(t, adminToken, "W-1")
+	s.store.SetBusinessRules(s.org.ID, models.BusinessRules{MaxTransactionQuantity: 100})
+
+	tests := []struct {
+		name       string
+		token      string
+		req        models.CreateTransactionRequest
+		wantStatus int
+	}{
+		{"viewer may not post", s.login(t, "viewer@example.com"), models.CreateTransactionRequest{SKUID: sku.ID, TransactionType: "in", Quantity: 1}, http.StatusForbidden},
+		{"no token", "", models.CreateTransactionRequest{SKUID: sku.ID, TransactionType: "in", Quantity: 1}, http.StatusUnauthorized},
+		{"bad type", adminToken, models.CreateTransactionRequest{SKUID: sku.ID, TransactionType: "adjust", Quantity: 1}, http.StatusBadRequest},
+		{"zero quantity", adminToken, models.CreateTransactionRequest{SKUID: sku.ID, TransactionType: "in"}, http.StatusBadRequest},
+		{"negative cost", adminToken, models.CreateTransactionRequest{SKUID: sku.ID, TransactionType: "in", Quantity: 1, UnitCost: -1}, http.StatusBadRequest},
+		{"business rule", adminToken, models.CreateTransactionRequest{SKUID: sku.ID, TransactionType: "in", Quantity: 101}, http.StatusBadRequest},
+		{"unknown custom field", adminToken, models.CreateTransactionRequest{SKUID: sku.ID, TransactionType: "in", Quantity: 1, CustomFields: models.CustomFieldValues{"lot": "A"}}, http.StatusBadRequest},
+		{"within the rules", adminToken, models.CreateTransactionRequest{SKUID: sku.ID, TransactionType: "in", Quantity: 100}, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := s.do(t, "POST", s.orgPath("/transactions"), tt.token, tt.req); w.Code != tt.wantStatus {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+}
+
+func TestFieldLabelLocales(t *testing.T) {
+	s := newTestServer(t)
+	s.store.SetDefaultLocale(s.org.ID, "pt-BR")
+	if _, err := s.store.CreateFieldAlias(context.Background(), s.org.ID, models.CreateFieldAliasRequest{
+		TableName: "skus", FieldName: "sku_code", DisplayName: "Item Code",
+	}); err != nil {
+		t.Fatalf("CreateFieldAlias: %v", err)
+	}
+
+	admin := s.addUser(t, "admin@example.com", "admin")
+	preferred := "de"
+	if _, err := s.store.UpdateUser(context.Background(), s.org.ID, admin.ID, models.UpdateUserRequest{
+		Name: admin.Name, Role: admin.Role, PreferredLocale: &preferred,
+	}); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	s.addUser(t, "manager@example.com", "manager")
+
+	tests := []struct {
+		name           string
+		email          string
+		query          string
+		acceptLanguage string
+		want           string
+	}{
+		{"organization default", "manager@example.com", "", "", "pt-BR"},
+		{"Accept-Language over the default", "manager@example.com", "", "fr-CA, es;q=0.5", "fr-CA"},
+		{"preferred locale over Accept-Language", "admin@example.com", "", "fr-CA", "de"},
+		{"explicit locale first", "admin@example.com", "?locale=es_mx", "fr-CA", "es-MX"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := s.login(t, tt.email)
+			get := func(path string) *httptest.ResponseRecorder {
+				t.Helper()
+				req := httptest.NewRequest("GET", s.orgPath(path+tt.query), nil)
+				req.Header.Set("Authorization", "Bearer "+token)
+				if tt.acceptLanguage != "" {
+					req.Header.Set("Accept-Language", tt.acceptLanguage)
+				}
+				w := httptest.NewRecorder()
+				s.router.ServeHTTP(w, req)
+				if w.Code != http.StatusOK {
+					t.Fatalf("GET %s: status %d: %s", path, w.Code, w.Body)
+				}
+				if got := w.Header().Get("Content-Language"); got != tt.want {
+					t.Errorf("GET %s: Content-Language = %q, want %q", path, got, tt.want)
+				}
+				return w
+			}
+
+			var aliases struct {
+				Items []*models.FieldAlias `json:"items"`
+			}
+			decode(t, get("/field-aliases"), &aliases)
+			if len(aliases.Items) != 1 || aliases.Items[0].DisplayName != "Item Code" {
+				t.Errorf("field aliases = %+v, want Item Code", aliases.Items)
+			}
+
+			var fields models.TableFieldsResponse
+			decode(t, get("/tables/skus/fields"), &fields)
+			if fields.Locale != tt.want || len(fields.Fields) != 1 {
+				t.Errorf("table fields = locale %q with %d fields, want %q with 1", fields.Locale, len(fields.Fields), tt.want)
+			}
+		})
+	}
+
+	t.Run("rejects an invalid locale", func(t *testing.T) {
+		token := s.login(t, "manager@example.com")
+		if w := s.do(t, "GET", s.orgPath("/field-aliases?locale=not_a_locale!"), token, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("status %d, want 400: %s", w.Code, w.Body)
+		}
+	})
+}
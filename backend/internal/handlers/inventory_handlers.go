@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"flex-erp-poc/internal/middleware"
 	"flex-erp-poc/internal/models"
@@ -18,19 +21,61 @@ func (h *Handler) GetInventory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	query := r.URL.Query()
+	params := parseInventoryListParams(query)
+
+	// Roll variant stock up into its parent SKU. Grouped rows are paged by page number only.
+	if query.Get("group_by") == "parent" {
+		if params.Sort != "" || params.Cursor != "" {
+			h.respondWithError(w, http.StatusBadRequest, "sort and cursor are not supported with group_by=parent")
+			return
+		}
+		inventory, err := h.Store.GetParentInventory(r.Context(), organizationID, params)
+		if err != nil {
+			if strings.HasPrefix(err.Error(), "invalid custom field filter") {
+				h.respondWithError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			h.respondWithServerError(w, r, err, "Failed to fetch inventory")
+			return
+		}
+		h.respondWithJSON(w, http.StatusOK, inventory)
+		return
+	}
+
+	inventory, page, err := h.Store.GetInventoryWithSKUs(r.Context(), organizationID, params)
+	if err != nil {
+		if isListParamError(err) {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to fetch inventory")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, models.ListResponse{Items: inventory, PageInfo: *page})
+}
+
+func parseInventoryListParams(query url.Values) models.InventoryListParams {
 	params := models.InventoryListParams{
 		Page:  1,
 		Limit: 50,
 	}
 
-	// Parse query parameters
-	query := r.URL.Query()
 	if category := query.Get("category"); category != "" {
 		params.Category = &category
 	}
+	if categoryID := query.Get("category_id"); categoryID != "" {
+		params.CategoryID = &categoryID
+	}
 	if search := query.Get("search"); search != "" {
 		params.Search = &search
 	}
+	if maxQuantityStr := query.Get("max_quantity"); maxQuantityStr != "" {
+		if maxQuantity, err := strconv.Atoi(maxQuantityStr); err == nil {
+			params.MaxQuantity = &maxQuantity
+		}
+	}
 	if pageStr := query.Get("page"); pageStr != "" {
 		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
 			params.Page = page
@@ -41,14 +86,11 @@ func (h *Handler) GetInventory(w http.ResponseWriter, r *http.Request) {
 			params.Limit = limit
 		}
 	}
+	params.CustomFields = parseCustomFieldFilters(query)
+	params.Sort = query.Get("sort")
+	params.Cursor = query.Get("cursor")
 
-	inventory, err := h.DB.GetInventoryWithSKUs(organizationID, params)
-	if err != nil {
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to fetch inventory")
-		return
-	}
-
-	h.respondWithJSON(w, http.StatusOK, inventory)
+	return params
 }
 
 func (h *Handler) GetInventoryBySKU(w http.ResponseWriter, r *http.Request) {
@@ -65,7 +107,7 @@ func (h *Handler) GetInventoryBySKU(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	inventory, err := h.DB.GetInventoryBySKUID(organizationID, skuID)
+	inventory, err := h.Store.GetInventoryBySKUID(r.Context(), organizationID, skuID)
 	if err != nil {
 		h.respondWithError(w, http.StatusNotFound, "Inventory not found")
 		return
@@ -100,9 +142,9 @@ func (h *Handler) UpdateManualCost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	inventory, err := h.DB.UpdateManualCost(organizationID, skuID, req)
+	inventory, err := h.Store.UpdateManualCost(r.Context(), organizationID, skuID, req)
 	if err != nil {
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to update manual cost")
+		h.respondWithServerError(w, r, err, "Failed to update manual cost")
 		return
 	}
 
@@ -116,12 +158,7 @@ func (h *Handler) CreateInventory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req struct {
-		SKUID        string  `json:"sku_id"`
-		Quantity     int     `json:"quantity"`
-		WeightedCost float64 `json:"weighted_cost"`
-	}
-
+	var req models.CreateInventoryRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
 		return
@@ -141,15 +178,56 @@ func (h *Handler) CreateInventory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	inventory, err := h.DB.CreateInventoryForSKU(organizationID, req.SKUID, req.Quantity, req.WeightedCost)
+	inventory, err := h.Store.CreateInventoryForSKU(r.Context(), organizationID, req)
 	if err != nil {
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to create inventory")
+		if strings.HasPrefix(err.Error(), "invalid custom field values") {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to create inventory")
 		return
 	}
 
 	h.respondWithJSON(w, http.StatusCreated, inventory)
 }
 
+func (h *Handler) UpdateInventoryCustomFields(w http.ResponseWriter, r *http.Request) {
+	organizationID := getOrganizationIDFromContext(r)
+	if organizationID == "" {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	skuID := vars["skuId"]
+	if skuID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid SKU ID")
+		return
+	}
+
+	var req models.UpdateCustomFieldValuesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	inventory, err := h.Store.UpdateInventoryCustomFields(r.Context(), organizationID, skuID, req.CustomFields)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.respondWithError(w, http.StatusNotFound, "Inventory not found")
+			return
+		}
+		if strings.HasPrefix(err.Error(), "invalid custom field values") {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to update inventory custom fields")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, inventory)
+}
+
 func getOrganizationIDFromContext(r *http.Request) string {
 	orgID, ok := middleware.GetOrganizationIDFromContext(r.Context())
 	if !ok {
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultJobRuns = 20
+	maxJobRuns     = 200
+)
+
+// Background jobs run for the whole server rather than one organization, so these
+// routes sit outside /orgs and are for admins only.
+
+func (h *Handler) GetScheduledJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.DB.GetScheduledJobs(r.Context())
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch jobs")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, jobs)
+}
+
+func (h *Handler) GetScheduledJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.DB.GetScheduledJob(r.Context(), mux.Vars(r)["jobName"])
+	if err != nil {
+		h.respondWithJobError(w, r, err, "Failed to fetch job")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, job)
+}
+
+// PATCH /admin/jobs/{jobName} pauses or resumes a job's schedule
+func (h *Handler) UpdateScheduledJob(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateScheduledJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Paused == nil {
+		h.respondWithError(w, http.StatusBadRequest, "paused is required")
+		return
+	}
+
+	job, err := h.DB.SetScheduledJobPaused(r.Context(), mux.Vars(r)["jobName"], *req.Paused)
+	if err != nil {
+		h.respondWithJobError(w, r, err, "Failed to update job")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, job)
+}
+
+// POST /admin/jobs/{jobName}/run asks for a run now, even of a paused job. It runs
+// when a scheduler next checks, within seconds; the run shows in the job's history.
+func (h *Handler) TriggerScheduledJob(w http.ResponseWriter, r *http.Request) {
+	job, err := h.DB.RequestJobRun(r.Context(), mux.Vars(r)["jobName"])
+	if err != nil {
+		h.respondWithJobError(w, r, err, "Failed to trigger job")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusAccepted, job)
+}
+
+// GET /admin/jobs/{jobName}/runs lists the most recent runs, newest first
+func (h *Handler) GetJobRuns(w http.ResponseWriter, r *http.Request) {
+	limit := defaultJobRuns
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = min(parsed, maxJobRuns)
+	}
+
+	runs, err := h.DB.GetJobRuns(r.Context(), mux.Vars(r)["jobName"], limit)
+	if err != nil {
+		h.respondWithJobError(w, r, err, "Failed to fetch job runs")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, runs)
+}
+
+func (h *Handler) respondWithJobError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	if err.Error() == "scheduled job not found" {
+		h.respondWithError(w, http.StatusNotFound, "Job not found")
+		return
+	}
+	h.respondWithServerError(w, r, err, message)
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) GetKits(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	kits, err := h.DB.GetKits(r.Context(), organizationID)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch kits")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, kits)
+}
+
+func (h *Handler) GetKitComponents(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	skuID := mux.Vars(r)["skuId"]
+	if skuID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid SKU ID")
+		return
+	}
+
+	kit, err := h.DB.GetKit(r.Context(), organizationID, skuID)
+	if err != nil {
+		if err.Error() == "SKU not found" {
+			h.respondWithError(w, http.StatusNotFound, "SKU not found")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to fetch bill of materials")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, kit)
+}
+
+func (h *Handler) SetKitComponents(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	skuID := mux.Vars(r)["skuId"]
+	if skuID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid SKU ID")
+		return
+	}
+
+	var req models.SetKitComponentsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	for _, component := range req.Components {
+		if component.ComponentSKUID == "" {
+			h.respondWithError(w, http.StatusBadRequest, "Component SKU ID is required")
+			return
+		}
+		if component.Quantity <= 0 {
+			h.respondWithError(w, http.StatusBadRequest, "Component quantity must be positive")
+			return
+		}
+	}
+
+	kit, err := h.DB.SetKitComponents(r.Context(), organizationID, skuID, req)
+	if err != nil {
+		switch {
+		case err.Error() == "SKU not found":
+			h.respondWithError(w, http.StatusNotFound, "SKU not found")
+		case strings.HasPrefix(err.Error(), "invalid kit components"):
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.respondWithServerError(w, r, err, "Failed to update bill of materials")
+		}
+		return
+	}
+
+	// Log the BOM change against the kit SKU
+	logReq := models.NewSKUChangeLog(organizationID, userID, skuID, "update")
+	reason := fmt.Sprintf("Bill of materials set to %d components", len(kit.Components))
+	logReq.Reason = &reason
+	h.Store.LogChange(r.Context(), organizationID, userID, *logReq)
+
+	h.respondWithJSON(w, http.StatusOK, kit)
+}
+
+func (h *Handler) AssembleKit(w http.ResponseWriter, r *http.Request) {
+	h.postKitAssembly(w, r, "assembly", h.DB.AssembleKit)
+}
+
+func (h *Handler) DisassembleKit(w http.ResponseWriter, r *http.Request) {
+	h.postKitAssembly(w, r, "disassembly", h.DB.DisassembleKit)
+}
+
+// postKitAssembly handles assembly and disassembly, which share a request body and
+// differ only in which way the stock moves
+func (h *Handler) postKitAssembly(w http.ResponseWriter, r *http.Request, action string,
+	post func(ctx context.Context, organizationID, userID, kitID string, req models.KitAssemblyRequest) (*models.KitAssemblyResponse, error)) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	skuID := mux.Vars(r)["skuId"]
+	if skuID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid SKU ID")
+		return
+	}
+
+	var req models.KitAssemblyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Quantity <= 0 {
+		h.respondWithError(w, http.StatusBadRequest, "Quantity must be positive")
+		return
+	}
+
+	result, err := post(r.Context(), organizationID, userID, skuID, req)
+	if err != nil {
+		switch {
+		case err.Error() == "SKU not found":
+			h.respondWithError(w, http.StatusNotFound, "SKU not found")
+		case err.Error() == "SKU is not a kit",
+			strings.HasPrefix(err.Error(), "SKU has variants"):
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+		case strings.HasPrefix(err.Error(), "insufficient inventory"):
+			h.respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			h.respondWithServerError(w, r, err, "Failed to post kit "+action)
+		}
+		return
+	}
+
+	// Log every transaction posted for the kit and its components
+	for _, transaction := range result.Transactions {
+		logReq := models.NewTransactionChangeLog(organizationID, userID, transaction.ID, transaction.SKUID)
+		reason := fmt.Sprintf("%s transaction - %d units for %s of %s", strings.ToUpper(transaction.TransactionType), transaction.Quantity, action, result.Kit.SKUCode)
+		logReq.Reason = &reason
+		h.Store.LogChange(r.Context(), organizationID, userID, *logReq)
+	}
+
+	h.respondWithJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/labels"
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) respondWithLabelTemplateError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	switch {
+	case err.Error() == "label template not found":
+		h.respondWithError(w, http.StatusNotFound, "Label template not found")
+	case strings.HasPrefix(err.Error(), "invalid label template"):
+		h.respondWithError(w, http.StatusBadRequest, err.Error())
+	case strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint"):
+		h.respondWithError(w, http.StatusConflict, "A label template with this name already exists")
+	default:
+		h.respondWithServerError(w, r, err, fallback)
+	}
+}
+
+func (h *Handler) GetLabelTemplates(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	templates, err := h.DB.GetLabelTemplates(r.Context(), organizationID)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch label templates")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, templates)
+}
+
+func (h *Handler) CreateLabelTemplate(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.CreateLabelTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	template, err := h.DB.CreateLabelTemplate(r.Context(), organizationID, req)
+	if err != nil {
+		h.respondWithLabelTemplateError(w, r, err, "Failed to create label template")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, template)
+}
+
+func (h *Handler) UpdateLabelTemplate(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.UpdateLabelTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	template, err := h.DB.UpdateLabelTemplate(r.Context(), organizationID, mux.Vars(r)["templateId"], req)
+	if err != nil {
+		h.respondWithLabelTemplateError(w, r, err, "Failed to update label template")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, template)
+}
+
+func (h *Handler) DeleteLabelTemplate(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.DB.DeleteLabelTemplate(r.Context(), organizationID, mux.Vars(r)["templateId"]); err != nil {
+		h.respondWithLabelTemplateError(w, r, err, "Failed to delete label template")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /labels renders labels for a list of SKUs
+func (h *Handler) PrintLabels(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.LabelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Format != "zpl" && req.Format != "pdf" {
+		h.respondWithError(w, http.StatusBadRequest, "Format must be zpl or pdf")
+		return
+	}
+	if len(req.Items) == 0 {
+		h.respondWithError(w, http.StatusBadRequest, "At least one item is required")
+		return
+	}
+
+	ids := make([]string, 0, len(req.Items))
+	for i, item := range req.Items {
+		if item.Copies == 0 {
+			req.Items[i].Copies = 1
+		}
+		if item.Copies < 0 {
+			h.respondWithError(w, http.StatusBadRequest, "Copies must be positive")
+			return
+		}
+		ids = append(ids, item.SKUID)
+	}
+
+	skus, err := h.Store.GetSKUsByID(r.Context(), organizationID, ids)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch SKUs")
+		return
+	}
+
+	items := make([]labels.Label, 0, len(req.Items))
+	for _, item := range req.Items {
+		sku, ok := skus[item.SKUID]
+		if !ok {
+			h.respondWithError(w, http.StatusNotFound, fmt.Sprintf("SKU %s not found", item.SKUID))
+			return
+		}
+		items = append(items, newLabel(sku, item.Copies, item.Price, item.Lot))
+	}
+
+	templateID := ""
+	if req.TemplateID != nil {
+		templateID = *req.TemplateID
+	}
+	h.writeLabels(w, r, organizationID, templateID, req.Format, req.StartPosition, items, "labels")
+}
+
+// GET /transactions/{transactionId}/labels prints one label per unit received by
+// an "in" transaction, ready to stick on the goods as they are put away
+func (h *Handler) PrintTransactionLabels(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "zpl"
+	}
+	if format != "zpl" && format != "pdf" {
+		h.respondWithError(w, http.StatusBadRequest, "Format must be zpl or pdf")
+		return
+	}
+
+	var price *float64
+	if value := query.Get("price"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid price")
+			return
+		}
+		price = &parsed
+	}
+	var lot *string
+	if value := strings.TrimSpace(query.Get("lot")); value != "" {
+		lot = &value
+	}
+	startPosition := 0
+	if value := query.Get("start_position"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid start position")
+			return
+		}
+		startPosition = parsed
+	}
+
+	transaction, err := h.DB.GetTransactionByID(r.Context(), organizationID, mux.Vars(r)["transactionId"])
+	if err != nil {
+		if err.Error() == "transaction not found" {
+			h.respondWithError(w, http.StatusNotFound, "Transaction not found")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to fetch transaction")
+		return
+	}
+	if transaction.TransactionType != "in" {
+		h.respondWithError(w, http.StatusBadRequest, "Labels can only be printed for receipts (in transactions)")
+		return
+	}
+
+	sku, err := h.Store.GetSKUByID(r.Context(), organizationID, transaction.SKUID)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch SKU")
+		return
+	}
+
+	items := []labels.Label{newLabel(sku, transaction.Quantity, price, lot)}
+	h.writeLabels(w, r, organizationID, query.Get("template_id"), format, startPosition, items, "receipt-"+sku.SKUCode)
+}
+
+func newLabel(sku *models.SKU, copies int, price *float64, lot *string) labels.Label {
+	label := labels.Label{
+		SKUCode:     sku.SKUCode,
+		ProductName: sku.ProductName,
+		Price:       price,
+		Lot:         lot,
+		Copies:      copies,
+	}
+	if sku.Barcode != nil {
+		label.Barcode = *sku.Barcode
+	} else {
+		// Without a barcode the SKU code is printed as Code 128
+		label.Barcode = sku.SKUCode
+	}
+	return label
+}
+
+// writeLabels renders the labels with the template and writes them as a download
+func (h *Handler) writeLabels(w http.ResponseWriter, r *http.Request, organizationID, templateID, format string, startPosition int, items []labels.Label, name string) {
+	if count := labels.Count(items); count > labels.MaxLabels {
+		h.respondWithError(w, http.StatusBadRequest, fmt.Sprintf("At most %d labels can be printed at once, requested %d", labels.MaxLabels, count))
+		return
+	}
+
+	template, err := h.DB.GetLabelTemplate(r.Context(), organizationID, templateID)
+	if err != nil {
+		h.respondWithLabelTemplateError(w, r, err, "Failed to fetch label template")
+		return
+	}
+
+	var output []byte
+	contentType := "text/plain; charset=utf-8"
+	if format == "pdf" {
+		contentType = "application/pdf"
+		output, err = labels.RenderPDF(template, items, startPosition)
+	} else {
+		output, err = labels.RenderZPL(template, items)
+	}
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().Format(time.DateOnly), format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(output)
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// GET /notifications lists the user's inbox, newest first. unread=true leaves out
+// read notifications; before (RFC 3339) pages back from the oldest one shown.
+func (h *Handler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	query := r.URL.Query()
+	params := models.NotificationListParams{}
+	if value := query.Get("unread"); value != "" {
+		unread, err := strconv.ParseBool(value)
+		if err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid unread value")
+			return
+		}
+		params.Unread = unread
+	}
+	if value := query.Get("before"); value != "" {
+		before, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid before time, expected RFC 3339")
+			return
+		}
+		params.Before = &before
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		params.Limit = limit
+	}
+
+	notifications, err := h.DB.GetNotifications(r.Context(), organizationID, userID, params)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch notifications")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, notifications)
+}
+
+func (h *Handler) GetUnreadNotificationCount(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	count, err := h.DB.GetUnreadNotificationCount(r.Context(), organizationID, userID)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to count notifications")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, map[string]int{"unread": count})
+}
+
+// PATCH /notifications/{notificationId} marks a notification read or unread
+func (h *Handler) UpdateNotification(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	var req models.UpdateNotificationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Read == nil {
+		h.respondWithError(w, http.StatusBadRequest, "read is required")
+		return
+	}
+
+	notification, err := h.DB.SetNotificationRead(r.Context(), organizationID, userID, mux.Vars(r)["notificationId"], *req.Read)
+	if err != nil {
+		if err.Error() == "notification not found" {
+			h.respondWithError(w, http.StatusNotFound, "Notification not found")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to update notification")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, notification)
+}
+
+func (h *Handler) MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	marked, err := h.DB.MarkAllNotificationsRead(r.Context(), organizationID, userID)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to mark notifications read")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, map[string]int64{"marked": marked})
+}
+
+func (h *Handler) GetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	preferences, err := h.DB.GetNotificationPreferences(r.Context(), organizationID, userID)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch notification preferences")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, preferences)
+}
+
+// PUT /notification-preferences saves the listed types; the rest are unchanged
+func (h *Handler) UpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	var req models.UpdateNotificationPreferencesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	preferences, err := h.DB.UpdateNotificationPreferences(r.Context(), organizationID, userID, req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid notification preferences") {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to update notification preferences")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, preferences)
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) GetPurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := models.PurchaseOrderListParams{
+		Page:  1,
+		Limit: 50,
+	}
+
+	// Parse query parameters
+	query := r.URL.Query()
+	if status := query.Get("status"); status != "" {
+		params.Status = &status
+	}
+	if supplier := query.Get("supplier"); supplier != "" {
+		params.Supplier = &supplier
+	}
+	if supplierID := query.Get("supplier_id"); supplierID != "" {
+		params.SupplierID = &supplierID
+	}
+	if search := query.Get("search"); search != "" {
+		params.Search = &search
+	}
+	if pageStr := query.Get("page"); pageStr != "" {
+		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
+			params.Page = page
+		}
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			params.Limit = limit
+		}
+	}
+
+	orders, err := h.DB.GetPurchaseOrders(r.Context(), organizationID, params)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch purchase orders")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, orders)
+}
+
+func (h *Handler) GetPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	purchaseOrderID := mux.Vars(r)["purchaseOrderId"]
+	if purchaseOrderID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid purchase order ID")
+		return
+	}
+
+	po, err := h.DB.GetPurchaseOrderByID(r.Context(), organizationID, purchaseOrderID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, "Purchase order not found")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, po)
+}
+
+func (h *Handler) CreatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req models.CreatePurchaseOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Basic validation
+	if req.PONumber == "" || (req.Supplier == "" && (req.SupplierID == nil || *req.SupplierID == "")) {
+		h.respondWithError(w, http.StatusBadRequest, "PO number and supplier are required")
+		return
+	}
+	if len(req.Lines) == 0 {
+		h.respondWithError(w, http.StatusBadRequest, "At least one line is required")
+		return
+	}
+	for _, line := range req.Lines {
+		if line.SKUID == "" {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid SKU ID")
+			return
+		}
+		if line.OrderedQuantity <= 0 {
+			h.respondWithError(w, http.StatusBadRequest, "Ordered quantity must be positive")
+			return
+		}
+		if line.UnitCost < 0 {
+			h.respondWithError(w, http.StatusBadRequest, "Unit cost must be non-negative")
+			return
+		}
+	}
+
+	po, err := h.DB.CreatePurchaseOrder(r.Context(), organizationID, userID, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
+			h.respondWithError(w, http.StatusConflict, "PO number already exists in this organization")
+			return
+		}
+		if strings.HasPrefix(err.Error(), "SKU not found") || err.Error() == "supplier not found" {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to create purchase order")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, po)
+}
+
+func (h *Handler) UpdatePurchaseOrderStatus(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	purchaseOrderID := mux.Vars(r)["purchaseOrderId"]
+	if purchaseOrderID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid purchase order ID")
+		return
+	}
+
+	var req models.UpdatePurchaseOrderStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Status == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Status is required")
+		return
+	}
+
+	po, err := h.DB.UpdatePurchaseOrderStatus(r.Context(), organizationID, purchaseOrderID, req.Status)
+	if err != nil {
+		if err.Error() == "purchase order not found" {
+			h.respondWithError(w, http.StatusNotFound, "Purchase order not found")
+			return
+		}
+		if strings.HasPrefix(err.Error(), "invalid status transition") {
+			h.respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to update purchase order status")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, po)
+}
+
+func (h *Handler) ReceivePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	purchaseOrderID := mux.Vars(r)["purchaseOrderId"]
+	if purchaseOrderID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid purchase order ID")
+		return
+	}
+
+	var req models.ReceivePurchaseOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Basic validation
+	if len(req.Lines) == 0 {
+		h.respondWithError(w, http.StatusBadRequest, "At least one received line is required")
+		return
+	}
+	for _, line := range req.Lines {
+		if line.LineID == "" {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid line ID")
+			return
+		}
+		if line.Quantity <= 0 {
+			h.respondWithError(w, http.StatusBadRequest, "Received quantity must be positive")
+			return
+		}
+	}
+
+	result, err := h.DB.ReceivePurchaseOrder(r.Context(), organizationID, userID, purchaseOrderID, req)
+	if err != nil {
+		switch {
+		case err.Error() == "purchase order not found":
+			h.respondWithError(w, http.StatusNotFound, "Purchase order not found")
+		case strings.HasPrefix(err.Error(), "over-receipt"),
+			strings.HasPrefix(err.Error(), "purchase order cannot be received"):
+			h.respondWithError(w, http.StatusConflict, err.Error())
+		case strings.HasPrefix(err.Error(), "purchase order line not found"):
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.respondWithServerError(w, r, err, "Failed to receive purchase order")
+		}
+		return
+	}
+
+	// Log the IN transactions posted by the receipt
+	for _, transaction := range result.Transactions {
+		logReq := models.NewTransactionChangeLog(organizationID, userID, transaction.ID, transaction.SKUID)
+		reason := fmt.Sprintf("IN transaction - %d units received on %s", transaction.Quantity, result.PurchaseOrder.PONumber)
+		logReq.Reason = &reason
+		h.Store.LogChange(r.Context(), organizationID, userID, *logReq)
+	}
+
+	h.respondWithJSON(w, http.StatusOK, result)
+}
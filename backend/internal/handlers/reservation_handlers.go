@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) GetReservations(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := models.ReservationListParams{
+		Page:  1,
+		Limit: 50,
+	}
+
+	// Parse query parameters
+	query := r.URL.Query()
+	if skuIDStr := query.Get("sku_id"); skuIDStr != "" {
+		params.SKUID = &skuIDStr
+	}
+	if status := query.Get("status"); status != "" {
+		params.Status = &status
+	}
+	if referenceNumber := query.Get("reference_number"); referenceNumber != "" {
+		params.ReferenceNumber = &referenceNumber
+	}
+	if pageStr := query.Get("page"); pageStr != "" {
+		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
+			params.Page = page
+		}
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			params.Limit = limit
+		}
+	}
+
+	reservations, err := h.DB.GetReservations(r.Context(), organizationID, params)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch reservations")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, reservations)
+}
+
+func (h *Handler) GetReservation(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	reservationID := mux.Vars(r)["reservationId"]
+	if reservationID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
+
+	reservation, err := h.DB.GetReservationByID(r.Context(), organizationID, reservationID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			h.respondWithError(w, http.StatusNotFound, "Reservation not found")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to retrieve reservation")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, reservation)
+}
+
+func (h *Handler) CreateReservation(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req models.CreateReservationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Basic validation
+	if req.SKUID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid SKU ID")
+		return
+	}
+	if req.Quantity <= 0 {
+		h.respondWithError(w, http.StatusBadRequest, "Quantity must be positive")
+		return
+	}
+
+	reservation, err := h.DB.CreateReservation(r.Context(), organizationID, userID, req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "insufficient inventory") {
+			h.respondWithError(w, http.StatusConflict, err.Error())
+			return
+		}
+		if strings.HasPrefix(err.Error(), "reservation expiry") {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to create reservation")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, reservation)
+}
+
+func (h *Handler) ReleaseReservation(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	reservationID := mux.Vars(r)["reservationId"]
+	if reservationID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid reservation ID")
+		return
+	}
+
+	reservation, err := h.DB.ReleaseReservation(r.Context(), organizationID, reservationID)
+	if err != nil {
+		switch err.Error() {
+		case "reservation not found":
+			h.respondWithError(w, http.StatusNotFound, "Reservation not found")
+		case "reservation is not active":
+			h.respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			h.respondWithServerError(w, r, err, "Failed to release reservation")
+		}
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, reservation)
+}
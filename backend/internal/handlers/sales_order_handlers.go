@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) GetSalesOrders(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := models.SalesOrderListParams{
+		Page:  1,
+		Limit: 50,
+	}
+
+	// Parse query parameters
+	query := r.URL.Query()
+	if status := query.Get("status"); status != "" {
+		params.Status = &status
+	}
+	if customer := query.Get("customer"); customer != "" {
+		params.Customer = &customer
+	}
+	if search := query.Get("search"); search != "" {
+		params.Search = &search
+	}
+	if pageStr := query.Get("page"); pageStr != "" {
+		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
+			params.Page = page
+		}
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			params.Limit = limit
+		}
+	}
+
+	orders, err := h.DB.GetSalesOrders(r.Context(), organizationID, params)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch sales orders")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, orders)
+}
+
+func (h *Handler) GetSalesOrder(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	salesOrderID := mux.Vars(r)["salesOrderId"]
+	if salesOrderID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid sales order ID")
+		return
+	}
+
+	so, err := h.DB.GetSalesOrderByID(r.Context(), organizationID, salesOrderID)
+	if err != nil {
+		h.respondWithError(w, http.StatusNotFound, "Sales order not found")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, so)
+}
+
+func (h *Handler) CreateSalesOrder(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req models.CreateSalesOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Basic validation
+	if req.OrderNumber == "" || req.Customer == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Order number and customer are required")
+		return
+	}
+	if len(req.Lines) == 0 {
+		h.respondWithError(w, http.StatusBadRequest, "At least one line is required")
+		return
+	}
+	for _, line := range req.Lines {
+		if line.SKUID == "" {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid SKU ID")
+			return
+		}
+		if line.OrderedQuantity <= 0 {
+			h.respondWithError(w, http.StatusBadRequest, "Ordered quantity must be positive")
+			return
+		}
+		if line.UnitPrice < 0 {
+			h.respondWithError(w, http.StatusBadRequest, "Unit price must be non-negative")
+			return
+		}
+	}
+
+	so, err := h.DB.CreateSalesOrder(r.Context(), organizationID, userID, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
+			h.respondWithError(w, http.StatusConflict, "Order number already exists in this organization")
+			return
+		}
+		if strings.HasPrefix(err.Error(), "SKU not found") {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to create sales order")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, so)
+}
+
+func (h *Handler) ConfirmSalesOrder(w http.ResponseWriter, r *http.Request) {
+	h.changeSalesOrder(w, r, "confirm", func(organizationID, userID, salesOrderID string) (*models.SalesOrder, error) {
+		return h.DB.ConfirmSalesOrder(r.Context(), organizationID, userID, salesOrderID)
+	})
+}
+
+func (h *Handler) AllocateSalesOrder(w http.ResponseWriter, r *http.Request) {
+	h.changeSalesOrder(w, r, "allocate", func(organizationID, userID, salesOrderID string) (*models.SalesOrder, error) {
+		return h.DB.AllocateSalesOrder(r.Context(), organizationID, userID, salesOrderID)
+	})
+}
+
+func (h *Handler) CancelSalesOrder(w http.ResponseWriter, r *http.Request) {
+	h.changeSalesOrder(w, r, "cancel", func(organizationID, userID, salesOrderID string) (*models.SalesOrder, error) {
+		return h.DB.CancelSalesOrder(r.Context(), organizationID, userID, salesOrderID)
+	})
+}
+
+// changeSalesOrder runs a body-less sales order action and maps its errors to responses
+func (h *Handler) changeSalesOrder(w http.ResponseWriter, r *http.Request, action string, change func(organizationID, userID, salesOrderID string) (*models.SalesOrder, error)) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	salesOrderID := mux.Vars(r)["salesOrderId"]
+	if salesOrderID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid sales order ID")
+		return
+	}
+
+	so, err := change(organizationID, userID, salesOrderID)
+	if err != nil {
+		switch {
+		case err.Error() == "sales order not found":
+			h.respondWithError(w, http.StatusNotFound, "Sales order not found")
+		case strings.HasPrefix(err.Error(), "sales order cannot be"),
+			strings.HasPrefix(err.Error(), "insufficient inventory"):
+			h.respondWithError(w, http.StatusConflict, err.Error())
+		default:
+			h.respondWithServerError(w, r, err, fmt.Sprintf("Failed to %s sales order", action))
+		}
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, so)
+}
+
+func (h *Handler) ShipSalesOrder(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	salesOrderID := mux.Vars(r)["salesOrderId"]
+	if salesOrderID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid sales order ID")
+		return
+	}
+
+	var req models.ShipSalesOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Basic validation
+	if len(req.Lines) == 0 {
+		h.respondWithError(w, http.StatusBadRequest, "At least one shipped line is required")
+		return
+	}
+	for _, line := range req.Lines {
+		if line.LineID == "" {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid line ID")
+			return
+		}
+		if line.Quantity <= 0 {
+			h.respondWithError(w, http.StatusBadRequest, "Shipped quantity must be positive")
+			return
+		}
+	}
+
+	result, err := h.DB.ShipSalesOrder(r.Context(), organizationID, userID, salesOrderID, req)
+	if err != nil {
+		switch {
+		case err.Error() == "sales order not found":
+			h.respondWithError(w, http.StatusNotFound, "Sales order not found")
+		case strings.HasPrefix(err.Error(), "over-shipment"),
+			strings.HasPrefix(err.Error(), "sales order cannot be shipped"),
+			strings.HasPrefix(err.Error(), "insufficient inventory"):
+			h.respondWithError(w, http.StatusConflict, err.Error())
+		case strings.HasPrefix(err.Error(), "sales order line not found"):
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.respondWithServerError(w, r, err, "Failed to ship sales order")
+		}
+		return
+	}
+
+	// Log the OUT transactions posted by the shipment
+	for _, transaction := range result.Transactions {
+		logReq := models.NewTransactionChangeLog(organizationID, userID, transaction.ID, transaction.SKUID)
+		reason := fmt.Sprintf("OUT transaction - %d units shipped on %s", transaction.Quantity, result.SalesOrder.OrderNumber)
+		logReq.Reason = &reason
+		h.Store.LogChange(r.Context(), organizationID, userID, *logReq)
+	}
+
+	h.respondWithJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,374 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"flex-erp-poc/internal/database"
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// savedViewField is the field a view's column, filter or sort key reads, for field
+// permission checks. Search and relevance read every field.
+func savedViewField(key string) string {
+	key = strings.TrimPrefix(key, "-")
+	switch {
+	case strings.HasPrefix(key, customFieldFilterPrefix):
+		return "custom_fields"
+	case key == "search" || key == "relevance":
+		return "*"
+	}
+	return key
+}
+
+// hiddenSavedViewKeys lists the filters and sort of a view that read fields the role
+// hides. A view that has any cannot be shown to or applied by that role.
+func hiddenSavedViewKeys(roleName, resource string, filters map[string]string, sort string) []string {
+	hidden := make([]string, 0)
+	for key := range filters {
+		if models.GetFieldPermission(roleName, resource, savedViewField(key)) == "hidden" {
+			hidden = append(hidden, key)
+		}
+	}
+	if sort != "" && models.GetFieldPermission(roleName, resource, savedViewField(sort)) == "hidden" {
+		hidden = append(hidden, "sort")
+	}
+	return hidden
+}
+
+// visibleColumns drops the columns the role hides
+func visibleColumns(roleName, resource string, columns []string) []string {
+	visible := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if models.GetFieldPermission(roleName, resource, savedViewField(column)) != "hidden" {
+			visible = append(visible, column)
+		}
+	}
+	return visible
+}
+
+// canUseSavedView reports whether a role may see a view: it must be able to read
+// the resource and the view must not filter or sort on fields the role hides
+func canUseSavedView(roleName string, view *models.SavedView) bool {
+	role := models.GetRoleByName(roleName)
+	if role == nil || !role.HasPermission(view.Resource, "read") {
+		return false
+	}
+	return len(hiddenSavedViewKeys(roleName, view.Resource, view.Filters, view.Sort)) == 0
+}
+
+// checkSavedViewFields rejects a view being saved with fields the saving role hides
+func checkSavedViewFields(roleName, resource string, filters map[string]string, sort *string, columns []string) error {
+	sortKey := ""
+	if sort != nil {
+		sortKey = *sort
+	}
+	hidden := hiddenSavedViewKeys(roleName, resource, filters, sortKey)
+	for _, column := range columns {
+		if models.GetFieldPermission(roleName, resource, savedViewField(column)) == "hidden" {
+			hidden = append(hidden, column)
+		}
+	}
+	if len(hidden) > 0 {
+		return fmt.Errorf("invalid saved view: %s not visible to your role", strings.Join(hidden, ", "))
+	}
+	return nil
+}
+
+// stripHiddenFields removes the fields a role hides from every item of a list
+func stripHiddenFields(items interface{}, roleName, resource string) (interface{}, error) {
+	hides := false
+	for _, level := range models.GetFieldPermissions(roleName, resource) {
+		if level == "hidden" {
+			hides = true
+			break
+		}
+	}
+	if !hides {
+		return items, nil
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		for field := range row {
+			if models.GetFieldPermission(roleName, resource, field) == "hidden" {
+				delete(row, field)
+			}
+		}
+	}
+	return rows, nil
+}
+
+func (h *Handler) GetSavedViews(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	roleName, _ := middleware.GetUserRoleFromContext(r.Context())
+
+	views, err := h.DB.GetSavedViews(r.Context(), organizationID, userID, r.URL.Query().Get("resource"))
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch saved views")
+		return
+	}
+
+	// Shared views the role cannot use are left out
+	visible := make([]*models.SavedView, 0, len(views))
+	for _, view := range views {
+		if view.UserID != userID && !canUseSavedView(roleName, view) {
+			continue
+		}
+		view.Columns = visibleColumns(roleName, view.Resource, view.Columns)
+		visible = append(visible, view)
+	}
+
+	h.respondWithJSON(w, http.StatusOK, visible)
+}
+
+// getSavedView loads a view the user may see, writing the error response otherwise
+func (h *Handler) getSavedView(w http.ResponseWriter, r *http.Request) (*models.SavedView, string, bool) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return nil, "", false
+	}
+
+	viewID := mux.Vars(r)["viewId"]
+	if viewID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid saved view ID")
+		return nil, "", false
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	roleName, _ := middleware.GetUserRoleFromContext(r.Context())
+
+	view, err := h.DB.GetSavedView(r.Context(), organizationID, userID, viewID)
+	if err != nil {
+		if err.Error() == "saved view not found" {
+			h.respondWithError(w, http.StatusNotFound, "Saved view not found")
+			return nil, "", false
+		}
+		h.respondWithServerError(w, r, err, "Failed to fetch saved view")
+		return nil, "", false
+	}
+
+	if !canUseSavedView(roleName, view) {
+		h.respondWithError(w, http.StatusForbidden, "Saved view uses fields your role cannot see")
+		return nil, "", false
+	}
+	view.Columns = visibleColumns(roleName, view.Resource, view.Columns)
+
+	return view, roleName, true
+}
+
+func (h *Handler) GetSavedView(w http.ResponseWriter, r *http.Request) {
+	view, _, ok := h.getSavedView(w, r)
+	if !ok {
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, view)
+}
+
+func (h *Handler) CreateSavedView(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	roleName, _ := middleware.GetUserRoleFromContext(r.Context())
+
+	var req models.CreateSavedViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Resource == "" || req.Name == "" {
+		h.respondWithError(w, http.StatusBadRequest, "resource and name are required")
+		return
+	}
+
+	role := models.GetRoleByName(roleName)
+	if role == nil || !role.HasPermission(req.Resource, "read") {
+		h.respondWithError(w, http.StatusForbidden, "Insufficient permissions")
+		return
+	}
+	if err := checkSavedViewFields(roleName, req.Resource, req.Filters, &req.Sort, req.Columns); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	view, err := h.DB.CreateSavedView(r.Context(), organizationID, userID, req)
+	if err != nil {
+		h.respondWithSavedViewError(w, r, err, "Failed to create saved view")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, view)
+}
+
+func (h *Handler) UpdateSavedView(w http.ResponseWriter, r *http.Request) {
+	view, roleName, ok := h.getSavedView(w, r)
+	if !ok {
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	var req models.UpdateSavedViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := checkSavedViewFields(roleName, view.Resource, req.Filters, req.Sort, req.Columns); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	view, err := h.DB.UpdateSavedView(r.Context(), view.OrganizationID, userID, view.ID, req)
+	if err != nil {
+		h.respondWithSavedViewError(w, r, err, "Failed to update saved view")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, view)
+}
+
+func (h *Handler) DeleteSavedView(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	viewID := mux.Vars(r)["viewId"]
+	if viewID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid saved view ID")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	if err := h.DB.DeleteSavedView(r.Context(), organizationID, userID, viewID); err != nil {
+		h.respondWithSavedViewError(w, r, err, "Failed to delete saved view")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) respondWithSavedViewError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case err.Error() == "saved view not found":
+		h.respondWithError(w, http.StatusNotFound, "Saved view not found")
+	case err.Error() == "saved view can only be changed by its owner":
+		h.respondWithError(w, http.StatusForbidden, "Saved view can only be changed by its owner")
+	case strings.HasPrefix(err.Error(), "invalid saved view"):
+		h.respondWithError(w, http.StatusBadRequest, err.Error())
+	case strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint"):
+		h.respondWithError(w, http.StatusConflict, "You already have a view with this name for this resource")
+	default:
+		h.respondWithServerError(w, r, err, message)
+	}
+}
+
+// ApplySavedView runs a view against its list endpoint. The request's cursor, page
+// and limit page through the results.
+func (h *Handler) ApplySavedView(w http.ResponseWriter, r *http.Request) {
+	view, roleName, ok := h.getSavedView(w, r)
+	if !ok {
+		return
+	}
+
+	resolved, err := database.ResolveSavedViewQuery(view, time.Now())
+	if err != nil {
+		h.respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := url.Values{}
+	for key, value := range resolved {
+		query.Set(key, value)
+	}
+	for _, key := range []string{"cursor", "page", "limit"} {
+		if value := r.URL.Query().Get(key); value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	locales, ok := h.requestLocales(r, view.OrganizationID)
+	if !ok {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid locale")
+		return
+	}
+
+	columns, err := h.DB.GetSavedViewColumns(r.Context(), view.OrganizationID, view, locales)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to resolve column labels")
+		return
+	}
+
+	items, page, err := h.listSavedViewResource(r.Context(), view.OrganizationID, view.Resource, query)
+	if err != nil {
+		if isListParamError(err) {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to apply saved view")
+		return
+	}
+
+	items, err = stripHiddenFields(items, roleName, view.Resource)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to apply saved view")
+		return
+	}
+
+	setContentLanguage(w, locales)
+	h.respondWithJSON(w, http.StatusOK, models.AppliedSavedView{
+		View:    view,
+		Columns: columns,
+		Query:   resolved,
+		Results: models.ListResponse{Items: items, PageInfo: *page},
+	})
+}
+
+func (h *Handler) listSavedViewResource(ctx context.Context, organizationID, resource string, query url.Values) (interface{}, *models.PageInfo, error) {
+	switch resource {
+	case "skus":
+		skus, page, err := h.Store.GetSKUs(ctx, organizationID, parseSKUListParams(query))
+		return skus, page, err
+	case "inventory":
+		inventory, page, err := h.Store.GetInventoryWithSKUs(ctx, organizationID, parseInventoryListParams(query))
+		return inventory, page, err
+	case "transactions":
+		transactions, page, err := h.Store.GetTransactionsWithDetails(ctx, organizationID, parseTransactionListParams(query))
+		return transactions, page, err
+	case "users":
+		users, page, err := h.Store.GetUsersWithDetails(ctx, organizationID, parseUserListParams(query))
+		return users, page, err
+	}
+	return nil, nil, fmt.Errorf("unsupported saved view resource %s", resource)
+}
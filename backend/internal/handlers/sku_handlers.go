@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -20,43 +21,60 @@ func (h *Handler) GetSKUs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse query parameters
+	skus, page, err := h.Store.GetSKUs(r.Context(), orgID, parseSKUListParams(r.URL.Query()))
+	if err != nil {
+		if isListParamError(err) {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to retrieve SKUs")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, models.ListResponse{Items: skus, PageInfo: *page})
+}
+
+func parseSKUListParams(query url.Values) models.SKUListParams {
 	params := models.SKUListParams{
-		IncludeDeactivated: r.URL.Query().Get("includeDeactivated") == "true",
+		IncludeDeactivated: query.Get("includeDeactivated") == "true",
+		IncludeArchived:    query.Get("includeArchived") == "true",
 		Page:               1,
 		Limit:              50,
 	}
 
-	if category := r.URL.Query().Get("category"); category != "" {
+	if category := query.Get("category"); category != "" {
 		params.Category = &category
 	}
 
-	if search := r.URL.Query().Get("search"); search != "" {
+	if categoryID := query.Get("category_id"); categoryID != "" {
+		params.CategoryID = &categoryID
+	}
+
+	if supplierID := query.Get("supplier_id"); supplierID != "" {
+		params.SupplierID = &supplierID
+	}
+
+	if search := query.Get("search"); search != "" {
 		params.Search = &search
 	}
 
-	if page := r.URL.Query().Get("page"); page != "" {
+	params.CustomFields = parseCustomFieldFilters(query)
+	params.Sort = query.Get("sort")
+	params.Cursor = query.Get("cursor")
+
+	if page := query.Get("page"); page != "" {
 		if p, err := strconv.Atoi(page); err == nil && p > 0 {
 			params.Page = p
 		}
 	}
 
-	if limit := r.URL.Query().Get("limit"); limit != "" {
+	if limit := query.Get("limit"); limit != "" {
 		if l, err := strconv.Atoi(limit); err == nil && l > 0 && l <= 100 {
 			params.Limit = l
 		}
 	}
 
-	skus, err := h.DB.GetSKUs(orgID, params)
-	if err != nil {
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to retrieve SKUs")
-		return
-	}
-
-	h.respondWithJSON(w, http.StatusOK, map[string]interface{}{
-		"skus":   skus,
-		"params": params,
-	})
+	return params
 }
 
 func (h *Handler) GetSKU(w http.ResponseWriter, r *http.Request) {
@@ -73,13 +91,13 @@ func (h *Handler) GetSKU(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sku, err := h.DB.GetSKUByID(orgID, skuID)
+	sku, err := h.Store.GetSKUByID(r.Context(), orgID, skuID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			h.respondWithError(w, http.StatusNotFound, "SKU not found")
 			return
 		}
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to retrieve SKU")
+		h.respondWithServerError(w, r, err, "Failed to retrieve SKU")
 		return
 	}
 
@@ -105,14 +123,23 @@ func (h *Handler) CreateSKU(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sku, err := h.DB.CreateSKU(orgID, req)
+	sku, err := h.Store.CreateSKU(r.Context(), orgID, req)
 	if err != nil {
+		if message, ok := barcodeConflict(err); ok {
+			h.respondWithError(w, http.StatusConflict, message)
+			return
+		}
 		// Check for unique constraint violation
 		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
 			h.respondWithError(w, http.StatusConflict, "SKU code already exists in this organization")
 			return
 		}
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to create SKU")
+		if err.Error() == "category not found" || err.Error() == "invalid category name" ||
+			strings.HasPrefix(err.Error(), "invalid custom field values") || strings.HasPrefix(err.Error(), "invalid barcode") {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to create SKU")
 		return
 	}
 
@@ -120,7 +147,7 @@ func (h *Handler) CreateSKU(w http.ResponseWriter, r *http.Request) {
 	userID, _ := r.Context().Value("user_id").(string)
 	logReq := models.NewSKUChangeLog(orgID, userID, sku.ID, "create") // orgID converted in LogChange
 	logReq.Reason = &[]string{"New SKU created"}[0]
-	h.DB.LogChange(orgID, userID, *logReq)
+	h.Store.LogChange(r.Context(), orgID, userID, *logReq)
 
 	h.respondWithJSON(w, http.StatusCreated, sku)
 }
@@ -151,13 +178,22 @@ func (h *Handler) UpdateSKU(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sku, err := h.DB.UpdateSKU(orgID, skuID, req)
+	sku, err := h.Store.UpdateSKU(r.Context(), orgID, skuID, req)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			h.respondWithError(w, http.StatusNotFound, "SKU not found")
 			return
 		}
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to update SKU")
+		if message, ok := barcodeConflict(err); ok {
+			h.respondWithError(w, http.StatusConflict, message)
+			return
+		}
+		if err.Error() == "category not found" || err.Error() == "invalid category name" ||
+			strings.HasPrefix(err.Error(), "invalid custom field values") || strings.HasPrefix(err.Error(), "invalid barcode") {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to update SKU")
 		return
 	}
 
@@ -165,7 +201,7 @@ func (h *Handler) UpdateSKU(w http.ResponseWriter, r *http.Request) {
 	userID, _ := r.Context().Value("user_id").(string)
 	logReq := models.NewSKUChangeLog(orgID, userID, sku.ID, "update")
 	logReq.Reason = &[]string{"SKU information updated"}[0]
-	h.DB.LogChange(orgID, userID, *logReq)
+	h.Store.LogChange(r.Context(), orgID, userID, *logReq)
 
 	h.respondWithJSON(w, http.StatusOK, sku)
 }
@@ -192,13 +228,13 @@ func (h *Handler) UpdateSKUStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sku, err := h.DB.UpdateSKUStatus(orgID, skuID, req.IsActive)
+	sku, err := h.Store.UpdateSKUStatus(r.Context(), orgID, skuID, req.IsActive)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			h.respondWithError(w, http.StatusNotFound, "SKU not found")
 			return
 		}
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to update SKU status")
+		h.respondWithServerError(w, r, err, "Failed to update SKU status")
 		return
 	}
 
@@ -214,7 +250,87 @@ func (h *Handler) UpdateSKUStatus(w http.ResponseWriter, r *http.Request) {
 		reason = "SKU deactivated"
 	}
 	logReq.Reason = &reason
-	h.DB.LogChange(orgID, userID, *logReq)
+	h.Store.LogChange(r.Context(), orgID, userID, *logReq)
 
 	h.respondWithJSON(w, http.StatusOK, sku)
 }
+
+// BulkUpdateSKUs applies one action to a list of SKUs or to every SKU matching a
+// filter of SKU list query parameters
+func (h *Handler) BulkUpdateSKUs(w http.ResponseWriter, r *http.Request) {
+	orgID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Organization not found in context")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	var req models.BulkSKURequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Archiving takes SKUs out of the list, so it needs the delete permission
+	if req.Action == "archive" {
+		roleName, _ := middleware.GetUserRoleFromContext(r.Context())
+		role := models.GetRoleByName(roleName)
+		if role == nil || !role.HasPermission("skus", "delete") {
+			h.respondWithError(w, http.StatusForbidden, "Insufficient permissions")
+			return
+		}
+	}
+
+	var filter *models.SKUListParams
+	if req.Filter != nil {
+		query := url.Values{}
+		for key, value := range req.Filter {
+			if !isSKUFilterKey(key) {
+				h.respondWithError(w, http.StatusBadRequest, "invalid bulk request: unsupported filter "+key)
+				return
+			}
+			query.Set(key, value)
+		}
+		params := parseSKUListParams(query)
+		filter = &params
+	}
+
+	response, err := h.Store.BulkUpdateSKUs(r.Context(), orgID, userID, req, filter)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid bulk request") || isListParamError(err) ||
+			err.Error() == "category not found" || err.Error() == "invalid category name" {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err.Error() == "supplier not found" {
+			h.respondWithError(w, http.StatusNotFound, "Supplier not found")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to apply bulk SKU action")
+		return
+	}
+
+	if !response.Applied {
+		h.respondWithJSON(w, http.StatusUnprocessableEntity, response)
+		return
+	}
+	h.respondWithJSON(w, http.StatusOK, response)
+}
+
+// isSKUFilterKey reports whether key is a SKU list filter, including cf.<field> filters
+func isSKUFilterKey(key string) bool {
+	if strings.HasPrefix(key, "cf.") && len(key) > len("cf.") {
+		return true
+	}
+	for _, allowed := range models.SavedViewFilters["skus"] {
+		if key == allowed {
+			return true
+		}
+	}
+	return false
+}
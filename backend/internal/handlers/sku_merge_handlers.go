@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) FindDuplicateSKUs(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	query := r.URL.Query()
+	params := models.SKUDuplicateParams{
+		MinScore:        0.6,
+		IncludeInactive: query.Get("include_inactive") == "true",
+		Limit:           50,
+	}
+	if minScore := query.Get("min_score"); minScore != "" {
+		score, err := strconv.ParseFloat(minScore, 64)
+		if err != nil {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid min_score")
+			return
+		}
+		params.MinScore = score
+	}
+	if limit := query.Get("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil && l > 0 && l <= 200 {
+			params.Limit = l
+		}
+	}
+
+	duplicates, err := h.DB.FindDuplicateSKUs(r.Context(), organizationID, params)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid duplicate search") {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to find duplicate SKUs")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, duplicates)
+}
+
+func (h *Handler) MergeSKUs(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	skuID := mux.Vars(r)["skuId"]
+	if skuID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid SKU ID")
+		return
+	}
+
+	var req models.MergeSKURequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.SourceSKUID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Source SKU ID is required")
+		return
+	}
+
+	result, err := h.DB.MergeSKUs(r.Context(), organizationID, userID, skuID, req)
+	if err != nil {
+		switch {
+		case err.Error() == "SKU not found":
+			h.respondWithError(w, http.StatusNotFound, "SKU not found")
+		case strings.HasPrefix(err.Error(), "invalid merge"):
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			h.respondWithServerError(w, r, err, "Failed to merge SKUs")
+		}
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, result)
+}
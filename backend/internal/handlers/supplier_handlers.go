@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) GetSuppliers(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := models.SupplierListParams{
+		IncludeInactive: r.URL.Query().Get("includeInactive") == "true",
+		Page:            1,
+		Limit:           50,
+	}
+
+	// Parse query parameters
+	query := r.URL.Query()
+	if search := query.Get("search"); search != "" {
+		params.Search = &search
+	}
+	if pageStr := query.Get("page"); pageStr != "" {
+		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
+			params.Page = page
+		}
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
+			params.Limit = limit
+		}
+	}
+
+	suppliers, err := h.DB.GetSuppliers(r.Context(), organizationID, params)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch suppliers")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, suppliers)
+}
+
+func (h *Handler) GetSupplier(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	supplierID := mux.Vars(r)["supplierId"]
+	if supplierID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid supplier ID")
+		return
+	}
+
+	supplier, err := h.DB.GetSupplierByID(r.Context(), organizationID, supplierID)
+	if err != nil {
+		if err.Error() == "supplier not found" {
+			h.respondWithError(w, http.StatusNotFound, "Supplier not found")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to fetch supplier")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, supplier)
+}
+
+func (h *Handler) CreateSupplier(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.CreateSupplierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Basic validation
+	if strings.TrimSpace(req.Name) == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Supplier name is required")
+		return
+	}
+	if req.Currency != "" && len(req.Currency) != 3 {
+		h.respondWithError(w, http.StatusBadRequest, "Currency must be a 3-letter ISO code")
+		return
+	}
+	if req.LeadTimeDays != nil && *req.LeadTimeDays < 0 {
+		h.respondWithError(w, http.StatusBadRequest, "Lead time must be non-negative")
+		return
+	}
+
+	supplier, err := h.DB.CreateSupplier(r.Context(), organizationID, req)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
+			h.respondWithError(w, http.StatusConflict, "Supplier already exists in this organization")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to create supplier")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, supplier)
+}
+
+func (h *Handler) UpdateSupplier(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	supplierID := mux.Vars(r)["supplierId"]
+	if supplierID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid supplier ID")
+		return
+	}
+
+	var req models.UpdateSupplierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Basic validation
+	if strings.TrimSpace(req.Name) == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Supplier name is required")
+		return
+	}
+	if req.Currency != "" && len(req.Currency) != 3 {
+		h.respondWithError(w, http.StatusBadRequest, "Currency must be a 3-letter ISO code")
+		return
+	}
+	if req.LeadTimeDays != nil && *req.LeadTimeDays < 0 {
+		h.respondWithError(w, http.StatusBadRequest, "Lead time must be non-negative")
+		return
+	}
+
+	supplier, err := h.DB.UpdateSupplier(r.Context(), organizationID, supplierID, req)
+	if err != nil {
+		if err.Error() == "supplier not found" {
+			h.respondWithError(w, http.StatusNotFound, "Supplier not found")
+			return
+		}
+		if strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint") {
+			h.respondWithError(w, http.StatusConflict, "Supplier already exists in this organization")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to update supplier")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, supplier)
+}
+
+func (h *Handler) GetSKUSuppliers(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	skuID := mux.Vars(r)["skuId"]
+	if skuID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid SKU ID")
+		return
+	}
+
+	links, err := h.DB.GetSKUSuppliers(r.Context(), organizationID, skuID)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch SKU suppliers")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, links)
+}
+
+func (h *Handler) UpsertSKUSupplier(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	skuID := vars["skuId"]
+	supplierID := vars["supplierId"]
+	if skuID == "" || supplierID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid SKU or supplier ID")
+		return
+	}
+
+	var req models.UpsertSKUSupplierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Basic validation
+	if req.LastPurchasePrice != nil && *req.LastPurchasePrice < 0 {
+		h.respondWithError(w, http.StatusBadRequest, "Last purchase price must be non-negative")
+		return
+	}
+	if req.MinimumOrderQuantity < 0 {
+		h.respondWithError(w, http.StatusBadRequest, "Minimum order quantity must be positive")
+		return
+	}
+
+	links, err := h.DB.UpsertSKUSupplier(r.Context(), organizationID, skuID, supplierID, req)
+	if err != nil {
+		if err.Error() == "SKU not found" || err.Error() == "supplier not found" {
+			h.respondWithError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to link supplier")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, links)
+}
+
+func (h *Handler) DeleteSKUSupplier(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	skuID := vars["skuId"]
+	supplierID := vars["supplierId"]
+	if skuID == "" || supplierID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid SKU or supplier ID")
+		return
+	}
+
+	if err := h.DB.DeleteSKUSupplier(r.Context(), organizationID, skuID, supplierID); err != nil {
+		if err.Error() == "SKU supplier link not found" {
+			h.respondWithError(w, http.StatusNotFound, "SKU supplier link not found")
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to unlink supplier")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -18,13 +19,25 @@ func (h *Handler) GetTransactions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	transactions, page, err := h.Store.GetTransactionsWithDetails(r.Context(), organizationID, parseTransactionListParams(r.URL.Query()))
+	if err != nil {
+		if isListParamError(err) {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to fetch transactions")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, models.ListResponse{Items: transactions, PageInfo: *page})
+}
+
+func parseTransactionListParams(query url.Values) models.TransactionListParams {
 	params := models.TransactionListParams{
 		Page:  1,
 		Limit: 50,
 	}
 
-	// Parse query parameters
-	query := r.URL.Query()
 	if transactionType := query.Get("transaction_type"); transactionType != "" {
 		params.TransactionType = &transactionType
 	}
@@ -34,6 +47,9 @@ func (h *Handler) GetTransactions(w http.ResponseWriter, r *http.Request) {
 	if category := query.Get("category"); category != "" {
 		params.Category = &category
 	}
+	if categoryID := query.Get("category_id"); categoryID != "" {
+		params.CategoryID = &categoryID
+	}
 	if search := query.Get("search"); search != "" {
 		params.Search = &search
 	}
@@ -53,14 +69,11 @@ func (h *Handler) GetTransactions(w http.ResponseWriter, r *http.Request) {
 	if endDate := query.Get("end_date"); endDate != "" {
 		params.EndDate = &endDate
 	}
+	params.CustomFields = parseCustomFieldFilters(query)
+	params.Sort = query.Get("sort")
+	params.Cursor = query.Get("cursor")
 
-	transactions, err := h.DB.GetTransactionsWithDetails(organizationID, params)
-	if err != nil {
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to fetch transactions")
-		return
-	}
-
-	h.respondWithJSON(w, http.StatusOK, transactions)
+	return params
 }
 
 func (h *Handler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
@@ -99,14 +112,21 @@ func (h *Handler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
 		h.respondWithError(w, http.StatusBadRequest, "Unit cost must be non-negative")
 		return
 	}
+	if req.ReservationID != nil && req.TransactionType != "out" {
+		h.respondWithError(w, http.StatusBadRequest, "Only 'out' transactions can consume a reservation")
+		return
+	}
 
-	transaction, err := h.DB.CreateTransaction(organizationID, userID, req)
+	transaction, err := h.Store.CreateTransaction(r.Context(), organizationID, userID, req)
 	if err != nil {
-		if err.Error() == "insufficient inventory: no inventory record found" ||
-			err.Error()[:21] == "insufficient inventory" {
+		if strings.HasPrefix(err.Error(), "insufficient inventory") ||
+			strings.HasPrefix(err.Error(), "reservation") ||
+			strings.HasPrefix(err.Error(), "SKU has variants") ||
+			strings.HasPrefix(err.Error(), "invalid custom field values") ||
+			strings.HasPrefix(err.Error(), "business rule violated") {
 			h.respondWithError(w, http.StatusBadRequest, err.Error())
 		} else {
-			h.respondWithError(w, http.StatusInternalServerError, "Failed to create transaction")
+			h.respondWithServerError(w, r, err, "Failed to create transaction")
 		}
 		return
 	}
@@ -118,7 +138,7 @@ func (h *Handler) CreateTransaction(w http.ResponseWriter, r *http.Request) {
 		reason = fmt.Sprintf("%s: %s", reason, *req.Notes)
 	}
 	logReq.Reason = &reason
-	h.DB.LogChange(organizationID, userID, *logReq)
+	h.Store.LogChange(r.Context(), organizationID, userID, *logReq)
 
 	h.respondWithJSON(w, http.StatusCreated, transaction)
 }
@@ -140,16 +160,24 @@ func (h *Handler) GetTransactionSummary(w http.ResponseWriter, r *http.Request)
 	if category := query.Get("category"); category != "" {
 		params.Category = &category
 	}
+	if categoryID := query.Get("category_id"); categoryID != "" {
+		params.CategoryID = &categoryID
+	}
 	if startDate := query.Get("start_date"); startDate != "" {
 		params.StartDate = &startDate
 	}
 	if endDate := query.Get("end_date"); endDate != "" {
 		params.EndDate = &endDate
 	}
+	params.CustomFields = parseCustomFieldFilters(query)
 
-	summary, err := h.DB.GetTransactionSummary(organizationID, params)
+	summary, err := h.Store.GetTransactionSummary(r.Context(), organizationID, params)
 	if err != nil {
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to fetch transaction summary")
+		if strings.HasPrefix(err.Error(), "invalid custom field filter") {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to fetch transaction summary")
 		return
 	}
 
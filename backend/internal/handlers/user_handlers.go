@@ -2,11 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"flex-erp-poc/internal/middleware"
 	"flex-erp-poc/internal/models"
+	"flex-erp-poc/internal/utils"
 
 	"github.com/gorilla/mux"
 )
@@ -19,54 +22,55 @@ func (h *Handler) GetUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse query parameters for filtering
+	users, page, err := h.Store.GetUsersWithDetails(r.Context(), orgID, parseUserListParams(r.URL.Query()))
+	if err != nil {
+		if isListParamError(err) {
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.respondWithServerError(w, r, err, "Failed to fetch users")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, models.ListResponse{Items: users, PageInfo: *page})
+}
+
+func parseUserListParams(query url.Values) models.UserListParams {
 	params := models.UserListParams{
 		Page:  1,
 		Limit: 50,
 	}
 
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+	if pageStr := query.Get("page"); pageStr != "" {
 		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
 			params.Page = page
 		}
 	}
 
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+	if limitStr := query.Get("limit"); limitStr != "" {
 		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 && limit <= 100 {
 			params.Limit = limit
 		}
 	}
 
-	if role := r.URL.Query().Get("role"); role != "" {
+	if role := query.Get("role"); role != "" {
 		params.Role = &role
 	}
 
-	if isActiveStr := r.URL.Query().Get("is_active"); isActiveStr != "" {
+	if isActiveStr := query.Get("is_active"); isActiveStr != "" {
 		if isActive, err := strconv.ParseBool(isActiveStr); err == nil {
 			params.IsActive = &isActive
 		}
 	}
 
-	if search := r.URL.Query().Get("search"); search != "" {
+	if search := query.Get("search"); search != "" {
 		params.Search = &search
 	}
 
-	users, err := h.DB.GetUsersWithDetails(orgID, params)
-	if err != nil {
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to fetch users")
-		return
-	}
+	params.Sort = query.Get("sort")
+	params.Cursor = query.Get("cursor")
 
-	response := map[string]interface{}{
-		"users": users,
-		"pagination": map[string]interface{}{
-			"page":  params.Page,
-			"limit": params.Limit,
-			"total": len(users),
-		},
-	}
-
-	h.respondWithJSON(w, http.StatusOK, response)
+	return params
 }
 
 // POST /api/users
@@ -103,16 +107,23 @@ func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.DB.CreateUser(orgID, req)
+	user, err := h.Store.CreateUser(r.Context(), orgID, req)
 	if err != nil {
 		if err.Error() == "user with this email already exists" {
 			h.respondWithError(w, http.StatusConflict, err.Error())
 			return
 		}
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to create user")
+		h.respondWithServerError(w, r, err, "Failed to create user")
 		return
 	}
 
+	if userID, ok := middleware.GetUserIDFromContext(r.Context()); ok {
+		logReq := models.NewUserChangeLog(orgID, userID, user.ID, "create")
+		reason := fmt.Sprintf("User %s created with role %s", user.Email, user.Role)
+		logReq.Reason = &reason
+		h.Store.LogChange(r.Context(), orgID, userID, *logReq)
+	}
+
 	h.respondWithJSON(w, http.StatusCreated, user)
 }
 
@@ -157,13 +168,23 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.DB.UpdateUser(orgID, userID, req)
+	// Validate preferred locale, an empty one clears it
+	if req.PreferredLocale != nil && *req.PreferredLocale != "" {
+		locale, ok := utils.NormalizeLocale(*req.PreferredLocale)
+		if !ok {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid preferred_locale")
+			return
+		}
+		req.PreferredLocale = &locale
+	}
+
+	user, err := h.Store.UpdateUser(r.Context(), orgID, userID, req)
 	if err != nil {
 		if err.Error() == "user not found or not authorized" {
 			h.respondWithError(w, http.StatusNotFound, err.Error())
 			return
 		}
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to update user")
+		h.respondWithServerError(w, r, err, "Failed to update user")
 		return
 	}
 
@@ -185,13 +206,13 @@ func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.DB.DeleteUser(orgID, userID)
+	err := h.Store.DeleteUser(r.Context(), orgID, userID)
 	if err != nil {
 		if err.Error() == "user not found or not authorized" {
 			h.respondWithError(w, http.StatusNotFound, err.Error())
 			return
 		}
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to delete user")
+		h.respondWithServerError(w, r, err, "Failed to delete user")
 		return
 	}
 
@@ -223,9 +244,9 @@ func (h *Handler) GetUserPermissions(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get user to determine their role
-	users, err := h.DB.GetUsersWithDetails(orgID, models.UserListParams{Page: 1, Limit: 100})
+	users, _, err := h.Store.GetUsersWithDetails(r.Context(), orgID, models.UserListParams{Page: 1, Limit: 100})
 	if err != nil {
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to fetch user")
+		h.respondWithServerError(w, r, err, "Failed to fetch user")
 		return
 	}
 
@@ -279,9 +300,9 @@ func (h *Handler) CheckUserPermission(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	hasPermission, err := h.DB.CheckUserPermission(userID, req.Resource, req.Action)
+	hasPermission, err := h.Store.CheckUserPermission(r.Context(), userID, req.Resource, req.Action)
 	if err != nil {
-		h.respondWithError(w, http.StatusInternalServerError, "Failed to check permission")
+		h.respondWithServerError(w, r, err, "Failed to check permission")
 		return
 	}
 
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) GetSKUVariants(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	skuID := mux.Vars(r)["skuId"]
+	if skuID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid SKU ID")
+		return
+	}
+
+	variants, err := h.DB.GetSKUVariants(r.Context(), organizationID, skuID)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch SKU variants")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, variants)
+}
+
+func (h *Handler) GenerateSKUVariants(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "User not found in context")
+		return
+	}
+
+	skuID := mux.Vars(r)["skuId"]
+	if skuID == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid SKU ID")
+		return
+	}
+
+	var req models.GenerateVariantsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	result, err := h.DB.GenerateVariants(r.Context(), organizationID, skuID, req)
+	if err != nil {
+		switch {
+		case err.Error() == "SKU not found":
+			h.respondWithError(w, http.StatusNotFound, "SKU not found")
+		case strings.HasPrefix(err.Error(), "invalid variant attributes"):
+			h.respondWithError(w, http.StatusBadRequest, err.Error())
+		case strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint"):
+			h.respondWithError(w, http.StatusConflict, "A generated variant code already exists in this organization")
+		default:
+			h.respondWithServerError(w, r, err, "Failed to generate SKU variants")
+		}
+		return
+	}
+
+	// Log each generated variant as a new SKU
+	for _, variant := range result.Created {
+		logReq := models.NewSKUChangeLog(organizationID, userID, variant.ID, "create")
+		reason := fmt.Sprintf("Variant of %s generated", result.Parent.SKUCode)
+		logReq.Reason = &reason
+		h.Store.LogChange(r.Context(), organizationID, userID, *logReq)
+	}
+
+	h.respondWithJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) respondWithWebhookError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	switch {
+	case err.Error() == "webhook endpoint not found":
+		h.respondWithError(w, http.StatusNotFound, "Webhook endpoint not found")
+	case err.Error() == "webhook delivery not found":
+		h.respondWithError(w, http.StatusNotFound, "Webhook delivery not found")
+	case strings.HasPrefix(err.Error(), "invalid webhook endpoint"), strings.HasPrefix(err.Error(), "invalid status"):
+		h.respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		h.respondWithServerError(w, r, err, fallback)
+	}
+}
+
+func (h *Handler) GetWebhookEndpoints(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	endpoints, err := h.DB.GetWebhookEndpoints(r.Context(), organizationID)
+	if err != nil {
+		h.respondWithServerError(w, r, err, "Failed to fetch webhook endpoints")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, endpoints)
+}
+
+func (h *Handler) GetWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	endpoint, err := h.DB.GetWebhookEndpoint(r.Context(), organizationID, mux.Vars(r)["webhookId"])
+	if err != nil {
+		h.respondWithWebhookError(w, r, err, "Failed to fetch webhook endpoint")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, endpoint)
+}
+
+func (h *Handler) CreateWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.CreateWebhookEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	endpoint, err := h.DB.CreateWebhookEndpoint(r.Context(), organizationID, req)
+	if err != nil {
+		h.respondWithWebhookError(w, r, err, "Failed to create webhook endpoint")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusCreated, endpoint)
+}
+
+func (h *Handler) UpdateWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.UpdateWebhookEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	endpoint, err := h.DB.UpdateWebhookEndpoint(r.Context(), organizationID, mux.Vars(r)["webhookId"], req)
+	if err != nil {
+		h.respondWithWebhookError(w, r, err, "Failed to update webhook endpoint")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, endpoint)
+}
+
+func (h *Handler) DeleteWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.DB.DeleteWebhookEndpoint(r.Context(), organizationID, mux.Vars(r)["webhookId"]); err != nil {
+		h.respondWithWebhookError(w, r, err, "Failed to delete webhook endpoint")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /webhooks/{webhookId}/deliveries lists the most recent deliveries first
+func (h *Handler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	query := r.URL.Query()
+	var params models.WebhookDeliveryListParams
+	if status := query.Get("status"); status != "" {
+		params.Status = &status
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			h.respondWithError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		params.Limit = limit
+	}
+
+	deliveries, err := h.DB.GetWebhookDeliveries(r.Context(), organizationID, mux.Vars(r)["webhookId"], params)
+	if err != nil {
+		h.respondWithWebhookError(w, r, err, "Failed to fetch webhook deliveries")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, deliveries)
+}
+
+// POST /webhooks/{webhookId}/deliveries/{deliveryId}/redeliver sends the same
+// event again, with the same event ID so receivers can deduplicate
+func (h *Handler) RedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	organizationID, ok := middleware.GetOrganizationIDFromContext(r.Context())
+	if !ok {
+		h.respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	vars := mux.Vars(r)
+	delivery, err := h.DB.RedeliverWebhook(r.Context(), organizationID, vars["webhookId"], vars["deliveryId"])
+	if err != nil {
+		h.respondWithWebhookError(w, r, err, "Failed to redeliver webhook")
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusAccepted, delivery)
+}
@@ -0,0 +1,127 @@
+package jobs
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"flex-erp-poc/internal/utils"
+)
+
+func at(value string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04:05", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestScheduleNext(t *testing.T) {
+	tests := []struct {
+		spec, from, want string
+	}{
+		{"* * * * *", "2024-01-01 10:07:30", "2024-01-01 10:08:00"},
+		{"*/15 * * * *", "2024-01-01 10:07:00", "2024-01-01 10:15:00"},
+		{"*/15 * * * *", "2024-01-01 10:45:00", "2024-01-01 11:00:00"},
+		{"5/15 * * * *", "2024-01-01 10:07:00", "2024-01-01 10:20:00"},
+		{"5/15 * * * *", "2024-01-01 10:50:00", "2024-01-01 11:05:00"},
+		{"0 9-17/4 * * *", "2024-01-01 10:00:00", "2024-01-01 13:00:00"},
+		{"0 9-17/4 * * *", "2024-01-01 17:30:00", "2024-01-02 09:00:00"},
+		{"0,30 8-9 * * *", "2024-01-01 08:30:00", "2024-01-01 09:00:00"},
+		{"30 8 * * MON-FRI", "2024-01-05 09:00:00", "2024-01-08 08:30:00"},
+		{"30 8 * * mon-fri", "2024-01-05 09:00:00", "2024-01-08 08:30:00"},
+		{"0 0 1 JAN,jul *", "2024-02-01 00:00:00", "2024-07-01 00:00:00"},
+		{"@hourly", "2024-01-01 10:07:00", "2024-01-01 11:00:00"},
+		{"@weekly", "2024-01-01 10:07:00", "2024-01-07 00:00:00"},
+		{"@yearly", "2024-01-01 00:00:00", "2025-01-01 00:00:00"},
+
+		// 7 is Sunday as well as 0
+		{"0 0 * * 7", "2024-01-01 00:00:00", "2024-01-07 00:00:00"},
+		{"0 0 * * 0", "2024-01-01 00:00:00", "2024-01-07 00:00:00"},
+		{"0 0 * * SUN", "2024-01-01 00:00:00", "2024-01-07 00:00:00"},
+		{"0 0 * * 5-7", "2024-01-01 00:00:00", "2024-01-05 00:00:00"},
+
+		// Both day fields restricted: either one matches. The 13th is a Saturday.
+		{"0 0 13 * MON", "2024-01-01 00:00:00", "2024-01-08 00:00:00"},
+		{"0 0 13 * MON", "2024-01-09 00:00:00", "2024-01-13 00:00:00"},
+		{"0 0 13 * MON", "2024-01-13 00:00:00", "2024-01-15 00:00:00"},
+		// One of them unrestricted: both must match, so odd-dated Mondays only
+		{"0 0 */2 * MON", "2024-01-01 00:00:00", "2024-01-15 00:00:00"},
+		{"0 0 13 * *", "2024-01-01 00:00:00", "2024-01-13 00:00:00"},
+
+		// Month and year rollover, skipping months without the day
+		{"0 0 31 * *", "2024-01-31 00:00:00", "2024-03-31 00:00:00"},
+		{"59 23 31 12 *", "2024-12-31 23:59:00", "2025-12-31 23:59:00"},
+		{"0 0 1 * *", "2024-12-15 12:00:00", "2025-01-01 00:00:00"},
+		{"0 0 29 2 *", "2024-03-01 00:00:00", "2028-02-29 00:00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec+" from "+tt.from, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule: %v", err)
+			}
+			if got := schedule.Next(at(tt.from)); !got.Equal(at(tt.want)) {
+				t.Errorf("Next = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNextIsUTC(t *testing.T) {
+	schedule, err := ParseSchedule("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2024, 1, 1, 8, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)) // 06:00 UTC
+	if got, want := schedule.Next(from), at("2024-01-01 09:00:00"); !got.Equal(want) {
+		t.Errorf("Next = %s, want %s", got, want)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	tests := []struct {
+		spec, wantErr string
+	}{
+		{"* * * *", "expected 5 fields"},
+		{"* * * * * *", "expected 5 fields"},
+		{"60 * * * *", "not between 0 and 59"},
+		{"* 24 * * *", "not between 0 and 23"},
+		{"* * 0 * *", "not between 1 and 31"},
+		{"* * * 13 *", "not between 1 and 12"},
+		{"* * * * 8", "not between 0 and 7"},
+		{"* * * FOO *", "not between 1 and 12"},
+		{"*/0 * * * *", "bad step"},
+		{"*/x * * * *", "bad step"},
+		{"30-10 * * * *", "bad range"},
+		{"0 0 30 2 *", "never runs"},
+		{"0 0 31 4,6,9,11 *", "never runs"},
+		{"@often", "expected 5 fields"},
+	}
+	for _, tt := range tests {
+		_, err := ParseSchedule(tt.spec)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ParseSchedule(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRetrySchedule(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{6, 32 * time.Minute},
+		{7, time.Hour},
+		{11, time.Hour},
+		{100, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := utils.Backoff(retryBase, retryLimit, tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Package jobs runs recurring background work on a cron schedule. Every server
+// instance runs a Scheduler with the same jobs registered; Postgres advisory locks
+// elect one instance to run each job, and the jobs table keeps the schedule, the
+// paused state and the history of runs shared between them.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"flex-erp-poc/internal/database"
+	"flex-erp-poc/internal/models"
+	"flex-erp-poc/internal/utils"
+)
+
+// Func does one run of a job, with ctx for its queries. scheduledFor is when the
+// run was due, or when it was requested for a manual run; the result is a short
+// summary for the run history.
+type Func func(ctx context.Context, scheduledFor time.Time) (string, error)
+
+type Job struct {
+	Name        string
+	Description string
+	Schedule    string // cron expression, see ParseSchedule
+	MaxRetries  int    // retries of a failed scheduled run before waiting for the next
+	Run         Func
+
+	schedule *Schedule
+}
+
+// Failed scheduled runs are retried after 1m, 2m, 4m and so on, capped at 1h
+const (
+	retryBase  = time.Minute
+	retryLimit = time.Hour
+)
+
+type Scheduler struct {
+	DB       *database.PostgresService
+	Instance string // recorded on each run to tell server instances apart
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	names   []string
+	synced  map[string]bool
+	running map[string]bool
+}
+
+func NewScheduler(db *database.PostgresService) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{
+		DB:       db,
+		Instance: fmt.Sprintf("%s:%d", host, os.Getpid()),
+		jobs:     make(map[string]*Job),
+		synced:   make(map[string]bool),
+		running:  make(map[string]bool),
+	}
+}
+
+// Register adds a job. Register every job before calling Run.
+func (s *Scheduler) Register(job Job) error {
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("job needs a name and a run function")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.Name]; ok {
+		return fmt.Errorf("job %s is already registered", job.Name)
+	}
+	job.schedule = schedule
+	s.jobs[job.Name] = &job
+	s.names = append(s.names, job.Name)
+	return nil
+}
+
+// Run starts the jobs that are due every interval until the process exits
+func (s *Scheduler) Run(interval time.Duration) {
+	ctx := context.Background()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for ; ; <-ticker.C {
+		if err := s.sync(ctx); err != nil {
+			log.Printf("Failed to record scheduled jobs: %v", err)
+			continue
+		}
+
+		due, err := s.DB.GetDueJobNames(ctx, s.names)
+		if err != nil {
+			log.Printf("Failed to check for due jobs: %v", err)
+			continue
+		}
+		for _, name := range due {
+			s.start(ctx, name)
+		}
+	}
+}
+
+// sync records the registered jobs not yet recorded since startup
+func (s *Scheduler) sync(ctx context.Context) error {
+	for _, name := range s.names {
+		if s.synced[name] {
+			continue
+		}
+		job := s.jobs[name]
+		err := s.DB.SyncScheduledJob(ctx, models.ScheduledJob{
+			Name:        job.Name,
+			Description: job.Description,
+			Schedule:    job.Schedule,
+			MaxRetries:  job.MaxRetries,
+			NextRunAt:   job.schedule.Next(time.Now()),
+		})
+		if err != nil {
+			return err
+		}
+		s.synced[name] = true
+	}
+	return nil
+}
+
+// start runs the job in the background unless this instance is already running it
+func (s *Scheduler) start(ctx context.Context, name string) {
+	s.mu.Lock()
+	job := s.jobs[name]
+	if job == nil || s.running[name] {
+		s.mu.Unlock()
+		return
+	}
+	s.running[name] = true
+	s.mu.Unlock()
+
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, name)
+			s.mu.Unlock()
+		}()
+
+		if _, err := s.DB.WithJobLock(ctx, name, func() error { return s.execute(ctx, job) }); err != nil {
+			log.Printf("Failed to run job %s: %v", name, err)
+		}
+	}()
+}
+
+// execute claims and runs one due run of the job, holding its lock
+func (s *Scheduler) execute(ctx context.Context, job *Job) error {
+	run, err := s.DB.ClaimJobRun(ctx, job.Name, s.Instance, job.schedule.Next)
+	if err != nil || run == nil {
+		return err
+	}
+
+	result, runErr := call(ctx, job, run.ScheduledFor)
+	var retryAt *time.Time
+	if runErr != nil {
+		log.Printf("Job %s failed (%s, attempt %d): %v", job.Name, run.Trigger, run.Attempt, runErr)
+		if run.Trigger != "manual" && run.Attempt <= job.MaxRetries {
+			at := time.Now().Add(utils.Backoff(retryBase, retryLimit, run.Attempt))
+			retryAt = &at
+		}
+	}
+	return s.DB.FinishJobRun(ctx, run, result, runErr, retryAt)
+}
+
+// call runs the job, turning a panic into a failed run
+func call(ctx context.Context, job *Job, scheduledFor time.Time) (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx, scheduledFor)
+}
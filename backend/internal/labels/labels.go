@@ -0,0 +1,133 @@
+// Package labels renders SKU labels as ZPL for thermal printers and as PDF sheets
+// for laser printers. Both formats share one layout, measured in millimetres from
+// the top left corner of the label.
+package labels
+
+import (
+	"fmt"
+	"math"
+
+	"flex-erp-poc/internal/models"
+)
+
+// MaxLabels caps the labels, copies included, rendered by one request
+const MaxLabels = 5000
+
+// Label is the content of one label, printed Copies times
+type Label struct {
+	SKUCode     string
+	ProductName string
+	Barcode     string
+	Price       *float64
+	Lot         *string
+	Copies      int
+}
+
+const (
+	labelPadding = 1.5  // mm inside the label edge
+	charWidth    = 0.55 // average Helvetica character width, in font sizes
+	minBarHeight = 3.0  // mm, shorter bars do not scan reliably
+)
+
+type textBox struct {
+	text string
+	x, y float64 // top left, mm
+	size float64 // font size, mm
+}
+
+type barcodeBox struct {
+	data          string
+	modules       []bool
+	x, y          float64
+	width, height float64 // bars only, the caption goes below
+	captionSize   float64 // 0 when there is no room for a caption
+}
+
+type layout struct {
+	texts   []textBox
+	barcode *barcodeBox
+}
+
+// layoutLabel places the template fields in order from the top. A barcode keeps
+// the bottom of the label, and text lines that would run into it are dropped.
+func layoutLabel(t *models.LabelTemplate, label Label) (*layout, error) {
+	contentWidth := t.WidthMM - 2*labelPadding
+	size := math.Min(math.Max(t.HeightMM*0.11, 1.8), 4.0)
+
+	withBarcode := false
+	for _, field := range t.Fields {
+		if field == "barcode" && label.Barcode != "" {
+			withBarcode = true
+		}
+	}
+
+	textBottom := t.HeightMM - labelPadding
+	if withBarcode {
+		textBottom -= math.Max(t.HeightMM*0.32, 6)
+	}
+
+	out := &layout{}
+	y := labelPadding
+	for _, field := range t.Fields {
+		text, fontSize := "", size
+		switch field {
+		case "product_name":
+			text, fontSize = label.ProductName, size*1.15
+		case "sku_code":
+			text = label.SKUCode
+		case "price":
+			if label.Price != nil {
+				text = fmt.Sprintf("%s%.2f", t.PricePrefix, *label.Price)
+			}
+		case "lot":
+			if label.Lot != nil && *label.Lot != "" {
+				text = "Lot " + *label.Lot
+			}
+		}
+		if text == "" || y+fontSize > textBottom {
+			continue
+		}
+		out.texts = append(out.texts, textBox{text: truncate(text, contentWidth, fontSize), x: labelPadding, y: y, size: fontSize})
+		y += fontSize * 1.25
+	}
+
+	if withBarcode {
+		modules, err := barcodeModules(label.Barcode)
+		if err != nil {
+			return nil, err
+		}
+		top := math.Max(y, textBottom) + 0.5
+		box := &barcodeBox{data: label.Barcode, modules: modules, x: labelPadding, y: top, width: contentWidth, captionSize: size * 0.8}
+		box.height = t.HeightMM - labelPadding - top - box.captionSize*1.1
+		if box.height < minBarHeight {
+			box.height, box.captionSize = t.HeightMM-labelPadding-top, 0
+		}
+		if box.height >= minBarHeight {
+			out.barcode = box
+		}
+	}
+
+	return out, nil
+}
+
+// truncate shortens text to about the number of characters that fit width
+func truncate(text string, width, size float64) string {
+	runes := []rune(text)
+	max := int(width / (size * charWidth))
+	if len(runes) <= max {
+		return text
+	}
+	if max <= 3 {
+		return string(runes[:max])
+	}
+	return string(runes[:max-3]) + "..."
+}
+
+// Count returns how many labels items print, copies included
+func Count(items []Label) int {
+	total := 0
+	for _, item := range items {
+		total += item.Copies
+	}
+	return total
+}
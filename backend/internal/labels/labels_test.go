@@ -0,0 +1,145 @@
+package labels
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"flex-erp-poc/internal/models"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares output with testdata/name, or rewrites the file with -update
+func checkGolden(t *testing.T, name string, output []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, output, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(output, want) {
+		t.Errorf("output differs from %s; rerun with -update and review the diff:\n%s", path, output)
+	}
+}
+
+func price(v float64) *float64 {
+	return &v
+}
+
+func lot(v string) *string {
+	return &v
+}
+
+func TestRenderZPL(t *testing.T) {
+	template := models.DefaultLabelTemplate()
+	items := []Label{
+		// ^ and ~ would start ZPL commands, _ is the escape character itself
+		{SKUCode: "BOLT^M6~10", ProductName: "Bolt ^FS~JA_x", Barcode: "4006381333931", Price: price(0.35), Lot: lot("L~7"), Copies: 2},
+		{SKUCode: "WASHER-M6", ProductName: "Washer", Barcode: "036000291452", Copies: 1},
+		{SKUCode: "NUT", ProductName: "Nut ü", Barcode: "NUT^6~_", Copies: 3},
+	}
+	template.PricePrefix = "$"
+
+	output, err := RenderZPL(template, items)
+	if err != nil {
+		t.Fatalf("RenderZPL: %v", err)
+	}
+	for _, unescaped := range []string{"^FS~", "BOLT^M6", "L~7", "NUT^6"} {
+		if bytes.Contains(output, []byte(unescaped)) {
+			t.Errorf("output contains unescaped %q", unescaped)
+		}
+	}
+	checkGolden(t, "labels.zpl", output)
+}
+
+func TestRenderZPLRejectsBarcodesTooWide(t *testing.T) {
+	template := models.DefaultLabelTemplate()
+	template.WidthMM = 10
+	_, err := RenderZPL(template, []Label{{SKUCode: "A", Barcode: "ABCDEFGHIJKLMNOPQRSTUVWXYZ", Copies: 1}})
+	if err == nil || !strings.Contains(err.Error(), "does not fit") {
+		t.Errorf("error = %v, want does not fit", err)
+	}
+}
+
+func TestSheet(t *testing.T) {
+	tests := []struct {
+		name          string
+		template      models.LabelTemplate
+		width, height float64
+		columns, rows int
+		wantErr       string
+	}{
+		{"a4 default", models.LabelTemplate{WidthMM: 50, HeightMM: 25, PageSize: "a4", MarginMM: 5}, 210, 297, 4, 11, ""},
+		{"letter with gaps", models.LabelTemplate{WidthMM: 66.7, HeightMM: 25.4, PageSize: "letter", MarginMM: 10, GapMM: 3}, 215.9, 279.4, 2, 9, ""},
+		{"gap only between labels", models.LabelTemplate{WidthMM: 100, HeightMM: 138.5, PageSize: "a4", MarginMM: 5, GapMM: 10}, 210, 297, 1, 2, ""},
+		{"one label per page", models.LabelTemplate{WidthMM: 62, HeightMM: 29, PageSize: "label", MarginMM: 5, GapMM: 2}, 62, 29, 1, 1, ""},
+		{"too large", models.LabelTemplate{WidthMM: 220, HeightMM: 25, PageSize: "a4"}, 0, 0, 0, 0, "does not fit"},
+		{"unknown page size", models.LabelTemplate{WidthMM: 50, HeightMM: 25, PageSize: "a5"}, 0, 0, 0, 0, "unknown page size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height, columns, rows, err := Sheet(&tt.template)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || width != tt.width || height != tt.height || columns != tt.columns || rows != tt.rows {
+				t.Errorf("Sheet = %v x %v, %d x %d, %v; want %v x %v, %d x %d",
+					width, height, columns, rows, err, tt.width, tt.height, tt.columns, tt.rows)
+			}
+		})
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	// Four columns and eleven rows of 50 x 25 mm labels on A4 with a 5 mm margin
+	template := models.DefaultLabelTemplate()
+	template.Fields = []string{"sku_code", "product_name"}
+	items := []Label{
+		{SKUCode: "A-1", ProductName: "Bracket (left) \\ 90°", Copies: 2},
+		{SKUCode: "B-2", ProductName: "Hinge 日本", Copies: 40},
+	}
+
+	output, err := RenderPDF(template, items, 5)
+	if err != nil {
+		t.Fatalf("RenderPDF: %v", err)
+	}
+	// 42 labels from the sixth slot fill the first page's 39 remaining slots and
+	// three of the second
+	if count := bytes.Count(output, []byte("/Type /Page ")); count != 2 {
+		t.Errorf("%d pages, want 2", count)
+	}
+	for _, want := range []string{
+		"/MediaBox [0 0 595.28 841.89]",
+		// The sixth slot is the second column of the second row: its SKU code
+		// starts 1.5 mm in from (55, 30) mm, 3.7 mm down to the baseline
+		"BT /F1 7.80 Tf 160.16 746.36 Td (A-1) Tj ET",
+		"(Bracket \\(left\\) \\\\ 90\\260)",
+		"(Hinge ??)",
+	} {
+		if !bytes.Contains(output, []byte(want)) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+	checkGolden(t, "labels.pdf", output)
+}
+
+func TestRenderPDFStartPosition(t *testing.T) {
+	template := models.DefaultLabelTemplate()
+	for _, start := range []int{-1, 44} {
+		if _, err := RenderPDF(template, nil, start); err == nil || !strings.Contains(err.Error(), "between 0 and 43") {
+			t.Errorf("start %d: error = %v", start, err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package labels
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"flex-erp-poc/internal/models"
+)
+
+// ptPerMM converts millimetres to PDF points
+const ptPerMM = 72 / 25.4
+
+// Sheet returns the page size and how many labels fit across and down it. The
+// "label" page size is exactly one label with no margin.
+func Sheet(t *models.LabelTemplate) (pageWidth, pageHeight float64, columns, rows int, err error) {
+	size, ok := models.LabelPageSizes[t.PageSize]
+	if !ok {
+		return 0, 0, 0, 0, fmt.Errorf("unknown page size %q", t.PageSize)
+	}
+	if t.PageSize == "label" {
+		return t.WidthMM, t.HeightMM, 1, 1, nil
+	}
+
+	columns = int((size[0] - 2*t.MarginMM + t.GapMM) / (t.WidthMM + t.GapMM))
+	rows = int((size[1] - 2*t.MarginMM + t.GapMM) / (t.HeightMM + t.GapMM))
+	if columns < 1 || rows < 1 {
+		return 0, 0, 0, 0, fmt.Errorf("a %.0fx%.0f mm label does not fit on a %s page", t.WidthMM, t.HeightMM, t.PageSize)
+	}
+	return size[0], size[1], columns, rows, nil
+}
+
+// RenderPDF lays the labels out on sheets, left to right and top to bottom,
+// starting startPosition labels into the first sheet so a partly used sheet
+// can be fed again. Text uses the standard Helvetica font, so characters
+// outside Latin-1 print as '?'.
+func RenderPDF(t *models.LabelTemplate, items []Label, startPosition int) ([]byte, error) {
+	pageWidth, pageHeight, columns, rows, err := Sheet(t)
+	if err != nil {
+		return nil, err
+	}
+	perPage := columns * rows
+	if startPosition < 0 || startPosition >= perPage {
+		return nil, fmt.Errorf("start position must be between 0 and %d", perPage-1)
+	}
+	margin, gap := t.MarginMM, t.GapMM
+	if t.PageSize == "label" {
+		margin, gap = 0, 0
+	}
+
+	var pages []*bytes.Buffer
+	position := startPosition
+	for _, item := range items {
+		out, err := layoutLabel(t, item)
+		if err != nil {
+			return nil, err
+		}
+		for n := 0; n < item.Copies; n++ {
+			if position/perPage >= len(pages) {
+				pages = append(pages, &bytes.Buffer{})
+			}
+			slot := position % perPage
+			left := margin + float64(slot%columns)*(t.WidthMM+gap)
+			top := margin + float64(slot/columns)*(t.HeightMM+gap)
+			drawLabel(pages[position/perPage], out, left, top, pageHeight)
+			position++
+		}
+	}
+	if len(pages) == 0 {
+		pages = append(pages, &bytes.Buffer{})
+	}
+
+	return writePDF(pages, pageWidth*ptPerMM, pageHeight*ptPerMM), nil
+}
+
+// drawLabel writes the content stream operators for one label. PDF measures y
+// up from the bottom of the page, the layout down from the top of the label.
+func drawLabel(buf *bytes.Buffer, out *layout, left, top, pageHeight float64) {
+	x := func(mm float64) string { return pdfNumber((left + mm) * ptPerMM) }
+	y := func(mm float64) string { return pdfNumber((pageHeight - top - mm) * ptPerMM) }
+
+	for _, text := range out.texts {
+		fmt.Fprintf(buf, "BT /F1 %s Tf %s %s Td (%s) Tj ET\n",
+			pdfNumber(text.size*ptPerMM), x(text.x), y(text.y+text.size*0.8), pdfString(text.text))
+	}
+
+	box := out.barcode
+	if box == nil {
+		return
+	}
+	module := box.width / float64(len(box.modules)+2*quietZone)
+	start := box.x + (box.width-module*float64(len(box.modules)))/2
+	for i := 0; i < len(box.modules); {
+		if !box.modules[i] {
+			i++
+			continue
+		}
+		run := i
+		for run < len(box.modules) && box.modules[run] {
+			run++
+		}
+		fmt.Fprintf(buf, "%s %s %s %s re\n",
+			x(start+float64(i)*module), y(box.y+box.height), pdfNumber(float64(run-i)*module*ptPerMM), pdfNumber(box.height*ptPerMM))
+		i = run
+	}
+	buf.WriteString("f\n")
+
+	if box.captionSize > 0 {
+		captionWidth := float64(len(box.data)) * box.captionSize * charWidth
+		fmt.Fprintf(buf, "BT /F1 %s Tf %s %s Td (%s) Tj ET\n",
+			pdfNumber(box.captionSize*ptPerMM), x(box.x+(box.width-captionWidth)/2),
+			y(box.y+box.height+box.captionSize*0.95), pdfString(box.data))
+	}
+}
+
+// writePDF assembles a PDF 1.4 file with one content stream per page
+func writePDF(pages []*bytes.Buffer, width, height float64) []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-3 are the catalog, page tree and font; each page is then a page
+	// object followed by its content stream
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	for i, page := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfNumber(width), pdfNumber(height), 5+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", page.Len(), page.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+func pdfNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// pdfString escapes text for a literal string in WinAnsiEncoding, which matches
+// Latin-1 for the printable characters above 127
+func pdfString(text string) string {
+	var buf bytes.Buffer
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r < 32:
+			buf.WriteByte(' ')
+		case r < 127:
+			buf.WriteRune(r)
+		case r >= 160 && r <= 255:
+			fmt.Fprintf(&buf, "\\%03o", r)
+		default:
+			buf.WriteByte('?')
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,144 @@
+package labels
+
+import "fmt"
+
+// code128Patterns are the bar and space widths of Code 128 symbol values 0-105,
+// followed by the stop pattern
+var code128Patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// eanLeftOdd are the L codes of EAN-13 digits; R codes are their complement and
+// G codes the reverse of R
+var eanLeftOdd = [...]string{
+	"0001101", "0011001", "0010011", "0111101", "0100011",
+	"0110001", "0101111", "0111011", "0110111", "0001011",
+}
+
+// eanParity picks L (0) or G (1) codes for the left half from the first digit
+var eanParity = [...]string{
+	"000000", "001011", "001101", "001110", "010011",
+	"011001", "011100", "010101", "010110", "011010",
+}
+
+// quietZone is the blank margin, in modules, kept on each side of a barcode
+const quietZone = 10
+
+// barcodeModules encodes data as a row of modules, true for a bar. 12 and 13 digit
+// codes are drawn as UPC-A/EAN-13, anything else as Code 128.
+func barcodeModules(data string) ([]bool, error) {
+	if (len(data) == 12 || len(data) == 13) && isDigits(data) {
+		if len(data) == 12 {
+			data = "0" + data
+		}
+		return ean13Modules(data), nil
+	}
+	return code128Modules(data)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// code128Modules uses code set C for even runs of digits and code set B otherwise
+func code128Modules(data string) ([]bool, error) {
+	var values []int
+	if len(data) >= 4 && len(data)%2 == 0 && isDigits(data) {
+		values = append(values, code128StartC)
+		for i := 0; i < len(data); i += 2 {
+			values = append(values, int(data[i]-'0')*10+int(data[i+1]-'0'))
+		}
+	} else {
+		values = append(values, code128StartB)
+		for _, r := range data {
+			if r < 32 || r > 126 {
+				return nil, fmt.Errorf("barcode %q has characters Code 128 cannot encode", data)
+			}
+			values = append(values, int(r)-32)
+		}
+	}
+
+	checksum := values[0]
+	for i, value := range values[1:] {
+		checksum += (i + 1) * value
+	}
+	values = append(values, checksum%103, code128Stop)
+
+	var modules []bool
+	for _, value := range values {
+		bar := true
+		for _, width := range code128Patterns[value] {
+			for i := 0; i < int(width-'0'); i++ {
+				modules = append(modules, bar)
+			}
+			bar = !bar
+		}
+	}
+	return modules, nil
+}
+
+// ean13Modules encodes 13 digits, guards included
+func ean13Modules(data string) []bool {
+	var pattern []byte
+	pattern = append(pattern, "101"...)
+	parity := eanParity[data[0]-'0']
+	for i := 1; i <= 6; i++ {
+		code := eanLeftOdd[data[i]-'0']
+		if parity[i-1] == '1' {
+			code = reverse(complement(code))
+		}
+		pattern = append(pattern, code...)
+	}
+	pattern = append(pattern, "01010"...)
+	for i := 7; i <= 12; i++ {
+		pattern = append(pattern, complement(eanLeftOdd[data[i]-'0'])...)
+	}
+	pattern = append(pattern, "101"...)
+
+	modules := make([]bool, len(pattern))
+	for i, c := range pattern {
+		modules[i] = c == '1'
+	}
+	return modules
+}
+
+func complement(code string) string {
+	out := []byte(code)
+	for i, c := range out {
+		if c == '0' {
+			out[i] = '1'
+		} else {
+			out[i] = '0'
+		}
+	}
+	return string(out)
+}
+
+func reverse(code string) string {
+	out := []byte(code)
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
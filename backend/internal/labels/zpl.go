@@ -0,0 +1,64 @@
+package labels
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+
+	"flex-erp-poc/internal/models"
+)
+
+// zplEscaper hex-escapes the characters ZPL treats as commands, for use after ^FH_
+var zplEscaper = strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E")
+
+// RenderZPL writes one label format per item, printed Copies times with ^PQ. Text
+// is sent as UTF-8; UPC-A and EAN-13 codes use the printer's own symbologies,
+// which add the check digit themselves.
+func RenderZPL(t *models.LabelTemplate, items []Label) ([]byte, error) {
+	dots := float64(t.DPI) / 25.4
+	toDots := func(mm float64) int { return int(math.Round(mm * dots)) }
+
+	var buf bytes.Buffer
+	for _, item := range items {
+		out, err := layoutLabel(t, item)
+		if err != nil {
+			return nil, err
+		}
+
+		fmt.Fprintf(&buf, "^XA\n^CI28\n^PW%d\n^LL%d\n", toDots(t.WidthMM), toDots(t.HeightMM))
+		for _, text := range out.texts {
+			fmt.Fprintf(&buf, "^FO%d,%d^A0N,%d,%d^FH_^FD%s^FS\n",
+				toDots(text.x), toDots(text.y), toDots(text.size), toDots(text.size), zplEscaper.Replace(text.text))
+		}
+
+		if box := out.barcode; box != nil {
+			width := toDots(box.width)
+			module := width / (len(box.modules) + 2*quietZone)
+			if module < 1 {
+				return nil, fmt.Errorf("barcode %s does not fit on a %.0f mm wide label", box.data, t.WidthMM)
+			}
+			if module > 4 {
+				module = 4
+			}
+			x := toDots(box.x) + (width-module*len(box.modules))/2
+			caption := "N"
+			if box.captionSize > 0 {
+				caption = "Y"
+			}
+
+			fmt.Fprintf(&buf, "^FO%d,%d^BY%d\n", x, toDots(box.y), module)
+			switch {
+			case len(box.data) == 13 && isDigits(box.data):
+				fmt.Fprintf(&buf, "^BEN,%d,%s,N^FD%s^FS\n", toDots(box.height), caption, box.data[:12])
+			case len(box.data) == 12 && isDigits(box.data):
+				fmt.Fprintf(&buf, "^BUN,%d,%s,N,Y^FD%s^FS\n", toDots(box.height), caption, box.data[:11])
+			default:
+				fmt.Fprintf(&buf, "^BCN,%d,%s,N,N,A^FH_^FD%s^FS\n", toDots(box.height), caption, zplEscaper.Replace(box.data))
+			}
+		}
+
+		fmt.Fprintf(&buf, "^PQ%d\n^XZ\n", item.Copies)
+	}
+	return buf.Bytes(), nil
+}
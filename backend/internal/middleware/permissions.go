@@ -14,7 +14,7 @@ import (
 )
 
 type PermissionMiddleware struct {
-	DB *database.PostgresService
+	DB database.UserRepository
 }
 
 type PermissionRequirement struct {
@@ -31,7 +31,7 @@ const (
 )
 
 // NewPermissionMiddleware creates a new permission middleware
-func NewPermissionMiddleware(db *database.PostgresService) *PermissionMiddleware {
+func NewPermissionMiddleware(db database.UserRepository) *PermissionMiddleware {
 	return &PermissionMiddleware{DB: db}
 }
 
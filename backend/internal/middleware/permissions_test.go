@@ -0,0 +1,177 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"flex-erp-poc/internal/database"
+	"flex-erp-poc/internal/handlers"
+	"flex-erp-poc/internal/middleware"
+	"flex-erp-poc/internal/models"
+
+	"github.com/gorilla/mux"
+)
+
+// tokens logs one user of each role in through the login handler, backed by a
+// MemoryStore, and returns their IDs and tokens by role
+func tokens(t *testing.T) (map[string]string, map[string]string) {
+	t.Helper()
+	store := database.NewMemoryStore()
+	org := store.AddOrganization("Acme")
+	h := &handlers.Handler{Store: store}
+
+	ids := make(map[string]string)
+	tokens := make(map[string]string)
+	for _, role := range []string{"admin", "manager", "user", "viewer"} {
+		email := role + "@example.com"
+		user, err := store.CreateUser(context.Background(), org.ID, models.CreateUserRequest{Email: email, Name: role, Role: role})
+		if err != nil {
+			t.Fatalf("CreateUser: %v", err)
+		}
+		body, _ := json.Marshal(handlers.LoginRequest{Email: email})
+		w := httptest.NewRecorder()
+		h.Login(w, httptest.NewRequest("POST", "/auth/login", bytes.NewReader(body)))
+		var response handlers.LoginResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || response.Token == "" {
+			t.Fatalf("login %s: status %d: %s", role, w.Code, w.Body)
+		}
+		ids[role] = user.ID
+		tokens[role] = response.Token
+	}
+	return ids, tokens
+}
+
+// echo responds with the user the middleware put in the request context
+var echo = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	role, _ := middleware.GetUserRoleFromContext(r.Context())
+	w.Write([]byte(userID + " " + role))
+})
+
+func TestRequirePermission(t *testing.T) {
+	ids, tokens := tokens(t)
+	pm := middleware.NewPermissionMiddleware(database.NewMemoryStore())
+
+	tests := []struct {
+		resource, action string
+		allowed          []string
+	}{
+		{"skus", "read", []string{"admin", "manager", "user", "viewer"}},
+		{"skus", "create", []string{"admin", "manager", "user"}},
+		{"skus", "delete", []string{"admin"}},
+		{"inventory", "update", []string{"admin", "manager", "user"}},
+		{"transactions", "create", []string{"admin", "manager", "user"}},
+		{"users", "read", []string{"admin", "manager"}},
+		{"users", "delete", []string{"admin"}},
+		{"settings", "update", []string{"admin", "manager"}},
+		{"logs", "create", []string{"admin"}},
+		{"purchase_orders", "receive", []string{"admin", "manager", "user"}},
+		{"sales_orders", "ship", []string{"admin", "manager", "user"}},
+		{"views", "delete", []string{"admin", "manager", "user", "viewer"}},
+		{"unknown", "read", nil},
+	}
+	for _, tt := range tests {
+		handler := pm.RequirePermission(tt.resource, tt.action)(echo)
+		for _, role := range []string{"admin", "manager", "user", "viewer"} {
+			allowed := false
+			for _, allowedRole := range tt.allowed {
+				allowed = allowed || allowedRole == role
+			}
+			t.Run(tt.resource+" "+tt.action+" as "+role, func(t *testing.T) {
+				req := httptest.NewRequest("GET", "/", nil)
+				req.Header.Set("Authorization", "Bearer "+tokens[role])
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, req)
+
+				if !allowed {
+					if w.Code != http.StatusForbidden {
+						t.Errorf("status %d, want 403", w.Code)
+					}
+					return
+				}
+				if w.Code != http.StatusOK {
+					t.Fatalf("status %d, want 200: %s", w.Code, w.Body)
+				}
+				if want := ids[role] + " " + role; w.Body.String() != want {
+					t.Errorf("context user = %q, want %q", w.Body, want)
+				}
+			})
+		}
+	}
+}
+
+func TestRequirePermissionRejectsBadTokens(t *testing.T) {
+	_, tokens := tokens(t)
+	handler := middleware.NewPermissionMiddleware(nil).RequirePermission("skus", "read")(echo)
+
+	tests := []struct {
+		name          string
+		authorization string
+	}{
+		{"missing", ""},
+		{"garbage", "Bearer garbage"},
+		{"tampered", "Bearer " + tokens["viewer"] + "x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("status %d, want 401", w.Code)
+			}
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	_, tokens := tokens(t)
+	handler := middleware.NewPermissionMiddleware(nil).RequireRole("admin", "manager")(echo)
+
+	for role, want := range map[string]int{"admin": 200, "manager": 200, "user": 403, "viewer": 403} {
+		t.Run(role, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tokens[role])
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != want {
+				t.Errorf("status %d, want %d", w.Code, want)
+			}
+		})
+	}
+}
+
+func TestRequireSelfOrPermission(t *testing.T) {
+	ids, tokens := tokens(t)
+	router := mux.NewRouter()
+	router.Handle("/users/{id}", middleware.NewPermissionMiddleware(nil).RequireSelfOrPermission("users", "read")(echo))
+
+	tests := []struct {
+		name   string
+		role   string
+		target string
+		want   int
+	}{
+		{"viewer reads themselves", "viewer", ids["viewer"], http.StatusOK},
+		{"viewer reads another user", "viewer", ids["admin"], http.StatusForbidden},
+		{"manager reads another user", "manager", ids["viewer"], http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/users/"+tt.target, nil)
+			req.Header.Set("Authorization", "Bearer "+tokens[tt.role])
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
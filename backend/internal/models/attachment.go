@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// AttachmentEntityTypes are the records files can be attached to
+var AttachmentEntityTypes = []string{"sku", "transaction"}
+
+// AttachmentContentTypes are the accepted upload types, as sniffed from the file
+// contents rather than taken from the client
+var AttachmentContentTypes = []string{
+	"image/jpeg",
+	"image/png",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+	"text/plain",
+}
+
+// ThumbnailContentTypes are the image types a thumbnail is generated for
+var ThumbnailContentTypes = []string{"image/jpeg", "image/png", "image/gif"}
+
+// DefaultMaxAttachmentBytes is the upload limit when ATTACHMENT_MAX_BYTES is not set
+const DefaultMaxAttachmentBytes = 10 << 20
+
+type Attachment struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	EntityType     string    `json:"entity_type"` // "sku" or "transaction"
+	EntityID       string    `json:"entity_id"`
+	FileName       string    `json:"file_name"`
+	ContentType    string    `json:"content_type"`
+	SizeBytes      int64     `json:"size_bytes"`
+	Checksum       string    `json:"checksum"` // hex SHA-256 of the contents
+	HasThumbnail   bool      `json:"has_thumbnail"`
+	UploadedBy     string    `json:"uploaded_by"`
+	UploadedByName string    `json:"uploaded_by_name"`
+	CreatedAt      time.Time `json:"created_at"`
+	StorageKey     string    `json:"-"`
+	ThumbnailKey   *string   `json:"-"`
+}
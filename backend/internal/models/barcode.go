@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// SKUBarcode is an alternate barcode of a SKU. A scan of it counts as UnitsPerScan
+// units, so a case barcode can receive a whole case at once.
+type SKUBarcode struct {
+	ID            string    `json:"id"`
+	SKUID         string    `json:"sku_id"`
+	Barcode       string    `json:"barcode"`
+	UnitOfMeasure string    `json:"unit_of_measure"`
+	UnitsPerScan  int       `json:"units_per_scan"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type CreateSKUBarcodeRequest struct {
+	Barcode       string `json:"barcode" validate:"required,max=50"`
+	UnitOfMeasure string `json:"unit_of_measure" validate:"omitempty,max=20"` // defaults to "each"
+	UnitsPerScan  int    `json:"units_per_scan" validate:"omitempty,min=1"`   // defaults to 1
+}
+
+// BarcodeMatch is the SKU a scanned code resolved to. Codes of a merged SKU resolve
+// to the SKU it was merged into.
+type BarcodeMatch struct {
+	Barcode       string     `json:"barcode"` // as stored, which may differ from the scan in GTIN zero padding
+	IsPrimary     bool       `json:"is_primary"`
+	UnitOfMeasure string     `json:"unit_of_measure"`
+	UnitsPerScan  int        `json:"units_per_scan"`
+	SKU           *SKU       `json:"sku"`
+	Inventory     *Inventory `json:"inventory,omitempty"`
+}
+
+// ScanRequest posts a transaction for a scanned barcode. Quantity counts scans and
+// defaults to 1; each scan moves the matched barcode's units_per_scan.
+type ScanRequest struct {
+	Barcode         string            `json:"barcode" validate:"required,max=50"`
+	Quantity        int               `json:"quantity" validate:"omitempty,min=1"`
+	UnitCost        *float64          `json:"unit_cost,omitempty" validate:"omitempty,min=0"` // defaults to the current weighted cost
+	ReferenceNumber *string           `json:"reference_number,omitempty"`
+	Notes           *string           `json:"notes,omitempty"`
+	ReservationID   *string           `json:"reservation_id,omitempty"` // scan-out only
+	CustomFields    CustomFieldValues `json:"custom_fields,omitempty"`
+}
+
+type ScanResponse struct {
+	Match       *BarcodeMatch `json:"match"`
+	Transaction *Transaction  `json:"transaction"`
+}
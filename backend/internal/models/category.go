@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// CategoryPathSeparator joins category names into a node's path, e.g. "Electronics/Phones"
+const CategoryPathSeparator = "/"
+
+type Category struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	ParentID       *string   `json:"parent_id,omitempty"`
+	Name           string    `json:"name"`
+	Path           string    `json:"path"`
+	Depth          int       `json:"depth"`
+	SKUCount       int       `json:"sku_count"` // SKUs assigned directly to this node
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+
+	Children []*Category `json:"children,omitempty"`
+}
+
+// Request/Response types
+type CreateCategoryRequest struct {
+	Name     string  `json:"name" validate:"required,max=100"`
+	ParentID *string `json:"parent_id,omitempty" validate:"omitempty,uuid"`
+}
+
+// UpdateCategoryRequest renames and/or moves a category; a nil parent makes it a root
+type UpdateCategoryRequest struct {
+	Name     string  `json:"name" validate:"required,max=100"`
+	ParentID *string `json:"parent_id,omitempty" validate:"omitempty,uuid"`
+}
+
+// CategoryRollupParams selects the tree level to aggregate at. Everything below a
+// node at that level is counted towards it.
+type CategoryRollupParams struct {
+	Level     int     `json:"level"`
+	ParentID  *string `json:"parent_id,omitempty"`
+	StartDate *string `json:"start_date,omitempty"`
+	EndDate   *string `json:"end_date,omitempty"`
+}
+
+// CategoryInventoryRollup is the inventory held under a category and its descendants
+type CategoryInventoryRollup struct {
+	CategoryID        string  `json:"category_id"`
+	Name              string  `json:"name"`
+	Path              string  `json:"path"`
+	Depth             int     `json:"depth"`
+	SKUCount          int     `json:"sku_count"`
+	Quantity          int     `json:"quantity"`
+	ReservedQuantity  int     `json:"reserved_quantity"`
+	AvailableQuantity int     `json:"available_quantity"`
+	TotalValue        float64 `json:"total_value"`
+}
+
+// CategoryTransactionRollup is a TransactionSummary for a category and its descendants
+type CategoryTransactionRollup struct {
+	CategoryID        string  `json:"category_id"`
+	Name              string  `json:"name"`
+	Path              string  `json:"path"`
+	Depth             int     `json:"depth"`
+	TransactionType   string  `json:"transaction_type"`
+	TotalTransactions int     `json:"total_transactions"`
+	TotalQuantity     int     `json:"total_quantity"`
+	TotalValue        float64 `json:"total_value"`
+}
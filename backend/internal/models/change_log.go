@@ -27,10 +27,10 @@ type ChangeLog struct {
 }
 
 type CreateChangeLogRequest struct {
-	EntityType string          `json:"entity_type" validate:"required,oneof=sku inventory transaction user field_alias"`
+	EntityType string          `json:"entity_type" validate:"required,oneof=sku inventory transaction user field_alias sales_order"`
 	EntityID   *string         `json:"entity_id,omitempty"`
 	SkuID      *string         `json:"sku_id,omitempty"`
-	ChangeType string          `json:"change_type" validate:"required,oneof=create update delete activate deactivate manual_cost_update"`
+	ChangeType string          `json:"change_type" validate:"required,oneof=create update delete activate deactivate archive merge manual_cost_update status_change"`
 	FieldName  *string         `json:"field_name,omitempty"`
 	OldValue   *string         `json:"old_value,omitempty"`
 	NewValue   *string         `json:"new_value,omitempty"`
@@ -44,9 +44,12 @@ type ChangeLogListParams struct {
 	SkuID       *string    `json:"sku_id,omitempty"`
 	UserID      *string    `json:"user_id,omitempty"`
 	ChangeType  *string    `json:"change_type,omitempty"`
+	BatchID     *string    `json:"batch_id,omitempty"` // entries written by one bulk action
 	LastDays    *int       `json:"last_days,omitempty"` // Filter to last N days
 	DateFrom    *time.Time `json:"date_from,omitempty"`
 	DateTo      *time.Time `json:"date_to,omitempty"`
+	Sort        string     `json:"sort,omitempty"`
+	Cursor      string     `json:"cursor,omitempty"`
 	Limit       int        `json:"limit,omitempty"`
 	Offset      int        `json:"offset,omitempty"`
 }
@@ -73,6 +76,7 @@ var SupportedEntityTypes = []string{
 	"transaction",
 	"user",
 	"field_alias",
+	"sales_order",
 }
 
 // Supported change types
@@ -82,7 +86,10 @@ var SupportedChangeTypes = []string{
 	"delete",
 	"activate",
 	"deactivate",
+	"archive",
+	"merge",
 	"manual_cost_update",
+	"status_change",
 }
 
 // Helper function to create a change log entry
@@ -118,4 +125,14 @@ func NewUserChangeLog(orgID, userID, targetUserID string, changeType string) *Cr
 	log := NewChangeLog(orgID, userID, "user", changeType)
 	log.EntityID = &targetUserID
 	return log
-}
\ No newline at end of file
+}
+
+func NewSalesOrderStatusChangeLog(orgID, userID, salesOrderID, oldStatus, newStatus string) *CreateChangeLogRequest {
+	log := NewChangeLog(orgID, userID, "sales_order", "status_change")
+	fieldName := "status"
+	log.EntityID = &salesOrderID
+	log.FieldName = &fieldName
+	log.OldValue = &oldStatus
+	log.NewValue = &newStatus
+	return log
+}
@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// ConfigBundleVersion is the bundle format written by exports. Imports reject any other version.
+const ConfigBundleVersion = 1
+
+// Config bundle sections
+const (
+	ConfigSectionDefaultLocale = "default_locale"
+	ConfigSectionBusinessRules = "business_rules"
+	ConfigSectionRoles         = "roles"
+	ConfigSectionFieldAliases  = "field_aliases"
+	ConfigSectionCustomFields  = "custom_fields"
+)
+
+// Config change actions
+const (
+	ConfigActionCreate = "create"
+	ConfigActionUpdate = "update"
+	ConfigActionDelete = "delete"
+	ConfigActionSkip   = "skip"
+)
+
+// ConfigBundle is an organization's configuration in a form that can be imported into
+// another organization. Field aliases and custom fields are matched by table and
+// field name, never by ID.
+type ConfigBundle struct {
+	Version       int                  `json:"version"`
+	ExportedAt    time.Time            `json:"exported_at"`
+	Organization  string               `json:"organization,omitempty"` // name of the source organization
+	DefaultLocale string               `json:"default_locale"`
+	BusinessRules BusinessRules        `json:"business_rules"`
+	Roles         []UserRole           `json:"roles"` // built in, exported for reference
+	FieldAliases  []*BundleFieldAlias  `json:"field_aliases"`
+	CustomFields  []*BundleCustomField `json:"custom_fields"`
+}
+
+type BundleFieldAlias struct {
+	TableName    string               `json:"table_name"`
+	FieldName    string               `json:"field_name"`
+	DisplayName  string               `json:"display_name"`
+	Description  *string              `json:"description,omitempty"`
+	IsHidden     bool                 `json:"is_hidden"`
+	SortOrder    int                  `json:"sort_order"`
+	Translations []*BundleTranslation `json:"translations,omitempty"`
+}
+
+type BundleCustomField struct {
+	TableName    string               `json:"table_name"`
+	FieldName    string               `json:"field_name"`
+	DisplayName  string               `json:"display_name"`
+	Description  *string              `json:"description,omitempty"`
+	FieldType    string               `json:"field_type"`
+	IsRequired   bool                 `json:"is_required"`
+	Options      []string             `json:"options,omitempty"`
+	MinValue     *float64             `json:"min_value,omitempty"`
+	MaxValue     *float64             `json:"max_value,omitempty"`
+	MaxLength    *int                 `json:"max_length,omitempty"`
+	Pattern      *string              `json:"pattern,omitempty"`
+	SortOrder    int                  `json:"sort_order"`
+	Translations []*BundleTranslation `json:"translations,omitempty"`
+}
+
+type BundleTranslation struct {
+	Locale      string  `json:"locale"`
+	DisplayName string  `json:"display_name"`
+	Description *string `json:"description,omitempty"`
+}
+
+// ConfigChange is one difference between a bundle and the organization's configuration
+type ConfigChange struct {
+	Section string      `json:"section"`
+	Key     string      `json:"key,omitempty"` // "<table_name>.<field_name>" or a role name
+	Action  string      `json:"action"`
+	Before  interface{} `json:"before,omitempty"`
+	After   interface{} `json:"after,omitempty"`
+	Note    string      `json:"note,omitempty"`
+}
+
+// ImportConfigBundleRequest imports a bundle. Without Apply only the diff is returned.
+// Prune deletes aliases and custom fields the bundle does not contain; deleting a
+// custom field also deletes the values stored for it.
+type ImportConfigBundleRequest struct {
+	Bundle ConfigBundle `json:"bundle"`
+	Apply  bool         `json:"apply"`
+	Prune  bool         `json:"prune"`
+}
+
+type ImportConfigBundleResponse struct {
+	Applied bool            `json:"applied"`
+	Changes []*ConfigChange `json:"changes"`
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Custom field types
+const (
+	CustomFieldTypeText    = "text"
+	CustomFieldTypeNumber  = "number"
+	CustomFieldTypeDate    = "date"
+	CustomFieldTypeEnum    = "enum"
+	CustomFieldTypeBoolean = "boolean"
+)
+
+// CustomFieldDateLayout is the format date values are stored and accepted in
+const CustomFieldDateLayout = "2006-01-02"
+
+// CustomFieldTables are the tables organizations can add custom fields to
+var CustomFieldTables = []string{
+	"skus",
+	"inventory",
+	"inventory_transactions",
+}
+
+// CustomField is an organization-defined field on one of CustomFieldTables
+type CustomField struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	TableName      string    `json:"table_name"`
+	FieldName      string    `json:"field_name"` // key in the entity's custom_fields
+	DisplayName    string    `json:"display_name"`
+	Description    *string   `json:"description,omitempty"`
+	FieldType      string    `json:"field_type"`
+	IsRequired     bool      `json:"is_required"`
+	Options        []string  `json:"options,omitempty"`    // enum
+	MinValue       *float64  `json:"min_value,omitempty"`  // number
+	MaxValue       *float64  `json:"max_value,omitempty"`  // number
+	MaxLength      *int      `json:"max_length,omitempty"` // text
+	Pattern        *string   `json:"pattern,omitempty"`    // text
+	SortOrder      int       `json:"sort_order"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	Locale         string    `json:"locale,omitempty"` // locale the label was resolved from, empty for the untranslated label
+}
+
+// CustomFieldValues holds an entity's custom field values keyed by field name
+type CustomFieldValues map[string]interface{}
+
+func (v CustomFieldValues) Value() (driver.Value, error) {
+	if v == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(v)
+}
+
+func (v *CustomFieldValues) Scan(src interface{}) error {
+	var data []byte
+	switch s := src.(type) {
+	case nil:
+		*v = CustomFieldValues{}
+		return nil
+	case []byte:
+		data = s
+	case string:
+		data = []byte(s)
+	default:
+		return fmt.Errorf("cannot scan %T into custom field values", src)
+	}
+	values := CustomFieldValues{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*v = values
+	return nil
+}
+
+// Request/Response types
+type CreateCustomFieldRequest struct {
+	TableName   string   `json:"table_name" validate:"required"`
+	FieldName   string   `json:"field_name" validate:"required,max=63"`
+	DisplayName string   `json:"display_name" validate:"required,max=255"`
+	Description *string  `json:"description,omitempty"`
+	FieldType   string   `json:"field_type" validate:"required,oneof=text number date enum boolean"`
+	IsRequired  bool     `json:"is_required"`
+	Options     []string `json:"options,omitempty"`
+	MinValue    *float64 `json:"min_value,omitempty"`
+	MaxValue    *float64 `json:"max_value,omitempty"`
+	MaxLength   *int     `json:"max_length,omitempty"`
+	Pattern     *string  `json:"pattern,omitempty"`
+	SortOrder   *int     `json:"sort_order,omitempty"`
+}
+
+// UpdateCustomFieldRequest changes a definition. The table, name and type are fixed
+// once values may have been stored against them.
+type UpdateCustomFieldRequest struct {
+	DisplayName *string  `json:"display_name,omitempty" validate:"omitempty,max=255"`
+	Description *string  `json:"description,omitempty"`
+	IsRequired  *bool    `json:"is_required,omitempty"`
+	Options     []string `json:"options,omitempty"`
+	MinValue    *float64 `json:"min_value,omitempty"`
+	MaxValue    *float64 `json:"max_value,omitempty"`
+	MaxLength   *int     `json:"max_length,omitempty"`
+	Pattern     *string  `json:"pattern,omitempty"`
+	SortOrder   *int     `json:"sort_order,omitempty"`
+}
+
+type UpdateCustomFieldValuesRequest struct {
+	CustomFields CustomFieldValues `json:"custom_fields"` // null removes a value
+}
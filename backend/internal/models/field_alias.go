@@ -13,6 +13,7 @@ type FieldAlias struct {
 	SortOrder      int       `json:"sort_order"`
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
+	Locale         string    `json:"locale,omitempty"` // locale the label was resolved from, empty for the untranslated label
 }
 
 type CreateFieldAliasRequest struct {
@@ -32,30 +33,36 @@ type UpdateFieldAliasRequest struct {
 }
 
 type FieldAliasListParams struct {
-	TableName *string `json:"table_name,omitempty"`
-	IsHidden  *bool   `json:"is_hidden,omitempty"`
-	Limit     int     `json:"limit,omitempty"`
-	Offset    int     `json:"offset,omitempty"`
+	TableName *string  `json:"table_name,omitempty"`
+	IsHidden  *bool    `json:"is_hidden,omitempty"`
+	Sort      string   `json:"sort,omitempty"`
+	Cursor    string   `json:"cursor,omitempty"`
+	Limit     int      `json:"limit,omitempty"`
+	Offset    int      `json:"offset,omitempty"`
+	Locales   []string `json:"locales,omitempty"` // fallback chain to resolve labels through
 }
 
 // TableFieldsResponse represents the customizable fields for a table
 type TableFieldsResponse struct {
-	TableName string                 `json:"table_name"`
-	Fields    []*FieldAlias          `json:"fields"`
-	Metadata  *TableFieldsMetadata   `json:"metadata,omitempty"`
+	TableName    string               `json:"table_name"`
+	Locale       string               `json:"locale,omitempty"` // most preferred locale requested
+	Fields       []*FieldAlias        `json:"fields"`
+	CustomFields []*CustomField       `json:"custom_fields"`
+	Metadata     *TableFieldsMetadata `json:"metadata,omitempty"`
 }
 
 type TableFieldsMetadata struct {
-	TotalFields   int    `json:"total_fields"`
-	HiddenFields  int    `json:"hidden_fields"`
-	CustomAliases int    `json:"custom_aliases"`
+	TotalFields   int        `json:"total_fields"`
+	HiddenFields  int        `json:"hidden_fields"`
+	CustomAliases int        `json:"custom_aliases"`
+	CustomFields  int        `json:"custom_fields"`
 	LastUpdated   *time.Time `json:"last_updated,omitempty"`
 }
 
 // Supported tables for field aliases
 var SupportedTables = []string{
 	"skus",
-	"inventory", 
+	"inventory",
 	"inventory_transactions",
 	"users",
 }
@@ -98,4 +105,4 @@ var DefaultTableFields = map[string][]DefaultFieldConfig{
 		{FieldName: "is_active", DisplayName: "Status", Description: "Account status", SortOrder: 4, IsRequired: false},
 		{FieldName: "last_login_at", DisplayName: "Last Login", Description: "Last login timestamp", SortOrder: 5, IsRequired: false},
 	},
-}
\ No newline at end of file
+}
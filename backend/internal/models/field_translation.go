@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Owners of a field translation
+const (
+	FieldTranslationKindAlias       = "field_alias"
+	FieldTranslationKindCustomField = "custom_field"
+)
+
+// FieldTranslation is the label of a field alias or custom field in one locale
+type FieldTranslation struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	FieldAliasID   *string   `json:"field_alias_id,omitempty"`
+	CustomFieldID  *string   `json:"custom_field_id,omitempty"`
+	Locale         string    `json:"locale"`
+	DisplayName    string    `json:"display_name"`
+	Description    *string   `json:"description,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type UpsertFieldTranslationRequest struct {
+	DisplayName string  `json:"display_name" validate:"required,max=255"`
+	Description *string `json:"description,omitempty"`
+}
+
+type UpdateDefaultLocaleRequest struct {
+	Locale string `json:"locale" validate:"required"`
+}
+
+// MissingTranslation is a field without a label in the requested locale or its parent language
+type MissingTranslation struct {
+	Kind        string `json:"kind"` // "field_alias" or "custom_field"
+	ID          string `json:"id"`
+	TableName   string `json:"table_name"`
+	FieldName   string `json:"field_name"`
+	DisplayName string `json:"display_name"` // untranslated label
+}
+
+type MissingTranslationsReport struct {
+	Locale           string                `json:"locale"`
+	TotalFields      int                   `json:"total_fields"`
+	TranslatedFields int                   `json:"translated_fields"`
+	Missing          []*MissingTranslation `json:"missing"`
+}
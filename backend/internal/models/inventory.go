@@ -5,28 +5,38 @@ import (
 )
 
 type Inventory struct {
-	ID             string  `json:"id" db:"id"`
-	OrganizationID string  `json:"organization_id" db:"organization_id"`
-	SKUID          string  `json:"sku_id" db:"sku_id"`
-	Quantity       int     `json:"quantity" db:"quantity"`
-	WeightedCost   float64 `json:"weighted_cost" db:"weighted_cost"`
-	TotalValue     float64 `json:"total_value" db:"total_value"`
-	IsManualCost   bool    `json:"is_manual_cost" db:"is_manual_cost"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	ID             string            `json:"id" db:"id"`
+	OrganizationID string            `json:"organization_id" db:"organization_id"`
+	SKUID          string            `json:"sku_id" db:"sku_id"`
+	Quantity       int               `json:"quantity" db:"quantity"` // On-hand quantity
+	WeightedCost   float64           `json:"weighted_cost" db:"weighted_cost"`
+	TotalValue     float64           `json:"total_value" db:"total_value"`
+	IsManualCost   bool              `json:"is_manual_cost" db:"is_manual_cost"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
+	CustomFields   CustomFieldValues `json:"custom_fields" db:"custom_fields"`
+
+	// Available-to-promise figures derived from active reservations
+	ReservedQuantity  int `json:"reserved_quantity"`
+	AvailableQuantity int `json:"available_quantity"`
 }
 
 type InventoryWithSKU struct {
-	ID             string  `json:"id"`
-	OrganizationID string  `json:"organization_id"`
-	SKUID          string  `json:"sku_id"`
-	Quantity       int     `json:"quantity"`
-	WeightedCost   float64 `json:"weighted_cost"`
-	TotalValue     float64 `json:"total_value"`
-	IsManualCost   bool    `json:"is_manual_cost"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
-	
+	ID             string            `json:"id"`
+	OrganizationID string            `json:"organization_id"`
+	SKUID          string            `json:"sku_id"`
+	Quantity       int               `json:"quantity"` // On-hand quantity
+	WeightedCost   float64           `json:"weighted_cost"`
+	TotalValue     float64           `json:"total_value"`
+	IsManualCost   bool              `json:"is_manual_cost"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	CustomFields   CustomFieldValues `json:"custom_fields"`
+
+	// Available-to-promise figures derived from active reservations
+	ReservedQuantity  int `json:"reserved_quantity"`
+	AvailableQuantity int `json:"available_quantity"`
+
 	// SKU details
 	SKUCode     string  `json:"sku_code"`
 	ProductName string  `json:"product_name"`
@@ -35,6 +45,15 @@ type InventoryWithSKU struct {
 	Supplier    *string `json:"supplier"`
 	Barcode     *string `json:"barcode"`
 	IsActive    bool    `json:"is_active"`
+
+	Search *SearchMatch `json:"search,omitempty"` // set when listed with a search term
+}
+
+type CreateInventoryRequest struct {
+	SKUID        string            `json:"sku_id"`
+	Quantity     int               `json:"quantity"`
+	WeightedCost float64           `json:"weighted_cost"`
+	CustomFields CustomFieldValues `json:"custom_fields,omitempty"`
 }
 
 type UpdateManualCostRequest struct {
@@ -42,8 +61,13 @@ type UpdateManualCostRequest struct {
 }
 
 type InventoryListParams struct {
-	Category     *string `json:"category"`
-	Search       *string `json:"search"`
-	Page         int     `json:"page"`
-	Limit        int     `json:"limit"`
-}
\ No newline at end of file
+	Category     *string           `json:"category"`
+	CategoryID   *string           `json:"category_id"`
+	Search       *string           `json:"search"`
+	MaxQuantity  *int              `json:"max_quantity,omitempty"`  // low stock: quantity on hand at most this
+	CustomFields map[string]string `json:"custom_fields,omitempty"` // cf.<field_name>[.min|.max] query filters
+	Sort         string            `json:"sort,omitempty"`
+	Cursor       string            `json:"cursor,omitempty"`
+	Page         int               `json:"page"`
+	Limit        int               `json:"limit"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// ScheduledJob is a recurring background job. The schedule is a five-field cron
+// expression evaluated in UTC.
+type ScheduledJob struct {
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	Schedule     string     `json:"schedule"`
+	MaxRetries   int        `json:"max_retries"`
+	Paused       bool       `json:"paused"`
+	NextRunAt    time.Time  `json:"next_run_at"`
+	RunRequested bool       `json:"run_requested"` // triggered and waiting for a scheduler to pick it up
+	LastRun      *JobRun    `json:"last_run,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	RetryFor     *time.Time `json:"-"`
+	RetryAttempt int        `json:"-"`
+}
+
+// JobRun is one run of a job. Trigger is "schedule", "retry" or "manual"; status is
+// "running", "succeeded" or "failed".
+type JobRun struct {
+	ID           string     `json:"id"`
+	JobName      string     `json:"job_name"`
+	Trigger      string     `json:"trigger"`
+	ScheduledFor time.Time  `json:"scheduled_for"`
+	Attempt      int        `json:"attempt"`
+	Status       string     `json:"status"`
+	Instance     string     `json:"instance"`
+	Result       *string    `json:"result,omitempty"`
+	Error        *string    `json:"error,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+type UpdateScheduledJobRequest struct {
+	Paused *bool `json:"paused" validate:"required"`
+}
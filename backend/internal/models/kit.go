@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Kit is a SKU built from other SKUs, with its bill of materials
+type Kit struct {
+	SKUID       string `json:"sku_id"`
+	SKUCode     string `json:"sku_code"`
+	ProductName string `json:"product_name"`
+
+	Components []*KitComponent `json:"components"`
+
+	// ComponentCost is the current weighted cost of one kit's worth of components
+	ComponentCost float64 `json:"component_cost"`
+	// BuildableQuantity is how many kits the available component stock can make
+	BuildableQuantity int `json:"buildable_quantity"`
+}
+
+type KitComponent struct {
+	ID             string    `json:"id"`
+	KitSKUID       string    `json:"kit_sku_id"`
+	ComponentSKUID string    `json:"component_sku_id"`
+	Quantity       int       `json:"quantity"` // Per kit
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+	// Component details
+	SKUCode           string  `json:"sku_code"`
+	ProductName       string  `json:"product_name"`
+	AvailableQuantity int     `json:"available_quantity"`
+	WeightedCost      float64 `json:"weighted_cost"`
+}
+
+// Request/Response types
+type SetKitComponentsRequest struct {
+	Components []KitComponentRequest `json:"components" validate:"required,min=1"`
+}
+
+type KitComponentRequest struct {
+	ComponentSKUID string `json:"component_sku_id" validate:"required,uuid"`
+	Quantity       int    `json:"quantity" validate:"required,min=1"`
+}
+
+type KitAssemblyRequest struct {
+	Quantity        int     `json:"quantity" validate:"required,min=1"`
+	ReferenceNumber *string `json:"reference_number,omitempty"`
+	Notes           *string `json:"notes,omitempty"`
+}
+
+type KitAssemblyResponse struct {
+	Kit          *Kit           `json:"kit"`
+	Transactions []*Transaction `json:"transactions"`
+}
@@ -0,0 +1,90 @@
+package models
+
+import "time"
+
+// LabelFields are the fields a label template can print, in the order given
+var LabelFields = []string{"product_name", "sku_code", "barcode", "price", "lot"}
+
+// LabelPageSizes are the PDF sheet sizes in millimetres. The "label" size prints
+// one label per page, for label printers driven through a PDF driver.
+var LabelPageSizes = map[string][2]float64{
+	"a4":     {210, 297},
+	"letter": {215.9, 279.4},
+	"label":  {0, 0},
+}
+
+// LabelTemplate sets the size and content of printed labels. ZPL output uses the
+// label size and DPI; PDF output lays the labels out in a grid on PageSize sheets.
+type LabelTemplate struct {
+	ID             string    `json:"id,omitempty"` // empty for the built-in default
+	OrganizationID string    `json:"organization_id,omitempty"`
+	Name           string    `json:"name"`
+	WidthMM        float64   `json:"width_mm"`
+	HeightMM       float64   `json:"height_mm"`
+	Fields         []string  `json:"fields"`
+	PricePrefix    string    `json:"price_prefix"` // printed before prices, such as "$"
+	DPI            int       `json:"dpi"`          // thermal printer resolution, 203 or 300
+	PageSize       string    `json:"page_size"`
+	MarginMM       float64   `json:"margin_mm"` // sheet margin
+	GapMM          float64   `json:"gap_mm"`    // space between labels on a sheet
+	IsDefault      bool      `json:"is_default"`
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at,omitempty"`
+}
+
+// DefaultLabelTemplate is used when an organization has no default template of its own
+func DefaultLabelTemplate() *LabelTemplate {
+	return &LabelTemplate{
+		Name:      "Default",
+		WidthMM:   50,
+		HeightMM:  25,
+		Fields:    []string{"product_name", "sku_code", "barcode", "price", "lot"},
+		DPI:       203,
+		PageSize:  "a4",
+		MarginMM:  5,
+		GapMM:     0,
+		IsDefault: true,
+	}
+}
+
+type CreateLabelTemplateRequest struct {
+	Name        string   `json:"name" validate:"required,max=100"`
+	WidthMM     float64  `json:"width_mm" validate:"required"`
+	HeightMM    float64  `json:"height_mm" validate:"required"`
+	Fields      []string `json:"fields"`
+	PricePrefix string   `json:"price_prefix" validate:"max=10"`
+	DPI         int      `json:"dpi"`       // defaults to 203
+	PageSize    string   `json:"page_size"` // defaults to a4
+	MarginMM    *float64 `json:"margin_mm,omitempty"`
+	GapMM       float64  `json:"gap_mm"`
+	IsDefault   bool     `json:"is_default"`
+}
+
+type UpdateLabelTemplateRequest struct {
+	Name        *string  `json:"name,omitempty"`
+	WidthMM     *float64 `json:"width_mm,omitempty"`
+	HeightMM    *float64 `json:"height_mm,omitempty"`
+	Fields      []string `json:"fields,omitempty"`
+	PricePrefix *string  `json:"price_prefix,omitempty"`
+	DPI         *int     `json:"dpi,omitempty"`
+	PageSize    *string  `json:"page_size,omitempty"`
+	MarginMM    *float64 `json:"margin_mm,omitempty"`
+	GapMM       *float64 `json:"gap_mm,omitempty"`
+	IsDefault   *bool    `json:"is_default,omitempty"`
+}
+
+// LabelRequest renders labels for a set of SKUs with the given template, or the
+// organization's default template
+type LabelRequest struct {
+	TemplateID    *string     `json:"template_id,omitempty"`
+	Format        string      `json:"format" validate:"required,oneof=zpl pdf"`
+	Items         []LabelItem `json:"items" validate:"required,min=1"`
+	StartPosition int         `json:"start_position"` // PDF: skip this many labels on a partly used first sheet
+}
+
+type LabelItem struct {
+	SKUID  string   `json:"sku_id" validate:"required,uuid"`
+	Copies int      `json:"copies" validate:"omitempty,min=1"` // defaults to 1
+	Price  *float64 `json:"price,omitempty"`
+	Lot    *string  `json:"lot,omitempty"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// NotificationTypes are the things users can be notified of
+var NotificationTypes = []string{"low_stock", "large_adjustment", "user_invitation"}
+
+// NotificationEmailModes: "digest" collects a day's notifications into one email
+var NotificationEmailModes = []string{"off", "immediate", "digest"}
+
+// NotificationAudiences are the roles notified of organization-wide events. An
+// invitation only goes to the invited user.
+var NotificationAudiences = map[string][]string{
+	"low_stock":        {"admin", "manager"},
+	"large_adjustment": {"admin", "manager"},
+}
+
+// DefaultNotificationPreferences apply to the types a user has not set
+var DefaultNotificationPreferences = map[string]NotificationPreference{
+	"low_stock":        {Type: "low_stock", InApp: true, Email: "digest"},
+	"large_adjustment": {Type: "large_adjustment", InApp: true, Email: "off"},
+	"user_invitation":  {Type: "user_invitation", InApp: true, Email: "immediate"},
+}
+
+type NotificationPreference struct {
+	Type  string `json:"type"`
+	InApp bool   `json:"in_app"`
+	Email string `json:"email"` // "off", "immediate" or "digest"
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	Preferences []NotificationPreference `json:"preferences" validate:"required"`
+}
+
+type Notification struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	EventID   string          `json:"event_id"`
+	Title     string          `json:"title"`
+	Body      string          `json:"body"`
+	Data      json.RawMessage `json:"data"`
+	Read      bool            `json:"read"`
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type NotificationListParams struct {
+	Unread bool       `json:"unread,omitempty"`
+	Before *time.Time `json:"before,omitempty"` // created before, for the next page
+	Limit  int        `json:"limit,omitempty"`
+}
+
+type UpdateNotificationRequest struct {
+	Read *bool `json:"read" validate:"required"`
+}
+
+// NotificationRecipient is a user to notify, with their preference for the type
+type NotificationRecipient struct {
+	UserID     string
+	Email      string
+	Name       string
+	Preference NotificationPreference
+}
+
+// NewNotification is one rendered notification for one user
+type NewNotification struct {
+	OrganizationID string
+	Recipient      NotificationRecipient
+	Type           string
+	EventID        string
+	Title          string
+	Body           string
+	Data           json.RawMessage
+	EmailSubject   string
+	EmailBody      string
+}
+
+// NotificationDigest is a user's notifications waiting for the daily digest
+type NotificationDigest struct {
+	OrganizationID string
+	UserID         string
+	Email          string
+	Name           string
+	Notifications  []*Notification
+}
+
+// PendingNotificationEmail is an email claimed for sending
+type PendingNotificationEmail struct {
+	ID       string
+	To       string
+	Subject  string
+	Body     string
+	Attempts int // including the attempt being made
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEvent is a domain event recorded with the change it describes. Sinks may
+// receive the same event more than once, so consumers should skip IDs they have
+// already handled.
+type OutboxEvent struct {
+	ID             string          `json:"id"`
+	Sequence       int64           `json:"sequence"`
+	Position       int64           `json:"position"` // publish order, set when published
+	OrganizationID string          `json:"organization_id"`
+	Type           string          `json:"type"`
+	AggregateType  string          `json:"aggregate_type"`
+	AggregateID    string          `json:"aggregate_id"`
+	Data           json.RawMessage `json:"data"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Attempts       int             `json:"-"`
+}
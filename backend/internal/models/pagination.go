@@ -0,0 +1,17 @@
+package models
+
+// PageInfo describes one page of a list. Cursors are opaque; pass one back as
+// ?cursor= together with the same sort to fetch the neighbouring page.
+type PageInfo struct {
+	Total      int     `json:"total"` // rows matching the filters across all pages
+	Limit      int     `json:"limit"`
+	Sort       string  `json:"sort"` // "-" prefix for descending
+	NextCursor *string `json:"next_cursor"`
+	PrevCursor *string `json:"prev_cursor"`
+}
+
+// ListResponse is the envelope list endpoints respond with
+type ListResponse struct {
+	Items interface{} `json:"items"`
+	PageInfo
+}
@@ -0,0 +1,111 @@
+package models
+
+import "time"
+
+type PurchaseOrder struct {
+	ID             string     `json:"id"`
+	OrganizationID string     `json:"organization_id"`
+	PONumber       string     `json:"po_number"`
+	Supplier       string     `json:"supplier"`
+	SupplierID     *string    `json:"supplier_id,omitempty"`
+	Status         string     `json:"status"`
+	ExpectedDate   *time.Time `json:"expected_date,omitempty"`
+	Notes          *string    `json:"notes,omitempty"`
+	CreatedBy      string     `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Totals across all lines
+	OrderedQuantity     int     `json:"ordered_quantity"`
+	ReceivedQuantity    int     `json:"received_quantity"`
+	OutstandingQuantity int     `json:"outstanding_quantity"`
+	TotalCost           float64 `json:"total_cost"`
+
+	Lines []*PurchaseOrderLine `json:"lines,omitempty"`
+}
+
+type PurchaseOrderLine struct {
+	ID                  string    `json:"id"`
+	PurchaseOrderID     string    `json:"purchase_order_id"`
+	SKUID               string    `json:"sku_id"`
+	OrderedQuantity     int       `json:"ordered_quantity"`
+	ReceivedQuantity    int       `json:"received_quantity"`
+	OutstandingQuantity int       `json:"outstanding_quantity"`
+	UnitCost            float64   `json:"unit_cost"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+	// SKU details
+	SKUCode     string `json:"sku_code"`
+	ProductName string `json:"product_name"`
+}
+
+// Request/Response types
+type CreatePurchaseOrderRequest struct {
+	PONumber     string                           `json:"po_number" validate:"required,max=100"`
+	Supplier     string                           `json:"supplier" validate:"required_without=SupplierID,max=255"`
+	SupplierID   *string                          `json:"supplier_id,omitempty" validate:"omitempty,uuid"`
+	ExpectedDate *time.Time                       `json:"expected_date,omitempty"`
+	Notes        *string                          `json:"notes,omitempty"`
+	Lines        []CreatePurchaseOrderLineRequest `json:"lines" validate:"required,min=1"`
+}
+
+type CreatePurchaseOrderLineRequest struct {
+	SKUID           string  `json:"sku_id" validate:"required,uuid"`
+	OrderedQuantity int     `json:"ordered_quantity" validate:"required,min=1"`
+	UnitCost        float64 `json:"unit_cost" validate:"min=0"`
+}
+
+type UpdatePurchaseOrderStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=sent closed"`
+}
+
+type ReceivePurchaseOrderRequest struct {
+	Lines []ReceivePurchaseOrderLineRequest `json:"lines" validate:"required,min=1"`
+	Notes *string                           `json:"notes,omitempty"`
+}
+
+type ReceivePurchaseOrderLineRequest struct {
+	LineID   string `json:"line_id" validate:"required,uuid"`
+	Quantity int    `json:"quantity" validate:"required,min=1"`
+}
+
+type ReceivePurchaseOrderResponse struct {
+	PurchaseOrder *PurchaseOrder `json:"purchase_order"`
+	Transactions  []*Transaction `json:"transactions"`
+}
+
+type PurchaseOrderListParams struct {
+	Status     *string `json:"status,omitempty"`
+	Supplier   *string `json:"supplier,omitempty"`
+	SupplierID *string `json:"supplier_id,omitempty"`
+	Search     *string `json:"search,omitempty"`
+	Page       int     `json:"page"`
+	Limit      int     `json:"limit"`
+}
+
+// Supported purchase order statuses
+var PurchaseOrderStatuses = []string{
+	"draft",
+	"sent",
+	"partially_received",
+	"received",
+	"closed",
+}
+
+// PurchaseOrderStatusTransitions lists the statuses that can be set by hand from each status.
+// "partially_received" and "received" are only reached by receiving goods.
+var PurchaseOrderStatusTransitions = map[string][]string{
+	"draft":              {"sent", "closed"},
+	"sent":               {"closed"},
+	"partially_received": {"closed"},
+	"received":           {"closed"},
+}
+
+func CanTransitionPurchaseOrder(from, to string) bool {
+	for _, allowed := range PurchaseOrderStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
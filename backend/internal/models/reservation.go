@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Reservation holds on-hand stock for a pending order so it no longer counts as available
+type Reservation struct {
+	ID                string     `json:"id"`
+	OrganizationID    string     `json:"organization_id"`
+	SKUID             string     `json:"sku_id"`
+	Quantity          int        `json:"quantity"`
+	ConsumedQuantity  int        `json:"consumed_quantity"`
+	RemainingQuantity int        `json:"remaining_quantity"`
+	ReferenceNumber   *string    `json:"reference_number,omitempty"`
+	Status            string     `json:"status"` // "active", "consumed", "released" or "expired"
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	Notes             *string    `json:"notes,omitempty"`
+	CreatedBy         string     `json:"created_by"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+type CreateReservationRequest struct {
+	SKUID           string     `json:"sku_id" validate:"required,uuid"`
+	Quantity        int        `json:"quantity" validate:"required,min=1"`
+	ReferenceNumber *string    `json:"reference_number,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	Notes           *string    `json:"notes,omitempty"`
+}
+
+type ReservationListParams struct {
+	SKUID           *string `json:"sku_id,omitempty"`
+	Status          *string `json:"status,omitempty"`
+	ReferenceNumber *string `json:"reference_number,omitempty"`
+	Page            int     `json:"page"`
+	Limit           int     `json:"limit"`
+}
+
+// Supported reservation statuses
+var ReservationStatuses = []string{
+	"active",
+	"consumed",
+	"released",
+	"expired",
+}
@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+type SalesOrder struct {
+	ID             string     `json:"id"`
+	OrganizationID string     `json:"organization_id"`
+	OrderNumber    string     `json:"order_number"`
+	Customer       string     `json:"customer"`
+	Status         string     `json:"status"`
+	RequestedDate  *time.Time `json:"requested_date,omitempty"`
+	Notes          *string    `json:"notes,omitempty"`
+	CreatedBy      string     `json:"created_by"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	// Totals across all lines
+	OrderedQuantity int     `json:"ordered_quantity"`
+	ShippedQuantity int     `json:"shipped_quantity"`
+	TotalPrice      float64 `json:"total_price"`
+
+	Lines []*SalesOrderLine `json:"lines,omitempty"`
+}
+
+type SalesOrderLine struct {
+	ID                  string     `json:"id"`
+	SalesOrderID        string     `json:"sales_order_id"`
+	SKUID               string     `json:"sku_id"`
+	OrderedQuantity     int        `json:"ordered_quantity"`
+	ShippedQuantity     int        `json:"shipped_quantity"`
+	ReservedQuantity    int        `json:"reserved_quantity"`
+	BackorderedQuantity int        `json:"backordered_quantity"`
+	UnitPrice           float64    `json:"unit_price"`
+	RequestedDate       *time.Time `json:"requested_date,omitempty"`
+	ReservationID       *string    `json:"reservation_id,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	// SKU details
+	SKUCode     string `json:"sku_code"`
+	ProductName string `json:"product_name"`
+}
+
+// Request/Response types
+type CreateSalesOrderRequest struct {
+	OrderNumber   string                        `json:"order_number" validate:"required,max=100"`
+	Customer      string                        `json:"customer" validate:"required,max=255"`
+	RequestedDate *time.Time                    `json:"requested_date,omitempty"`
+	Notes         *string                       `json:"notes,omitempty"`
+	Lines         []CreateSalesOrderLineRequest `json:"lines" validate:"required,min=1"`
+}
+
+type CreateSalesOrderLineRequest struct {
+	SKUID           string     `json:"sku_id" validate:"required,uuid"`
+	OrderedQuantity int        `json:"ordered_quantity" validate:"required,min=1"`
+	UnitPrice       float64    `json:"unit_price" validate:"min=0"`
+	RequestedDate   *time.Time `json:"requested_date,omitempty"`
+}
+
+type ShipSalesOrderRequest struct {
+	Lines []ShipSalesOrderLineRequest `json:"lines" validate:"required,min=1"`
+	Notes *string                     `json:"notes,omitempty"`
+}
+
+type ShipSalesOrderLineRequest struct {
+	LineID   string `json:"line_id" validate:"required,uuid"`
+	Quantity int    `json:"quantity" validate:"required,min=1"`
+}
+
+type ShipSalesOrderResponse struct {
+	SalesOrder   *SalesOrder    `json:"sales_order"`
+	Transactions []*Transaction `json:"transactions"`
+}
+
+type SalesOrderListParams struct {
+	Status   *string `json:"status,omitempty"`
+	Customer *string `json:"customer,omitempty"`
+	Search   *string `json:"search,omitempty"`
+	Page     int     `json:"page"`
+	Limit    int     `json:"limit"`
+}
+
+// Supported sales order statuses
+var SalesOrderStatuses = []string{
+	"draft",
+	"confirmed",
+	"partially_shipped",
+	"shipped",
+	"cancelled",
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// SavedViewFilters are the filters a saved view can store for each resource, named
+// after the list endpoint's query parameters. Resources with custom fields also
+// take cf.<field_name>[.min|.max] filters.
+var SavedViewFilters = map[string][]string{
+	"skus":         {"category", "category_id", "supplier_id", "search", "includeDeactivated", "includeArchived"},
+	"inventory":    {"category", "category_id", "search", "max_quantity"},
+	"transactions": {"transaction_type", "sku_id", "category", "category_id", "search", "start_date", "end_date"},
+	"users":        {"role", "is_active", "search"},
+}
+
+// SavedViewAliasTables maps a saved view resource to the table its field aliases and
+// custom fields are defined on
+var SavedViewAliasTables = map[string]string{
+	"skus":         "skus",
+	"inventory":    "inventory",
+	"transactions": "inventory_transactions",
+	"users":        "users",
+}
+
+// SavedView is a named filter, sort and column set for one of the list endpoints.
+// Shared views are visible to everyone in the organization; only the owner can
+// change them.
+type SavedView struct {
+	ID             string            `json:"id"`
+	OrganizationID string            `json:"organization_id"`
+	UserID         string            `json:"user_id"`
+	OwnerName      string            `json:"owner_name"`
+	Resource       string            `json:"resource"`
+	Name           string            `json:"name"`
+	Filters        map[string]string `json:"filters"` // start_date and end_date also take today, week_start, month_start or -<n>d
+	Sort           string            `json:"sort,omitempty"`
+	Columns        []string          `json:"columns"`
+	IsShared       bool              `json:"is_shared"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+type CreateSavedViewRequest struct {
+	Resource string            `json:"resource"`
+	Name     string            `json:"name"`
+	Filters  map[string]string `json:"filters"`
+	Sort     string            `json:"sort"`
+	Columns  []string          `json:"columns"`
+	IsShared bool              `json:"is_shared"`
+}
+
+type UpdateSavedViewRequest struct {
+	Name     *string           `json:"name,omitempty"`
+	Filters  map[string]string `json:"filters,omitempty"`
+	Sort     *string           `json:"sort,omitempty"`
+	Columns  []string          `json:"columns,omitempty"`
+	IsShared *bool             `json:"is_shared,omitempty"`
+}
+
+// SavedViewColumn is a column of a view with its label resolved from field aliases
+// and custom fields
+type SavedViewColumn struct {
+	Field string `json:"field"`
+	Label string `json:"label"`
+}
+
+// AppliedSavedView is a view run against its list endpoint. Query is the resolved
+// set of list parameters, with relative dates turned into timestamps.
+type AppliedSavedView struct {
+	View    *SavedView         `json:"view"`
+	Columns []*SavedViewColumn `json:"columns"`
+	Query   map[string]string  `json:"query"`
+	Results ListResponse       `json:"results"`
+}
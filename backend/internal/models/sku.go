@@ -5,40 +5,97 @@ import (
 )
 
 type SKU struct {
-	ID             string    `json:"id" db:"id"`
-	OrganizationID string    `json:"organization_id" db:"organization_id"`
-	SKUCode        string    `json:"sku_code" db:"sku_code"`
-	ProductName    string    `json:"product_name" db:"product_name"`
-	Description    *string   `json:"description" db:"description"`
-	Category       *string   `json:"category" db:"category"`
-	Supplier       *string   `json:"supplier" db:"supplier"`
-	Barcode        *string   `json:"barcode" db:"barcode"`
-	IsActive       bool      `json:"is_active" db:"is_active"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	ID             string            `json:"id" db:"id"`
+	OrganizationID string            `json:"organization_id" db:"organization_id"`
+	SKUCode        string            `json:"sku_code" db:"sku_code"`
+	ProductName    string            `json:"product_name" db:"product_name"`
+	Description    *string           `json:"description" db:"description"`
+	Category       *string           `json:"category" db:"category"` // path of the assigned category
+	CategoryID     *string           `json:"category_id" db:"category_id"`
+	Supplier       *string           `json:"supplier" db:"supplier"` // preferred supplier name, kept for older clients
+	Barcode        *string           `json:"barcode" db:"barcode"`
+	IsActive       bool              `json:"is_active" db:"is_active"`
+	ParentSKUID    *string           `json:"parent_sku_id,omitempty" db:"parent_sku_id"` // set on generated variants
+	CustomFields   CustomFieldValues `json:"custom_fields" db:"custom_fields"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
+	Search         *SearchMatch      `json:"search,omitempty" db:"-"` // set when listed with a search term
+}
+
+// SearchMatch describes how a list result matched the search term
+type SearchMatch struct {
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights,omitempty"` // field name -> snippet with the matched terms in <mark>
 }
 
 type CreateSKURequest struct {
-	SKUCode     string  `json:"sku_code" validate:"required,max=50"`
-	ProductName string  `json:"product_name" validate:"required,max=255"`
-	Description *string `json:"description"`
-	Category    *string `json:"category" validate:"omitempty,max=100"`
-	Supplier    *string `json:"supplier" validate:"omitempty,max=255"`
-	Barcode     *string `json:"barcode" validate:"omitempty,max=50"`
+	SKUCode      string            `json:"sku_code" validate:"required,max=50"`
+	ProductName  string            `json:"product_name" validate:"required,max=255"`
+	Description  *string           `json:"description"`
+	Category     *string           `json:"category" validate:"omitempty,max=255"` // resolved to a category node, created if missing
+	CategoryID   *string           `json:"category_id" validate:"omitempty,uuid"`
+	Supplier     *string           `json:"supplier" validate:"omitempty,max=255"`
+	Barcode      *string           `json:"barcode" validate:"omitempty,max=50"`
+	CustomFields CustomFieldValues `json:"custom_fields,omitempty"`
 }
 
 type UpdateSKURequest struct {
-	ProductName string  `json:"product_name" validate:"required,max=255"`
-	Description *string `json:"description"`
-	Category    *string `json:"category" validate:"omitempty,max=100"`
-	Supplier    *string `json:"supplier" validate:"omitempty,max=255"`
-	Barcode     *string `json:"barcode" validate:"omitempty,max=50"`
+	ProductName  string            `json:"product_name" validate:"required,max=255"`
+	Description  *string           `json:"description"`
+	Category     *string           `json:"category" validate:"omitempty,max=255"` // resolved to a category node, created if missing
+	CategoryID   *string           `json:"category_id" validate:"omitempty,uuid"`
+	Supplier     *string           `json:"supplier" validate:"omitempty,max=255"`
+	Barcode      *string           `json:"barcode" validate:"omitempty,max=50"`
+	CustomFields CustomFieldValues `json:"custom_fields,omitempty"`
 }
 
 type SKUListParams struct {
-	IncludeDeactivated bool    `json:"include_deactivated"`
-	Category           *string `json:"category"`
-	Search             *string `json:"search"`
-	Page               int     `json:"page"`
-	Limit              int     `json:"limit"`
-}
\ No newline at end of file
+	IncludeDeactivated bool              `json:"include_deactivated"`
+	IncludeArchived    bool              `json:"include_archived"`
+	Category           *string           `json:"category"`    // includes descendant categories
+	CategoryID         *string           `json:"category_id"` // includes descendant categories
+	SupplierID         *string           `json:"supplier_id"`
+	Search             *string           `json:"search"`
+	CustomFields       map[string]string `json:"custom_fields,omitempty"` // cf.<field_name>[.min|.max] query filters
+	Sort               string            `json:"sort,omitempty"`
+	Cursor             string            `json:"cursor,omitempty"`
+	Page               int               `json:"page"`
+	Limit              int               `json:"limit"`
+}
+
+// BulkSKUActions are the actions a bulk SKU request can apply
+var BulkSKUActions = []string{"activate", "deactivate", "set_category", "set_supplier", "archive"}
+
+// BulkSKURequest applies one action to the SKUs listed in SKUIDs or, when Filter is
+// set, to every SKU the SKU list returns for those query parameters
+type BulkSKURequest struct {
+	SKUIDs       []string          `json:"sku_ids,omitempty"`
+	Filter       map[string]string `json:"filter,omitempty"`
+	Action       string            `json:"action" validate:"required,oneof=activate deactivate set_category set_supplier archive"`
+	CategoryID   *string           `json:"category_id,omitempty" validate:"omitempty,uuid"` // set_category
+	Category     *string           `json:"category,omitempty" validate:"omitempty,max=255"` // set_category, created if missing
+	SupplierID   *string           `json:"supplier_id,omitempty" validate:"omitempty,uuid"` // set_supplier
+	Supplier     *string           `json:"supplier,omitempty" validate:"omitempty,max=255"` // set_supplier, created if missing
+	Reason       *string           `json:"reason,omitempty"`
+	AllOrNothing bool              `json:"all_or_nothing"` // roll back every item when one fails
+}
+
+// BulkSKUResult is the outcome for one SKU: updated, unchanged or failed
+type BulkSKUResult struct {
+	SKUID   string  `json:"sku_id"`
+	SKUCode *string `json:"sku_code,omitempty"`
+	Status  string  `json:"status"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// BulkSKUResponse reports a bulk action. The change log entries it wrote carry
+// BatchID in their metadata.
+type BulkSKUResponse struct {
+	BatchID   string           `json:"batch_id"`
+	Action    string           `json:"action"`
+	Applied   bool             `json:"applied"` // false when all_or_nothing rolled the batch back
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Failed    int              `json:"failed"`
+	Results   []*BulkSKUResult `json:"results"`
+}
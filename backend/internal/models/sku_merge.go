@@ -0,0 +1,41 @@
+package models
+
+// SKUDuplicateParams tunes the duplicate finder
+type SKUDuplicateParams struct {
+	MinScore        float64 `json:"min_score"` // 0.3 to 1, pairs scoring lower are left out
+	IncludeInactive bool    `json:"include_inactive"`
+	Limit           int     `json:"limit"`
+}
+
+// SKUDuplicate is a pair of SKUs that look like the same product. Score is 1 for a
+// shared barcode, otherwise the trigram similarity of their product names.
+type SKUDuplicate struct {
+	SKU            *SKU     `json:"sku"`
+	Duplicate      *SKU     `json:"duplicate"`
+	Score          float64  `json:"score"`
+	NameSimilarity float64  `json:"name_similarity"`
+	CodeSimilarity float64  `json:"code_similarity"`
+	Reasons        []string `json:"reasons"` // barcode, product_name, sku_code
+}
+
+// MergeSKURequest merges the source SKU into the SKU named in the URL, which survives
+type MergeSKURequest struct {
+	SourceSKUID string  `json:"source_sku_id" validate:"required,uuid"`
+	Reason      *string `json:"reason,omitempty"`
+}
+
+// SKUMergeResult reports what a merge moved onto the surviving SKU
+type SKUMergeResult struct {
+	Survivor  *SKU       `json:"survivor"`
+	Merged    *SKU       `json:"merged"`
+	Inventory *Inventory `json:"inventory,omitempty"` // combined stock of the survivor
+
+	MovedTransactions int `json:"moved_transactions"`
+	MovedReservations int `json:"moved_reservations"`
+	MovedOrderLines   int `json:"moved_order_lines"` // purchase and sales order lines
+	MovedChangeLogs   int `json:"moved_change_logs"`
+	MovedSuppliers    int `json:"moved_suppliers"`
+	MovedKitLines     int `json:"moved_kit_lines"`
+	MovedBarcodes     int `json:"moved_barcodes"` // alternate barcodes
+	MovedAttachments  int `json:"moved_attachments"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+type Supplier struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	Name           string    `json:"name"`
+	ContactName    *string   `json:"contact_name,omitempty"`
+	Email          *string   `json:"email,omitempty"`
+	Phone          *string   `json:"phone,omitempty"`
+	Address        *string   `json:"address,omitempty"`
+	LeadTimeDays   *int      `json:"lead_time_days,omitempty"`
+	Currency       string    `json:"currency"`
+	Notes          *string   `json:"notes,omitempty"`
+	IsActive       bool      `json:"is_active"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// SKUSupplier links a SKU to one of the suppliers it can be bought from
+type SKUSupplier struct {
+	ID                   string    `json:"id"`
+	SKUID                string    `json:"sku_id"`
+	SupplierID           string    `json:"supplier_id"`
+	SupplierItemCode     *string   `json:"supplier_item_code,omitempty"`
+	LastPurchasePrice    *float64  `json:"last_purchase_price,omitempty"`
+	MinimumOrderQuantity int       `json:"minimum_order_quantity"`
+	IsPreferred          bool      `json:"is_preferred"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+	// Supplier details
+	SupplierName string `json:"supplier_name"`
+	Currency     string `json:"currency"`
+	LeadTimeDays *int   `json:"lead_time_days,omitempty"`
+}
+
+// Request/Response types
+type CreateSupplierRequest struct {
+	Name         string  `json:"name" validate:"required,max=255"`
+	ContactName  *string `json:"contact_name" validate:"omitempty,max=255"`
+	Email        *string `json:"email" validate:"omitempty,email,max=255"`
+	Phone        *string `json:"phone" validate:"omitempty,max=50"`
+	Address      *string `json:"address"`
+	LeadTimeDays *int    `json:"lead_time_days" validate:"omitempty,min=0"`
+	Currency     string  `json:"currency" validate:"omitempty,len=3"`
+	Notes        *string `json:"notes"`
+}
+
+type UpdateSupplierRequest struct {
+	Name         string  `json:"name" validate:"required,max=255"`
+	ContactName  *string `json:"contact_name" validate:"omitempty,max=255"`
+	Email        *string `json:"email" validate:"omitempty,email,max=255"`
+	Phone        *string `json:"phone" validate:"omitempty,max=50"`
+	Address      *string `json:"address"`
+	LeadTimeDays *int    `json:"lead_time_days" validate:"omitempty,min=0"`
+	Currency     string  `json:"currency" validate:"omitempty,len=3"`
+	Notes        *string `json:"notes"`
+	IsActive     *bool   `json:"is_active,omitempty"`
+}
+
+type UpsertSKUSupplierRequest struct {
+	SupplierItemCode     *string  `json:"supplier_item_code" validate:"omitempty,max=100"`
+	LastPurchasePrice    *float64 `json:"last_purchase_price" validate:"omitempty,min=0"`
+	MinimumOrderQuantity int      `json:"minimum_order_quantity" validate:"omitempty,min=1"`
+	IsPreferred          bool     `json:"is_preferred"`
+}
+
+type SupplierListParams struct {
+	IncludeInactive bool    `json:"include_inactive"`
+	Search          *string `json:"search,omitempty"`
+	Page            int     `json:"page"`
+	Limit           int     `json:"limit"`
+}
+
+// DefaultSupplierCurrency is used when a supplier is created without a currency
+const DefaultSupplierCurrency = "USD"
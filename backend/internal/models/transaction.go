@@ -3,34 +3,36 @@ package models
 import "time"
 
 type Transaction struct {
-	ID              string    `json:"id"`
-	OrganizationID  string    `json:"organization_id"`
-	SKUID           string    `json:"sku_id"`
-	TransactionType string    `json:"transaction_type"` // "in" or "out"
-	Quantity        int       `json:"quantity"`
-	UnitCost        float64   `json:"unit_cost"`
-	TotalCost       float64   `json:"total_cost"`
-	ReferenceNumber *string   `json:"reference_number,omitempty"`
-	Notes           *string   `json:"notes,omitempty"`
-	CreatedBy       string    `json:"created_by"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              string            `json:"id"`
+	OrganizationID  string            `json:"organization_id"`
+	SKUID           string            `json:"sku_id"`
+	TransactionType string            `json:"transaction_type"` // "in" or "out"
+	Quantity        int               `json:"quantity"`
+	UnitCost        float64           `json:"unit_cost"`
+	TotalCost       float64           `json:"total_cost"`
+	ReferenceNumber *string           `json:"reference_number,omitempty"`
+	Notes           *string           `json:"notes,omitempty"`
+	CreatedBy       string            `json:"created_by"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+	CustomFields    CustomFieldValues `json:"custom_fields"`
 }
 
 // TransactionWithSKU includes SKU details for transaction listings
 type TransactionWithSKU struct {
-	ID              string    `json:"id"`
-	OrganizationID  string    `json:"organization_id"`
-	SKUID           string    `json:"sku_id"`
-	TransactionType string    `json:"transaction_type"`
-	Quantity        int       `json:"quantity"`
-	UnitCost        float64   `json:"unit_cost"`
-	TotalCost       float64   `json:"total_cost"`
-	ReferenceNumber *string   `json:"reference_number,omitempty"`
-	Notes           *string   `json:"notes,omitempty"`
-	CreatedBy       string    `json:"created_by"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              string            `json:"id"`
+	OrganizationID  string            `json:"organization_id"`
+	SKUID           string            `json:"sku_id"`
+	TransactionType string            `json:"transaction_type"`
+	Quantity        int               `json:"quantity"`
+	UnitCost        float64           `json:"unit_cost"`
+	TotalCost       float64           `json:"total_cost"`
+	ReferenceNumber *string           `json:"reference_number,omitempty"`
+	Notes           *string           `json:"notes,omitempty"`
+	CreatedBy       string            `json:"created_by"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+	CustomFields    CustomFieldValues `json:"custom_fields"`
 	// SKU details
 	SKUCode     string  `json:"sku_code"`
 	ProductName string  `json:"product_name"`
@@ -38,34 +40,45 @@ type TransactionWithSKU struct {
 	Category    *string `json:"category,omitempty"`
 	// User details
 	CreatedByName string `json:"created_by_name"`
+
+	Search *SearchMatch `json:"search,omitempty"` // set when listed with a search term
 }
 
 // Request/Response types
 type CreateTransactionRequest struct {
-	SKUID           string  `json:"sku_id" validate:"required,uuid"`
-	TransactionType string  `json:"transaction_type" validate:"required,oneof=in out"`
-	Quantity        int     `json:"quantity" validate:"required,min=1"`
-	UnitCost        float64 `json:"unit_cost" validate:"required,min=0"`
-	ReferenceNumber *string `json:"reference_number,omitempty"`
-	Notes           *string `json:"notes,omitempty"`
+	SKUID           string            `json:"sku_id" validate:"required,uuid"`
+	TransactionType string            `json:"transaction_type" validate:"required,oneof=in out"`
+	Quantity        int               `json:"quantity" validate:"required,min=1"`
+	UnitCost        float64           `json:"unit_cost" validate:"required,min=0"`
+	ReferenceNumber *string           `json:"reference_number,omitempty"`
+	Notes           *string           `json:"notes,omitempty"`
+	ReservationID   *string           `json:"reservation_id,omitempty"` // Only for "out" transactions
+	CustomFields    CustomFieldValues `json:"custom_fields,omitempty"`
 }
 
 type TransactionListParams struct {
-	TransactionType *string `json:"transaction_type,omitempty"`
-	SKUID           *string `json:"sku_id,omitempty"`
-	Category        *string `json:"category,omitempty"`
-	Search          *string `json:"search,omitempty"`
-	Page            int     `json:"page"`
-	Limit           int     `json:"limit"`
-	StartDate       *string `json:"start_date,omitempty"`
-	EndDate         *string `json:"end_date,omitempty"`
+	TransactionType *string           `json:"transaction_type,omitempty"`
+	SKUID           *string           `json:"sku_id,omitempty"`
+	Category        *string           `json:"category,omitempty"`
+	CategoryID      *string           `json:"category_id,omitempty"`
+	Search          *string           `json:"search,omitempty"`
+	Sort            string            `json:"sort,omitempty"`
+	Cursor          string            `json:"cursor,omitempty"`
+	Page            int               `json:"page"`
+	Limit           int               `json:"limit"`
+	StartDate       *string           `json:"start_date,omitempty"`
+	EndDate         *string           `json:"end_date,omitempty"`
+	CustomFields    map[string]string `json:"custom_fields,omitempty"` // cf.<field_name>[.min|.max] query filters
 }
 
 // BusinessRules defines inventory business rules
 type BusinessRules struct {
-	AllowNegativeInventory bool `json:"allow_negative_inventory"`
+	AllowNegativeInventory bool `json:"allow_negative_inventory"` // not supported yet, must be false
 	RequireReferenceNumber bool `json:"require_reference_number"`
-	MaxTransactionQuantity int  `json:"max_transaction_quantity"`
+	MaxTransactionQuantity int  `json:"max_transaction_quantity"` // 0 means no limit
+	LowStockThreshold      int  `json:"low_stock_threshold"`      // inventory.low_stock fires at or below this, 0 turns it off
+	// Transactions of at least this quantity notify managers, 0 turns it off
+	LargeAdjustmentQuantity int `json:"large_adjustment_quantity"`
 }
 
 // TransactionSummary for reporting
@@ -74,4 +87,4 @@ type TransactionSummary struct {
 	TotalTransactions int     `json:"total_transactions"`
 	TotalQuantity     int     `json:"total_quantity"`
 	TotalValue        float64 `json:"total_value"`
-}
\ No newline at end of file
+}
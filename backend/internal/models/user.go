@@ -4,15 +4,16 @@ import "time"
 
 // Enhanced User model for user management
 type UserWithDetails struct {
-	ID             string    `json:"id"`
-	OrganizationID string    `json:"organization_id"`
-	Email          string    `json:"email"`
-	Name           string    `json:"name"`
-	Role           string    `json:"role"`
-	IsActive       bool      `json:"is_active"`
-	LastLoginAt    *time.Time `json:"last_login_at,omitempty"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID              string     `json:"id"`
+	OrganizationID  string     `json:"organization_id"`
+	Email           string     `json:"email"`
+	Name            string     `json:"name"`
+	Role            string     `json:"role"`
+	IsActive        bool       `json:"is_active"`
+	LastLoginAt     *time.Time `json:"last_login_at,omitempty"`
+	PreferredLocale *string    `json:"preferred_locale,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 	// Organization details
 	OrganizationName string `json:"organization_name"`
 }
@@ -25,17 +26,20 @@ type CreateUserRequest struct {
 }
 
 type UpdateUserRequest struct {
-	Name     string `json:"name" validate:"required,min=1,max=100"`
-	Role     string `json:"role" validate:"required,oneof=admin manager user viewer"`
-	IsActive *bool  `json:"is_active,omitempty"`
+	Name            string  `json:"name" validate:"required,min=1,max=100"`
+	Role            string  `json:"role" validate:"required,oneof=admin manager user viewer"`
+	IsActive        *bool   `json:"is_active,omitempty"`
+	PreferredLocale *string `json:"preferred_locale,omitempty"` // empty string clears it
 }
 
 type UserListParams struct {
-	Role       *string `json:"role,omitempty"`
-	IsActive   *bool   `json:"is_active,omitempty"`
-	Search     *string `json:"search,omitempty"`
-	Page       int     `json:"page"`
-	Limit      int     `json:"limit"`
+	Role     *string `json:"role,omitempty"`
+	IsActive *bool   `json:"is_active,omitempty"`
+	Search   *string `json:"search,omitempty"`
+	Sort     string  `json:"sort,omitempty"`
+	Cursor   string  `json:"cursor,omitempty"`
+	Page     int     `json:"page"`
+	Limit    int     `json:"limit"`
 }
 
 // Role-based permissions
@@ -46,14 +50,14 @@ type UserRole struct {
 }
 
 type Permission struct {
-	Resource string   `json:"resource"` // "skus", "inventory", "transactions", "users"
+	Resource string   `json:"resource"` // "skus", "inventory", "transactions", "purchase_orders", "sales_orders", "suppliers", "users", "views", "notifications"
 	Actions  []string `json:"actions"`  // "read", "create", "update", "delete"
 }
 
 // Field-level permissions
 type FieldPermission struct {
-	Resource string                 `json:"resource"`
-	Fields   map[string]string     `json:"fields"` // field_name -> permission_level ("read", "write", "hidden")
+	Resource string            `json:"resource"`
+	Fields   map[string]string `json:"fields"` // field_name -> permission_level ("read", "write", "hidden")
 }
 
 // Predefined roles and their permissions
@@ -66,8 +70,13 @@ var DefaultRoles = []UserRole{
 			{Resource: "inventory", Actions: []string{"read", "create", "update", "delete"}},
 			{Resource: "transactions", Actions: []string{"read", "create", "update", "delete"}},
 			{Resource: "users", Actions: []string{"read", "create", "update", "delete"}},
+			{Resource: "purchase_orders", Actions: []string{"read", "create", "update", "receive"}},
+			{Resource: "sales_orders", Actions: []string{"read", "create", "update", "ship"}},
+			{Resource: "suppliers", Actions: []string{"read", "create", "update"}},
 			{Resource: "settings", Actions: []string{"read", "update"}},
 			{Resource: "logs", Actions: []string{"read", "create"}},
+			{Resource: "views", Actions: []string{"read", "create", "update", "delete"}},
+			{Resource: "notifications", Actions: []string{"read", "update"}},
 		},
 	},
 	{
@@ -78,8 +87,13 @@ var DefaultRoles = []UserRole{
 			{Resource: "inventory", Actions: []string{"read", "create", "update"}},
 			{Resource: "transactions", Actions: []string{"read", "create", "update"}},
 			{Resource: "users", Actions: []string{"read"}},
+			{Resource: "purchase_orders", Actions: []string{"read", "create", "update", "receive"}},
+			{Resource: "sales_orders", Actions: []string{"read", "create", "update", "ship"}},
+			{Resource: "suppliers", Actions: []string{"read", "create", "update"}},
 			{Resource: "settings", Actions: []string{"read", "update"}},
 			{Resource: "logs", Actions: []string{"read"}},
+			{Resource: "views", Actions: []string{"read", "create", "update", "delete"}},
+			{Resource: "notifications", Actions: []string{"read", "update"}},
 		},
 	},
 	{
@@ -89,7 +103,12 @@ var DefaultRoles = []UserRole{
 			{Resource: "skus", Actions: []string{"read", "create", "update"}},
 			{Resource: "inventory", Actions: []string{"read", "update"}},
 			{Resource: "transactions", Actions: []string{"read", "create"}},
+			{Resource: "purchase_orders", Actions: []string{"read", "receive"}},
+			{Resource: "sales_orders", Actions: []string{"read", "ship"}},
+			{Resource: "suppliers", Actions: []string{"read"}},
 			{Resource: "logs", Actions: []string{"read"}},
+			{Resource: "views", Actions: []string{"read", "create", "update", "delete"}},
+			{Resource: "notifications", Actions: []string{"read", "update"}},
 		},
 	},
 	{
@@ -99,7 +118,12 @@ var DefaultRoles = []UserRole{
 			{Resource: "skus", Actions: []string{"read"}},
 			{Resource: "inventory", Actions: []string{"read"}},
 			{Resource: "transactions", Actions: []string{"read"}},
+			{Resource: "purchase_orders", Actions: []string{"read"}},
+			{Resource: "sales_orders", Actions: []string{"read"}},
+			{Resource: "suppliers", Actions: []string{"read"}},
 			{Resource: "logs", Actions: []string{"read"}},
+			{Resource: "views", Actions: []string{"read", "create", "update", "delete"}},
+			{Resource: "notifications", Actions: []string{"read", "update"}},
 		},
 	},
 }
@@ -155,6 +179,19 @@ func GetRoleByName(roleName string) *UserRole {
 	return nil
 }
 
+// GetFieldPermission resolves the permission level of one field, falling back to the
+// resource's "*" entry and then to "read"
+func GetFieldPermission(roleName, resource, field string) string {
+	fields := GetFieldPermissions(roleName, resource)
+	if level, ok := fields[field]; ok {
+		return level
+	}
+	if level, ok := fields["*"]; ok {
+		return level
+	}
+	return "read"
+}
+
 func GetFieldPermissions(roleName, resource string) map[string]string {
 	if permissions, exists := DefaultFieldPermissions[roleName]; exists {
 		for _, fieldPerm := range permissions {
@@ -164,4 +201,4 @@ func GetFieldPermissions(roleName, resource string) map[string]string {
 		}
 	}
 	return map[string]string{}
-}
\ No newline at end of file
+}
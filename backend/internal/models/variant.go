@@ -0,0 +1,45 @@
+package models
+
+// VariantAttribute is one axis of a parent SKU's variant matrix, e.g. size with S, M and L
+type VariantAttribute struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+}
+
+// SKUVariant is a child SKU together with the attribute values it was generated from
+type SKUVariant struct {
+	SKU
+	VariantValues map[string]string `json:"variant_values"`
+}
+
+// Request/Response types
+type GenerateVariantsRequest struct {
+	Attributes  []VariantAttribute `json:"attributes" validate:"required,min=1"`
+	CodePattern string             `json:"code_pattern,omitempty" validate:"omitempty,max=100"` // defaults to "{parent}-{attr1}-{attr2}..."
+}
+
+type GenerateVariantsResponse struct {
+	Parent      *SKU               `json:"parent"`
+	Attributes  []VariantAttribute `json:"attributes"`
+	CodePattern string             `json:"code_pattern"`
+	Created     []*SKUVariant      `json:"created"`
+	Existing    []*SKUVariant      `json:"existing"`
+}
+
+// ParentInventory aggregates the stock of a parent SKU's variants. SKUs without variants
+// are returned as their own group with a VariantCount of zero.
+type ParentInventory struct {
+	SKUID             string  `json:"sku_id"`
+	SKUCode           string  `json:"sku_code"`
+	ProductName       string  `json:"product_name"`
+	Category          *string `json:"category"`
+	VariantCount      int     `json:"variant_count"`
+	Quantity          int     `json:"quantity"`
+	ReservedQuantity  int     `json:"reserved_quantity"`
+	AvailableQuantity int     `json:"available_quantity"`
+	WeightedCost      float64 `json:"weighted_cost"`
+	TotalValue        float64 `json:"total_value"`
+}
+
+// VariantCodePlaceholderParent is replaced by the parent's SKU code in a variant code pattern
+const VariantCodePlaceholderParent = "{parent}"
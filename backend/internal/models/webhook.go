@@ -0,0 +1,98 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEvents are the event types endpoints can subscribe to. "*" subscribes
+// to all of them, including types added later.
+var WebhookEvents = []string{
+	"sku.created",
+	"sku.updated",
+	"transaction.created",
+	"inventory.low_stock",
+	"user.created",
+}
+
+// WebhookDeliveryStatuses: pending deliveries are retried until they succeed or
+// run out of attempts and fail
+var WebhookDeliveryStatuses = []string{"pending", "succeeded", "failed"}
+
+type WebhookEndpoint struct {
+	ID             string    `json:"id"`
+	OrganizationID string    `json:"organization_id"`
+	URL            string    `json:"url"`
+	Description    string    `json:"description"`
+	Events         []string  `json:"events"`
+	Secret         string    `json:"secret,omitempty"` // only returned when created or rotated
+	IsActive       bool      `json:"is_active"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type CreateWebhookEndpointRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	Description string   `json:"description" validate:"max=255"`
+	Events      []string `json:"events" validate:"required,min=1"`
+	IsActive    *bool    `json:"is_active,omitempty"` // defaults to true
+}
+
+type UpdateWebhookEndpointRequest struct {
+	URL          *string  `json:"url,omitempty"`
+	Description  *string  `json:"description,omitempty"`
+	Events       []string `json:"events,omitempty"`
+	IsActive     *bool    `json:"is_active,omitempty"`
+	RotateSecret bool     `json:"rotate_secret,omitempty"`
+}
+
+// WebhookEvent is the JSON body posted to endpoints
+type WebhookEvent struct {
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	OrganizationID string          `json:"organization_id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Data           json.RawMessage `json:"data"`
+}
+
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	EndpointID     string          `json:"endpoint_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	ResponseBody   *string         `json:"response_body,omitempty"` // truncated
+	Error          *string         `json:"error,omitempty"`
+	RedeliveryOf   *string         `json:"redelivery_of,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	Payload        json.RawMessage `json:"payload"`
+}
+
+type WebhookDeliveryListParams struct {
+	Status *string `json:"status,omitempty"`
+	Limit  int     `json:"limit,omitempty"`
+}
+
+// PendingWebhookDelivery is a delivery claimed for sending, with the endpoint
+// it goes to
+type PendingWebhookDelivery struct {
+	ID        string
+	EventType string
+	Payload   []byte
+	Attempts  int // including the attempt being made
+	URL       string
+	Secret    string
+}
+
+// WebhookAttempt is the outcome of sending a delivery once
+type WebhookAttempt struct {
+	ResponseStatus *int
+	ResponseBody   *string
+	Error          *string
+	Succeeded      bool
+	NextAttemptAt  *time.Time // nil when the delivery has no attempts left
+}
@@ -0,0 +1,256 @@
+package notifications
+
+import (
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+// readMessage parses a built message and returns it with its decoded body
+func readMessage(t *testing.T, raw []byte) (*mail.Message, string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v\n%s", err, raw)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+	if err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	return msg, string(body)
+}
+
+func TestBuildMessage(t *testing.T) {
+	from := &mail.Address{Name: "Flex ERP", Address: "erp@example.com"}
+	to := &mail.Address{Name: "Ana Núñez", Address: "ana@example.org"}
+	subject := "Low stock: CAFÉ-01 ≤ 5"
+	body := "Café molido (CAFÉ-01) is down to 3 on hand.\n" + strings.Repeat("long line ", 20) + "\nlast=line"
+
+	raw, err := buildMessage(from, to, subject, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(string(raw), "\r\n") {
+		if len(line) > 76 {
+			t.Errorf("line of %d characters: %q", len(line), line)
+		}
+		if strings.Contains(line, "\n") {
+			t.Errorf("bare line feed in %q", line)
+		}
+	}
+
+	msg, gotBody := readMessage(t, raw)
+	headers := map[string]string{
+		"From":                      `"Flex ERP" <erp@example.com>`,
+		"MIME-Version":              "1.0",
+		"Content-Type":              `text/plain; charset="utf-8"`,
+		"Content-Transfer-Encoding": "quoted-printable",
+	}
+	for name, want := range headers {
+		if got := msg.Header.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	rcpt, err := msg.Header.AddressList("To")
+	if err != nil || len(rcpt) != 1 || rcpt[0].Name != to.Name || rcpt[0].Address != to.Address {
+		t.Errorf("To = %q (%v), want %v", msg.Header.Get("To"), err, to)
+	}
+
+	rawSubject := msg.Header.Get("Subject")
+	if !strings.HasPrefix(rawSubject, "=?utf-8?q?") {
+		t.Errorf("Subject = %q, want it Q-encoded", rawSubject)
+	}
+	if decoded, err := new(mime.WordDecoder).DecodeHeader(rawSubject); err != nil || decoded != subject {
+		t.Errorf("decoded Subject = %q (%v), want %q", decoded, err, subject)
+	}
+
+	if _, err := msg.Header.Date(); err != nil {
+		t.Errorf("Date: %v", err)
+	}
+	if id := msg.Header.Get("Message-ID"); !strings.HasPrefix(id, "<") || !strings.HasSuffix(id, "@example.com>") {
+		t.Errorf("Message-ID = %q, want one at the sender's domain", id)
+	}
+
+	if want := strings.ReplaceAll(body, "\n", "\r\n"); gotBody != want {
+		t.Errorf("body = %q, want %q", gotBody, want)
+	}
+}
+
+func TestBuildMessageASCIISubject(t *testing.T) {
+	raw, err := buildMessage(&mail.Address{Address: "erp@example.com"}, &mail.Address{Address: "ana@example.org"}, "Daily digest", "Hi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, _ := readMessage(t, raw)
+	if got := msg.Header.Get("Subject"); got != "Daily digest" {
+		t.Errorf("Subject = %q, want it unencoded", got)
+	}
+}
+
+func TestBuildMessageRejectsLineBreaksInHeaders(t *testing.T) {
+	from := &mail.Address{Address: "erp@example.com"}
+	to := &mail.Address{Address: "ana@example.org"}
+	tests := []struct {
+		name     string
+		from, to *mail.Address
+		subject  string
+	}{
+		{"subject CRLF", from, to, "Low stock\r\nBcc: victim@example.net"},
+		{"subject LF", from, to, "Low stock\nBcc: victim@example.net"},
+		{"subject CR", from, to, "Low stock\rBcc: victim@example.net"},
+		{"recipient address", from, &mail.Address{Address: "ana@example.org\r\nBcc: victim"}, "Low stock"},
+		{"sender address", &mail.Address{Address: "erp@example.com\nBcc: victim"}, to, "Low stock"},
+	}
+	for _, tt := range tests {
+		if raw, err := buildMessage(tt.from, tt.to, tt.subject, "body"); err == nil {
+			t.Errorf("%s: built %q, want an error", tt.name, raw)
+		}
+	}
+}
+
+// fakeSMTP is an SMTP server on a local listener that accepts one message and
+// records it. rcptReply is the reply to RCPT TO, "250 OK" when empty.
+type fakeSMTP struct {
+	addr      string
+	rcptReply string
+	done      chan struct{}
+
+	mailFrom string
+	rcptTo   []string
+	data     string
+	err      error
+}
+
+func startFakeSMTP(t *testing.T, rcptReply string) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &fakeSMTP{addr: ln.Addr().String(), rcptReply: rcptReply, done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		conn, err := ln.Accept()
+		if err != nil {
+			s.err = err
+			return
+		}
+		defer conn.Close()
+		s.err = s.serve(textproto.NewConn(conn))
+	}()
+	return s
+}
+
+func (s *fakeSMTP) serve(c *textproto.Conn) error {
+	if err := c.PrintfLine("220 localhost fake SMTP"); err != nil {
+		return err
+	}
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return err
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			err = c.PrintfLine("250 localhost")
+		case "MAIL":
+			s.mailFrom = arg
+			err = c.PrintfLine("250 OK")
+		case "RCPT":
+			s.rcptTo = append(s.rcptTo, arg)
+			reply := s.rcptReply
+			if reply == "" {
+				reply = "250 OK"
+			}
+			err = c.PrintfLine("%s", reply)
+		case "DATA":
+			if err := c.PrintfLine("354 Go ahead"); err != nil {
+				return err
+			}
+			data, err := io.ReadAll(c.DotReader())
+			if err != nil {
+				return err
+			}
+			s.data = string(data)
+			err = c.PrintfLine("250 Queued")
+		case "RSET", "NOOP":
+			err = c.PrintfLine("250 OK")
+		case "QUIT":
+			return c.PrintfLine("221 Bye")
+		default:
+			err = c.PrintfLine("502 Command not implemented")
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	server := startFakeSMTP(t, "")
+	mailer := NewSMTPMailer(server.addr, "Flex ERP <erp@example.com>", "", "")
+
+	body := "3 units of Café molido were issued.\n\n--\nFooter"
+	if err := mailer.Send("Ana <ana@example.org>", "Large stock issue: CAFÉ-01", body); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	<-server.done
+	if server.err != nil {
+		t.Fatalf("server: %v", server.err)
+	}
+
+	if server.mailFrom != "FROM:<erp@example.com>" {
+		t.Errorf("MAIL %s, want FROM:<erp@example.com>", server.mailFrom)
+	}
+	if len(server.rcptTo) != 1 || server.rcptTo[0] != "TO:<ana@example.org>" {
+		t.Errorf("RCPT %v, want TO:<ana@example.org>", server.rcptTo)
+	}
+
+	msg, gotBody := readMessage(t, []byte(strings.ReplaceAll(server.data, "\n", "\r\n")))
+	if subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); subject != "Large stock issue: CAFÉ-01" {
+		t.Errorf("Subject = %q", subject)
+	}
+	// DATA ends the message on a line of its own, adding a final line break
+	if want := strings.ReplaceAll(body, "\n", "\r\n") + "\r\n"; gotBody != want {
+		t.Errorf("body = %q, want %q", gotBody, want)
+	}
+}
+
+func TestSMTPMailerSendRejectedRecipient(t *testing.T) {
+	server := startFakeSMTP(t, "550 No such user")
+	mailer := NewSMTPMailer(server.addr, "erp@example.com", "", "")
+
+	err := mailer.Send("nobody@example.org", "Subject", "Body")
+	if err == nil || !strings.Contains(err.Error(), "No such user") {
+		t.Errorf("Send error = %v, want the server's rejection", err)
+	}
+	<-server.done
+	if server.data != "" {
+		t.Errorf("server received a message after rejecting the recipient: %q", server.data)
+	}
+}
+
+func TestSMTPMailerSendInvalidAddresses(t *testing.T) {
+	tests := []struct {
+		name, from, to string
+	}{
+		{"sender", "not an address", "ana@example.org"},
+		{"recipient", "erp@example.com", "ana@example.org\r\nBcc: victim@example.net"},
+	}
+	for _, tt := range tests {
+		// Nothing listens here: the addresses are refused before connecting
+		mailer := NewSMTPMailer("127.0.0.1:1", tt.from, "", "")
+		if err := mailer.Send(tt.to, "Subject", "Body"); err == nil || !strings.Contains(err.Error(), "invalid "+tt.name) {
+			t.Errorf("%s: Send error = %v, want invalid %s address", tt.name, err, tt.name)
+		}
+	}
+}
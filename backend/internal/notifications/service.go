@@ -0,0 +1,196 @@
+// Package notifications turns domain events into notifications for the users who
+// should hear about them: an entry in their in-app inbox, an email sent straight
+// away, or a line in a daily digest email, as each user prefers.
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"flex-erp-poc/internal/database"
+	"flex-erp-poc/internal/models"
+	"flex-erp-poc/internal/utils"
+)
+
+const (
+	// MaxEmailAttempts is how many times an email is sent before it is marked failed
+	MaxEmailAttempts = 6
+
+	emailBatchSize  = 20
+	emailClaimLease = 5 * time.Minute // longer than sending a batch can take
+
+	// Failed emails are retried after 1m, 2m, 4m and so on, capped at 1h
+	emailRetryBase  = time.Minute
+	emailRetryLimit = time.Hour
+)
+
+type Service struct {
+	DB     *database.PostgresService
+	Mailer Mailer // nil leaves emails queued
+	AppURL string // linked from messages
+}
+
+func NewService(db *database.PostgresService, mailer Mailer, appURL string) *Service {
+	return &Service{DB: db, Mailer: mailer, AppURL: appURL}
+}
+
+// HandleEvent notifies the users an event concerns. Subscribe it to the event bus;
+// an event handled twice does not notify anyone twice.
+func (s *Service) HandleEvent(event *models.OutboxEvent) error {
+	ctx := context.Background()
+	switch event.Type {
+	case "inventory.low_stock":
+		var payload struct {
+			SKU *models.SKU `json:"sku"`
+		}
+		if err := json.Unmarshal(event.Data, &payload); err != nil || payload.SKU == nil {
+			return err
+		}
+		return s.notify(ctx, event, "low_stock", "", map[string]string{"sku_id": payload.SKU.ID}, nil)
+
+	case "transaction.created":
+		var payload struct {
+			Transaction *models.Transaction `json:"transaction"`
+		}
+		if err := json.Unmarshal(event.Data, &payload); err != nil || payload.Transaction == nil {
+			return err
+		}
+		rules, err := s.DB.GetBusinessRules(ctx, event.OrganizationID)
+		if err != nil {
+			return err
+		}
+		transaction := payload.Transaction
+		if rules.LargeAdjustmentQuantity <= 0 || transaction.Quantity < rules.LargeAdjustmentQuantity {
+			return nil
+		}
+		sku, err := s.DB.GetSKUByID(ctx, event.OrganizationID, transaction.SKUID)
+		if err != nil {
+			return err
+		}
+		link := map[string]string{"sku_id": transaction.SKUID, "transaction_id": transaction.ID}
+		return s.notify(ctx, event, "large_adjustment", "", link, map[string]interface{}{"sku": sku})
+
+	case "user.created":
+		var payload struct {
+			User *models.UserWithDetails `json:"user"`
+		}
+		if err := json.Unmarshal(event.Data, &payload); err != nil || payload.User == nil {
+			return err
+		}
+		return s.notify(ctx, event, "user_invitation", payload.User.ID, map[string]string{"user_id": payload.User.ID}, nil)
+	}
+	return nil
+}
+
+// notify renders the notification type for the event and records it for each
+// recipient: the type's audience roles, or the one user when userID is set. link
+// is kept with the notification so the app can open what it is about; extra is
+// added to the event data the templates see.
+func (s *Service) notify(ctx context.Context, event *models.OutboxEvent, notificationType, userID string, link map[string]string, extra map[string]interface{}) error {
+	data := make(map[string]interface{})
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		return err
+	}
+	if len(extra) > 0 {
+		// Round trip through JSON so templates see the same field names throughout
+		raw, err := json.Marshal(extra)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return err
+		}
+	}
+	data["app_url"] = s.AppURL
+
+	title, body, err := render(notificationType, data)
+	if err != nil {
+		return err
+	}
+	linkData, err := json.Marshal(link)
+	if err != nil {
+		return err
+	}
+
+	var roles []string
+	if userID == "" {
+		roles = models.NotificationAudiences[notificationType]
+	}
+	recipients, err := s.DB.GetNotificationRecipients(ctx, event.OrganizationID, notificationType, roles, userID)
+	if err != nil {
+		return err
+	}
+	for _, recipient := range recipients {
+		err := s.DB.CreateNotification(ctx, models.NewNotification{
+			OrganizationID: event.OrganizationID,
+			Recipient:      recipient,
+			Type:           notificationType,
+			EventID:        event.ID,
+			Title:          title,
+			Body:           body,
+			Data:           linkData,
+			EmailSubject:   title,
+			EmailBody:      emailBody(body, s.AppURL),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to notify user %s: %w", recipient.UserID, err)
+		}
+	}
+	return nil
+}
+
+// QueueDigests queues one digest email per user for the notifications waiting
+// from before cutoff, and returns how many it queued
+func (s *Service) QueueDigests(ctx context.Context, cutoff time.Time) (int, error) {
+	digests, err := s.DB.GetNotificationDigests(ctx, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	queued := 0
+	for _, digest := range digests {
+		subject, body := renderDigest(digest, s.AppURL)
+		if err := s.DB.QueueNotificationDigest(ctx, digest, subject, body); err != nil {
+			return queued, err
+		}
+		queued++
+	}
+	return queued, nil
+}
+
+// SendDue sends the emails that are due, batch by batch, and returns how many
+// were sent. Without a mailer nothing is sent.
+func (s *Service) SendDue(ctx context.Context) (int, error) {
+	if s.Mailer == nil {
+		return 0, nil
+	}
+	sent := 0
+	for {
+		emails, err := s.DB.ClaimNotificationEmails(ctx, emailBatchSize, emailClaimLease)
+		if err != nil {
+			return sent, err
+		}
+		if len(emails) == 0 {
+			return sent, nil
+		}
+
+		for _, email := range emails {
+			sendErr := s.Mailer.Send(email.To, email.Subject, email.Body)
+			var next *time.Time
+			if sendErr != nil {
+				log.Printf("Failed to send notification email %s (attempt %d): %v", email.ID, email.Attempts, sendErr)
+				if email.Attempts < MaxEmailAttempts {
+					retryAt := time.Now().Add(utils.Backoff(emailRetryBase, emailRetryLimit, email.Attempts))
+					next = &retryAt
+				}
+			} else {
+				sent++
+			}
+			if err := s.DB.RecordNotificationEmailAttempt(ctx, email.ID, sendErr, next); err != nil {
+				return sent, err
+			}
+		}
+	}
+}
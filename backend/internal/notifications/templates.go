@@ -0,0 +1,82 @@
+package notifications
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"flex-erp-poc/internal/models"
+)
+
+// messageTemplate renders one notification type. Templates see the event data
+// decoded from JSON, plus app_url.
+type messageTemplate struct {
+	title *template.Template
+	body  *template.Template
+}
+
+func newMessageTemplate(name, title, body string) messageTemplate {
+	return messageTemplate{
+		title: template.Must(template.New(name + ".title").Parse(title)),
+		body:  template.Must(template.New(name + ".body").Parse(body)),
+	}
+}
+
+var messageTemplates = map[string]messageTemplate{
+	"low_stock": newMessageTemplate("low_stock",
+		`Low stock: {{.sku.sku_code}}`,
+		`{{.sku.product_name}} ({{.sku.sku_code}}) is down to {{.inventory.quantity}} on hand, `+
+			`at or below the low stock threshold of {{.threshold}}.`),
+	"large_adjustment": newMessageTemplate("large_adjustment",
+		`Large stock {{if eq .transaction.transaction_type "in"}}receipt{{else}}issue{{end}}: {{.sku.sku_code}}`,
+		`{{.transaction.quantity}} units of {{.sku.product_name}} ({{.sku.sku_code}}) were `+
+			`{{if eq .transaction.transaction_type "in"}}received{{else}}issued{{end}}`+
+			`{{with .transaction.reference_number}} under reference {{.}}{{end}}.`),
+	"user_invitation": newMessageTemplate("user_invitation",
+		`You have been added to {{or .user.organization_name "Flex ERP"}}`,
+		`Hi {{.user.name}}, you now have {{.user.role}} access to {{or .user.organization_name "Flex ERP"}}. `+
+			`Sign in at {{.app_url}} with {{.user.email}}.`),
+}
+
+const emailFooter = "\n\n--\nYou are receiving this because of your Flex ERP notification preferences. " +
+	"You can change them in the app at %s."
+
+// render fills in the title and body of a notification type
+func render(notificationType string, data map[string]interface{}) (title, body string, err error) {
+	t, ok := messageTemplates[notificationType]
+	if !ok {
+		return "", "", fmt.Errorf("no template for notification type %s", notificationType)
+	}
+	var buf bytes.Buffer
+	if err := t.title.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	title = strings.TrimSpace(buf.String())
+	buf.Reset()
+	if err := t.body.Execute(&buf, data); err != nil {
+		return "", "", err
+	}
+	return title, strings.TrimSpace(buf.String()), nil
+}
+
+// emailBody is a notification's body as an email, with the footer
+func emailBody(body, appURL string) string {
+	return body + fmt.Sprintf(emailFooter, appURL)
+}
+
+// renderDigest lists a day's notifications in one email, oldest first
+func renderDigest(digest *models.NotificationDigest, appURL string) (subject, body string) {
+	subject = fmt.Sprintf("Your Flex ERP digest: %d notification", len(digest.Notifications))
+	if len(digest.Notifications) != 1 {
+		subject += "s"
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Hi %s, here is what happened since your last digest.\n", digest.Name)
+	for _, n := range digest.Notifications {
+		fmt.Fprintf(&buf, "\n%s  %s\n%s\n", n.CreatedAt.UTC().Format(time.DateTime+" UTC"), n.Title, n.Body)
+	}
+	return subject, emailBody(buf.String(), appURL)
+}
@@ -0,0 +1,115 @@
+package notifications
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"flex-erp-poc/internal/models"
+)
+
+// eventData decodes JSON the way notify hands event data to templates
+func eventData(t *testing.T, raw string) map[string]interface{} {
+	t.Helper()
+	data := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		t.Fatal(err)
+	}
+	data["app_url"] = "https://erp.example.com"
+	return data
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name, notificationType, data string
+		wantTitle, wantBody          string
+	}{
+		{
+			"low stock", "low_stock",
+			`{"sku": {"sku_code": "BOLT-M6", "product_name": "M6 bolt"}, "inventory": {"quantity": 4}, "threshold": 10}`,
+			"Low stock: BOLT-M6",
+			"M6 bolt (BOLT-M6) is down to 4 on hand, at or below the low stock threshold of 10.",
+		},
+		{
+			"large receipt with reference", "large_adjustment",
+			`{"sku": {"sku_code": "BOLT-M6", "product_name": "M6 bolt"}, "transaction": {"transaction_type": "in", "quantity": 500, "reference_number": "PO-17"}}`,
+			"Large stock receipt: BOLT-M6",
+			"500 units of M6 bolt (BOLT-M6) were received under reference PO-17.",
+		},
+		{
+			"large issue without reference", "large_adjustment",
+			`{"sku": {"sku_code": "BOLT-M6", "product_name": "M6 bolt"}, "transaction": {"transaction_type": "out", "quantity": 250, "reference_number": null}}`,
+			"Large stock issue: BOLT-M6",
+			"250 units of M6 bolt (BOLT-M6) were issued.",
+		},
+		{
+			"invitation", "user_invitation",
+			`{"user": {"name": "Ana", "role": "manager", "email": "ana@example.org", "organization_name": "Acme"}}`,
+			"You have been added to Acme",
+			"Hi Ana, you now have manager access to Acme. Sign in at https://erp.example.com with ana@example.org.",
+		},
+		{
+			"invitation without an organization name", "user_invitation",
+			`{"user": {"name": "Ana", "role": "viewer", "email": "ana@example.org"}}`,
+			"You have been added to Flex ERP",
+			"Hi Ana, you now have viewer access to Flex ERP. Sign in at https://erp.example.com with ana@example.org.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, body, err := render(tt.notificationType, eventData(t, tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", title, tt.wantTitle)
+			}
+			if body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRenderUnknownType(t *testing.T) {
+	if _, _, err := render("no_such_type", map[string]interface{}{}); err == nil {
+		t.Error("render of an unknown type succeeded")
+	}
+}
+
+func TestEmailBody(t *testing.T) {
+	body := emailBody("M6 bolt is low.", "https://erp.example.com")
+	if !strings.HasPrefix(body, "M6 bolt is low.\n\n--\n") {
+		t.Errorf("body = %q, want the notification then the footer", body)
+	}
+	if !strings.HasSuffix(body, "in the app at https://erp.example.com.") {
+		t.Errorf("footer = %q, want a link to the app", body)
+	}
+}
+
+func TestRenderDigest(t *testing.T) {
+	at := time.Date(2024, 3, 5, 8, 30, 0, 0, time.FixedZone("CST", -6*60*60))
+	one := &models.Notification{Title: "Low stock: BOLT-M6", Body: "M6 bolt is down to 4 on hand.", CreatedAt: at}
+	two := &models.Notification{Title: "Large stock receipt: NUT-M6", Body: "500 units were received.", CreatedAt: at.Add(90 * time.Minute)}
+
+	subject, body := renderDigest(&models.NotificationDigest{Name: "Ana", Notifications: []*models.Notification{one}}, "https://erp.example.com")
+	if subject != "Your Flex ERP digest: 1 notification" {
+		t.Errorf("subject = %q", subject)
+	}
+	want := "Hi Ana, here is what happened since your last digest.\n" +
+		"\n2024-03-05 14:30:00 UTC  Low stock: BOLT-M6\nM6 bolt is down to 4 on hand.\n" +
+		emailBody("", "https://erp.example.com")
+	if body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+
+	subject, body = renderDigest(&models.NotificationDigest{Name: "Ana", Notifications: []*models.Notification{one, two}}, "https://erp.example.com")
+	if subject != "Your Flex ERP digest: 2 notifications" {
+		t.Errorf("subject = %q", subject)
+	}
+	first, second := strings.Index(body, "Low stock: BOLT-M6"), strings.Index(body, "16:00:00 UTC  Large stock receipt: NUT-M6")
+	if first < 0 || second < first {
+		t.Errorf("body = %q, want both notifications oldest first", body)
+	}
+}
@@ -0,0 +1,21 @@
+// Package storage keeps uploaded file contents. Metadata lives in Postgres; a
+// Storage only maps keys to bytes, so another backend such as an object store
+// can replace the local filesystem without touching the handlers.
+package storage
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned by Open when nothing is stored under the key
+var ErrNotFound = errors.New("stored file not found")
+
+type Storage interface {
+	// Put stores the contents of r under key, replacing what was there, and
+	// returns the number of bytes written
+	Put(key string, r io.Reader) (int64, error)
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes key; deleting a missing key is not an error
+	Delete(key string) error
+}
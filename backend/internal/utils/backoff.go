@@ -0,0 +1,12 @@
+package utils
+
+import "time"
+
+// Backoff is the wait before retrying after the given failed attempt: base,
+// then doubling with each attempt, capped at limit. Attempts below 1 are
+// treated as the first.
+func Backoff(base, limit time.Duration, attempt int) time.Duration {
+	attempt = max(attempt, 1)
+	wait := base << min(attempt-1, 10)
+	return min(wait, limit)
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{-3, time.Second},
+		{0, time.Second},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{6, 32 * time.Second},
+		{7, time.Minute},
+		{100, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := Backoff(time.Second, time.Minute, tt.attempt); got != tt.want {
+			t.Errorf("Backoff(1s, 1m, %d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestBackoffShiftIsBounded(t *testing.T) {
+	// The doubling stops after ten attempts, so a huge attempt count cannot
+	// overflow the duration below the limit
+	if got := Backoff(time.Second, 24*time.Hour, 1<<30); got != 1024*time.Second {
+		t.Errorf("Backoff(1s, 24h, 1<<30) = %v, want %v", got, 1024*time.Second)
+	}
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gtinLengths are the GS1 code lengths: EAN-8, UPC-A, EAN-13 and GTIN-14
+var gtinLengths = map[int]bool{8: true, 12: true, 13: true, 14: true}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// IsGTIN reports whether code has the length and digits of a GS1 code
+func IsGTIN(code string) bool {
+	return gtinLengths[len(code)] && isDigits(code)
+}
+
+// GTINCheckDigit computes the GS1 check digit for the digits of a code without it.
+// Weights alternate 3 and 1 starting from the rightmost digit.
+func GTINCheckDigit(digits string) int {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10 - sum%10) % 10
+}
+
+// ValidateBarcode trims a barcode and checks it. EAN-8, UPC-A, EAN-13 and GTIN-14
+// codes must carry a valid check digit; other codes, such as Code 128 labels, only
+// need to be printable and at most 50 characters.
+func ValidateBarcode(code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if code == "" || len(code) > 50 {
+		return "", fmt.Errorf("invalid barcode: must be 1 to 50 characters")
+	}
+	for _, r := range code {
+		if r < 0x21 || r > 0x7e {
+			return "", fmt.Errorf("invalid barcode: %q has characters a scanner cannot read", code)
+		}
+	}
+
+	if IsGTIN(code) {
+		body, check := code[:len(code)-1], int(code[len(code)-1]-'0')
+		if expected := GTINCheckDigit(body); expected != check {
+			return "", fmt.Errorf("invalid barcode: check digit of %s should be %d", code, expected)
+		}
+	}
+	return code, nil
+}
+
+// BarcodeLookupKeys returns the forms a scanned code may be stored under. A UPC-A,
+// EAN-13 or GTIN-14 code matches the same number zero-padded to the other lengths,
+// so a UPC read as EAN-13 still finds its SKU.
+func BarcodeLookupKeys(code string) []string {
+	code = strings.TrimSpace(code)
+	if !IsGTIN(code) || len(code) == 8 {
+		return []string{code}
+	}
+
+	trimmed := strings.TrimLeft(code, "0")
+	keys := make([]string, 0, 3)
+	for _, length := range []int{12, 13, 14} {
+		if len(trimmed) <= length {
+			keys = append(keys, strings.Repeat("0", length-len(trimmed))+trimmed)
+		}
+	}
+	return keys
+}
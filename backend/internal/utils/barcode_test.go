@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGTINCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   int
+	}{
+		{"9638507", 4},       // EAN-8
+		{"03600029145", 2},   // UPC-A
+		{"400638133393", 1},  // EAN-13
+		{"590123412345", 7},  // EAN-13
+		{"1001234567890", 2}, // GTIN-14
+		{"0000000", 0},
+	}
+	for _, tt := range tests {
+		if got := GTINCheckDigit(tt.digits); got != tt.want {
+			t.Errorf("GTINCheckDigit(%s) = %d, want %d", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestValidateBarcode(t *testing.T) {
+	tests := []struct {
+		code, want, wantErr string
+	}{
+		{"96385074", "96385074", ""},
+		{"036000291452", "036000291452", ""},
+		{"4006381333931", "4006381333931", ""},
+		{"10012345678902", "10012345678902", ""},
+		{"  5901234123457\n", "5901234123457", ""},
+		// Codes of other lengths or with letters have no check digit
+		{"1234567", "1234567", ""},
+		{"123456789", "123456789", ""},
+		{"SKU-00042/B", "SKU-00042/B", ""},
+
+		{"96385075", "", "check digit of 96385075 should be 4"},
+		{"036000291453", "", "check digit of 036000291453 should be 2"},
+		{"4006381333932", "", "check digit of 4006381333932 should be 1"},
+		{"10012345678901", "", "check digit of 10012345678901 should be 2"},
+		{"", "", "1 to 50 characters"},
+		{"   ", "", "1 to 50 characters"},
+		{strings.Repeat("9", 51), "", "1 to 50 characters"},
+		{"SKU 42", "", "characters a scanner cannot read"},
+		{"SKU-é", "", "characters a scanner cannot read"},
+	}
+	for _, tt := range tests {
+		got, err := ValidateBarcode(tt.code)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateBarcode(%q) error = %v, want %q", tt.code, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ValidateBarcode(%q) = %q, %v; want %q", tt.code, got, err, tt.want)
+		}
+	}
+}
+
+func TestBarcodeLookupKeys(t *testing.T) {
+	tests := []struct {
+		code string
+		want []string
+	}{
+		// A UPC-A, and the same number read as EAN-13 or GTIN-14, find each other
+		{"036000291452", []string{"036000291452", "0036000291452", "00036000291452"}},
+		{"0036000291452", []string{"036000291452", "0036000291452", "00036000291452"}},
+		{"00036000291452", []string{"036000291452", "0036000291452", "00036000291452"}},
+		{" 036000291452 ", []string{"036000291452", "0036000291452", "00036000291452"}},
+		// Numbers too long for the shorter forms only pad to the longer ones
+		{"4006381333931", []string{"4006381333931", "04006381333931"}},
+		{"10012345678902", []string{"10012345678902"}},
+		// EAN-8 and other codes are only looked up as they are
+		{"96385074", []string{"96385074"}},
+		{"00000000", []string{"00000000"}},
+		{"SKU-00042", []string{"SKU-00042"}},
+		{"12345", []string{"12345"}},
+	}
+	for _, tt := range tests {
+		if got := BarcodeLookupKeys(tt.code); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("BarcodeLookupKeys(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
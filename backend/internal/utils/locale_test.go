@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := []struct {
+		tag, want string
+		ok        bool
+	}{
+		{"en", "en", true},
+		{"ES-mx", "es-MX", true},
+		{"pt_br", "pt-BR", true},
+		{" fr-CA ", "fr-CA", true},
+		{"zh-hant-tw", "zh-Hant-TW", true},
+		{"SR-LATN", "sr-Latn", true},
+		{"es-419", "es-419", true},
+		{"de-CH-1996", "de-CH-1996", true},
+		{"fil", "fil", true},
+		{"", "", false},
+		{"e", "", false},
+		{"english", "", false},
+		{"en-", "", false},
+		{"en--US", "", false},
+		{"en US", "", false},
+		{"*", "", false},
+		{"en-" + strings.Repeat("abcdefgh-", 4) + "x1", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizeLocale(tt.tag)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizeLocale(%q) = %q, %v; want %q, %v", tt.tag, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"es-MX", []string{"es-MX"}},
+		{"fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5", []string{"fr-CH", "fr", "en", "de"}},
+		// Ordered by q, ties keep the header's order, missing q is 1
+		{"en;q=0.5, es-mx;q=0.9, de, pt_BR;q=0.9", []string{"de", "es-MX", "pt-BR", "en"}},
+		{"en ; q=0.3 , fr ; q=0.1, it;q=0.6", []string{"it", "en", "fr"}},
+		// q=0 means not acceptable; a malformed q counts as 1
+		{"en;q=0, es;q=0.0, fr;q=abc", []string{"fr"}},
+		{"not a locale, ja;q=0.2", []string{"ja"}},
+	}
+	for _, tt := range tests {
+		if got := ParseAcceptLanguage(tt.header); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleFallbackChain(t *testing.T) {
+	tests := []struct {
+		locales []string
+		want    []string
+	}{
+		{[]string{"es-MX", "en"}, []string{"es-MX", "es", "en"}},
+		{[]string{"es-mx", "es-ES", "en-US", "en"}, []string{"es-MX", "es", "es-ES", "en-US", "en"}},
+		{[]string{"zh-Hant-TW"}, []string{"zh-Hant-TW", "zh-Hant", "zh"}},
+		{[]string{"fr", "fr-CA", "fr"}, []string{"fr", "fr-CA"}},
+		{[]string{"bad locale", "", "de"}, []string{"de"}},
+		{nil, []string{}},
+	}
+	for _, tt := range tests {
+		if got := LocaleFallbackChain(tt.locales...); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("LocaleFallbackChain(%v) = %v, want %v", tt.locales, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// nonPublicNets are ranges net.IP has no predicate for: "this network" and the
+// shared address space of carrier-grade NAT, which some clouds use internally
+var nonPublicNets = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return ipNet
+}
+
+// IsPublicIP reports whether ip is a public unicast address, and not a loopback,
+// private, link-local (such as cloud metadata services), unspecified or multicast one
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, ipNet := range nonPublicNets {
+		if ipNet.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsPublicHost reports whether a URL host may be public: a public IP address, or
+// a name other than localhost. Names are checked again when they are resolved,
+// by PublicDialControl.
+func IsPublicHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+		return IsPublicIP(ip)
+	}
+	return host != ""
+}
+
+// PublicDialControl is a net.Dialer Control function that refuses connections to
+// addresses that are not public. It sees the address after name resolution, so a
+// name that resolves, or later re-resolves, to an internal address is refused too.
+func PublicDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if !IsPublicIP(net.ParseIP(host)) {
+		return fmt.Errorf("connection to non-public address %s refused", host)
+	}
+	return nil
+}
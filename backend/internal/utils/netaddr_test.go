@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"8.8.8.8", true},
+		{"2606:4700:4700::1111", true},
+		{"127.0.0.1", false},
+		{"127.1.2.3", false},
+		{"::1", false},
+		{"10.0.0.1", false},
+		{"172.16.5.4", false},
+		{"192.168.1.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false}, // cloud metadata
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"::", false},
+		{"100.64.0.1", false},
+		{"224.0.0.1", false},
+		{"ff02::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := IsPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("IsPublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+	if IsPublicIP(nil) {
+		t.Error("IsPublicIP(nil) = true")
+	}
+}
+
+func TestIsPublicHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"example.com", true},
+		{"hooks.example.com.", true},
+		{"93.184.216.34", true},
+		{"[2606:4700:4700::1111]", true},
+		{"localhost", false},
+		{"LOCALHOST.", false},
+		{"api.localhost", false},
+		{"127.0.0.1", false},
+		{"[::1]", false},
+		{"169.254.169.254", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsPublicHost(tt.host); got != tt.want {
+			t.Errorf("IsPublicHost(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestPublicDialControl(t *testing.T) {
+	tests := []struct {
+		address string
+		wantErr bool
+	}{
+		{"93.184.216.34:443", false},
+		{"[2606:4700:4700::1111]:443", false},
+		{"127.0.0.1:8080", true},
+		{"[::1]:80", true},
+		{"169.254.169.254:80", true},
+		{"10.1.2.3:443", true},
+		{"not-an-address", true},
+	}
+	for _, tt := range tests {
+		if err := PublicDialControl("tcp", tt.address, nil); (err != nil) != tt.wantErr {
+			t.Errorf("PublicDialControl(%s) error = %v, want error %v", tt.address, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Package webhooks sends queued webhook deliveries to the endpoints organizations
+// registered. Each request is signed with the endpoint's secret so receivers can
+// check it came from us and was not replayed.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"flex-erp-poc/internal/database"
+	"flex-erp-poc/internal/models"
+	"flex-erp-poc/internal/utils"
+)
+
+const (
+	// MaxAttempts is how many times a delivery is sent before it is marked failed
+	MaxAttempts = 8
+
+	batchSize       = 20
+	requestTimeout  = 10 * time.Second
+	claimLease      = 2 * time.Minute // longer than a request can take
+	maxResponseBody = 1024            // bytes of the response kept in the delivery log
+)
+
+// Sign returns the signature sent in the X-Webhook-Signature header: the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the endpoint secret
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliveries are retried after 30s, 1m, 2m and so on, capped at 4h
+const (
+	retryBase  = 30 * time.Second
+	retryLimit = 4 * time.Hour
+)
+
+type Dispatcher struct {
+	DB     *database.PostgresService
+	Client *http.Client
+}
+
+// NewDispatcher sends deliveries with a client that only connects to public
+// addresses, whatever the endpoint's host name resolves to at the time, and
+// never through a proxy
+func NewDispatcher(db *database.PostgresService) *Dispatcher {
+	dialer := &net.Dialer{Timeout: requestTimeout, Control: utils.PublicDialControl}
+	return &Dispatcher{
+		DB: db,
+		Client: &http.Client{
+			Timeout: requestTimeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: requestTimeout,
+				MaxIdleConnsPerHost: 2,
+			},
+		},
+	}
+}
+
+// Run sends due deliveries every interval until the process exits
+func (d *Dispatcher) Run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := d.DispatchDue(context.Background()); err != nil {
+			log.Printf("Failed to dispatch webhooks: %v", err)
+		}
+	}
+}
+
+// DispatchDue sends the deliveries that are due, batch by batch, and returns how
+// many it sent
+func (d *Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	sent := 0
+	for {
+		deliveries, err := d.DB.ClaimWebhookDeliveries(ctx, batchSize, claimLease)
+		if err != nil {
+			return sent, err
+		}
+
+		var wg sync.WaitGroup
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func(delivery *models.PendingWebhookDelivery) {
+				defer wg.Done()
+				if err := d.DB.RecordWebhookAttempt(ctx, delivery.ID, d.send(delivery)); err != nil {
+					log.Printf("Failed to record webhook delivery %s: %v", delivery.ID, err)
+				}
+			}(delivery)
+		}
+		wg.Wait()
+
+		sent += len(deliveries)
+		if len(deliveries) < batchSize {
+			return sent, nil
+		}
+	}
+}
+
+// send posts one delivery. Any 2xx response counts as received; redirects are
+// not followed, so a moved endpoint shows up as a failure in the log.
+func (d *Dispatcher) send(delivery *models.PendingWebhookDelivery) models.WebhookAttempt {
+	attempt := models.WebhookAttempt{}
+	fail := func(message string) models.WebhookAttempt {
+		attempt.Error = &message
+		if delivery.Attempts < MaxAttempts {
+			next := time.Now().Add(utils.Backoff(retryBase, retryLimit, delivery.Attempts))
+			attempt.NextAttemptAt = &next
+		}
+		return attempt
+	}
+
+	req, err := http.NewRequest(http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return fail(err.Error())
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "flex-erp-webhooks/1.0")
+	req.Header.Set("X-Webhook-Id", delivery.ID)
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Webhook-Signature", Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	client := *d.Client
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	resp, err := client.Do(req)
+	if err != nil {
+		return fail(err.Error())
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	// Postgres text takes neither invalid UTF-8 nor NUL bytes
+	body = bytes.ReplaceAll(bytes.ToValidUTF8(body, []byte("?")), []byte{0}, nil)
+	status, bodyText := resp.StatusCode, string(body)
+	attempt.ResponseStatus, attempt.ResponseBody = &status, &bodyText
+	if status < 200 || status > 299 {
+		return fail(fmt.Sprintf("endpoint responded with %d", status))
+	}
+	attempt.Succeeded = true
+	return attempt
+}
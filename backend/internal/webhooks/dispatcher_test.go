@@ -0,0 +1,80 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"flex-erp-poc/internal/models"
+	"flex-erp-poc/internal/utils"
+)
+
+// Receivers verify signatures with their own code, so the format must not change
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"sku.created"}`)
+	want := "sha256=03e25b4bd126e163f5e7cbd09f95d92cc99bfe1bc729089f347339f096c94886"
+	if got := Sign("whsec_test", 1700000000, body); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+	if Sign("whsec_test", 1700000001, body) == want {
+		t.Error("signature does not cover the timestamp")
+	}
+	if Sign("whsec_other", 1700000000, body) == want {
+		t.Error("signature does not depend on the secret")
+	}
+}
+
+func TestRetrySchedule(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 4 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, 64 * time.Minute},
+		{9, 128 * time.Minute},
+		{10, 4 * time.Hour},
+		{11, 4 * time.Hour},
+		{100, 4 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := utils.Backoff(retryBase, retryLimit, tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestSendRefusesLoopbackEndpoints(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("internal secrets"))
+	}))
+	defer server.Close()
+
+	// A name that resolves to loopback is refused the same as the address itself
+	urls := []string{server.URL, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)}
+	for _, url := range urls {
+		attempt := NewDispatcher(nil).send(&models.PendingWebhookDelivery{
+			ID: "d1", URL: url, EventType: "sku.created", Secret: "s", Payload: []byte("{}"), Attempts: 1,
+		})
+		if attempt.Succeeded || attempt.Error == nil {
+			t.Fatalf("send to %s succeeded", url)
+		}
+		if !strings.Contains(*attempt.Error, "non-public address") {
+			t.Errorf("send to %s error = %q", url, *attempt.Error)
+		}
+		if attempt.ResponseBody != nil || attempt.NextAttemptAt == nil {
+			t.Errorf("send to %s attempt = %+v", url, attempt)
+		}
+	}
+	if requests != 0 {
+		t.Errorf("server received %d requests", requests)
+	}
+}
@@ -0,0 +1,24 @@
+-- Migration: Create stock_reservations table for available-to-promise quantities
+-- A reservation holds on-hand stock for a pending order so it is no longer
+-- counted as available. OUT transactions can consume a reservation.
+
+CREATE TABLE stock_reservations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    sku_id UUID NOT NULL REFERENCES skus(id),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    consumed_quantity INT NOT NULL DEFAULT 0,
+    reference_number VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'consumed', 'released', 'expired')),
+    expires_at TIMESTAMPTZ,
+    notes TEXT,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT chk_reservation_consumed CHECK (consumed_quantity >= 0 AND consumed_quantity <= quantity)
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_stock_reservations_org_sku_active ON stock_reservations(organization_id, sku_id) WHERE status = 'active';
+CREATE INDEX idx_stock_reservations_expiry ON stock_reservations(expires_at) WHERE status = 'active';
+CREATE INDEX idx_stock_reservations_reference ON stock_reservations(organization_id, reference_number);
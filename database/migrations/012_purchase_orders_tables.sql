@@ -0,0 +1,35 @@
+-- Migration: Create purchase_orders and purchase_order_lines tables
+-- Purchase orders back the "PO-..." reference numbers on IN transactions and
+-- track how much of each line is still outstanding.
+
+CREATE TABLE purchase_orders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    po_number VARCHAR(100) NOT NULL,
+    supplier VARCHAR(255) NOT NULL,
+    status VARCHAR(30) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'sent', 'partially_received', 'received', 'closed')),
+    expected_date DATE,
+    notes TEXT,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (organization_id, po_number)
+);
+
+CREATE TABLE purchase_order_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    purchase_order_id UUID NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    sku_id UUID NOT NULL REFERENCES skus(id),
+    ordered_quantity INT NOT NULL CHECK (ordered_quantity > 0),
+    received_quantity INT NOT NULL DEFAULT 0,
+    unit_cost NUMERIC(12,4) NOT NULL DEFAULT 0.0 CHECK (unit_cost >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT chk_po_line_received CHECK (received_quantity >= 0 AND received_quantity <= ordered_quantity)
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_purchase_orders_org_status ON purchase_orders(organization_id, status);
+CREATE INDEX idx_purchase_orders_org_created ON purchase_orders(organization_id, created_at DESC);
+CREATE INDEX idx_purchase_order_lines_po ON purchase_order_lines(purchase_order_id);
+CREATE INDEX idx_purchase_order_lines_sku ON purchase_order_lines(sku_id);
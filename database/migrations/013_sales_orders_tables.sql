@@ -0,0 +1,46 @@
+-- Migration: Create sales_orders and sales_order_lines tables
+-- Sales orders are the demand side: confirming an order reserves stock and
+-- shipping it posts OUT transactions that consume those reservations.
+
+CREATE TABLE sales_orders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    order_number VARCHAR(100) NOT NULL,
+    customer VARCHAR(255) NOT NULL,
+    status VARCHAR(30) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'confirmed', 'partially_shipped', 'shipped', 'cancelled')),
+    requested_date DATE,
+    notes TEXT,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (organization_id, order_number)
+);
+
+CREATE TABLE sales_order_lines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    sales_order_id UUID NOT NULL REFERENCES sales_orders(id) ON DELETE CASCADE,
+    sku_id UUID NOT NULL REFERENCES skus(id),
+    ordered_quantity INT NOT NULL CHECK (ordered_quantity > 0),
+    shipped_quantity INT NOT NULL DEFAULT 0,
+    unit_price NUMERIC(12,4) NOT NULL DEFAULT 0.0 CHECK (unit_price >= 0),
+    requested_date DATE,
+    reservation_id UUID REFERENCES stock_reservations(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT chk_so_line_shipped CHECK (shipped_quantity >= 0 AND shipped_quantity <= ordered_quantity)
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_sales_orders_org_status ON sales_orders(organization_id, status);
+CREATE INDEX idx_sales_orders_org_created ON sales_orders(organization_id, created_at DESC);
+CREATE INDEX idx_sales_order_lines_so ON sales_order_lines(sales_order_id);
+CREATE INDEX idx_sales_order_lines_sku ON sales_order_lines(sku_id);
+
+-- Record sales order status history in the audit trail
+ALTER TABLE change_logs DROP CONSTRAINT IF EXISTS change_logs_entity_type_check;
+ALTER TABLE change_logs ADD CONSTRAINT change_logs_entity_type_check
+    CHECK (entity_type IN ('sku', 'inventory', 'transaction', 'user', 'field_alias', 'sales_order'));
+
+ALTER TABLE change_logs DROP CONSTRAINT IF EXISTS change_logs_change_type_check;
+ALTER TABLE change_logs ADD CONSTRAINT change_logs_change_type_check
+    CHECK (change_type IN ('create', 'update', 'delete', 'activate', 'deactivate', 'manual_cost_update', 'status_change'));
@@ -0,0 +1,89 @@
+-- Migration: Create suppliers and sku_suppliers tables
+-- Replaces the free-text skus.supplier column with supplier records per
+-- organization. Existing spellings are de-duplicated on a normalized key, so
+-- "ACME Corp.", "Acme Corp" and "acme  corp" become a single supplier.
+
+-- Lowercases the name and collapses punctuation and whitespace runs
+CREATE OR REPLACE FUNCTION supplier_name_key(name TEXT) RETURNS TEXT AS $$
+    SELECT btrim(regexp_replace(lower(name), '[[:punct:][:space:]]+', ' ', 'g'))
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE TABLE suppliers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    contact_name VARCHAR(255),
+    email VARCHAR(255),
+    phone VARCHAR(50),
+    address TEXT,
+    lead_time_days INT CHECK (lead_time_days >= 0),
+    currency CHAR(3) NOT NULL DEFAULT 'USD',
+    notes TEXT,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE sku_suppliers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    sku_id UUID NOT NULL REFERENCES skus(id) ON DELETE CASCADE,
+    supplier_id UUID NOT NULL REFERENCES suppliers(id) ON DELETE CASCADE,
+    supplier_item_code VARCHAR(100),
+    last_purchase_price NUMERIC(12,4) CHECK (last_purchase_price >= 0),
+    minimum_order_quantity INT NOT NULL DEFAULT 1 CHECK (minimum_order_quantity > 0),
+    is_preferred BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (sku_id, supplier_id)
+);
+
+-- Create indexes for better performance
+CREATE UNIQUE INDEX idx_suppliers_org_name_key ON suppliers(organization_id, supplier_name_key(name));
+CREATE INDEX idx_suppliers_org_active ON suppliers(organization_id, is_active);
+CREATE INDEX idx_sku_suppliers_supplier ON sku_suppliers(supplier_id);
+CREATE UNIQUE INDEX idx_sku_suppliers_preferred ON sku_suppliers(sku_id) WHERE is_preferred;
+
+-- Purchase orders keep the supplier name as printed, plus a link to the record
+ALTER TABLE purchase_orders ADD COLUMN supplier_id UUID REFERENCES suppliers(id);
+CREATE INDEX idx_purchase_orders_supplier ON purchase_orders(supplier_id);
+
+-- De-duplicate existing supplier strings, keeping the most used spelling
+INSERT INTO suppliers (organization_id, name)
+SELECT DISTINCT ON (organization_id, supplier_name_key(name)) organization_id, name
+FROM (
+    SELECT organization_id, regexp_replace(btrim(supplier), '\s+', ' ', 'g') AS name, COUNT(*) AS uses
+    FROM (
+        SELECT organization_id, supplier FROM skus
+        UNION ALL
+        SELECT organization_id, supplier FROM purchase_orders
+    ) named
+    WHERE supplier IS NOT NULL AND supplier_name_key(supplier) <> ''
+    GROUP BY 1, 2
+) spellings
+ORDER BY organization_id, supplier_name_key(name), uses DESC, name;
+
+-- Every SKU with a supplier gets it as its preferred supplier
+INSERT INTO sku_suppliers (sku_id, supplier_id, is_preferred)
+SELECT s.id, sup.id, true
+FROM skus s
+JOIN suppliers sup ON sup.organization_id = s.organization_id
+    AND supplier_name_key(sup.name) = supplier_name_key(s.supplier)
+WHERE s.supplier IS NOT NULL;
+
+UPDATE purchase_orders po
+SET supplier_id = sup.id
+FROM suppliers sup
+WHERE sup.organization_id = po.organization_id
+    AND supplier_name_key(sup.name) = supplier_name_key(po.supplier);
+
+-- Last purchase price comes from the most recent PO line per SKU and supplier
+UPDATE sku_suppliers ss
+SET last_purchase_price = latest.unit_cost
+FROM (
+    SELECT DISTINCT ON (l.sku_id, po.supplier_id) l.sku_id, po.supplier_id, l.unit_cost
+    FROM purchase_order_lines l
+    JOIN purchase_orders po ON l.purchase_order_id = po.id
+    WHERE po.supplier_id IS NOT NULL
+    ORDER BY l.sku_id, po.supplier_id, po.created_at DESC
+) latest
+WHERE ss.sku_id = latest.sku_id AND ss.supplier_id = latest.supplier_id;
@@ -0,0 +1,79 @@
+-- Migration: Create categories table
+-- Categories form a tree per organization. Each node stores its full path
+-- ("Electronics/Phones/Cases") and depth so descendant filters and rollups are
+-- a prefix match rather than a recursive query. skus.category is kept as a
+-- copy of the assigned node's path for older clients.
+
+CREATE TABLE categories (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES categories(id) ON DELETE RESTRICT,
+    name VARCHAR(100) NOT NULL CHECK (name <> '' AND position('/' IN name) = 0),
+    path TEXT NOT NULL,
+    depth INT NOT NULL DEFAULT 0 CHECK (depth >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Create indexes for better performance
+CREATE UNIQUE INDEX idx_categories_org_path ON categories(organization_id, lower(path));
+CREATE INDEX idx_categories_parent ON categories(parent_id);
+CREATE INDEX idx_categories_org_depth ON categories(organization_id, depth);
+CREATE INDEX idx_categories_path_prefix ON categories(organization_id, path text_pattern_ops);
+
+ALTER TABLE skus ADD COLUMN category_id UUID REFERENCES categories(id) ON DELETE SET NULL;
+CREATE INDEX idx_skus_category_id ON skus(category_id);
+
+-- Full paths can be longer than a single category name
+ALTER TABLE skus ALTER COLUMN category TYPE TEXT;
+
+-- Migrate free-text categories into the tree. Values such as "Electronics > Phones"
+-- or "Electronics/Phones" become nested nodes; matching is case-insensitive.
+DO $$
+DECLARE
+    rec RECORD;
+    part TEXT;
+    parent UUID;
+    parent_path TEXT;
+    node UUID;
+    node_path TEXT;
+    node_depth INT;
+BEGIN
+    FOR rec IN
+        SELECT DISTINCT organization_id, btrim(category) AS category
+        FROM skus
+        WHERE category IS NOT NULL AND btrim(category) <> ''
+    LOOP
+        parent := NULL;
+        parent_path := NULL;
+        node_depth := 0;
+
+        FOREACH part IN ARRAY regexp_split_to_array(rec.category, '\s*[/>]\s*') LOOP
+            part := left(btrim(part), 100);
+            CONTINUE WHEN part = '';
+
+            node_path := CASE WHEN parent_path IS NULL THEN part ELSE parent_path || '/' || part END;
+            node := NULL;
+            SELECT id, path INTO node, node_path
+            FROM categories
+            WHERE organization_id = rec.organization_id AND lower(path) = lower(node_path);
+
+            IF node IS NULL THEN
+                node_path := CASE WHEN parent_path IS NULL THEN part ELSE parent_path || '/' || part END;
+                INSERT INTO categories (organization_id, parent_id, name, path, depth)
+                VALUES (rec.organization_id, parent, part, node_path, node_depth)
+                RETURNING id INTO node;
+            END IF;
+
+            parent := node;
+            parent_path := node_path;
+            node_depth := node_depth + 1;
+        END LOOP;
+
+        IF parent IS NOT NULL THEN
+            UPDATE skus
+            SET category_id = parent, category = parent_path
+            WHERE organization_id = rec.organization_id AND btrim(category) = rec.category;
+        END IF;
+    END LOOP;
+END $$;
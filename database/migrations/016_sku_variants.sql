@@ -0,0 +1,18 @@
+-- Migration: SKU variants
+-- A parent SKU defines variant attributes (e.g. size and color) and a code
+-- pattern; one child SKU is generated per attribute combination. Stock is held
+-- on the children, the parent only groups them.
+
+ALTER TABLE skus ADD COLUMN parent_sku_id UUID REFERENCES skus(id) ON DELETE RESTRICT;
+ALTER TABLE skus ADD COLUMN variant_attributes JSONB; -- parent: [{"name": "size", "values": ["S", "M"]}]
+ALTER TABLE skus ADD COLUMN variant_code_pattern VARCHAR(100); -- parent: e.g. "{parent}-{size}-{color}"
+ALTER TABLE skus ADD COLUMN variant_values JSONB; -- child: {"size": "S", "color": "Red"}
+
+ALTER TABLE skus ADD CONSTRAINT chk_skus_variant_child CHECK (
+    (parent_sku_id IS NULL AND variant_values IS NULL) OR
+    (parent_sku_id IS NOT NULL AND variant_values IS NOT NULL AND variant_attributes IS NULL)
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_skus_parent ON skus(parent_sku_id);
+CREATE UNIQUE INDEX idx_skus_parent_variant_values ON skus(parent_sku_id, variant_values) WHERE parent_sku_id IS NOT NULL;
@@ -0,0 +1,20 @@
+-- Migration: Create kit_components table
+-- A kit SKU's bill of materials: how many of each component SKU go into one
+-- kit. Assembling issues the components and receives the kit; disassembling
+-- does the reverse.
+
+CREATE TABLE kit_components (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    kit_sku_id UUID NOT NULL REFERENCES skus(id) ON DELETE CASCADE,
+    component_sku_id UUID NOT NULL REFERENCES skus(id) ON DELETE RESTRICT,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (kit_sku_id, component_sku_id),
+    CONSTRAINT chk_kit_not_own_component CHECK (kit_sku_id <> component_sku_id)
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_kit_components_org_kit ON kit_components(organization_id, kit_sku_id);
+CREATE INDEX idx_kit_components_component ON kit_components(component_sku_id);
@@ -0,0 +1,35 @@
+-- Migration: Organization-defined custom fields
+-- Field aliases can only relabel the fixed columns; custom fields add new ones.
+-- Definitions live in custom_fields and the values are stored in a JSONB
+-- column on each entity, keyed by field_name.
+
+CREATE TABLE custom_fields (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    table_name VARCHAR(100) NOT NULL CHECK (table_name IN ('skus', 'inventory', 'inventory_transactions')),
+    field_name VARCHAR(63) NOT NULL CHECK (field_name ~ '^[a-z][a-z0-9_]*$'),
+    display_name VARCHAR(255) NOT NULL,
+    description TEXT,
+    field_type VARCHAR(20) NOT NULL CHECK (field_type IN ('text', 'number', 'date', 'enum', 'boolean')),
+    is_required BOOLEAN NOT NULL DEFAULT false,
+    options JSONB, -- enum: ["Domestic", "Imported"]
+    min_value NUMERIC(15,4), -- number
+    max_value NUMERIC(15,4), -- number
+    max_length INT CHECK (max_length > 0), -- text
+    pattern TEXT, -- text, regular expression the value must match
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (organization_id, table_name, field_name),
+    CONSTRAINT chk_custom_fields_enum_options CHECK (field_type <> 'enum' OR jsonb_array_length(options) > 0)
+);
+
+ALTER TABLE skus ADD COLUMN custom_fields JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE inventory ADD COLUMN custom_fields JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE transactions ADD COLUMN custom_fields JSONB NOT NULL DEFAULT '{}';
+
+-- Create indexes for better performance
+CREATE INDEX idx_custom_fields_org_table ON custom_fields(organization_id, table_name, sort_order);
+CREATE INDEX idx_skus_custom_fields ON skus USING GIN (custom_fields);
+CREATE INDEX idx_inventory_custom_fields ON inventory USING GIN (custom_fields);
+CREATE INDEX idx_transactions_custom_fields ON transactions USING GIN (custom_fields);
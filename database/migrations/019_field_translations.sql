@@ -0,0 +1,26 @@
+-- Migration: Localized field labels
+-- Per-locale display names and descriptions for field aliases and custom fields.
+-- The label stored on the alias or custom field itself is the untranslated
+-- fallback. Labels resolve through the requested locales, then the
+-- organization's default locale, then that fallback.
+
+CREATE TABLE field_translations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    field_alias_id UUID REFERENCES field_aliases(id) ON DELETE CASCADE,
+    custom_field_id UUID REFERENCES custom_fields(id) ON DELETE CASCADE,
+    locale VARCHAR(35) NOT NULL, -- BCP 47 tag, e.g. "es" or "es-MX"
+    display_name VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT chk_field_translations_owner CHECK ((field_alias_id IS NULL) <> (custom_field_id IS NULL))
+);
+
+ALTER TABLE organizations ADD COLUMN default_locale VARCHAR(35) NOT NULL DEFAULT 'en';
+ALTER TABLE users ADD COLUMN preferred_locale VARCHAR(35);
+
+-- Create indexes for better performance
+CREATE UNIQUE INDEX idx_field_translations_alias_locale ON field_translations(field_alias_id, locale) WHERE field_alias_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_field_translations_custom_field_locale ON field_translations(custom_field_id, locale) WHERE custom_field_id IS NOT NULL;
+CREATE INDEX idx_field_translations_org_locale ON field_translations(organization_id, locale);
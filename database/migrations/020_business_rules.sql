@@ -0,0 +1,6 @@
+-- Migration: Per-organization business rules
+-- Stores the inventory rules that were previously only a model type, so they can
+-- be edited per organization and carried in configuration bundles.
+
+ALTER TABLE organizations ADD COLUMN business_rules JSONB NOT NULL
+    DEFAULT '{"allow_negative_inventory": false, "require_reference_number": false, "max_transaction_quantity": 0}';
@@ -0,0 +1,26 @@
+-- Migration: Full-text and fuzzy search
+-- Weighted search vectors for SKUs and transactions, trigram indexes for fuzzy
+-- and substring matches on codes and names, and a barcode lookup index.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE skus ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(sku_code, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(product_name, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B')
+) STORED;
+
+ALTER TABLE transactions ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(reference_number, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(notes, '')), 'B')
+) STORED;
+
+-- Create indexes for better performance
+CREATE INDEX idx_skus_search_vector ON skus USING GIN (search_vector);
+CREATE INDEX idx_skus_sku_code_trgm ON skus USING GIN (LOWER(sku_code) gin_trgm_ops);
+CREATE INDEX idx_skus_product_name_trgm ON skus USING GIN (LOWER(product_name) gin_trgm_ops);
+CREATE INDEX idx_skus_description_trgm ON skus USING GIN (LOWER(description) gin_trgm_ops);
+CREATE INDEX idx_skus_org_barcode ON skus(organization_id, barcode) WHERE barcode IS NOT NULL;
+CREATE INDEX idx_transactions_search_vector ON transactions USING GIN (search_vector);
+CREATE INDEX idx_transactions_reference_trgm ON transactions USING GIN (LOWER(reference_number) gin_trgm_ops);
+CREATE INDEX idx_transactions_notes_trgm ON transactions USING GIN (LOWER(notes) gin_trgm_ops);
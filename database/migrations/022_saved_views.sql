@@ -0,0 +1,23 @@
+-- Migration: Saved views
+-- Named filter, sort and column presets for the SKU, inventory, transaction and
+-- user lists. A view belongs to the user who saved it; shared views are visible
+-- to the whole organization but only their owner can change them.
+
+CREATE TABLE saved_views (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    resource VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    filters JSONB NOT NULL DEFAULT '{}', -- list query parameters
+    sort VARCHAR(100) NOT NULL DEFAULT '',
+    columns TEXT[] NOT NULL DEFAULT '{}',
+    is_shared BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT chk_saved_views_resource CHECK (resource IN ('skus', 'inventory', 'transactions', 'users')),
+    CONSTRAINT uq_saved_views_user_name UNIQUE (organization_id, user_id, resource, name)
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_saved_views_org_resource ON saved_views(organization_id, resource);
@@ -0,0 +1,14 @@
+-- Migration: Bulk SKU actions
+-- Archived SKUs are deactivated and left out of the SKU list unless asked for.
+-- Bulk actions write one change log entry per SKU, grouped by the batch_id in
+-- their metadata.
+
+ALTER TABLE skus ADD COLUMN archived_at TIMESTAMPTZ;
+
+-- Create indexes for better performance
+CREATE INDEX idx_change_logs_batch_id ON change_logs ((metadata->>'batch_id')) WHERE metadata ? 'batch_id';
+
+-- Bulk archiving logs an 'archive' change
+ALTER TABLE change_logs DROP CONSTRAINT IF EXISTS change_logs_change_type_check;
+ALTER TABLE change_logs ADD CONSTRAINT change_logs_change_type_check
+    CHECK (change_type IN ('create', 'update', 'delete', 'activate', 'deactivate', 'archive', 'manual_cost_update', 'status_change'));
@@ -0,0 +1,13 @@
+-- Migration: SKU merges
+-- Merging a duplicate SKU moves its stock and history onto the surviving SKU and
+-- deactivates it, pointing it at the SKU it was merged into.
+
+ALTER TABLE skus ADD COLUMN merged_into_sku_id UUID REFERENCES skus(id) ON DELETE RESTRICT;
+
+-- Create indexes for better performance
+CREATE INDEX idx_skus_merged_into ON skus(merged_into_sku_id) WHERE merged_into_sku_id IS NOT NULL;
+
+-- Merges log a 'merge' change on both SKUs
+ALTER TABLE change_logs DROP CONSTRAINT IF EXISTS change_logs_change_type_check;
+ALTER TABLE change_logs ADD CONSTRAINT change_logs_change_type_check
+    CHECK (change_type IN ('create', 'update', 'delete', 'activate', 'deactivate', 'archive', 'merge', 'manual_cost_update', 'status_change'));
@@ -0,0 +1,35 @@
+-- Migration: SKU barcodes
+-- Alternate barcodes per SKU, each for a unit of measure that a scan counts as
+-- units_per_scan units of stock (a case of 12, an inner pack of 6). Barcodes are
+-- unique within an organization across primary and alternate codes; the
+-- application checks across both tables, the indexes back each table.
+
+CREATE TABLE sku_barcodes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    sku_id UUID NOT NULL REFERENCES skus(id) ON DELETE CASCADE,
+    barcode VARCHAR(50) NOT NULL,
+    unit_of_measure VARCHAR(20) NOT NULL DEFAULT 'each',
+    units_per_scan INT NOT NULL DEFAULT 1 CHECK (units_per_scan > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (organization_id, barcode)
+);
+
+-- Primary barcodes shared by several SKUs stay on the oldest one. Merged SKUs
+-- keep theirs so old labels still resolve to the SKU they were merged into.
+UPDATE skus s SET barcode = NULL, updated_at = now()
+WHERE s.barcode IS NOT NULL AND s.merged_into_sku_id IS NULL
+  AND EXISTS (
+    SELECT 1 FROM skus o
+    WHERE o.organization_id = s.organization_id AND o.barcode = s.barcode
+      AND o.merged_into_sku_id IS NULL
+      AND (o.created_at, o.id) < (s.created_at, s.id)
+  );
+
+UPDATE skus SET barcode = NULL WHERE barcode = '';
+
+-- Create indexes for better performance
+CREATE UNIQUE INDEX idx_skus_org_barcode_unique ON skus(organization_id, barcode)
+    WHERE barcode IS NOT NULL AND merged_into_sku_id IS NULL;
+CREATE INDEX idx_sku_barcodes_sku ON sku_barcodes(sku_id);
@@ -0,0 +1,25 @@
+-- Migration: Label templates
+-- Per-organization label layouts for printing SKU labels as ZPL or PDF sheets.
+-- fields lists what is printed, in order; one template per organization can be
+-- the default used when a print request names none.
+
+CREATE TABLE label_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    width_mm NUMERIC(6,2) NOT NULL CHECK (width_mm > 0),
+    height_mm NUMERIC(6,2) NOT NULL CHECK (height_mm > 0),
+    fields TEXT[] NOT NULL DEFAULT '{}',
+    price_prefix VARCHAR(10) NOT NULL DEFAULT '',
+    dpi INT NOT NULL DEFAULT 203,
+    page_size VARCHAR(20) NOT NULL DEFAULT 'a4',
+    margin_mm NUMERIC(6,2) NOT NULL DEFAULT 5,
+    gap_mm NUMERIC(6,2) NOT NULL DEFAULT 0,
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (organization_id, name)
+);
+
+-- Create indexes for better performance
+CREATE UNIQUE INDEX idx_label_templates_org_default ON label_templates(organization_id) WHERE is_default;
@@ -0,0 +1,23 @@
+-- Migration: Attachments
+-- Files attached to SKUs and transactions, such as product photos and delivery
+-- notes. The contents live in the configured storage under storage_key; this
+-- table keeps the metadata. entity_id points at skus or transactions depending
+-- on entity_type, so it has no foreign key.
+
+CREATE TABLE attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('sku', 'transaction')),
+    entity_id UUID NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL CHECK (size_bytes >= 0),
+    checksum VARCHAR(64) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL UNIQUE,
+    thumbnail_key VARCHAR(255),
+    uploaded_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_attachments_entity ON attachments(organization_id, entity_type, entity_id, created_at);
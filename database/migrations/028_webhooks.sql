@@ -0,0 +1,46 @@
+-- Migration: Webhooks
+-- Endpoints organizations register to be notified of changes, and the log of
+-- every delivery made to them. Deliveries are written when the change is logged
+-- and sent by a background dispatcher, which retries failures with backoff.
+-- A manual redelivery is a new row pointing at the original.
+
+CREATE TABLE webhook_endpoints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    events TEXT[] NOT NULL DEFAULT '{}',
+    secret VARCHAR(100) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    endpoint_id UUID NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ,
+    last_attempt_at TIMESTAMPTZ,
+    response_status INT,
+    response_body TEXT,
+    error TEXT,
+    redelivery_of UUID REFERENCES webhook_deliveries(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Low stock events are off until an organization sets a threshold
+ALTER TABLE organizations ALTER COLUMN business_rules SET DEFAULT
+    '{"allow_negative_inventory": false, "require_reference_number": false, "max_transaction_quantity": 0, "low_stock_threshold": 0}';
+UPDATE organizations SET business_rules = business_rules || '{"low_stock_threshold": 0}'
+WHERE NOT business_rules ? 'low_stock_threshold';
+
+-- Create indexes for better performance
+CREATE INDEX idx_webhook_endpoints_org ON webhook_endpoints(organization_id) WHERE is_active;
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_endpoint ON webhook_deliveries(endpoint_id, created_at DESC);
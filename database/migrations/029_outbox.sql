@@ -0,0 +1,30 @@
+-- Migration: Transactional Outbox
+-- Domain events are written here in the same transaction as the change they
+-- describe, so an event exists if and only if the change committed. A background
+-- dispatcher publishes them to the configured sinks and marks them published.
+-- txid is the writing transaction, used to publish each organization's events in
+-- a stable order that never places a late commit behind published events.
+
+CREATE TABLE outbox_events (
+    sequence BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id UUID NOT NULL,
+    data JSONB NOT NULL,
+    txid XID8 NOT NULL DEFAULT pg_current_xact_id(),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error TEXT,
+    published_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Webhook deliveries are now queued from outbox events, which may be published
+-- more than once; the event ID keeps them to one delivery per endpoint
+CREATE UNIQUE INDEX idx_webhook_deliveries_event ON webhook_deliveries(endpoint_id, event_id) WHERE redelivery_of IS NULL;
+
+-- Create indexes for better performance
+CREATE INDEX idx_outbox_events_pending ON outbox_events(organization_id, txid, sequence) WHERE published_at IS NULL;
+CREATE INDEX idx_outbox_events_published ON outbox_events(published_at) WHERE published_at IS NOT NULL;
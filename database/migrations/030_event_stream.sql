@@ -0,0 +1,13 @@
+-- Migration: Event Stream
+-- Published events get a position from one sequence in the order they are
+-- published, which is the order live streams deliver them. A client reconnecting
+-- with the last position it saw is sent the events published after it.
+-- Change log entries are events too, keyed by their integer ID, so aggregate IDs
+-- are no longer always UUIDs.
+
+CREATE SEQUENCE outbox_events_position_seq;
+ALTER TABLE outbox_events ADD COLUMN position BIGINT;
+ALTER TABLE outbox_events ALTER COLUMN aggregate_id TYPE VARCHAR(64);
+
+-- Create indexes for better performance
+CREATE UNIQUE INDEX idx_outbox_events_position ON outbox_events(organization_id, position) WHERE position IS NOT NULL;
@@ -0,0 +1,59 @@
+-- Migration: Notifications
+-- Users are notified of low stock, large stock movements and being added to an
+-- organization. Each user chooses per notification type whether it shows in the
+-- in-app inbox and whether it is emailed right away, in a daily digest or not at
+-- all. Emails wait in an outbound queue that a background sender retries.
+
+CREATE TABLE notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    notification_type VARCHAR(50) NOT NULL,
+    in_app BOOLEAN NOT NULL,
+    email VARCHAR(20) NOT NULL CHECK (email IN ('off', 'immediate', 'digest')),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, notification_type)
+);
+
+CREATE TABLE notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    notification_type VARCHAR(50) NOT NULL,
+    event_id UUID NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    in_app BOOLEAN NOT NULL,
+    digest_pending BOOLEAN NOT NULL DEFAULT false,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    -- An event published more than once notifies each user once
+    UNIQUE (user_id, event_id, notification_type)
+);
+
+CREATE TABLE notification_emails (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    to_address VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ,
+    last_error TEXT,
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Large movement notifications are off until an organization sets a quantity
+ALTER TABLE organizations ALTER COLUMN business_rules SET DEFAULT
+    '{"allow_negative_inventory": false, "require_reference_number": false, "max_transaction_quantity": 0, "low_stock_threshold": 0, "large_adjustment_quantity": 0}';
+UPDATE organizations SET business_rules = business_rules || '{"large_adjustment_quantity": 0}'
+WHERE NOT business_rules ? 'large_adjustment_quantity';
+
+-- Create indexes for better performance
+CREATE INDEX idx_notifications_inbox ON notifications(user_id, created_at DESC) WHERE in_app;
+CREATE INDEX idx_notifications_unread ON notifications(user_id) WHERE in_app AND read_at IS NULL;
+CREATE INDEX idx_notifications_digest ON notifications(user_id, created_at) WHERE digest_pending;
+CREATE INDEX idx_notification_emails_due ON notification_emails(next_attempt_at) WHERE status = 'pending';
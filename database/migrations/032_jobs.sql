@@ -0,0 +1,39 @@
+-- Migration: Background Jobs
+-- Recurring server work (reservation expiry, notification digests and so on) is
+-- registered in code with a cron schedule and recorded here. Every server instance
+-- runs the scheduler; a job runs on whichever instance takes its advisory lock, so
+-- only one at a time. Schedules and descriptions are written by the code on
+-- startup; paused and run requests are set through the admin API.
+
+CREATE TABLE scheduled_jobs (
+    name VARCHAR(100) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    schedule VARCHAR(100) NOT NULL,
+    max_retries INT NOT NULL DEFAULT 0,
+    paused BOOLEAN NOT NULL DEFAULT false,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    -- A failed run to be retried at next_run_at, and the attempts made so far
+    retry_for TIMESTAMPTZ,
+    retry_attempt INT NOT NULL DEFAULT 0,
+    run_requested_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE job_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_name VARCHAR(100) NOT NULL REFERENCES scheduled_jobs(name) ON DELETE CASCADE,
+    trigger VARCHAR(20) NOT NULL CHECK (trigger IN ('schedule', 'retry', 'manual')),
+    scheduled_for TIMESTAMPTZ NOT NULL,
+    attempt INT NOT NULL DEFAULT 1,
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'succeeded', 'failed')),
+    instance VARCHAR(255) NOT NULL,
+    result TEXT,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_job_runs_job ON job_runs(job_name, started_at DESC);
+CREATE INDEX idx_job_runs_started_at ON job_runs(started_at);
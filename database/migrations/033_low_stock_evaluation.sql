@@ -0,0 +1,17 @@
+-- Migration: Low stock evaluation
+-- low_stock_since marks stock that inventory.low_stock was recorded for, so a
+-- scheduled evaluation only reports stock that fell to the threshold some other
+-- way than an issue (a threshold change, a merge or an import) and each dip is
+-- reported once. It is cleared when the stock is back above the threshold.
+
+ALTER TABLE inventory ADD COLUMN low_stock_since TIMESTAMPTZ;
+
+-- Stock already low predates the evaluation and is not reported again
+UPDATE inventory i SET low_stock_since = now()
+FROM organizations o
+WHERE o.id = i.organization_id
+    AND COALESCE((o.business_rules->>'low_stock_threshold')::int, 0) > 0
+    AND i.quantity <= (o.business_rules->>'low_stock_threshold')::int;
+
+-- Create indexes for better performance
+CREATE INDEX idx_inventory_low_stock_since ON inventory(organization_id) WHERE low_stock_since IS NOT NULL;
@@ -61,7 +61,8 @@ const inventoryAPI = {
       throw new Error('Failed to fetch inventory');
     }
 
-    return response.json();
+    const data = await response.json();
+    return data.items;
   },
 
   updateManualCost: async (
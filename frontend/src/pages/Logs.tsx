@@ -127,7 +127,8 @@ export default function Logs() {
       if (!response.ok) {
         throw new Error('Failed to fetch change logs');
       }
-      return response.json();
+      const data = await response.json();
+      return data.items;
     },
     enabled: !!orgId && !!token,
   });
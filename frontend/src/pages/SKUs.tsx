@@ -47,7 +47,7 @@ const skuAPI = {
   list: async (
     params: SKUListParams = {},
     orgId: string
-  ): Promise<{ skus: SKU[] }> => {
+  ): Promise<{ items: SKU[]; total: number }> => {
     const token = localStorage.getItem('auth_token');
     const queryParams = new URLSearchParams();
 
@@ -197,7 +197,7 @@ export function SKUs() {
     }));
   };
 
-  const skus = data?.skus || [];
+  const skus = data?.items || [];
   const categories = [
     ...new Set(skus.map((sku) => sku.category).filter(Boolean)),
   ];
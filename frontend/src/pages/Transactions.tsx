@@ -91,7 +91,8 @@ const transactionAPI = {
       throw new Error('Failed to fetch transactions');
     }
 
-    return response.json();
+    const data = await response.json();
+    return data.items;
   },
 
   create: async (
@@ -150,7 +151,7 @@ const transactionAPI = {
 };
 
 const skuAPI = {
-  list: async (orgId: string): Promise<{ skus: SKU[] }> => {
+  list: async (orgId: string): Promise<{ items: SKU[] }> => {
     const token = localStorage.getItem('auth_token');
     const response = await fetch(
       `http://localhost:8080/api/v1/orgs/${orgId}/skus`,
@@ -199,7 +200,7 @@ export function Transactions() {
     queryFn: () => skuAPI.list(authState.organization?.id!),
   });
 
-  const skus = skusData?.skus || [];
+  const skus = skusData?.items || [];
 
   const createMutation = useMutation({
     mutationFn: (data: CreateTransactionRequest) =>
@@ -57,18 +57,22 @@ export function Users() {
   const queryClient = useQueryClient(); // add this to context
   const { state } = useAuth();
 
-  const fetchUsers = async (): Promise<{
-    users: UserWithDetails[];
-    pagination: any;
+  const fetchUsers = async (
+    isActive: string,
+    limit?: number
+  ): Promise<{
+    items: UserWithDetails[];
+    total: number;
   }> => {
     if (!state.token || !state.organization) {
-      return { users: [], pagination: {} };
+      return { items: [], total: 0 };
     }
 
     const params = new URLSearchParams();
     if (selectedRole) params.append('role', selectedRole);
-    if (isActiveFilter !== 'all') params.append('is_active', isActiveFilter);
+    if (isActive !== 'all') params.append('is_active', isActive);
     if (searchQuery) params.append('search', searchQuery);
+    if (limit) params.append('limit', limit.toString());
 
     const response = await fetch(
       `http://localhost:8080/api/v1/orgs/${
@@ -113,10 +117,17 @@ export function Users() {
 
   const { data: usersData, isLoading } = useQuery({
     queryKey: ['users', selectedRole, isActiveFilter, searchQuery],
-    queryFn: fetchUsers,
+    queryFn: () => fetchUsers(isActiveFilter),
     refetchOnMount: 'always',
   });
 
+  // The list holds one page, so active users are counted by the server
+  const { data: activeUsersData } = useQuery({
+    queryKey: ['users', selectedRole, 'true', searchQuery, 'count'],
+    queryFn: () => fetchUsers('true', 1),
+    enabled: isActiveFilter === 'all',
+  });
+
   const { data: rolesData } = useQuery({
     queryKey: ['user-roles'],
     queryFn: fetchUserRoles,
@@ -285,10 +296,15 @@ export function Users() {
     }
   };
 
-  const users = usersData?.users || [];
+  const users = usersData?.items || [];
   const roles = rolesData?.roles || [];
-  const activeUsersCount = users.filter((u) => u.is_active).length;
-  const totalUsers = users.length;
+  const totalUsers = usersData?.total ?? 0;
+  const activeUsersCount =
+    isActiveFilter === 'all'
+      ? activeUsersData?.total ?? 0
+      : isActiveFilter === 'true'
+      ? totalUsers
+      : 0;
 
   if (isLoading) {
     return (